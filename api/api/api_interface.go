@@ -74,6 +74,7 @@ type ServiceInterface interface {
 	RollBack(w http.ResponseWriter, r *http.Request)
 	AddVolume(w http.ResponseWriter, r *http.Request)
 	UpdVolume(w http.ResponseWriter, r *http.Request)
+	UpdVolumeCapacity(w http.ResponseWriter, r *http.Request)
 	DeleteVolume(w http.ResponseWriter, r *http.Request)
 	Pods(w http.ResponseWriter, r *http.Request)
 	VolumeDependency(w http.ResponseWriter, r *http.Request)
@@ -85,6 +86,13 @@ type ServiceInterface interface {
 	GetDeployVersion(w http.ResponseWriter, r *http.Request)
 	AutoscalerRules(w http.ResponseWriter, r *http.Request)
 	ScalingRecords(w http.ResponseWriter, r *http.Request)
+	AutoscalerSchedules(w http.ResponseWriter, r *http.Request)
+	IdlePolicy(w http.ResponseWriter, r *http.Request)
+	VolumeBackups(w http.ResponseWriter, r *http.Request)
+	DeleteVolumeBackup(w http.ResponseWriter, r *http.Request)
+	RestoreVolume(w http.ResponseWriter, r *http.Request)
+	VolumeBackupPolicy(w http.ResponseWriter, r *http.Request)
+	DeleteAutoscalerSchedule(w http.ResponseWriter, r *http.Request)
 	AddServiceMonitors(w http.ResponseWriter, r *http.Request)
 	DeleteServiceMonitors(w http.ResponseWriter, r *http.Request)
 	UpdateServiceMonitors(w http.ResponseWriter, r *http.Request)
@@ -108,6 +116,8 @@ type TenantInterfaceWithV1 interface {
 //LogInterface log interface
 type LogInterface interface {
 	HistoryLogs(w http.ResponseWriter, r *http.Request)
+	SearchLogs(w http.ResponseWriter, r *http.Request)
+	TenantSearchLogs(w http.ResponseWriter, r *http.Request)
 	LogList(w http.ResponseWriter, r *http.Request)
 	LogFile(w http.ResponseWriter, r *http.Request)
 	LogSocket(w http.ResponseWriter, r *http.Request)
@@ -176,6 +186,7 @@ type ApplicationInterface interface {
 	DeleteConfigGroup(w http.ResponseWriter, r *http.Request)
 	ListConfigGroups(w http.ResponseWriter, r *http.Request)
 	SyncComponents(w http.ResponseWriter, r *http.Request)
+	ApplyApp(w http.ResponseWriter, r *http.Request)
 	SyncAppConfigGroups(w http.ResponseWriter, r *http.Request)
 	ListAppStatuses(w http.ResponseWriter, r *http.Request)
 }
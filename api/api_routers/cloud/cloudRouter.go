@@ -21,11 +21,15 @@ package cloud
 import (
 	"github.com/go-chi/chi"
 	"github.com/gridworkz/kato/api/controller"
+	"github.com/gridworkz/kato/api/middleware"
+	"github.com/gridworkz/kato/api/rbac"
 )
 
 //Routes routes
 func Routes() chi.Router {
 	r := chi.NewRouter()
+	// the tokens of the console are not scoped
+	r.Use(middleware.Authorize(rbac.ResourceCluster))
 	r.Get("/show", controller.GetCloudRouterManager().Show)
 	r.Post("/auth", controller.GetCloudRouterManager().CreateToken)
 	r.Get("/auth/{eid}", controller.GetCloudRouterManager().GetTokenInfo)
@@ -22,6 +22,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/gridworkz/kato/api/controller"
 	"github.com/gridworkz/kato/api/middleware"
+	"github.com/gridworkz/kato/api/rbac"
 	"github.com/gridworkz/kato/cmd/api/option"
 	dbmodel "github.com/gridworkz/kato/db/model"
 )
@@ -36,32 +37,57 @@ func (v2 *V2) Routes() chi.Router {
 	r := chi.NewRouter()
 	license := middleware.NewLicense(v2.Cfg)
 	r.Use(license.Verify)
+	// the routes without a resource are open to any valid token
 	r.Get("/show", controller.GetManager().Show)
 	r.Post("/show", controller.GetManager().Show)
-	r.Mount("/tenants", v2.tenantRouter())
-	r.Mount("/cluster", v2.clusterRouter())
-	r.Mount("/notificationEvent", v2.notificationEventRouter())
-	r.Mount("/resources", v2.resourcesRouter())
-	r.Mount("/prometheus", v2.prometheusRouter())
-	r.Get("/event", controller.GetManager().Event)
-	r.Mount("/app", v2.appRouter())
 	r.Get("/health", controller.GetManager().Health)
-	r.Post("/alertmanager-webhook", controller.GetManager().AlertManagerWebHook)
 	r.Get("/version", controller.GetManager().Version)
-	// deprecated use /gateway/ports
-	r.Mount("/port", v2.portRouter())
-	// deprecated, use /events/<event_id>/log
-	r.Get("/event-log", controller.GetManager().LogByAction)
 	r.Mount("/events", v2.eventsRouter())
-	r.Get("/gateway/ips", controller.GetGatewayIPs)
-	r.Get("/gateway/ports", controller.GetManager().GetAvailablePort)
-	r.Get("/volume-options", controller.VolumeOptions)
-	r.Get("/volume-options/page/{page}/size/{pageSize}", controller.ListVolumeType)
-	r.Post("/volume-options", controller.VolumeSetVar)
-	r.Delete("/volume-options/{volume_type}", controller.DeleteVolumeType)
-	r.Put("/volume-options/{volume_type}", controller.UpdateVolumeType)
+	r.Mount("/tenants", v2.tenantRouter())
+	r.Mount("/app", v2.appRouter())
 	r.Mount("/enterprise/{enterprise_id}", v2.enterpriseRouter())
-	r.Mount("/monitor", v2.monitorRouter())
+	r.Mount("/tokens", v2.tokenRouter())
+	r.Mount("/audit-logs", v2.auditRouter())
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authorize(rbac.ResourceCluster))
+		r.Mount("/cluster", v2.clusterRouter())
+		r.Mount("/notificationEvent", v2.notificationEventRouter())
+		r.Mount("/resources", v2.resourcesRouter())
+		r.Mount("/prometheus", v2.prometheusRouter())
+		r.Post("/alertmanager-webhook", controller.GetManager().AlertManagerWebHook)
+		// the event ids in the body can not be scoped
+		r.Get("/event", controller.GetManager().Event)
+		// deprecated, use /events/<event_id>/log
+		r.Get("/event-log", controller.GetManager().LogByAction)
+		// deprecated use /gateway/ports
+		r.Mount("/port", v2.portRouter())
+		r.Get("/gateway/ips", controller.GetGatewayIPs)
+		r.Get("/gateway/ports", controller.GetManager().GetAvailablePort)
+		r.Get("/volume-options", controller.VolumeOptions)
+		r.Get("/volume-options/page/{page}/size/{pageSize}", controller.ListVolumeType)
+		r.Post("/volume-options", controller.VolumeSetVar)
+		r.Delete("/volume-options/{volume_type}", controller.DeleteVolumeType)
+		r.Put("/volume-options/{volume_type}", controller.UpdateVolumeType)
+		r.Mount("/monitor", v2.monitorRouter())
+	})
+	return r
+}
+
+func (v2 *V2) tokenRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Authorize(rbac.ResourceToken))
+	r.Get("/", controller.ListAPITokens)
+	r.Post("/", controller.CreateAPIToken)
+	r.Delete("/{name}", controller.RevokeAPIToken)
+	return r
+}
+
+func (v2 *V2) auditRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Authorize(rbac.ResourceAudit))
+	r.Get("/", controller.ListAuditLogs)
+	r.Get("/export", controller.ExportAuditLogs)
+	r.Get("/verify", controller.VerifyAuditLogs)
 	return r
 }
 
@@ -73,16 +99,19 @@ func (v2 *V2) monitorRouter() chi.Router {
 
 func (v2 *V2) enterpriseRouter() chi.Router {
 	r := chi.NewRouter()
+	r.Use(middleware.Authorize(rbac.ResourceEnterprise))
 	r.Get("/running-services", controller.GetRunningServices)
 	return r
 }
 
 func (v2 *V2) eventsRouter() chi.Router {
 	r := chi.NewRouter()
+	// the tenant and the service of the events are authorized
+	authorize := r.With(middleware.InitEvent, middleware.Authorize(rbac.ResourceService))
 	// get target's event list with page
-	r.Get("/", controller.GetManager().Events)
+	authorize.Get("/", controller.GetManager().Events)
 	// get target's event content
-	r.Get("/{eventID}/log", controller.GetManager().EventLog)
+	authorize.Get("/{eventID}/log", controller.GetManager().EventLog)
 	return r
 }
 
@@ -99,10 +128,13 @@ func (v2 *V2) clusterRouter() chi.Router {
 
 func (v2 *V2) tenantRouter() chi.Router {
 	r := chi.NewRouter()
-	r.Post("/", controller.GetManager().Tenants)
 	r.Mount("/{tenant_name}", v2.tenantNameRouter())
-	r.Get("/", controller.GetManager().Tenants)
-	r.Get("/services-count", controller.GetManager().ServicesCount)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authorize(rbac.ResourceEnterprise))
+		r.Post("/", controller.GetManager().Tenants)
+		r.Get("/", controller.GetManager().Tenants)
+		r.Get("/services-count", controller.GetManager().ServicesCount)
+	})
 	return r
 }
 
@@ -110,70 +142,76 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 	r := chi.NewRouter()
 	//Initialize tenant and service letter
 	r.Use(middleware.InitTenant)
-	r.Put("/", controller.GetManager().Tenant)
-	r.Get("/", controller.GetManager().Tenant)
-	r.Delete("/", controller.GetManager().Tenant)
-	//Logs in the tenant
-	r.Post("/event-log", controller.GetManager().TenantLogByAction)
-	r.Get("/protocols", controller.GetManager().GetSupportProtocols)
-	//Plug-in pre-installation
-	r.Post("/transplugins", controller.GetManager().TransPlugins)
-	//Code detection
-	r.Post("/code-check", controller.GetManager().CheckCode)
-	r.Post("/servicecheck", controller.Check)
-	r.Get("/servicecheck/{uuid}", controller.GetServiceCheckInfo)
-	r.Get("/resources", controller.GetManager().SingleTenantResources)
-	r.Get("/services", controller.GetManager().ServicesInfo)
-	//Create application
-	r.Post("/services", middleware.WrapEL(controller.GetManager().CreateService, dbmodel.TargetTypeService, "create-service", dbmodel.SYNEVENTTYPE))
-	r.Post("/plugin", controller.GetManager().PluginAction)
-	r.Post("/plugins/{plugin_id}/share", controller.GetManager().SharePlugin)
-	r.Get("/plugins/{plugin_id}/share/{share_id}", controller.GetManager().SharePluginResult)
-	r.Get("/plugin", controller.GetManager().PluginAction)
-	// batch install and build plugins
-	r.Post("/plugins", controller.GetManager().BatchInstallPlugins)
-	r.Post("/batch-build-plugins", controller.GetManager().BatchBuildPlugins)
-	r.Post("/services_status", controller.GetManager().StatusServiceList)
+	// the components and the apps are authorized by their routers
 	r.Mount("/services/{service_alias}", v2.serviceRouter())
-	r.Mount("/plugin/{plugin_id}", v2.pluginRouter())
-	r.Get("/event", controller.GetManager().Event)
-	r.Get("/chargesverify", controller.ChargesVerifyController)
-	//tenant app
-	r.Get("/pods/{pod_name}", controller.GetManager().PodDetail)
-	r.Post("/apps", controller.GetManager().CreateApp)
-	r.Post("/batch_create_apps", controller.GetManager().BatchCreateApp)
-	r.Get("/apps", controller.GetManager().ListApps)
-	r.Post("/checkResourceName", controller.GetManager().CheckResourceName)
-	r.Get("/appstatuses", controller.GetManager().ListAppStatuses)
 	r.Mount("/apps/{app_id}", v2.applicationRouter())
-	//get some service pod info
-	r.Get("/pods", controller.Pods)
-	r.Get("/pod_nums", controller.PodNums)
-	//app backup
-	r.Get("/groupapp/backups", controller.Backups)
-	r.Post("/groupapp/backups", controller.NewBackups)
-	r.Post("/groupapp/backupcopy", controller.BackupCopy)
-	r.Get("/groupapp/backups/{backup_id}", controller.GetBackup)
-	r.Delete("/groupapp/backups/{backup_id}", controller.DeleteBackup)
-	r.Post("/groupapp/backups/{backup_id}/restore", controller.Restore)
-	r.Get("/groupapp/backups/{backup_id}/restore/{restore_id}", controller.RestoreResult)
-	r.Post("/deployversions", controller.GetManager().GetManyDeployVersion)
-	//Team resource limit
-	r.Post("/limit_memory", controller.GetManager().LimitTenantMemory)
-	r.Get("/limit_memory", controller.GetManager().TenantResourcesStatus)
-
-	// Gateway
-	r.Post("/http-rule", controller.GetManager().HTTPRule)
-	r.Delete("/http-rule", controller.GetManager().HTTPRule)
-	r.Put("/http-rule", controller.GetManager().HTTPRule)
-	r.Post("/tcp-rule", controller.GetManager().TCPRule)
-	r.Delete("/tcp-rule", controller.GetManager().TCPRule)
-	r.Put("/tcp-rule", controller.GetManager().TCPRule)
-	r.Mount("/gateway", v2.gatewayRouter())
-
-	//batch operation
-	r.Post("/batchoperation", controller.BatchOperation)
-
+	// the queries sent by POST
+	r.With(middleware.AuthorizeRead(rbac.ResourceTenant)).Post("/services_status", controller.GetManager().StatusServiceList)
+	r.With(middleware.AuthorizeRead(rbac.ResourceTenant)).Post("/deployversions", controller.GetManager().GetManyDeployVersion)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authorize(rbac.ResourceTenant))
+		r.Put("/", controller.GetManager().Tenant)
+		r.Get("/", controller.GetManager().Tenant)
+		r.With(middleware.Authorize(rbac.ResourceEnterprise)).Delete("/", controller.GetManager().Tenant)
+		//Logs in the tenant
+		r.Post("/event-log", controller.GetManager().TenantLogByAction)
+		r.Get("/logs/search", controller.GetManager().TenantSearchLogs)
+		r.Get("/protocols", controller.GetManager().GetSupportProtocols)
+		//Plug-in pre-installation
+		r.Post("/transplugins", controller.GetManager().TransPlugins)
+		//Code detection
+		r.Post("/code-check", controller.GetManager().CheckCode)
+		r.Post("/servicecheck", controller.Check)
+		r.Get("/servicecheck/{uuid}", controller.GetServiceCheckInfo)
+		r.Get("/resources", controller.GetManager().SingleTenantResources)
+		r.Get("/services", controller.GetManager().ServicesInfo)
+		//Create application
+		r.Post("/services", middleware.WrapEL(controller.GetManager().CreateService, dbmodel.TargetTypeService, "create-service", dbmodel.SYNEVENTTYPE))
+		r.Post("/plugin", controller.GetManager().PluginAction)
+		r.Post("/plugins/{plugin_id}/share", controller.GetManager().SharePlugin)
+		r.Get("/plugins/{plugin_id}/share/{share_id}", controller.GetManager().SharePluginResult)
+		r.Get("/plugin", controller.GetManager().PluginAction)
+		// batch install and build plugins
+		r.Post("/plugins", controller.GetManager().BatchInstallPlugins)
+		r.Post("/batch-build-plugins", controller.GetManager().BatchBuildPlugins)
+		r.Mount("/plugin/{plugin_id}", v2.pluginRouter())
+		r.Get("/event", controller.GetManager().Event)
+		r.Get("/chargesverify", controller.ChargesVerifyController)
+		//tenant app
+		r.Get("/pods/{pod_name}", controller.GetManager().PodDetail)
+		r.Post("/apps", controller.GetManager().CreateApp)
+		r.Post("/batch_create_apps", controller.GetManager().BatchCreateApp)
+		r.Get("/apps", controller.GetManager().ListApps)
+		r.Post("/checkResourceName", controller.GetManager().CheckResourceName)
+		r.Get("/appstatuses", controller.GetManager().ListAppStatuses)
+		//get some service pod info
+		r.Get("/pods", controller.Pods)
+		r.Get("/pod_nums", controller.PodNums)
+		//app backup
+		r.Get("/groupapp/backups", controller.Backups)
+		r.Post("/groupapp/backups", controller.NewBackups)
+		r.Post("/groupapp/backupcopy", controller.BackupCopy)
+		r.Get("/groupapp/backups/{backup_id}", controller.GetBackup)
+		r.Delete("/groupapp/backups/{backup_id}", controller.DeleteBackup)
+		r.Post("/groupapp/backups/{backup_id}/restore", controller.Restore)
+		r.Get("/groupapp/backups/{backup_id}/restore/{restore_id}", controller.RestoreResult)
+		//Team resource limit
+		r.With(middleware.Authorize(rbac.ResourceEnterprise)).Post("/limit_memory", controller.GetManager().LimitTenantMemory)
+		r.Get("/limit_memory", controller.GetManager().TenantResourcesStatus)
+		r.With(middleware.Authorize(rbac.ResourceEnterprise)).Put("/quota", controller.UpdateTenantQuota)
+
+		// Gateway
+		r.Post("/http-rule", controller.GetManager().HTTPRule)
+		r.Delete("/http-rule", controller.GetManager().HTTPRule)
+		r.Put("/http-rule", controller.GetManager().HTTPRule)
+		r.Post("/tcp-rule", controller.GetManager().TCPRule)
+		r.Delete("/tcp-rule", controller.GetManager().TCPRule)
+		r.Put("/tcp-rule", controller.GetManager().TCPRule)
+		r.Mount("/gateway", v2.gatewayRouter())
+
+		//batch operation
+		r.Post("/batchoperation", controller.BatchOperation)
+	})
 	return r
 }
 
@@ -188,6 +226,7 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r := chi.NewRouter()
 	//Initialize application information
 	r.Use(middleware.InitService)
+	r.Use(middleware.Authorize(rbac.ResourceService))
 	r.Put("/", middleware.WrapEL(controller.GetManager().UpdateService, dbmodel.TargetTypeService, "update-service", dbmodel.SYNEVENTTYPE))
 	// component build
 	r.Post("/build", middleware.WrapEL(controller.GetManager().BuildService, dbmodel.TargetTypeService, "build-service", dbmodel.ASYNEVENTTYPE))
@@ -220,6 +259,7 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Post("/share", middleware.WrapEL(controller.GetManager().Share, dbmodel.TargetTypeService, "share-service", dbmodel.SYNEVENTTYPE))
 	r.Get("/share/{share_id}", controller.GetManager().ShareResult)
 	r.Get("/logs", controller.GetManager().HistoryLogs)
+	r.Get("/logs/search", controller.GetManager().SearchLogs)
 	r.Get("/log-file", controller.GetManager().LogList)
 	r.Get("/log-instance", controller.GetManager().LogSocket)
 	r.Post("/event-log", controller.GetManager().LogByAction)
@@ -247,6 +287,13 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Put("/volumes", middleware.WrapEL(controller.GetManager().UpdVolume, dbmodel.TargetTypeService, "update-service-volume", dbmodel.SYNEVENTTYPE))
 	r.Get("/volumes", controller.GetVolume)
 	r.Delete("/volumes/{volume_name}", middleware.WrapEL(controller.DeleteVolume, dbmodel.TargetTypeService, "delete-service-volume", dbmodel.SYNEVENTTYPE))
+	r.Put("/volumes/{volume_name}/capacity", middleware.WrapEL(controller.GetManager().UpdVolumeCapacity, dbmodel.TargetTypeService, "expand-service-volume", dbmodel.ASYNEVENTTYPE))
+	r.Get("/volumes/{volume_name}/backups", controller.GetManager().VolumeBackups)
+	r.Post("/volumes/{volume_name}/backups", middleware.WrapEL(controller.GetManager().VolumeBackups, dbmodel.TargetTypeService, "backup-service-volume", dbmodel.ASYNEVENTTYPE))
+	r.Delete("/volumes/{volume_name}/backups/{backup_id}", middleware.WrapEL(controller.GetManager().DeleteVolumeBackup, dbmodel.TargetTypeService, "delete-service-volume-backup", dbmodel.ASYNEVENTTYPE))
+	r.Post("/volumes/{volume_name}/restore", middleware.WrapEL(controller.GetManager().RestoreVolume, dbmodel.TargetTypeService, "restore-service-volume", dbmodel.ASYNEVENTTYPE))
+	r.Get("/volumes/{volume_name}/backup-policy", controller.GetManager().VolumeBackupPolicy)
+	r.Put("/volumes/{volume_name}/backup-policy", middleware.WrapEL(controller.GetManager().VolumeBackupPolicy, dbmodel.TargetTypeService, "update-service-volume-backup-policy", dbmodel.SYNEVENTTYPE))
 	r.Post("/depvolumes", middleware.WrapEL(controller.AddVolumeDependency, dbmodel.TargetTypeService, "add-service-depvolume", dbmodel.SYNEVENTTYPE))
 	r.Delete("/depvolumes", middleware.WrapEL(controller.DeleteVolumeDependency, dbmodel.TargetTypeService, "delete-service-depvolume", dbmodel.SYNEVENTTYPE))
 	r.Get("/depvolumes", controller.GetDepVolume)
@@ -299,6 +346,12 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Post("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "add-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Put("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "update-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Get("/xparecords", controller.GetManager().ScalingRecords)
+	r.Get("/xpaschedules", controller.GetManager().AutoscalerSchedules)
+	r.Post("/xpaschedules", middleware.WrapEL(controller.GetManager().AutoscalerSchedules, dbmodel.TargetTypeService, "add-app-autoscaler-schedule", dbmodel.SYNEVENTTYPE))
+	r.Put("/xpaschedules", middleware.WrapEL(controller.GetManager().AutoscalerSchedules, dbmodel.TargetTypeService, "update-app-autoscaler-schedule", dbmodel.SYNEVENTTYPE))
+	r.Delete("/xpaschedules/{schedule_id}", middleware.WrapEL(controller.GetManager().DeleteAutoscalerSchedule, dbmodel.TargetTypeService, "delete-app-autoscaler-schedule", dbmodel.SYNEVENTTYPE))
+	r.Get("/idle-policy", controller.GetManager().IdlePolicy)
+	r.Put("/idle-policy", middleware.WrapEL(controller.GetManager().IdlePolicy, dbmodel.TargetTypeService, "update-app-idle-policy", dbmodel.SYNEVENTTYPE))
 
 	//service monitor
 	r.Post("/service-monitors", middleware.WrapEL(controller.GetManager().AddServiceMonitors, dbmodel.TargetTypeService, "add-app-service-monitor", dbmodel.SYNEVENTTYPE))
@@ -314,6 +367,7 @@ func (v2 *V2) applicationRouter() chi.Router {
 	r := chi.NewRouter()
 	// Init Application
 	r.Use(middleware.InitApplication)
+	r.Use(middleware.Authorize(rbac.ResourceApp))
 	// Operation application
 	r.Put("/", controller.GetManager().UpdateApp)
 	r.Delete("/", controller.GetManager().DeleteApp)
@@ -336,6 +390,8 @@ func (v2 *V2) applicationRouter() chi.Router {
 
 	// Synchronize component information, full coverage
 	r.Post("/components", controller.GetManager().SyncComponents)
+	// Declarative apply, ?dry_run=true only returns the diff
+	r.Post("/apply", controller.GetManager().ApplyApp)
 	r.Post("/app-config-groups", controller.GetManager().SyncAppConfigGroups)
 	return r
 }
@@ -361,6 +417,7 @@ func (v2 *V2) prometheusRouter() chi.Router {
 
 func (v2 *V2) appRouter() chi.Router {
 	r := chi.NewRouter()
+	r.Use(middleware.Authorize(rbac.ResourceEnterprise))
 	r.Post("/export", controller.GetManager().ExportApp)
 	r.Get("/export/{eventID}", controller.GetManager().ExportApp)
 
@@ -0,0 +1,68 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/middleware"
+	"github.com/gridworkz/kato/api/model"
+	httputil "github.com/gridworkz/kato/util/http"
+)
+
+// ListAPITokens lists the api tokens within the scope of the request
+func ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := handler.GetAPITokenHandler().ListTokens(middleware.GetPrincipal(r))
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, tokens)
+}
+
+// CreateAPIToken creates an api token, the token is only returned here
+func CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateAPITokenReq
+	if err := httputil.ReadEntity(r, &req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	if err := httputil.ValidateStruct(&req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	token, err := handler.GetAPITokenHandler().CreateToken(middleware.GetPrincipal(r), &req)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, token)
+}
+
+// RevokeAPIToken revokes the api token
+func RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	if err := handler.GetAPITokenHandler().RevokeToken(middleware.GetPrincipal(r), chi.URLParam(r, "name")); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
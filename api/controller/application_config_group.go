@@ -124,6 +124,22 @@ func (a *ApplicationController) SyncComponents(w http.ResponseWriter, r *http.Re
 	httputil.ReturnSuccess(r, w, nil)
 }
 
+// ApplyApp applies the full component spec of the application, or only diffs it with dry_run=true.
+func (a *ApplicationController) ApplyApp(w http.ResponseWriter, r *http.Request) {
+	var req model.ApplyAppReq
+	app := r.Context().Value(ctxutil.ContextKey("application")).(*dbmodel.Application)
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	diff, err := handler.GetApplicationHandler().ApplyApp(app, req.Components, dryRun)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, diff)
+}
+
 // SyncAppConfigGroups -
 func (a *ApplicationController) SyncAppConfigGroups(w http.ResponseWriter, r *http.Request) {
 	var syncAppConfigGroupReq model.SyncAppConfigGroup
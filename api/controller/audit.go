@@ -0,0 +1,85 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/model"
+	httputil "github.com/gridworkz/kato/util/http"
+	"github.com/sirupsen/logrus"
+)
+
+// ListAuditLogs lists the audit logs, the times of the query are in RFC3339
+func ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	query := model.AuditLogQuery{
+		TenantID:  values.Get("tenant_id"),
+		ServiceID: values.Get("service_id"),
+		Principal: values.Get("principal"),
+	}
+	for key, t := range map[string]*time.Time{"start": &query.Start, "end": &query.End} {
+		if values.Get(key) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, values.Get(key))
+		if err != nil {
+			httputil.ReturnError(r, w, 400, "invalid "+key+" time: "+err.Error())
+			return
+		}
+		*t = parsed
+	}
+	query.Page, _ = strconv.Atoi(values.Get("page"))
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	query.PageSize, _ = strconv.Atoi(values.Get("page_size"))
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	logs, total, err := handler.GetAuditHandler().ListAuditLogs(&query)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnList(r, w, int(total), query.Page, logs)
+}
+
+// ExportAuditLogs exports all the audit logs in JSON lines
+func ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.jsonl")
+	if err := handler.GetAuditHandler().ExportAuditLogs(w); err != nil {
+		// the logs may have been partly written, the error can only be logged
+		logrus.Errorf("export audit logs: %v", err)
+	}
+}
+
+// VerifyAuditLogs verifies the hash chain of the audit logs
+func VerifyAuditLogs(w http.ResponseWriter, r *http.Request) {
+	status, err := handler.GetAuditHandler().VerifyAuditLogs()
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, status)
+}
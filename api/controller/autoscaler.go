@@ -19,17 +19,25 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 
 	"github.com/gridworkz/kato/api/handler"
 	"github.com/gridworkz/kato/api/middleware"
 	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/db"
 	"github.com/gridworkz/kato/db/errors"
+	"github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/util/cron"
 	httputil "github.com/gridworkz/kato/util/http"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AutoscalerRules -
@@ -42,12 +50,67 @@ func (t *TenantStruct) AutoscalerRules(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// the target types supported by each metric type
+var metricTargetTypes = map[string][]string{
+	"resource_metrics": {"utilization", "average_value"},
+	"pods_metrics":     {"average_value"},
+	"object_metrics":   {"value", "average_value"},
+	"external_metrics": {"value", "average_value"},
+}
+
+func validateAutoscalerRule(req *model.AutoscalerRuleReq) url.Values {
+	values := url.Values{}
+	if req.MinReplicas < 0 || req.MaxReplicas < 1 || req.MinReplicas > req.MaxReplicas {
+		values["max_replicas"] = []string{"The max_replicas must be positive and not less than min_replicas"}
+	}
+	for idx, metric := range req.Metrics {
+		field := fmt.Sprintf("metrics[%d]", idx)
+		targetTypes, ok := metricTargetTypes[metric.MetricsType]
+		if !ok {
+			values[field+".metric_type"] = []string{fmt.Sprintf("The metric_type %s is not supported", metric.MetricsType)}
+			continue
+		}
+		if metric.MetricsName == "" {
+			values[field+".metric_name"] = []string{"The metric_name field is required"}
+		}
+		if !util.StringArrayContains(targetTypes, metric.MetricTargetType) {
+			values[field+".metric_target_type"] = []string{fmt.Sprintf("The metric_target_type of %s must be one of %v", metric.MetricsType, targetTypes)}
+		}
+		if metric.MetricsType == "resource_metrics" {
+			if metric.MetricsName != "cpu" && metric.MetricsName != "memory" {
+				values[field+".metric_name"] = []string{"The metric_name of resource_metrics must be cpu or memory"}
+			}
+			if metric.MetricTargetValue <= 0 {
+				values[field+".metric_target_value"] = []string{"The metric_target_value of resource_metrics must be positive"}
+			}
+			continue
+		}
+		// the target value of custom metrics can be 0
+		if metric.MetricTargetValue < 0 {
+			values[field+".metric_target_value"] = []string{"The metric_target_value can not be negative"}
+		}
+		if metric.MetricSelector != "" {
+			if _, err := metav1.ParseToLabelSelector(metric.MetricSelector); err != nil {
+				values[field+".metric_selector"] = []string{fmt.Sprintf("The metric_selector is invalid: %v", err)}
+			}
+		}
+		if metric.MetricsType == "object_metrics" && (metric.ObjectKind == "" || metric.ObjectName == "") {
+			values[field+".object_name"] = []string{"The object_kind and object_name fields are required by object_metrics"}
+		}
+	}
+	return values
+}
+
 func (t *TenantStruct) addAutoscalerRule(w http.ResponseWriter, r *http.Request) {
 	var req model.AutoscalerRuleReq
 	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
 	if !ok {
 		return
 	}
+	if values := validateAutoscalerRule(&req); len(values) != 0 {
+		httputil.ReturnValidationError(r, w, values)
+		return
+	}
 
 	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
 	req.ServiceID = serviceID
@@ -70,6 +133,10 @@ func (t *TenantStruct) updAutoscalerRule(w http.ResponseWriter, r *http.Request)
 	if !ok {
 		return
 	}
+	if values := validateAutoscalerRule(&req); len(values) != 0 {
+		httputil.ReturnValidationError(r, w, values)
+		return
+	}
 
 	if err := handler.GetServiceManager().UpdAutoscalerRule(&req); err != nil {
 		if err == errors.ErrRecordAlreadyExist {
@@ -88,6 +155,176 @@ func (t *TenantStruct) updAutoscalerRule(w http.ResponseWriter, r *http.Request)
 	httputil.ReturnSuccess(r, w, nil)
 }
 
+// AutoscalerSchedules -
+func (t *TenantStruct) AutoscalerSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		t.listAutoscalerSchedules(w, r)
+	case "POST":
+		t.addAutoscalerSchedule(w, r)
+	case "PUT":
+		t.updAutoscalerSchedule(w, r)
+	}
+}
+
+func validateAutoscalerSchedule(req *model.AutoscalerScheduleReq) url.Values {
+	values := url.Values{}
+	if _, err := cron.Parse(req.Schedule); err != nil {
+		values["schedule"] = []string{fmt.Sprintf("The schedule is invalid: %v", err)}
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			values["time_zone"] = []string{fmt.Sprintf("The time_zone %s is unknown", req.TimeZone)}
+		}
+	}
+	if req.Replicas < 0 {
+		values["replicas"] = []string{"The replicas can not be negative"}
+	}
+	return values
+}
+
+func (t *TenantStruct) listAutoscalerSchedules(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	schedules, err := handler.GetServiceManager().ListAutoscalerSchedules(serviceID)
+	if err != nil {
+		logrus.Errorf("list autoscaler schedules: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, schedules)
+}
+
+func (t *TenantStruct) addAutoscalerSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.AutoscalerScheduleReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+	if values := validateAutoscalerSchedule(&req); len(values) != 0 {
+		httputil.ReturnValidationError(r, w, values)
+		return
+	}
+
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	if err := handler.GetServiceManager().AddAutoscalerSchedule(&req); err != nil {
+		if err == errors.ErrRecordAlreadyExist {
+			httputil.ReturnError(r, w, 400, err.Error())
+			return
+		}
+		logrus.Errorf("add autoscaler schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+func (t *TenantStruct) updAutoscalerSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.AutoscalerScheduleReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+	if values := validateAutoscalerSchedule(&req); len(values) != 0 {
+		httputil.ReturnValidationError(r, w, values)
+		return
+	}
+
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	if err := handler.GetServiceManager().UpdAutoscalerSchedule(&req); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			httputil.ReturnError(r, w, 404, err.Error())
+			return
+		}
+		logrus.Errorf("update autoscaler schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// DeleteAutoscalerSchedule -
+func (t *TenantStruct) DeleteAutoscalerSchedule(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	scheduleID := chi.URLParam(r, "schedule_id")
+	if err := handler.GetServiceManager().DeleteAutoscalerSchedule(serviceID, scheduleID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			httputil.ReturnError(r, w, 404, err.Error())
+			return
+		}
+		logrus.Errorf("delete autoscaler schedule: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// minIdleMinutes the gateway metrics are scraped periodically, a shorter window may miss requests
+const minIdleMinutes = 5
+
+// IdlePolicy -
+func (t *TenantStruct) IdlePolicy(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		t.getIdlePolicy(w, r)
+	case "PUT":
+		t.updIdlePolicy(w, r)
+	}
+}
+
+// validateIdlePolicy the activity is only known from the gateway, a component without http rules
+// would be always idle. The internal traffic from other components is not counted.
+func validateIdlePolicy(req *model.IdlePolicyReq, httpRules int) url.Values {
+	values := url.Values{}
+	if req.Enable && req.IdleMinutes < minIdleMinutes {
+		values["idle_minutes"] = []string{fmt.Sprintf("The idle_minutes can not be less than %d", minIdleMinutes)}
+	}
+	if req.Enable && httpRules == 0 {
+		values["enable"] = []string{"The component without http rules can not be scaled to zero when idle"}
+	}
+	return values
+}
+
+func (t *TenantStruct) getIdlePolicy(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().GetIdlePolicy(serviceID)
+	if err != nil {
+		logrus.Errorf("get idle policy: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
+
+func (t *TenantStruct) updIdlePolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.IdlePolicyReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil)
+	if !ok {
+		return
+	}
+	req.ServiceID = r.Context().Value(middleware.ContextKey("service_id")).(string)
+	rules, err := db.GetManager().HTTPRuleDao().ListByServiceID(req.ServiceID)
+	if err != nil {
+		logrus.Errorf("list http rules: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	if values := validateIdlePolicy(&req, len(rules)); len(values) != 0 {
+		httputil.ReturnValidationError(r, w, values)
+		return
+	}
+
+	if err := handler.GetServiceManager().UpdIdlePolicy(&req); err != nil {
+		logrus.Errorf("update idle policy: %v", err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
+
 // ScalingRecords -
 func (t *TenantStruct) ScalingRecords(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
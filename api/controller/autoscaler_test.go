@@ -0,0 +1,118 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"testing"
+
+	"github.com/gridworkz/kato/api/model"
+)
+
+func TestValidateAutoscalerRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric model.RuleMetric
+		field  string
+	}{
+		{
+			name:   "resource metric",
+			metric: model.RuleMetric{MetricsType: "resource_metrics", MetricsName: "cpu", MetricTargetType: "utilization", MetricTargetValue: 50},
+		},
+		{
+			name:   "zero resource target",
+			metric: model.RuleMetric{MetricsType: "resource_metrics", MetricsName: "cpu", MetricTargetType: "utilization"},
+			field:  "metrics[0].metric_target_value",
+		},
+		{
+			name:   "zero external target",
+			metric: model.RuleMetric{MetricsType: "external_metrics", MetricsName: "queue_messages_ready", MetricTargetType: "average_value", MetricSelector: "queue=orders"},
+		},
+		{
+			name:   "invalid selector",
+			metric: model.RuleMetric{MetricsType: "external_metrics", MetricsName: "queue_messages_ready", MetricTargetType: "value", MetricSelector: "queue in orders"},
+			field:  "metrics[0].metric_selector",
+		},
+		{
+			name:   "pods metric with value target",
+			metric: model.RuleMetric{MetricsType: "pods_metrics", MetricsName: "http_requests", MetricTargetType: "value", MetricTargetValue: 10},
+			field:  "metrics[0].metric_target_type",
+		},
+		{
+			name:   "object metric without object",
+			metric: model.RuleMetric{MetricsType: "object_metrics", MetricsName: "requests_per_second", MetricTargetType: "value", MetricTargetValue: 10},
+			field:  "metrics[0].object_name",
+		},
+		{
+			name:   "unknown metric type",
+			metric: model.RuleMetric{MetricsType: "foo_metrics"},
+			field:  "metrics[0].metric_type",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &model.AutoscalerRuleReq{
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				Metrics:     []model.RuleMetric{tc.metric},
+			}
+			values := validateAutoscalerRule(req)
+			if tc.field == "" {
+				if len(values) != 0 {
+					t.Fatalf("unexpected errors %v", values)
+				}
+				return
+			}
+			if _, ok := values[tc.field]; !ok {
+				t.Fatalf("expect error of %s, got %v", tc.field, values)
+			}
+		})
+	}
+}
+
+func TestValidateAutoscalerSchedule(t *testing.T) {
+	req := &model.AutoscalerScheduleReq{Schedule: "0 8 * * 1-5", TimeZone: "UTC", Replicas: 10}
+	if values := validateAutoscalerSchedule(req); len(values) != 0 {
+		t.Fatalf("unexpected errors %v", values)
+	}
+	req = &model.AutoscalerScheduleReq{Schedule: "0 25 * * *", TimeZone: "Mars/Olympus", Replicas: -1}
+	values := validateAutoscalerSchedule(req)
+	for _, field := range []string{"schedule", "time_zone", "replicas"} {
+		if _, ok := values[field]; !ok {
+			t.Errorf("expect error of %s, got %v", field, values)
+		}
+	}
+}
+
+func TestValidateIdlePolicy(t *testing.T) {
+	tests := []struct {
+		req       model.IdlePolicyReq
+		httpRules int
+		valid     bool
+	}{
+		{req: model.IdlePolicyReq{Enable: true, IdleMinutes: 30}, httpRules: 1, valid: true},
+		{req: model.IdlePolicyReq{Enable: true, IdleMinutes: 1}, httpRules: 1},
+		{req: model.IdlePolicyReq{Enable: true, IdleMinutes: 30}},
+		{req: model.IdlePolicyReq{Enable: false}, valid: true},
+	}
+	for _, tc := range tests {
+		values := validateIdlePolicy(&tc.req, tc.httpRules)
+		if valid := len(values) == 0; valid != tc.valid {
+			t.Errorf("idle policy %+v: expect valid %v, got errors %v", tc.req, tc.valid, values)
+		}
+	}
+}
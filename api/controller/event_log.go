@@ -21,6 +21,7 @@ package controller
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -57,6 +58,63 @@ func (e *EventLogStruct) HistoryLogs(w http.ResponseWriter, r *http.Request) {
 	e.EventlogServerProxy.Proxy(w, r)
 }
 
+//SearchLogs search the persisted logs of the service
+//proxy
+func (e *EventLogStruct) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v2/tenants/{tenant_name}/services/{service_alias}/logs/search v2 searchLogs
+	//
+	// Search the service logs or event logs of the application
+	//
+	// query: query, regex, kind(service or event), level, since, until, offset, limit,
+	// event_id only narrows the events of the service
+	//
+	// ---
+	// produces:
+	// - application/json
+	//
+	// responses:
+	//   default:
+	//     schema:
+	//       "$ref": "#/responses/commandResponse"
+	//     description: Unified return format
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	query := r.URL.Query()
+	query.Del("tenant_id")
+	query.Del("service_alias")
+	query.Set("service_id", serviceID)
+	e.proxySearch(w, r, query)
+}
+
+//TenantSearchLogs search the persisted logs of the services in the tenant
+//proxy
+func (e *EventLogStruct) TenantSearchLogs(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v2/tenants/{tenant_name}/logs/search v2 tenantSearchLogs
+	//
+	// Search the service logs or event logs of the applications in the tenant,
+	// service_alias or service_id filters the applications, all applications are searched if not set,
+	// event_id only narrows the events of these applications
+	//
+	// ---
+	// produces:
+	// - application/json
+	//
+	// responses:
+	//   default:
+	//     schema:
+	//       "$ref": "#/responses/commandResponse"
+	//     description: Unified return format
+	tenantID := r.Context().Value(middleware.ContextKey("tenant_id")).(string)
+	query := r.URL.Query()
+	query.Set("tenant_id", tenantID)
+	e.proxySearch(w, r, query)
+}
+
+func (e *EventLogStruct) proxySearch(w http.ResponseWriter, r *http.Request, query url.Values) {
+	r.URL.Path = "/logs/search"
+	r.URL.RawQuery = query.Encode()
+	e.EventlogServerProxy.Proxy(w, r)
+}
+
 //LogList GetLogList
 func (e *EventLogStruct) LogList(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation GET  /v2/tenants/{tenant_name}/services/{service_alias}/log-file v2 logList
@@ -29,6 +29,7 @@ import (
 	api_model "github.com/gridworkz/kato/api/model"
 	ctxutil "github.com/gridworkz/kato/api/util/ctx"
 	"github.com/gridworkz/kato/cmd/api/option"
+	dbmodel "github.com/gridworkz/kato/db/model"
 	"github.com/gridworkz/kato/mq/client"
 	httputil "github.com/gridworkz/kato/util/http"
 	"github.com/jinzhu/gorm"
@@ -98,6 +99,12 @@ func (g *GatewayStruct) addHTTPRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaGatewayRules: 1}); err != nil {
+		httputil.ReturnError(r, w, 412, err.Error())
+		return
+	}
+
 	h := handler.GetGatewayHandler()
 	err := h.AddHTTPRule(&req)
 	if err != nil {
@@ -227,6 +234,11 @@ func (g *GatewayStruct) AddTCPRule(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnValidationError(r, w, values)
 		return
 	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaGatewayRules: 1}); err != nil {
+		httputil.ReturnError(r, w, 412, err.Error())
+		return
+	}
 	err := h.AddTCPRule(&req)
 	if err != nil {
 		httputil.ReturnError(r, w, 500, fmt.Sprintf("Unexpected error occorred while "+
@@ -465,6 +465,9 @@ func (t *TenantStruct) AddTenant(w http.ResponseWriter, r *http.Request) {
 			httputil.ReturnError(r, w, 500, fmt.Sprintf("create tenant error, %v", err))
 			return
 		}
+		if err := handler.GetTenantManager().SyncTenantQuota(r.Context(), &dbts); err != nil {
+			logrus.Warningf("sync quota of tenant %s: %v", dbts.UUID, err)
+		}
 		rc := make(map[string]string)
 		rc["tenant_id"] = id
 		rc["tenang_name"] = name
@@ -582,6 +585,9 @@ func (t *TenantStruct) UpdateTenant(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnError(r, w, 500, "update tenant error")
 		return
 	}
+	if err := handler.GetTenantManager().SyncTenantQuota(r.Context(), tenant); err != nil {
+		logrus.Warningf("sync quota of tenant %s: %v", tenant.UUID, err)
+	}
 	httputil.ReturnSuccess(r, w, tenant)
 }
 
@@ -679,6 +685,15 @@ func (t *TenantStruct) CreateService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	var capacity int
+	for _, volume := range ss.VolumesInfo {
+		capacity += int(volume.VolumeCapacity)
+	}
+	if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaComponents: 1, handler.QuotaStorage: capacity}); err != nil {
+		httputil.ReturnResNotEnough(r, w, r.Context().Value(ctxutil.ContextKey("event_id")).(string), err.Error())
+		return
+	}
 	ss.TenantID = tenantID
 	if err := handler.GetServiceManager().ServiceCreate(&ss); err != nil {
 		if strings.Contains(err.Error(), "is exist in tenant") {
@@ -1856,6 +1871,13 @@ func (t *TenantStruct) SingleTenantResources(w http.ResponseWriter, r *http.Requ
 	statsInfo, _ := handler.GetTenantManager (). StatsMemCPU (services)
 	//900ms
 	statsInfo.UUID = tenantID
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	used, err := handler.GetTenantManager().TenantQuotaUsage(tenant)
+	if err != nil {
+		logrus.Warningf("get quota usage of tenant %s: %v", tenantID, err)
+	} else {
+		statsInfo.Quotas = handler.QuotaUsages(tenant, used)
+	}
 	httputil.ReturnSuccess(r, w, statsInfo)
 	return
 }
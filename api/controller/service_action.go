@@ -69,7 +69,16 @@ func (t *TenantStruct) StartService(w http.ResponseWriter, r *http.Request) {
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
 	sEvent := r.Context().Value(ctxutil.ContextKey("event")).(*dbmodel.ServiceEvent)
 	if service.Kind != "third_party" {
-		if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
+		cpu, memory, err := handler.ComponentPodQuota(service, service.ContainerMemory)
+		if err != nil {
+			httputil.ReturnError(r, w, 500, err.Error())
+			return
+		}
+		if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*memory); err != nil {
+			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+			return
+		}
+		if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaPods: service.Replicas, handler.QuotaCPU: service.Replicas * cpu}); err != nil {
 			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
 			return
 		}
@@ -233,6 +242,21 @@ func (t *TenantStruct) VerticalService(w http.ResponseWriter, r *http.Request) {
 			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
 			return
 		}
+		// the cpu limit follows the memory
+		oldCPU, _, err := handler.ComponentPodQuota(service, service.ContainerMemory)
+		if err != nil {
+			httputil.ReturnError(r, w, 500, err.Error())
+			return
+		}
+		newCPU, _, err := handler.ComponentPodQuota(service, *memorySet)
+		if err != nil {
+			httputil.ReturnError(r, w, 500, err.Error())
+			return
+		}
+		if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaCPU: (newCPU - oldCPU) * service.Replicas}); err != nil {
+			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+			return
+		}
 	}
 	verticalTask := &model.VerticalScalingTaskBody{
 		TenantID:        tenantID,
@@ -285,7 +309,17 @@ func (t *TenantStruct) HorizontalService(w http.ResponseWriter, r *http.Request)
 
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
-	if err := handler.CheckTenantResource(r.Context(), tenant, service.ContainerMemory*int(replicas)); err != nil {
+	cpu, memory, err := handler.ComponentPodQuota(service, service.ContainerMemory)
+	if err != nil {
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	if err := handler.CheckTenantResource(r.Context(), tenant, memory*int(replicas)); err != nil {
+		httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+		return
+	}
+	added := int(replicas) - service.Replicas
+	if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaPods: added, handler.QuotaCPU: added * cpu}); err != nil {
 		httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
 		return
 	}
@@ -347,6 +381,10 @@ func (t *TenantStruct) BuildService(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnResNotEnough(r, w, build.EventID, err.Error())
 		return
 	}
+	if err := handler.CheckTenantBuildConcurrency(tenant); err != nil {
+		httputil.ReturnResNotEnough(r, w, build.EventID, err.Error())
+		return
+	}
 
 	res, err := handler.GetOperationHandler().Build(&build)
 	if err != nil {
@@ -689,6 +727,9 @@ func (t *TenantStruct) LimitTenantMemory(w http.ResponseWriter, r *http.Request)
 	if err := db.GetManager().TenantDao().UpdateModel(tenant); err != nil {
 		httputil.ReturnError(r, w, 500, err.Error())
 	}
+	if err := handler.GetTenantManager().SyncTenantQuota(r.Context(), tenant); err != nil {
+		logrus.Warningf("sync quota of tenant %s: %v", tenant.UUID, err)
+	}
 	httputil.ReturnSuccess(r, w, "success!")
 
 }
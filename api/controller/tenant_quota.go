@@ -0,0 +1,48 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/model"
+	ctxutil "github.com/gridworkz/kato/api/util/ctx"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	httputil "github.com/gridworkz/kato/util/http"
+)
+
+// UpdateTenantQuota replaces the limits of the tenant, the usage against them is got with the resources of the tenant
+func UpdateTenantQuota(w http.ResponseWriter, r *http.Request) {
+	var req model.TenantQuota
+	if err := httputil.ReadEntity(r, &req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	if err := httputil.ValidateStruct(&req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.GetTenantManager().UpdateTenantQuota(r.Context(), tenant, &req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, req)
+}
@@ -27,6 +27,7 @@ import (
 	api_model "github.com/gridworkz/kato/api/model"
 	"github.com/gridworkz/kato/api/util/bcode"
 	ctxutil "github.com/gridworkz/kato/api/util/ctx"
+	"github.com/gridworkz/kato/db"
 	dbmodel "github.com/gridworkz/kato/db/model"
 	httputil "github.com/gridworkz/kato/util/http"
 	"github.com/sirupsen/logrus"
@@ -199,6 +200,30 @@ func (t *TenantStruct) UpdVolume(w http.ResponseWriter, r *http.Request) {
 	httputil.ReturnSuccess(r, w, "success")
 }
 
+// UpdVolumeCapacity expands the capacity of service volume.
+func (t *TenantStruct) UpdVolumeCapacity(w http.ResponseWriter, r *http.Request) {
+	var req api_model.UpdVolumeCapacityReq
+	if ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil); !ok {
+		return
+	}
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(ctxutil.ContextKey("event_id")).(string)
+	volumeName := chi.URLParam(r, "volume_name")
+	if volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(serviceID, volumeName); err == nil {
+		tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+		if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaStorage: int(req.VolumeCapacity - volume.VolumeCapacity)}); err != nil {
+			httputil.ReturnResNotEnough(r, w, eventID, err.Error())
+			return
+		}
+	}
+	if err := handler.GetServiceManager().ExpandVolume(tenantID, serviceID, volumeName, eventID, req.VolumeCapacity); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
 //DeleteVolume DeleteVolume
 func (t *TenantStruct) DeleteVolume(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation DELETE /v2/tenants/{tenant_name}/services/{service_alias}/volume v2 deleteVolume
@@ -388,6 +413,11 @@ func AddVolume(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnError(r, w, 400, "volume path is invalid,must begin with /")
 		return
 	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.CheckTenantQuota(tenant, map[string]int{handler.QuotaStorage: int(avs.Body.VolumeCapacity)}); err != nil {
+		httputil.ReturnResNotEnough(r, w, r.Context().Value(ctxutil.ContextKey("event_id")).(string), err.Error())
+		return
+	}
 	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, avs.Body.FileContent, "add"); err != nil {
 		err.Handle(r, w)
 		return
@@ -0,0 +1,134 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/middleware"
+	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/util/cron"
+	httputil "github.com/gridworkz/kato/util/http"
+)
+
+// VolumeBackups lists or creates the backups of the volume data
+func (t *TenantStruct) VolumeBackups(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	volumeName := chi.URLParam(r, "volume_name")
+	switch r.Method {
+	case "GET":
+		backups, err := handler.GetVolumeBackupHandler().ListBackups(serviceID, volumeName)
+		if err != nil {
+			err.Handle(r, w)
+			return
+		}
+		httputil.ReturnSuccess(r, w, backups)
+	case "POST":
+		eventID := r.Context().Value(middleware.ContextKey("event_id")).(string)
+		backup, err := handler.GetVolumeBackupHandler().CreateBackup(serviceID, volumeName, eventID)
+		if err != nil {
+			err.Handle(r, w)
+			return
+		}
+		httputil.ReturnSuccess(r, w, backup)
+	}
+}
+
+// DeleteVolumeBackup deletes the backup of the volume data
+func (t *TenantStruct) DeleteVolumeBackup(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(middleware.ContextKey("event_id")).(string)
+	if err := handler.GetVolumeBackupHandler().DeleteBackup(serviceID, chi.URLParam(r, "volume_name"), chi.URLParam(r, "backup_id"), eventID); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// RestoreVolume restores the data of a volume backup into the volume
+func (t *TenantStruct) RestoreVolume(w http.ResponseWriter, r *http.Request) {
+	var req model.VolumeRestoreReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(middleware.ContextKey("event_id")).(string)
+	if err := handler.GetVolumeBackupHandler().RestoreBackup(serviceID, chi.URLParam(r, "volume_name"), eventID, &req); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// VolumeBackupPolicy gets or updates the backup policy of the volume
+func (t *TenantStruct) VolumeBackupPolicy(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(middleware.ContextKey("service_id")).(string)
+	volumeName := chi.URLParam(r, "volume_name")
+	switch r.Method {
+	case "GET":
+		policy, err := handler.GetVolumeBackupHandler().GetBackupPolicy(serviceID, volumeName)
+		if err != nil {
+			err.Handle(r, w)
+			return
+		}
+		httputil.ReturnSuccess(r, w, policy)
+	case "PUT":
+		var req model.VolumeBackupPolicyReq
+		if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+			return
+		}
+		if values := validateVolumeBackupPolicy(&req); len(values) != 0 {
+			httputil.ReturnValidationError(r, w, values)
+			return
+		}
+		req.ServiceID = serviceID
+		req.VolumeName = volumeName
+		if err := handler.GetVolumeBackupHandler().UpdBackupPolicy(&req); err != nil {
+			err.Handle(r, w)
+			return
+		}
+		httputil.ReturnSuccess(r, w, nil)
+	}
+}
+
+func validateVolumeBackupPolicy(req *model.VolumeBackupPolicyReq) url.Values {
+	values := url.Values{}
+	if req.Enable || req.Schedule != "" {
+		if _, err := cron.Parse(req.Schedule); err != nil {
+			values["schedule"] = []string{fmt.Sprintf("The schedule is invalid: %v", err)}
+		}
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			values["time_zone"] = []string{fmt.Sprintf("The time_zone %s is unknown", req.TimeZone)}
+		}
+	}
+	if req.Retain < 0 {
+		values["retain"] = []string{"The retain can not be negative"}
+	}
+	if req.RetainDays < 0 {
+		values["retain_days"] = []string{"The retain_days can not be negative"}
+	}
+	return values
+}
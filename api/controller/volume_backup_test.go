@@ -0,0 +1,43 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"testing"
+
+	"github.com/gridworkz/kato/api/model"
+)
+
+func TestValidateVolumeBackupPolicy(t *testing.T) {
+	tests := []struct {
+		req   model.VolumeBackupPolicyReq
+		valid bool
+	}{
+		{req: model.VolumeBackupPolicyReq{Enable: true, Schedule: "0 2 * * *", TimeZone: "UTC", Retain: 7}, valid: true},
+		{req: model.VolumeBackupPolicyReq{Enable: false}, valid: true},
+		{req: model.VolumeBackupPolicyReq{Enable: true}},
+		{req: model.VolumeBackupPolicyReq{Enable: true, Schedule: "0 2 * * *", TimeZone: "Mars/Olympus"}},
+		{req: model.VolumeBackupPolicyReq{Enable: true, Schedule: "0 2 * * *", RetainDays: -1}},
+	}
+	for _, tc := range tests {
+		values := validateVolumeBackupPolicy(&tc.req)
+		if valid := len(values) == 0; valid != tc.valid {
+			t.Errorf("volume backup policy %+v: expect valid %v, got errors %v", tc.req, tc.valid, values)
+		}
+	}
+}
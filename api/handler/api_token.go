@@ -0,0 +1,213 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	api_model "github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/rbac"
+	"github.com/gridworkz/kato/api/util"
+	"github.com/gridworkz/kato/db"
+	dberrors "github.com/gridworkz/kato/db/errors"
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
+//APITokenPrefix the prefix of the named api tokens, which tells them from the tokens of the console
+const APITokenPrefix = "kt_"
+
+//ErrInvalidAPIToken the token does not exist or it is expired
+var ErrInvalidAPIToken = errors.New("invalid api token")
+
+// the revoked tokens may be accepted by the other api instances until their caches expire
+var apiTokenCacheTTL = time.Minute
+
+//APITokenHandler manages the named api tokens and authenticates the requests with them
+type APITokenHandler interface {
+	Authenticate(token string) (*rbac.Principal, error)
+	CreateToken(creator *rbac.Principal, req *api_model.CreateAPITokenReq) (*api_model.APITokenInfo, *util.APIHandleError)
+	ListTokens(principal *rbac.Principal) ([]*dbmodel.APIToken, *util.APIHandleError)
+	RevokeToken(principal *rbac.Principal, name string) *util.APIHandleError
+}
+
+var defaultAPITokenHandler APITokenHandler
+
+//CreateAPITokenManager create api token manager
+func CreateAPITokenManager() *APITokenAction {
+	return &APITokenAction{cache: make(map[string]*cachedAPIToken)}
+}
+
+//GetAPITokenHandler get api token handler
+func GetAPITokenHandler() APITokenHandler {
+	return defaultAPITokenHandler
+}
+
+type cachedAPIToken struct {
+	token    *dbmodel.APIToken
+	cachedAt time.Time
+}
+
+//APITokenAction action
+type APITokenAction struct {
+	lock  sync.Mutex
+	cache map[string]*cachedAPIToken
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+//APITokenPrincipal returns the identity of the requests with the token
+func APITokenPrincipal(token *dbmodel.APIToken) *rbac.Principal {
+	return &rbac.Principal{
+		Name:         token.Name,
+		TokenID:      fmt.Sprint(token.ID),
+		Role:         rbac.Role(token.Role),
+		Scope:        rbac.Scope(token.Scope),
+		EnterpriseID: token.EnterpriseID,
+		TenantID:     token.TenantID,
+		AppID:        token.AppID,
+	}
+}
+
+//Authenticate returns the identity of the token
+func (a *APITokenAction) Authenticate(token string) (*rbac.Principal, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+	tokenHash := hashAPIToken(token)
+	a.lock.Lock()
+	cached, ok := a.cache[tokenHash]
+	a.lock.Unlock()
+	if !ok || time.Since(cached.cachedAt) > apiTokenCacheTTL {
+		t, err := db.GetManager().APITokenDao().GetByTokenHash(tokenHash)
+		if err != nil {
+			return nil, ErrInvalidAPIToken
+		}
+		cached = &cachedAPIToken{token: t, cachedAt: time.Now()}
+		a.lock.Lock()
+		a.cache[tokenHash] = cached
+		a.lock.Unlock()
+	}
+	if cached.token.ExpireTime != nil && cached.token.ExpireTime.Before(time.Now()) {
+		return nil, ErrInvalidAPIToken
+	}
+	return APITokenPrincipal(cached.token), nil
+}
+
+//CreateToken creates a token within the scope of the creator, the creator is nil when the api is not authenticated
+func (a *APITokenAction) CreateToken(creator *rbac.Principal, req *api_model.CreateAPITokenReq) (*api_model.APITokenInfo, *util.APIHandleError) {
+	t := &dbmodel.APIToken{
+		Name:  req.Name,
+		Role:  req.Role,
+		Scope: req.Scope,
+	}
+	if !rbac.Role(t.Role).Valid() || !rbac.Scope(t.Scope).Valid() {
+		return nil, util.CreateAPIHandleErrorf(400, "invalid role %s or scope %s", t.Role, t.Scope)
+	}
+	switch rbac.Scope(t.Scope) {
+	case rbac.ScopeEnterprise:
+		t.EnterpriseID = req.EnterpriseID
+	case rbac.ScopeTenant:
+		if req.TenantName == "" {
+			return nil, util.CreateAPIHandleErrorf(400, "tenant name is required by the tenant scope")
+		}
+		tenant, err := db.GetManager().TenantDao().GetTenantIDByName(req.TenantName)
+		if err != nil {
+			return nil, util.CreateAPIHandleErrorFromDBError("get tenant", err)
+		}
+		t.EnterpriseID, t.TenantID = tenant.EID, tenant.UUID
+	case rbac.ScopeApp:
+		if req.AppID == "" {
+			return nil, util.CreateAPIHandleErrorf(400, "app id is required by the app scope")
+		}
+		app, err := db.GetManager().ApplicationDao().GetAppByID(req.AppID)
+		if err != nil {
+			return nil, util.CreateAPIHandleErrorFromDBError("get app", err)
+		}
+		t.EnterpriseID, t.TenantID, t.AppID = app.EID, app.TenantID, app.AppID
+	}
+	if creator != nil {
+		if !creator.Covers(APITokenPrincipal(t)) {
+			return nil, util.CreateAPIHandleErrorf(403, "%s can not grant the role %s in the scope", creator.Name, t.Role)
+		}
+		t.Creator = creator.Name
+	}
+	if req.ExpireDays > 0 {
+		expireTime := time.Now().AddDate(0, 0, req.ExpireDays)
+		t.ExpireTime = &expireTime
+	}
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, util.CreateAPIHandleError(500, err)
+	}
+	token := APITokenPrefix + hex.EncodeToString(secret)
+	t.TokenHash = hashAPIToken(token)
+	if err := db.GetManager().APITokenDao().AddModel(t); err != nil {
+		if err == dberrors.ErrRecordAlreadyExist {
+			return nil, util.CreateAPIHandleErrorf(409, "token %s already exists", t.Name)
+		}
+		return nil, util.CreateAPIHandleErrorFromDBError("create api token", err)
+	}
+	return &api_model.APITokenInfo{APIToken: t, Token: token}, nil
+}
+
+//ListTokens lists the tokens within the scope of the principal
+func (a *APITokenAction) ListTokens(principal *rbac.Principal) ([]*dbmodel.APIToken, *util.APIHandleError) {
+	tokens, err := db.GetManager().APITokenDao().List()
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("list api tokens", err)
+	}
+	if principal == nil {
+		return tokens, nil
+	}
+	var result []*dbmodel.APIToken
+	for _, t := range tokens {
+		if principal.Covers(APITokenPrincipal(t)) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+//RevokeToken deletes the token, it must be within the scope of the principal
+func (a *APITokenAction) RevokeToken(principal *rbac.Principal, name string) *util.APIHandleError {
+	t, err := db.GetManager().APITokenDao().GetByName(name)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get api token", err)
+	}
+	if principal != nil && !principal.Covers(APITokenPrincipal(t)) {
+		return util.CreateAPIHandleErrorf(403, "%s can not revoke the token %s", principal.Name, name)
+	}
+	if err := db.GetManager().APITokenDao().DeleteByName(name); err != nil {
+		return util.CreateAPIHandleErrorFromDBError("delete api token", err)
+	}
+	a.lock.Lock()
+	delete(a.cache, t.TokenHash)
+	a.lock.Unlock()
+	return nil
+}
@@ -0,0 +1,502 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handler
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/util/bcode"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
+// component sections compared by the apply diff, in output order
+const (
+	sectionBase        = "base"
+	sectionPorts       = "ports"
+	sectionEnvs        = "envs"
+	sectionVolumes     = "volumes"
+	sectionConfigFiles = "config_files"
+	sectionProbes      = "probes"
+	sectionLabels      = "labels"
+	sectionRelations   = "relations"
+	sectionPlugins     = "plugins"
+	sectionHTTPRules   = "http_rules"
+	sectionTCPRules    = "tcp_rules"
+)
+
+var componentSections = []string{sectionBase, sectionPorts, sectionEnvs, sectionVolumes, sectionConfigFiles,
+	sectionProbes, sectionLabels, sectionRelations, sectionPlugins, sectionHTTPRules, sectionTCPRules}
+
+// componentState is the comparable state of a component: section -> key -> value.
+// A section missing from a desired state is not managed by the spec and is never diffed.
+type componentState map[string]map[string]string
+
+func (s componentState) manage(section string) {
+	if _, ok := s[section]; !ok {
+		s[section] = make(map[string]string)
+	}
+}
+
+func (s componentState) set(section, key, value string) {
+	s.manage(section)
+	s[section][key] = value
+}
+
+func stateValue(kv ...interface{}) string {
+	var parts []string
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, fmt.Sprintf("%v=%v", kv[i], kv[i+1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func contentDigest(content string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))[:19]
+}
+
+func envKey(env *dbmodel.TenantServiceEnvVar) string {
+	if env.ContainerPort != 0 {
+		return fmt.Sprintf("%s@%d", env.AttrName, env.ContainerPort)
+	}
+	return env.AttrName
+}
+
+func (s componentState) setBase(svc *dbmodel.TenantServices) {
+	s.set(sectionBase, "component_alias", svc.ServiceAlias)
+	s.set(sectionBase, "component_name", svc.ServiceName)
+	s.set(sectionBase, "comment", svc.Comment)
+	s.set(sectionBase, "container_cpu", fmt.Sprint(svc.ContainerCPU))
+	s.set(sectionBase, "container_memory", fmt.Sprint(svc.ContainerMemory))
+	s.set(sectionBase, "container_gpu", fmt.Sprint(svc.ContainerGPU))
+	s.set(sectionBase, "extend_method", svc.ExtendMethod)
+	s.set(sectionBase, "replicas", fmt.Sprint(svc.Replicas))
+	s.set(sectionBase, "category", svc.Category)
+	s.set(sectionBase, "kind", svc.Kind)
+}
+
+func (s componentState) setPorts(ports []*dbmodel.TenantServicesPort) {
+	s.manage(sectionPorts)
+	for _, p := range ports {
+		s.set(sectionPorts, fmt.Sprint(p.ContainerPort), stateValue("protocol", p.Protocol, "alias", p.PortAlias,
+			"mapping_port", p.MappingPort, "inner", boolValue(p.IsInnerService), "outer", boolValue(p.IsOuterService),
+			"k8s_service_name", p.K8sServiceName))
+	}
+}
+
+func (s componentState) setEnvs(envs []*dbmodel.TenantServiceEnvVar) {
+	s.manage(sectionEnvs)
+	for _, env := range envs {
+		s.set(sectionEnvs, envKey(env), stateValue("value", env.AttrValue, "scope", env.Scope, "is_change", env.IsChange))
+	}
+}
+
+func (s componentState) setVolumes(volumes []*dbmodel.TenantServiceVolume) {
+	s.manage(sectionVolumes)
+	for _, v := range volumes {
+		s.set(sectionVolumes, v.VolumeName, stateValue("path", v.VolumePath, "type", v.VolumeType,
+			"capacity", v.VolumeCapacity, "read_only", v.IsReadOnly, "access_mode", v.AccessMode))
+	}
+}
+
+func (s componentState) setConfigFiles(files []*dbmodel.TenantServiceConfigFile) {
+	s.manage(sectionConfigFiles)
+	for _, f := range files {
+		s.set(sectionConfigFiles, f.VolumeName, contentDigest(f.FileContent))
+	}
+}
+
+func (s componentState) setProbes(probes []*dbmodel.TenantServiceProbe) {
+	s.manage(sectionProbes)
+	for _, p := range probes {
+		s.set(sectionProbes, p.Mode, stateValue("scheme", p.Scheme, "port", p.Port, "path", p.Path, "cmd", p.Cmd,
+			"initial_delay", p.InitialDelaySecond, "period", p.PeriodSecond, "timeout", p.TimeoutSecond,
+			"failure_threshold", p.FailureThreshold, "success_threshold", p.SuccessThreshold, "is_used", intValue(p.IsUsed)))
+	}
+}
+
+func (s componentState) setLabels(labels []*dbmodel.TenantServiceLable) {
+	s.manage(sectionLabels)
+	for _, l := range labels {
+		s.set(sectionLabels, l.LabelKey, l.LabelValue)
+	}
+}
+
+func (s componentState) setRelations(relations []*dbmodel.TenantServiceRelation) {
+	s.manage(sectionRelations)
+	for _, r := range relations {
+		s.set(sectionRelations, r.DependServiceID, stateValue("type", r.DependServiceType, "order", r.DependOrder))
+	}
+}
+
+func (s componentState) setPlugins(plugins []*dbmodel.TenantServicePluginRelation) {
+	s.manage(sectionPlugins)
+	for _, p := range plugins {
+		s.set(sectionPlugins, p.PluginID, stateValue("version", p.VersionID, "model", p.PluginModel, "switch", p.Switch,
+			"cpu", p.ContainerCPU, "memory", p.ContainerMemory))
+	}
+}
+
+func (s componentState) setHTTPRules(rules []*dbmodel.HTTPRule) {
+	s.manage(sectionHTTPRules)
+	for _, r := range rules {
+		s.set(sectionHTTPRules, r.UUID, stateValue("domain", r.Domain, "path", r.Path, "port", r.ContainerPort,
+			"header", r.Header, "cookie", r.Cookie, "weight", r.Weight, "ip", r.IP, "certificate", r.CertificateID))
+	}
+}
+
+func (s componentState) setTCPRules(rules []*dbmodel.TCPRule) {
+	s.manage(sectionTCPRules)
+	for _, r := range rules {
+		s.set(sectionTCPRules, r.UUID, stateValue("ip", r.IP, "port", r.Port, "container_port", r.ContainerPort))
+	}
+}
+
+// desiredComponentState converts a component spec through the same db models SyncComponents writes,
+// so that defaults and normalization are identical on both sides of the diff.
+func desiredComponentState(tenantID, appID string, c *model.Component) componentState {
+	componentID := c.ComponentBase.ComponentID
+	state := make(componentState)
+	state.setBase(c.ComponentBase.DbModel(tenantID, appID, ""))
+	if c.Ports != nil {
+		var ports []*dbmodel.TenantServicesPort
+		for i := range c.Ports {
+			ports = append(ports, c.Ports[i].DbModel(tenantID, componentID))
+		}
+		state.setPorts(ports)
+	}
+	if c.Envs != nil {
+		var envs []*dbmodel.TenantServiceEnvVar
+		for i := range c.Envs {
+			envs = append(envs, c.Envs[i].DbModel(tenantID, componentID))
+		}
+		state.setEnvs(envs)
+	}
+	if c.Volumes != nil {
+		var volumes []*dbmodel.TenantServiceVolume
+		for i := range c.Volumes {
+			volumes = append(volumes, c.Volumes[i].DbModel(componentID))
+		}
+		state.setVolumes(volumes)
+	}
+	if c.ConfigFiles != nil {
+		var files []*dbmodel.TenantServiceConfigFile
+		for i := range c.ConfigFiles {
+			files = append(files, c.ConfigFiles[i].DbModel(componentID))
+		}
+		state.setConfigFiles(files)
+	}
+	if c.Probes != nil {
+		var probes []*dbmodel.TenantServiceProbe
+		for i := range c.Probes {
+			probes = append(probes, c.Probes[i].DbModel(componentID))
+		}
+		state.setProbes(probes)
+	}
+	if c.Labels != nil {
+		var labels []*dbmodel.TenantServiceLable
+		for i := range c.Labels {
+			labels = append(labels, c.Labels[i].DbModel(componentID))
+		}
+		state.setLabels(labels)
+	}
+	if c.Relations != nil {
+		var relations []*dbmodel.TenantServiceRelation
+		for i := range c.Relations {
+			relations = append(relations, c.Relations[i].DbModel(tenantID, componentID))
+		}
+		state.setRelations(relations)
+	}
+	if c.Plugins != nil {
+		var plugins []*dbmodel.TenantServicePluginRelation
+		for i := range c.Plugins {
+			plugins = append(plugins, c.Plugins[i].DbModel(componentID))
+		}
+		state.setPlugins(plugins)
+	}
+	if c.HTTPRules != nil {
+		var rules []*dbmodel.HTTPRule
+		for i := range c.HTTPRules {
+			rules = append(rules, c.HTTPRules[i].DbModel(componentID))
+		}
+		state.setHTTPRules(rules)
+	}
+	if c.TCPRules != nil {
+		var rules []*dbmodel.TCPRule
+		for i := range c.TCPRules {
+			rules = append(rules, c.TCPRules[i].DbModel(componentID))
+		}
+		state.setTCPRules(rules)
+	}
+	return state
+}
+
+// currentComponentState loads every section of a component from the database.
+func currentComponentState(svc *dbmodel.TenantServices, volumes []*dbmodel.TenantServiceVolume,
+	httpRules []*dbmodel.HTTPRule, tcpRules []*dbmodel.TCPRule) (componentState, error) {
+	state := make(componentState)
+	state.setBase(svc)
+	ports, err := db.GetManager().TenantServicesPortDao().GetPortsByServiceID(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setPorts(ports)
+	envs, err := db.GetManager().TenantServiceEnvVarDao().GetServiceEnvs(svc.ServiceID, nil)
+	if err != nil {
+		return nil, err
+	}
+	state.setEnvs(envs)
+	state.setVolumes(volumes)
+	files, err := db.GetManager().TenantServiceConfigFileDao().GetConfigFileByServiceID(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setConfigFiles(files)
+	probes, err := db.GetManager().ServiceProbeDao().GetServiceProbes(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setProbes(probes)
+	labels, err := db.GetManager().TenantServiceLabelDao().GetTenantServiceLabel(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setLabels(labels)
+	relations, err := db.GetManager().TenantServiceRelationDao().GetTenantServiceRelations(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setRelations(relations)
+	plugins, err := db.GetManager().TenantServicePluginRelationDao().GetALLRelationByServiceID(svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	state.setPlugins(plugins)
+	state.setHTTPRules(httpRules)
+	state.setTCPRules(tcpRules)
+	return state, nil
+}
+
+// diffComponentState compares the managed sections of desired with current.
+// A nil current state means the component does not exist yet.
+func diffComponentState(current, desired componentState) []*model.FieldChange {
+	var changes []*model.FieldChange
+	for _, section := range componentSections {
+		want, managed := desired[section]
+		if !managed {
+			continue
+		}
+		have := current[section]
+		var keys []string
+		for key := range have {
+			keys = append(keys, key)
+		}
+		for key := range want {
+			if _, ok := have[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			oldValue, inOld := have[key]
+			newValue, inNew := want[key]
+			change := &model.FieldChange{Section: section, Key: key, Old: oldValue, New: newValue}
+			switch {
+			case !inOld:
+				change.Action = model.ChangeActionAdd
+			case !inNew:
+				change.Action = model.ChangeActionRemove
+			case oldValue != newValue:
+				change.Action = model.ChangeActionModify
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// DiffApp compares the desired components of the application with the database.
+// It returns the diff and the ids of the components that are not in the spec anymore.
+func (a *ApplicationAction) DiffApp(app *dbmodel.Application, components []*model.Component) (*model.AppDiff, []string, error) {
+	diff, deleteIDs, _, err := a.diffApp(app, components)
+	return diff, deleteIDs, err
+}
+
+// diffApp also returns the quota the apply needs on top of the current components
+func (a *ApplicationAction) diffApp(app *dbmodel.Application, components []*model.Component) (*model.AppDiff, []string, map[string]int, error) {
+	var componentIDs []string
+	desired := make(map[string]bool, len(components))
+	for _, c := range components {
+		componentID := c.ComponentBase.ComponentID
+		if desired[componentID] {
+			return nil, nil, nil, bcode.NewBadRequest(fmt.Sprintf("duplicate component %s", componentID))
+		}
+		desired[componentID] = true
+		componentIDs = append(componentIDs, componentID)
+	}
+	others, err := db.GetManager().TenantServiceDao().GetServiceByIDs(componentIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, svc := range others {
+		if svc.TenantID != app.TenantID || svc.AppID != app.AppID {
+			return nil, nil, nil, bcode.NewBadRequest(fmt.Sprintf("component %s belongs to another application", svc.ServiceID))
+		}
+	}
+
+	existing, err := db.GetManager().TenantServiceDao().ListByAppID(app.AppID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var existingIDs []string
+	for _, svc := range existing {
+		existingIDs = append(existingIDs, svc.ServiceID)
+	}
+	volumes, err := db.GetManager().TenantServiceVolumeDao().ListVolumesByComponentIDs(existingIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	httpRules, err := db.GetManager().HTTPRuleDao().ListByComponentIDs(existingIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tcpRules, err := db.GetManager().TCPRuleDao().ListByComponentIDs(existingIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	volumesByComponent := make(map[string][]*dbmodel.TenantServiceVolume)
+	for _, v := range volumes {
+		volumesByComponent[v.ServiceID] = append(volumesByComponent[v.ServiceID], v)
+	}
+	httpRulesByComponent := make(map[string][]*dbmodel.HTTPRule)
+	for _, r := range httpRules {
+		httpRulesByComponent[r.ServiceID] = append(httpRulesByComponent[r.ServiceID], r)
+	}
+	tcpRulesByComponent := make(map[string][]*dbmodel.TCPRule)
+	for _, r := range tcpRules {
+		tcpRulesByComponent[r.ServiceID] = append(tcpRulesByComponent[r.ServiceID], r)
+	}
+
+	current := make(map[string]componentState, len(existing))
+	diff := &model.AppDiff{}
+	need := make(map[string]int)
+	var deleteIDs []string
+	var deleted []*model.ComponentDiff
+	for _, svc := range existing {
+		if !desired[svc.ServiceID] {
+			deleteIDs = append(deleteIDs, svc.ServiceID)
+			need[QuotaComponents]--
+			need[QuotaStorage] -= volumeCapacity(volumesByComponent[svc.ServiceID])
+			need[QuotaGatewayRules] -= len(httpRulesByComponent[svc.ServiceID]) + len(tcpRulesByComponent[svc.ServiceID])
+			deleted = append(deleted, &model.ComponentDiff{
+				ComponentID:    svc.ServiceID,
+				ComponentAlias: svc.ServiceAlias,
+				Action:         model.DiffActionDelete,
+			})
+			continue
+		}
+		state, err := currentComponentState(svc, volumesByComponent[svc.ServiceID],
+			httpRulesByComponent[svc.ServiceID], tcpRulesByComponent[svc.ServiceID])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		current[svc.ServiceID] = state
+	}
+	for _, c := range components {
+		cd := &model.ComponentDiff{
+			ComponentID:    c.ComponentBase.ComponentID,
+			ComponentAlias: c.ComponentBase.ComponentAlias,
+		}
+		state, ok := current[cd.ComponentID]
+		cd.Changes = diffComponentState(state, desiredComponentState(app.TenantID, app.AppID, c))
+		// the sections left out of the spec are kept as they are
+		if c.Volumes != nil {
+			need[QuotaStorage] -= volumeCapacity(volumesByComponent[cd.ComponentID])
+			for _, v := range c.Volumes {
+				need[QuotaStorage] += int(v.VolumeCapacity)
+			}
+		}
+		if c.HTTPRules != nil {
+			need[QuotaGatewayRules] += len(c.HTTPRules) - len(httpRulesByComponent[cd.ComponentID])
+		}
+		if c.TCPRules != nil {
+			need[QuotaGatewayRules] += len(c.TCPRules) - len(tcpRulesByComponent[cd.ComponentID])
+		}
+		switch {
+		case !ok:
+			need[QuotaComponents]++
+			cd.Action = model.DiffActionCreate
+		case len(cd.Changes) > 0:
+			cd.Action = model.DiffActionUpdate
+		default:
+			cd.Action = model.DiffActionNone
+		}
+		diff.Components = append(diff.Components, cd)
+	}
+	diff.Components = append(diff.Components, deleted...)
+	return diff, deleteIDs, need, nil
+}
+
+// ApplyApp makes the components of the application match the spec in one transaction.
+// With dryRun it only returns the diff.
+func (a *ApplicationAction) ApplyApp(app *dbmodel.Application, components []*model.Component, dryRun bool) (*model.AppDiff, error) {
+	diff, deleteIDs, need, err := a.diffApp(app, components)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || !diff.Changed() {
+		return diff, nil
+	}
+	tenant, err := db.GetManager().TenantDao().GetTenantByUUID(app.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckTenantQuota(tenant, need); err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	if err := a.SyncComponents(app, components, deleteIDs); err != nil {
+		return nil, err
+	}
+	diff.Applied = true
+	return diff, nil
+}
+
+func volumeCapacity(volumes []*dbmodel.TenantServiceVolume) int {
+	var capacity int
+	for _, v := range volumes {
+		capacity += int(v.VolumeCapacity)
+	}
+	return capacity
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handler
+
+import (
+	"testing"
+
+	"github.com/gridworkz/kato/api/model"
+)
+
+func TestDiffComponentState(t *testing.T) {
+	spec := &model.Component{
+		ComponentBase: model.ComponentBase{ComponentID: "c1", ComponentAlias: "web", ContainerMemory: 512, Replicas: 2},
+		Envs: []model.ComponentEnv{
+			{AttrName: "MODE", AttrValue: "prod", Scope: "inner"},
+			{AttrName: "NEW", AttrValue: "1", Scope: "inner"},
+		},
+		Labels: []model.ComponentLabel{},
+	}
+	desired := desiredComponentState("t1", "a1", spec)
+
+	if changes := diffComponentState(desired, desired); len(changes) != 0 {
+		t.Fatalf("want no changes, got %d", len(changes))
+	}
+
+	created := diffComponentState(nil, desired)
+	for _, c := range created {
+		if c.Action != model.ChangeActionAdd {
+			t.Errorf("create: want add for %s/%s, got %s", c.Section, c.Key, c.Action)
+		}
+	}
+
+	current := desiredComponentState("t1", "a1", &model.Component{
+		ComponentBase: model.ComponentBase{ComponentID: "c1", ComponentAlias: "web", ContainerMemory: 512, Replicas: 1},
+		Envs: []model.ComponentEnv{
+			{AttrName: "MODE", AttrValue: "dev", Scope: "inner"},
+			{AttrName: "OLD", AttrValue: "1", Scope: "inner"},
+		},
+		Labels: []model.ComponentLabel{{LabelKey: "zone", LabelValue: "a"}},
+		Ports:  []model.TenantServicesPort{{ContainerPort: 80, Protocol: "http", PortAlias: "WEB"}},
+	})
+	want := map[string]string{
+		"base/replicas": model.ChangeActionModify,
+		"envs/MODE":     model.ChangeActionModify,
+		"envs/NEW":      model.ChangeActionAdd,
+		"envs/OLD":      model.ChangeActionRemove,
+		"labels/zone":   model.ChangeActionRemove,
+	}
+	changes := diffComponentState(current, desired)
+	if len(changes) != len(want) {
+		t.Fatalf("want %d changes, got %d", len(want), len(changes))
+	}
+	for _, c := range changes {
+		if action := want[c.Section+"/"+c.Key]; action != c.Action {
+			t.Errorf("%s/%s: want %q, got %q", c.Section, c.Key, action, c.Action)
+		}
+	}
+}
@@ -58,6 +58,8 @@ type ApplicationHandler interface {
 	DeleteConfigGroup(appID, configGroupName string) error
 	ListConfigGroups(appID string, page, pageSize int) (*model.ListApplicationConfigGroupResp, error)
 	SyncComponents(app *dbmodel.Application, components []*model.Component, deleteComponentIDs []string) error
+	DiffApp(app *dbmodel.Application, components []*model.Component) (*model.AppDiff, []string, error)
+	ApplyApp(app *dbmodel.Application, components []*model.Component, dryRun bool) (*model.AppDiff, error)
 	SyncComponentConfigGroupRels(tx *gorm.DB, app *dbmodel.Application, components []*model.Component) error
 	SyncAppConfigGroups(app *dbmodel.Application, appConfigGroups []model.AppConfigGroup) error
 	ListAppStatuses(ctx context.Context, appIDs []string) ([]*model.AppStatus, error)
@@ -676,6 +678,15 @@ func (a *ApplicationAction) deleteByComponentIDs(tx *gorm.DB, app *dbmodel.Appli
 	if err := db.GetManager().ThirdPartySvcDiscoveryCfgDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
 		return err
 	}
+	if err := db.GetManager().TenantServiceAutoscalerSchedulesDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
+		return err
+	}
+	if err := db.GetManager().TenantServiceIdlePolicyDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
+		return err
+	}
+	if err := db.GetManager().TenantServiceVolumeBackupPolicyDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
+		return err
+	}
 	autoScaleRules, err := db.GetManager().TenantServceAutoscalerRulesDaoTransactions(tx).ListByComponentIDs(componentIDs)
 	if err != nil {
 		return err
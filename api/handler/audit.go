@@ -0,0 +1,147 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	api_model "github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/util"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/sirupsen/logrus"
+)
+
+// the logs are read in batches when they are exported or verified
+var auditLogBatchSize = 500
+
+//AuditHandler records the mutating api calls and queries them
+type AuditHandler interface {
+	Record(log *dbmodel.AuditLog)
+	ListAuditLogs(query *api_model.AuditLogQuery) ([]*dbmodel.AuditLog, int64, *util.APIHandleError)
+	ExportAuditLogs(w io.Writer) error
+	VerifyAuditLogs() (*api_model.AuditChainStatus, *util.APIHandleError)
+}
+
+var defaultAuditHandler AuditHandler
+
+//CreateAuditManager create audit manager, the logs are appended by a single goroutine
+func CreateAuditManager() *AuditAction {
+	a := &AuditAction{logs: make(chan *dbmodel.AuditLog, 1024)}
+	go a.run()
+	return a
+}
+
+//GetAuditHandler get audit handler
+func GetAuditHandler() AuditHandler {
+	return defaultAuditHandler
+}
+
+//AuditAction action
+type AuditAction struct {
+	logs chan *dbmodel.AuditLog
+}
+
+//Record appends the log asynchronously, it is appended by the caller when the queue is full
+func (a *AuditAction) Record(log *dbmodel.AuditLog) {
+	select {
+	case a.logs <- log:
+	default:
+		a.append(log)
+	}
+}
+
+func (a *AuditAction) run() {
+	for log := range a.logs {
+		a.append(log)
+	}
+}
+
+func (a *AuditAction) append(log *dbmodel.AuditLog) {
+	var err error
+	for i := 0; i < 3; i++ {
+		// the hash is computed with the seq and the prev hash got in the append
+		if err = db.GetManager().AuditLogDao().Append(log); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
+	}
+	logrus.Errorf("append audit log of %s %s by %s: %v", log.Method, log.Path, log.Principal, err)
+}
+
+//ListAuditLogs lists the logs, newest first
+func (a *AuditAction) ListAuditLogs(query *api_model.AuditLogQuery) ([]*dbmodel.AuditLog, int64, *util.APIHandleError) {
+	logs, total, err := db.GetManager().AuditLogDao().ListAuditLogs(query.TenantID, query.ServiceID, query.Principal, query.Start, query.End, query.Page, query.PageSize)
+	if err != nil {
+		return nil, 0, util.CreateAPIHandleErrorFromDBError("list audit logs", err)
+	}
+	return logs, total, nil
+}
+
+//ExportAuditLogs writes all the logs in the chain order, one json object a line
+func (a *AuditAction) ExportAuditLogs(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	var seq int64
+	for {
+		logs, err := db.GetManager().AuditLogDao().ListAuditLogsAfter(seq, auditLogBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if err := encoder.Encode(log); err != nil {
+				return err
+			}
+			seq = log.Seq
+		}
+		if len(logs) < auditLogBatchSize {
+			return nil
+		}
+	}
+}
+
+//VerifyAuditLogs walks the chain from the first log, it stops at the first log which is modified or follows a removed one
+func (a *AuditAction) VerifyAuditLogs() (*api_model.AuditChainStatus, *util.APIHandleError) {
+	status := &api_model.AuditChainStatus{}
+	var seq int64
+	var prevHash string
+	for {
+		logs, err := db.GetManager().AuditLogDao().ListAuditLogsAfter(seq, auditLogBatchSize)
+		if err != nil {
+			return nil, util.CreateAPIHandleErrorFromDBError("list audit logs", err)
+		}
+		if broken := dbmodel.VerifyAuditChain(logs, seq, prevHash); broken != 0 {
+			status.BrokenSeq = broken
+			for _, log := range logs {
+				if log.Seq == broken {
+					break
+				}
+				status.Verified++
+			}
+			return status, nil
+		}
+		status.Verified += int64(len(logs))
+		if len(logs) < auditLogBatchSize {
+			status.Intact = true
+			return status, nil
+		}
+		seq, prevHash = logs[len(logs)-1].Seq, logs[len(logs)-1].Hash
+	}
+}
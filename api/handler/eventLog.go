@@ -110,7 +110,7 @@ func (l *LogAction) GetLogInstance(serviceID string) (string, error) {
 
 //GetLevelLog get event log
 func (l *LogAction) GetLevelLog(eventID string, level string) (*api_model.DataLog, error) {
-	re, err := l.eventdb.GetMessages(eventID, level, 0)
+	re, err := l.eventdb.GetMessages(eventID, level, eventdb.MessageQuery{})
 	if err != nil {
 		return nil, err
 	}
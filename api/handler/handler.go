@@ -70,6 +70,8 @@ func InitHandle(conf option.Config,
 	defaultEventHandler = CreateLogManager(etcdcli)
 	shareHandler = &share.ServiceShareHandle{MQClient: mqClient, EtcdCli: etcdcli}
 	pluginShareHandler = &share.PluginShareHandle{MQClient: mqClient, EtcdCli: etcdcli}
+	defaultAPITokenHandler = CreateAPITokenManager()
+	defaultAuditHandler = CreateAuditManager()
 	if err := CreateTokenIdenHandler(conf); err != nil {
 		logrus.Errorf("create token identification mannager error, %v", err)
 		return err
@@ -82,6 +84,7 @@ func InitHandle(conf option.Config,
 	defPodHandler = NewPodHandler(statusCli)
 	defClusterHandler = NewClusterHandler(kubeClient, conf.RbdNamespace)
 	defaultVolumeTypeHandler = CreateVolumeTypeManger(statusCli)
+	defaultVolumeBackupHandler = CreateVolumeBackupManager(mqClient, statusCli)
 	defaultEtcdHandler = NewEtcdHandler(etcdcli)
 	defaultmonitorHandler = NewMonitorHandler(prometheusCli)
 	defServiceEventHandler = NewServiceEventHandler()
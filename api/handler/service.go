@@ -169,9 +169,11 @@ func (s *ServiceAction) buildFromMarketSlug(r *api_model.BuildServiceStruct, ser
 		topic = gclient.WindowsBuilderTopic
 	}
 	return s.MQClient.SendBuilderTopic(gclient.TaskStruct{
-		Topic:    topic,
-		TaskType: "build_from_market_slug",
-		TaskBody: body,
+		Topic:     topic,
+		TaskType:  "build_from_market_slug",
+		TaskBody:  body,
+		Priority:  r.Body.Priority,
+		NotBefore: unixTime(r.Body.NotBefore),
 	})
 }
 
@@ -206,9 +208,11 @@ func (s *ServiceAction) buildFromImage(r *api_model.BuildServiceStruct, service
 		topic = gclient.WindowsBuilderTopic
 	}
 	return s.MQClient.SendBuilderTopic(gclient.TaskStruct{
-		Topic:    topic,
-		TaskType: "build_from_image",
-		TaskBody: body,
+		Topic:     topic,
+		TaskType:  "build_from_image",
+		TaskBody:  body,
+		Priority:  r.Body.Priority,
+		NotBefore: unixTime(r.Body.NotBefore),
 	})
 }
 
@@ -246,12 +250,22 @@ func (s *ServiceAction) buildFromSourceCode(r *api_model.BuildServiceStruct, ser
 		topic = gclient.WindowsBuilderTopic
 	}
 	return s.MQClient.SendBuilderTopic(gclient.TaskStruct{
-		Topic:    topic,
-		TaskType: "build_from_source_code",
-		TaskBody: body,
+		Topic:     topic,
+		TaskType:  "build_from_source_code",
+		TaskBody:  body,
+		Priority:  r.Body.Priority,
+		NotBefore: unixTime(r.Body.NotBefore),
 	})
 }
 
+//unixTime converts the unix timestamp to time, zero timestamp is zero time
+func unixTime(timestamp int64) time.Time {
+	if timestamp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
+
 func (s *ServiceAction) isWindowsService(serviceID string) bool {
 	label, err := db.GetManager().TenantServiceLabelDao().GetLabelByNodeSelectorKey(serviceID, "windows")
 	if label == nil || err != nil {
@@ -1701,6 +1715,11 @@ func (s *ServiceAction) VolumnVar(tsv *dbmodel.TenantServiceVolume, tenantID, fi
 				return util.CreateAPIHandleErrorFromDBError("delete volume", err)
 			}
 
+			if err := db.GetManager().TenantServiceVolumeBackupPolicyDaoTransactions(tx).DeleteByServiceVolume(tsv.ServiceID, tsv.VolumeName); err != nil {
+				tx.Rollback()
+				return util.CreateAPIHandleErrorFromDBError("delete volume backup policy", err)
+			}
+
 			err = s.MQClient.SendBuilderTopic(gclient.TaskStruct{
 				Topic:    gclient.WorkerTopic,
 				TaskType: "volume_gc",
@@ -2237,6 +2256,7 @@ func (s *ServiceAction) deleteComponent(tx *gorm.DB, service *dbmodel.TenantServ
 		db.GetManager().ThirdPartySvcDiscoveryCfgDaoTransactions(tx).DeleteByServiceID,
 		db.GetManager().TenantServiceLabelDaoTransactions(tx).DeleteLabelByServiceID,
 		db.GetManager().VersionInfoDaoTransactions(tx).DeleteVersionByServiceID,
+		db.GetManager().BuildCacheDaoTransactions(tx).DeleteByServiceID,
 		db.GetManager().TenantPluginVersionENVDaoTransactions(tx).DeleteEnvByServiceID,
 		db.GetManager().ServiceProbeDaoTransactions(tx).DELServiceProbesByServiceID,
 		db.GetManager().ServiceEventDaoTransactions(tx).DelEventByServiceID,
@@ -2256,7 +2276,13 @@ func (s *ServiceAction) deleteComponent(tx *gorm.DB, service *dbmodel.TenantServ
 			}
 		}
 	}
-	return nil
+	if err := db.GetManager().TenantServiceAutoscalerSchedulesDaoTransactions(tx).DeleteByComponentIDs([]string{service.ServiceID}); err != nil {
+		return err
+	}
+	if err := db.GetManager().TenantServiceIdlePolicyDaoTransactions(tx).DeleteByComponentIDs([]string{service.ServiceID}); err != nil {
+		return err
+	}
+	return db.GetManager().TenantServiceVolumeBackupPolicyDaoTransactions(tx).DeleteByComponentIDs([]string{service.ServiceID})
 }
 
 // delServiceMetadata deletes service-related metadata in the database.
@@ -2381,13 +2407,7 @@ func (s *ServiceAction) AddAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 	}
 
 	for _, metric := range req.Metrics {
-		m := &dbmodel.TenantServiceAutoscalerRuleMetrics{
-			RuleID:            req.RuleID,
-			MetricsType:       metric.MetricsType,
-			MetricsName:       metric.MetricsName,
-			MetricTargetType:  metric.MetricTargetType,
-			MetricTargetValue: metric.MetricTargetValue,
-		}
+		m := metric.DbModel(req.RuleID)
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
 			return err
@@ -2438,13 +2458,7 @@ func (s *ServiceAction) UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 	}
 
 	for _, metric := range req.Metrics {
-		m := &dbmodel.TenantServiceAutoscalerRuleMetrics{
-			RuleID:            req.RuleID,
-			MetricsType:       metric.MetricsType,
-			MetricsName:       metric.MetricsName,
-			MetricTargetType:  metric.MetricTargetType,
-			MetricTargetValue: metric.MetricTargetValue,
-		}
+		m := metric.DbModel(req.RuleID)
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
 			return err
@@ -2483,6 +2497,81 @@ func (s *ServiceAction) ListScalingRecords(serviceID string, page, pageSize int)
 	return records, count, nil
 }
 
+// AddAutoscalerSchedule -
+func (s *ServiceAction) AddAutoscalerSchedule(req *api_model.AutoscalerScheduleReq) error {
+	return db.GetManager().TenantServiceAutoscalerSchedulesDao().AddModel(&dbmodel.TenantServiceAutoscalerSchedules{
+		ScheduleID: req.ScheduleID,
+		ServiceID:  req.ServiceID,
+		Enable:     req.Enable,
+		Schedule:   req.Schedule,
+		TimeZone:   req.TimeZone,
+		Replicas:   req.Replicas,
+	})
+}
+
+// UpdAutoscalerSchedule -
+func (s *ServiceAction) UpdAutoscalerSchedule(req *api_model.AutoscalerScheduleReq) error {
+	schedule, err := db.GetManager().TenantServiceAutoscalerSchedulesDao().GetByScheduleID(req.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule.ServiceID != req.ServiceID {
+		return gorm.ErrRecordNotFound
+	}
+	schedule.Enable = req.Enable
+	schedule.Schedule = req.Schedule
+	schedule.TimeZone = req.TimeZone
+	schedule.Replicas = req.Replicas
+	return db.GetManager().TenantServiceAutoscalerSchedulesDao().UpdateModel(schedule)
+}
+
+// DeleteAutoscalerSchedule -
+func (s *ServiceAction) DeleteAutoscalerSchedule(serviceID, scheduleID string) error {
+	schedule, err := db.GetManager().TenantServiceAutoscalerSchedulesDao().GetByScheduleID(scheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule.ServiceID != serviceID {
+		return gorm.ErrRecordNotFound
+	}
+	return db.GetManager().TenantServiceAutoscalerSchedulesDao().DeleteByScheduleID(scheduleID)
+}
+
+// ListAutoscalerSchedules -
+func (s *ServiceAction) ListAutoscalerSchedules(serviceID string) ([]*dbmodel.TenantServiceAutoscalerSchedules, error) {
+	return db.GetManager().TenantServiceAutoscalerSchedulesDao().ListByServiceID(serviceID)
+}
+
+// GetIdlePolicy returns the idle policy of the component, a disabled one if it is not set
+func (s *ServiceAction) GetIdlePolicy(serviceID string) (*dbmodel.TenantServiceIdlePolicy, error) {
+	policy, err := db.GetManager().TenantServiceIdlePolicyDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &dbmodel.TenantServiceIdlePolicy{ServiceID: serviceID}, nil
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdIdlePolicy creates or updates the idle policy of the component
+func (s *ServiceAction) UpdIdlePolicy(req *api_model.IdlePolicyReq) error {
+	policy, err := db.GetManager().TenantServiceIdlePolicyDao().GetByServiceID(req.ServiceID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		return db.GetManager().TenantServiceIdlePolicyDao().AddModel(&dbmodel.TenantServiceIdlePolicy{
+			ServiceID:   req.ServiceID,
+			Enable:      req.Enable,
+			IdleMinutes: req.IdleMinutes,
+		})
+	}
+	policy.Enable = req.Enable
+	policy.IdleMinutes = req.IdleMinutes
+	return db.GetManager().TenantServiceIdlePolicyDao().UpdateModel(policy)
+}
+
 // SyncComponentBase -
 func (s *ServiceAction) SyncComponentBase(tx *gorm.DB, app *dbmodel.Application, components []*api_model.Component) error {
 	var (
@@ -2696,6 +2785,9 @@ func (s *ServiceAction) SyncComponentProbes(tx *gorm.DB, components []*api_model
 		probes       []*dbmodel.TenantServiceProbe
 	)
 	for _, component := range components {
+		if component.Probes == nil {
+			continue
+		}
 		componentIDs = append(componentIDs, component.ComponentBase.ComponentID)
 		modes := make(map[string]struct{})
 		for _, probe := range component.Probes {
@@ -2845,6 +2937,9 @@ func (s *ServiceAction) SyncComponentScaleRules(tx *gorm.DB, components []*api_m
 		autoScaleRuleMetrics []*dbmodel.TenantServiceAutoscalerRuleMetrics
 	)
 	for _, component := range components {
+		if component.AutoScaleRule.RuleID == "" {
+			continue
+		}
 		componentIDs = append(componentIDs, component.ComponentBase.ComponentID)
 		autoScaleRuleIDs = append(autoScaleRuleIDs, component.AutoScaleRule.RuleID)
 		autoScaleRules = append(autoScaleRules, component.AutoScaleRule.DbModel(component.ComponentBase.ComponentID))
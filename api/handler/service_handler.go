@@ -57,6 +57,7 @@ type ServiceHandler interface {
 	PortInner(tenantName, serviceID, operation string, port int) error
 	VolumnVar(avs *dbmodel.TenantServiceVolume, tenantID, fileContent, action string) *util.APIHandleError
 	UpdVolume(sid string, req *api_model.UpdVolumeReq) error
+	ExpandVolume(tenantID, serviceID, volumeName, eventID string, capacity int64) *util.APIHandleError
 	VolumeDependency(tsr *dbmodel.TenantServiceMountRelation, action string) *util.APIHandleError
 	GetDepVolumes(serviceID string) ([]*dbmodel.TenantServiceMountRelation, *util.APIHandleError)
 	GetVolumes(serviceID string) ([]*api_model.VolumeWithStatusStruct, *util.APIHandleError)
@@ -85,6 +86,12 @@ type ServiceHandler interface {
 	AddAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	ListScalingRecords(serviceID string, page, pageSize int) ([]*dbmodel.TenantServiceScalingRecords, int, error)
+	AddAutoscalerSchedule(req *api_model.AutoscalerScheduleReq) error
+	UpdAutoscalerSchedule(req *api_model.AutoscalerScheduleReq) error
+	DeleteAutoscalerSchedule(serviceID, scheduleID string) error
+	ListAutoscalerSchedules(serviceID string) ([]*dbmodel.TenantServiceAutoscalerSchedules, error)
+	GetIdlePolicy(serviceID string) (*dbmodel.TenantServiceIdlePolicy, error)
+	UpdIdlePolicy(req *api_model.IdlePolicyReq) error
 
 	UpdateServiceMonitor(tenantID, serviceID, name string, update api_model.UpdateServiceMonitorRequestStruct) (*dbmodel.TenantServiceMonitor, error)
 	DeleteServiceMonitor(tenantID, serviceID, name string) (*dbmodel.TenantServiceMonitor, error)
@@ -276,18 +276,20 @@ func (o *OperationHandler) buildFromMarketSlug(r *model.ComponentBuildReq, servi
 	body["service_alias"] = service.ServiceAlias
 	body["slug_info"] = r.SlugInfo
 	body["configs"] = r.Configs
-	return o.sendBuildTopic(service.ServiceID, "build_from_market_slug", body)
+	return o.sendBuildTopic(r, service.ServiceID, "build_from_market_slug", body)
 }
-func (o *OperationHandler) sendBuildTopic(serviceID, taskType string, body map[string]interface{}) error {
+func (o *OperationHandler) sendBuildTopic(r *model.ComponentBuildReq, serviceID, taskType string, body map[string]interface{}) error {
 
 	topic := gclient.BuilderTopic
 	if o.isWindowsService(serviceID) {
 		topic = gclient.WindowsBuilderTopic
 	}
 	return o.mqCli.SendBuilderTopic(gclient.TaskStruct{
-		Topic:    topic,
-		TaskType: taskType,
-		TaskBody: body,
+		Topic:     topic,
+		TaskType:  taskType,
+		TaskBody:  body,
+		Priority:  r.Priority,
+		NotBefore: unixTime(r.NotBefore),
 	})
 }
 
@@ -314,7 +316,7 @@ func (o *OperationHandler) buildFromImage(r *model.ComponentBuildReq, service *d
 		body["password"] = r.ImageInfo.Password
 	}
 	body["configs"] = r.Configs
-	return o.sendBuildTopic(service.ServiceID, "build_from_image", body)
+	return o.sendBuildTopic(r, service.ServiceID, "build_from_image", body)
 }
 
 func (o *OperationHandler) buildFromSourceCode(r *model.ComponentBuildReq, service *dbmodel.TenantServices) error {
@@ -341,7 +343,7 @@ func (o *OperationHandler) buildFromSourceCode(r *model.ComponentBuildReq, servi
 	}
 	body["expire"] = 180
 	body["configs"] = r.Configs
-	return o.sendBuildTopic(service.ServiceID, "build_from_source_code", body)
+	return o.sendBuildTopic(r, service.ServiceID, "build_from_source_code", body)
 }
 
 func (o *OperationHandler) isWindowsService(serviceID string) bool {
@@ -0,0 +1,115 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	gclient "github.com/gridworkz/kato/mq/client"
+	"github.com/gridworkz/kato/util"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	workerutil "github.com/gridworkz/kato/worker/util"
+	"github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//volumeStorageClass returns the storage class of the claims of the volume
+func volumeStorageClass(volume *dbmodel.TenantServiceVolume) string {
+	switch volume.VolumeType {
+	case dbmodel.ShareFileVolumeType.String():
+		return v1.KatoStatefuleShareStorageClass
+	case dbmodel.LocalVolumeType.String():
+		return v1.KatoStatefuleLocalStorageClass
+	default:
+		return volume.VolumeType
+	}
+}
+
+//ExpandVolume grows the capacity of the volume, the claims of the component are resized by the worker
+func (s *ServiceAction) ExpandVolume(tenantID, serviceID, volumeName, eventID string, capacity int64) *util.APIHandleError {
+	volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(serviceID, volumeName)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get volume", err)
+	}
+	switch volume.VolumeType {
+	case dbmodel.MemoryFSVolumeType.String(), dbmodel.ConfigFileVolumeType.String():
+		return util.CreateAPIHandleError(400, fmt.Errorf("the volume of type %s has no capacity", volume.VolumeType))
+	}
+	if !volume.AllowExpansion {
+		return util.CreateAPIHandleError(400, fmt.Errorf("the volume %s does not allow expansion", volumeName))
+	}
+	if capacity <= volume.VolumeCapacity {
+		return util.CreateAPIHandleError(400, fmt.Errorf("the capacity can only be increased, the current capacity is %d", volume.VolumeCapacity))
+	}
+	storageClass := volumeStorageClass(volume)
+	sc, err := s.kubeClient.StorageV1().StorageClasses().Get(context.Background(), storageClass, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return util.CreateAPIHandleError(400, fmt.Errorf("the storage class %s is not found", storageClass))
+		}
+		return util.CreateAPIHandleError(500, fmt.Errorf("get storage class %s: %v", storageClass, err))
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return util.CreateAPIHandleError(400, fmt.Errorf("the storage class %s does not allow volume expansion", storageClass))
+	}
+	volumeType, err := db.GetManager().VolumeTypeDao().GetVolumeTypeByType(volume.VolumeType)
+	if err == nil && volumeType != nil && volumeType.CapacityValidation != "" {
+		if err := workerutil.ValidateVolumeCapacity(volumeType.CapacityValidation, capacity); err != nil {
+			return util.CreateAPIHandleError(400, err)
+		}
+	}
+
+	tx := db.GetManager().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Unexpected panic occurred, rollback transaction: %v", r)
+			tx.Rollback()
+		}
+	}()
+	volume.VolumeCapacity = capacity
+	if err := db.GetManager().TenantServiceVolumeDaoTransactions(tx).UpdateModel(volume); err != nil {
+		tx.Rollback()
+		return util.CreateAPIHandleErrorFromDBError("update volume capacity", err)
+	}
+	// the new deployments use the capacity in db, the running claims are resized by the worker
+	err = s.MQClient.SendBuilderTopic(gclient.TaskStruct{
+		TaskType: "expand_volume",
+		TaskBody: map[string]interface{}{
+			"tenant_id":   tenantID,
+			"service_id":  serviceID,
+			"volume_id":   volume.ID,
+			"volume_name": volume.VolumeName,
+			"capacity":    capacity,
+			"event_id":    eventID,
+		},
+		Topic: gclient.WorkerTopic,
+	})
+	if err != nil {
+		tx.Rollback()
+		logrus.Errorf("send 'expand_volume' task: %v", err)
+		return util.CreateAPIHandleError(500, fmt.Errorf("send expand volume task: %v", err))
+	}
+	if err := tx.Commit().Error; err != nil {
+		return util.CreateAPIHandleErrorFromDBError("commit volume capacity", err)
+	}
+	return nil
+}
@@ -50,4 +50,7 @@ type TenantHandler interface {
 	DeleteTenant(ctx context.Context, tenantID string) error
 	GetClusterResource(ctx context.Context) *ClusterResourceStats
 	CheckResourceName(ctx context.Context, namespace string, req *model.CheckResourceNameReq) (*model.CheckResourceNameResp, error)
+	TenantQuotaUsage(tenant *dbmodel.Tenants) (map[string]int, error)
+	UpdateTenantQuota(ctx context.Context, tenant *dbmodel.Tenants, quota *api_model.TenantQuota) error
+	SyncTenantQuota(ctx context.Context, tenant *dbmodel.Tenants) error
 }
@@ -0,0 +1,320 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api_model "github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/util/constants"
+	workerutil "github.com/gridworkz/kato/worker/util"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the dimensions of the tenant quota
+const (
+	QuotaMemory           = "memory"
+	QuotaCPU              = "cpu"
+	QuotaStorage          = "storage"
+	QuotaComponents       = "components"
+	QuotaPods             = "pods"
+	QuotaGatewayRules     = "gateway_rules"
+	QuotaBuildConcurrency = "build_concurrency"
+)
+
+var quotaDimensions = []string{QuotaMemory, QuotaCPU, QuotaStorage, QuotaComponents, QuotaPods, QuotaGatewayRules, QuotaBuildConcurrency}
+
+// the names of the objects mirroring the quota in the tenant namespace
+const (
+	tenantResourceQuotaName = "kato-tenant-quota"
+	tenantLimitRangeName    = "kato-tenant-limits"
+)
+
+// the builds not finished in an hour are taken as lost
+var buildConcurrencyWindow = time.Hour
+
+// the deployments roll with the default max surge of 25%, the mirrored ResourceQuota leaves
+// room for the pods of the new version which are started before the old ones are stopped
+const quotaSurgePercent = 25
+
+func tenantQuotaLimits(tenant *dbmodel.Tenants) map[string]int {
+	return map[string]int{
+		QuotaMemory:           tenant.LimitMemory,
+		QuotaCPU:              tenant.LimitCPU,
+		QuotaStorage:          tenant.LimitStorage,
+		QuotaComponents:       tenant.LimitComponents,
+		QuotaPods:             tenant.LimitPods,
+		QuotaGatewayRules:     tenant.LimitGatewayRules,
+		QuotaBuildConcurrency: tenant.LimitBuildConcurrency,
+	}
+}
+
+//checkTenantQuota returns the error of the first limited dimension which can not afford the need
+func checkTenantQuota(limits, used, need map[string]int) error {
+	for _, dim := range quotaDimensions {
+		if need[dim] <= 0 || limits[dim] == 0 {
+			continue
+		}
+		if used[dim]+need[dim] > limits[dim] {
+			return fmt.Errorf("tenant_lack_of_%s", dim)
+		}
+	}
+	return nil
+}
+
+//QuotaUsages returns the usages of the tenant against its limits, in the order of the dimensions
+func QuotaUsages(tenant *dbmodel.Tenants, used map[string]int) []*api_model.QuotaUsage {
+	limits := tenantQuotaLimits(tenant)
+	usages := make([]*api_model.QuotaUsage, 0, len(quotaDimensions))
+	for _, dim := range quotaDimensions {
+		usages = append(usages, &api_model.QuotaUsage{Dimension: dim, Limit: limits[dim], Used: used[dim]})
+	}
+	return usages
+}
+
+//ComponentPodQuota returns the cpu in millicores and the memory in MB that a pod of the component with the
+//memory takes, counted as the worker converts it: the cpu limit of the main container is ES_CPULIMIT or derived
+//from its memory, and every enabled plugin runs as a sidecar with its own resources
+func ComponentPodQuota(service *dbmodel.TenantServices, memory int) (cpu, mem int, err error) {
+	_, limit := workerutil.DefaultCPU(memory)
+	env, err := db.GetManager().TenantServiceEnvVarDao().GetEnv(service.ServiceID, "ES_CPULIMIT")
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, 0, errors.Wrap(err, "get cpu limit env")
+	}
+	if env != nil {
+		if v, _ := strconv.Atoi(env.AttrValue); v > 0 {
+			limit = int64(v)
+		}
+	}
+	cpu, mem = int(limit), memory
+	plugins, err := db.GetManager().TenantServicePluginRelationDao().GetALLRelationByServiceID(service.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, 0, errors.Wrap(err, "list plugins")
+	}
+	for _, plugin := range plugins {
+		if !plugin.Switch {
+			continue
+		}
+		_, limit := workerutil.DefaultCPU(plugin.ContainerMemory)
+		if plugin.ContainerCPU > 0 {
+			limit = int64(plugin.ContainerCPU)
+		}
+		cpu += int(limit)
+		mem += plugin.ContainerMemory
+	}
+	return cpu, mem, nil
+}
+
+//TenantQuotaUsage returns the usage of every quota dimension, the memory, cpu and pods are counted on the running components
+func (t *TenantAction) TenantQuotaUsage(tenant *dbmodel.Tenants) (map[string]int, error) {
+	used := make(map[string]int, len(quotaDimensions))
+	services, err := db.GetManager().TenantServiceDao().GetServicesByTenantID(tenant.UUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list components")
+	}
+	used[QuotaComponents] = len(services)
+	var serviceIDs []string
+	for _, service := range services {
+		serviceIDs = append(serviceIDs, service.ServiceID)
+	}
+	if len(serviceIDs) > 0 {
+		statuses := t.statusCli.GetStatuss(strings.Join(serviceIDs, ","))
+		for _, service := range services {
+			if dbmodel.ServiceKind(service.Kind) == dbmodel.ServiceKindThirdParty || t.statusCli.IsClosedStatus(statuses[service.ServiceID]) {
+				continue
+			}
+			cpu, mem, err := ComponentPodQuota(service, service.ContainerMemory)
+			if err != nil {
+				return nil, err
+			}
+			used[QuotaMemory] += mem * service.Replicas
+			used[QuotaCPU] += cpu * service.Replicas
+			used[QuotaPods] += service.Replicas
+		}
+		volumes, err := db.GetManager().TenantServiceVolumeDao().ListVolumesByComponentIDs(serviceIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "list volumes")
+		}
+		for _, volume := range volumes {
+			used[QuotaStorage] += int(volume.VolumeCapacity)
+		}
+		httpRules, err := db.GetManager().HTTPRuleDao().ListByComponentIDs(serviceIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "list http rules")
+		}
+		tcpRules, err := db.GetManager().TCPRuleDao().ListByComponentIDs(serviceIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "list tcp rules")
+		}
+		used[QuotaGatewayRules] = len(httpRules) + len(tcpRules)
+	}
+	builds, err := runningBuilds(tenant)
+	if err != nil {
+		return nil, err
+	}
+	used[QuotaBuildConcurrency] = builds
+	return used, nil
+}
+
+func runningBuilds(tenant *dbmodel.Tenants) (int, error) {
+	builds, err := db.GetManager().ServiceEventDao().CountUnfinishedEventsByTenant(tenant.UUID, "build-service", time.Now().Add(-buildConcurrencyWindow))
+	if err != nil {
+		return 0, errors.Wrap(err, "count running builds")
+	}
+	return builds, nil
+}
+
+//UpdateTenantQuota saves the limits of the tenant and mirrors them in its namespace
+func (t *TenantAction) UpdateTenantQuota(ctx context.Context, tenant *dbmodel.Tenants, quota *api_model.TenantQuota) error {
+	tenant.LimitMemory = quota.LimitMemory
+	tenant.LimitCPU = quota.LimitCPU
+	tenant.LimitStorage = quota.LimitStorage
+	tenant.LimitComponents = quota.LimitComponents
+	tenant.LimitPods = quota.LimitPods
+	tenant.LimitGatewayRules = quota.LimitGatewayRules
+	tenant.LimitBuildConcurrency = quota.LimitBuildConcurrency
+	if err := db.GetManager().TenantDao().UpdateModel(tenant); err != nil {
+		return errors.Wrap(err, "update tenant")
+	}
+	return t.SyncTenantQuota(ctx, tenant)
+}
+
+//SyncTenantQuota mirrors the memory, cpu, storage and pods limits of the tenant as the ResourceQuota and LimitRange of its namespace,
+//the LimitRange gives the containers without resources the defaults the ResourceQuota requires
+func (t *TenantAction) SyncTenantQuota(ctx context.Context, tenant *dbmodel.Tenants) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	quota, limitRange := tenantResourceQuota(tenant), tenantLimitRange(tenant)
+	if quota != nil || limitRange != nil {
+		_, err := t.kubeClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   tenant.UUID,
+				Labels: map[string]string{constants.ResourceManagedByLabel: constants.Kato},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "create tenant namespace")
+		}
+	}
+
+	quotas := t.kubeClient.CoreV1().ResourceQuotas(tenant.UUID)
+	if quota == nil {
+		if err := quotas.Delete(ctx, tenantResourceQuotaName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete resource quota")
+		}
+	} else {
+		old, err := quotas.Get(ctx, tenantResourceQuotaName, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			_, err = quotas.Create(ctx, quota, metav1.CreateOptions{})
+		} else if err == nil {
+			quota.ResourceVersion = old.ResourceVersion
+			_, err = quotas.Update(ctx, quota, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return errors.Wrap(err, "apply resource quota")
+		}
+	}
+
+	limitRanges := t.kubeClient.CoreV1().LimitRanges(tenant.UUID)
+	if limitRange == nil {
+		if err := limitRanges.Delete(ctx, tenantLimitRangeName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete limit range")
+		}
+		return nil
+	}
+	old, err := limitRanges.Get(ctx, tenantLimitRangeName, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = limitRanges.Create(ctx, limitRange, metav1.CreateOptions{})
+	} else if err == nil {
+		limitRange.ResourceVersion = old.ResourceVersion
+		_, err = limitRanges.Update(ctx, limitRange, metav1.UpdateOptions{})
+	}
+	return errors.Wrap(err, "apply limit range")
+}
+
+func withSurge(limit int) int64 {
+	return int64(limit) + (int64(limit)*quotaSurgePercent+99)/100
+}
+
+func tenantResourceQuota(tenant *dbmodel.Tenants) *corev1.ResourceQuota {
+	hard := corev1.ResourceList{}
+	if tenant.LimitMemory > 0 {
+		hard[corev1.ResourceLimitsMemory] = *resource.NewQuantity(withSurge(tenant.LimitMemory)*1024*1024, resource.BinarySI)
+	}
+	if tenant.LimitCPU > 0 {
+		hard[corev1.ResourceLimitsCPU] = *resource.NewMilliQuantity(withSurge(tenant.LimitCPU), resource.DecimalSI)
+	}
+	if tenant.LimitStorage > 0 {
+		hard[corev1.ResourceRequestsStorage] = *resource.NewQuantity(int64(tenant.LimitStorage)*1024*1024*1024, resource.BinarySI)
+	}
+	if tenant.LimitPods > 0 {
+		hard[corev1.ResourcePods] = *resource.NewQuantity(withSurge(tenant.LimitPods), resource.DecimalSI)
+	}
+	if len(hard) == 0 {
+		return nil
+	}
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenantResourceQuotaName,
+			Namespace: tenant.UUID,
+			Labels:    map[string]string{constants.ResourceManagedByLabel: constants.Kato},
+		},
+		Spec: corev1.ResourceQuotaSpec{Hard: hard},
+	}
+}
+
+func tenantLimitRange(tenant *dbmodel.Tenants) *corev1.LimitRange {
+	limits, requests := corev1.ResourceList{}, corev1.ResourceList{}
+	if tenant.LimitMemory > 0 {
+		limits[corev1.ResourceMemory] = resource.MustParse("128Mi")
+		requests[corev1.ResourceMemory] = resource.MustParse("64Mi")
+	}
+	if tenant.LimitCPU > 0 {
+		limits[corev1.ResourceCPU] = resource.MustParse("100m")
+		requests[corev1.ResourceCPU] = resource.MustParse("30m")
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenantLimitRangeName,
+			Namespace: tenant.UUID,
+			Labels:    map[string]string{constants.ResourceManagedByLabel: constants.Kato},
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				Default:        limits,
+				DefaultRequest: requests,
+			}},
+		},
+	}
+}
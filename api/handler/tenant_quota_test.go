@@ -0,0 +1,77 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package handler
+
+import (
+	"testing"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCheckTenantQuota(t *testing.T) {
+	limits := map[string]int{QuotaCPU: 1000, QuotaPods: 4, QuotaGatewayRules: 0}
+	used := map[string]int{QuotaCPU: 800, QuotaPods: 4, QuotaGatewayRules: 100}
+	tests := []struct {
+		name string
+		need map[string]int
+		want string
+	}{
+		{name: "within", need: map[string]int{QuotaCPU: 200}},
+		{name: "cpu exceeded", need: map[string]int{QuotaCPU: 201}, want: "tenant_lack_of_cpu"},
+		{name: "pods exceeded", need: map[string]int{QuotaPods: 1}, want: "tenant_lack_of_pods"},
+		{name: "unlimited", need: map[string]int{QuotaGatewayRules: 1}},
+		{name: "released", need: map[string]int{QuotaPods: -2, QuotaCPU: -400}},
+	}
+	for _, tc := range tests {
+		err := checkTenantQuota(limits, used, tc.need)
+		if tc.want == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if tc.want != "" && (err == nil || err.Error() != tc.want) {
+			t.Errorf("%s: want %s, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestTenantResourceQuota(t *testing.T) {
+	if quota := tenantResourceQuota(&dbmodel.Tenants{UUID: "t1", LimitComponents: 3}); quota != nil {
+		t.Errorf("want no resource quota, got %v", quota.Spec.Hard)
+	}
+	if limitRange := tenantLimitRange(&dbmodel.Tenants{UUID: "t1", LimitStorage: 10}); limitRange != nil {
+		t.Errorf("want no limit range, got %v", limitRange.Spec.Limits)
+	}
+
+	tenant := &dbmodel.Tenants{UUID: "t1", LimitMemory: 2048, LimitCPU: 1500, LimitStorage: 10, LimitPods: 8}
+	hard := tenantResourceQuota(tenant).Spec.Hard
+	for name, want := range map[corev1.ResourceName]string{
+		corev1.ResourceLimitsMemory:    "2560Mi",
+		corev1.ResourceLimitsCPU:       "1875m",
+		corev1.ResourceRequestsStorage: "10Gi",
+		corev1.ResourcePods:            "10",
+	} {
+		if got := hard[name]; got.String() != want {
+			t.Errorf("%s: want %s, got %s", name, want, got.String())
+		}
+	}
+	limits := tenantLimitRange(tenant).Spec.Limits
+	if len(limits) != 1 || len(limits[0].Default) != 2 || len(limits[0].DefaultRequest) != 2 {
+		t.Errorf("want defaults of memory and cpu, got %v", limits)
+	}
+}
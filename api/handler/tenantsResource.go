@@ -37,6 +37,41 @@ func CheckTenantResource(ctx context.Context, tenant *dbmodel.Tenants, needMemor
 	return nil
 }
 
+// CheckTenantQuota checks the tenant can afford the need of every quota dimension, the memory is checked by CheckTenantResource
+func CheckTenantQuota(tenant *dbmodel.Tenants, need map[string]int) error {
+	limits := tenantQuotaLimits(tenant)
+	var limited bool
+	for dim, n := range need {
+		if n > 0 && limits[dim] > 0 {
+			limited = true
+		}
+	}
+	if !limited {
+		return nil
+	}
+	used, err := GetTenantManager().TenantQuotaUsage(tenant)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("tenant %s quota limits: %v, used: %v, need: %v", tenant.UUID, limits, used, need)
+	return checkTenantQuota(limits, used, need)
+}
+
+// CheckTenantBuildConcurrency checks the running builds of the tenant, including the one of the request, are within the quota
+func CheckTenantBuildConcurrency(tenant *dbmodel.Tenants) error {
+	if tenant.LimitBuildConcurrency == 0 {
+		return nil
+	}
+	builds, err := runningBuilds(tenant)
+	if err != nil {
+		return err
+	}
+	if builds > tenant.LimitBuildConcurrency {
+		return errors.New("tenant_lack_of_build_concurrency")
+	}
+	return nil
+}
+
 // ClusterAllocMemory returns the allocatable memory of the cluster.
 func ClusterAllocMemory(ctx context.Context) (int64, error) {
 	if logrus.IsLevelEnabled(logrus.DebugLevel) {
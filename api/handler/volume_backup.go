@@ -0,0 +1,243 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handler
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	api_model "github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/util"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	mqclient "github.com/gridworkz/kato/mq/client"
+	core_util "github.com/gridworkz/kato/util"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	"github.com/gridworkz/kato/worker/client"
+)
+
+//VolumeBackupHandler backups the data of the component volumes to the object storage and restores them
+type VolumeBackupHandler interface {
+	CreateBackup(serviceID, volumeName, eventID string) (*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError)
+	ListBackups(serviceID, volumeName string) ([]*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError)
+	DeleteBackup(serviceID, volumeName, backupID, eventID string) *util.APIHandleError
+	RestoreBackup(serviceID, volumeName, eventID string, req *api_model.VolumeRestoreReq) *util.APIHandleError
+	GetBackupPolicy(serviceID, volumeName string) (*dbmodel.TenantServiceVolumeBackupPolicy, *util.APIHandleError)
+	UpdBackupPolicy(req *api_model.VolumeBackupPolicyReq) *util.APIHandleError
+}
+
+var defaultVolumeBackupHandler VolumeBackupHandler
+
+//CreateVolumeBackupManager create volume backup manager
+func CreateVolumeBackupManager(mqClient mqclient.MQClient, statusCli *client.AppRuntimeSyncClient) *VolumeBackupAction {
+	return &VolumeBackupAction{mqClient: mqClient, statusCli: statusCli}
+}
+
+//GetVolumeBackupHandler get volume backup handler
+func GetVolumeBackupHandler() VolumeBackupHandler {
+	return defaultVolumeBackupHandler
+}
+
+//VolumeBackupAction action
+type VolumeBackupAction struct {
+	mqClient  mqclient.MQClient
+	statusCli *client.AppRuntimeSyncClient
+}
+
+//getBackupVolume returns the volume which data can be backed up
+func getBackupVolume(serviceID, volumeName string) (*dbmodel.TenantServiceVolume, *util.APIHandleError) {
+	volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(serviceID, volumeName)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("get volume", err)
+	}
+	if volume.VolumeType != dbmodel.ShareFileVolumeType.String() && volume.VolumeType != dbmodel.LocalVolumeType.String() {
+		return nil, util.CreateAPIHandleErrorf(400, "the data of %s volumes can not be backed up", volume.VolumeType)
+	}
+	return volume, nil
+}
+
+//CreateBackup backups the data of the volume
+func (v *VolumeBackupAction) CreateBackup(serviceID, volumeName, eventID string) (*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError) {
+	volume, apiErr := getBackupVolume(serviceID, volumeName)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("get component", err)
+	}
+	backup := &dbmodel.TenantServiceVolumeBackup{
+		BackupID:   core_util.NewUUID(),
+		TenantID:   service.TenantID,
+		ServiceID:  serviceID,
+		VolumeName: volume.VolumeName,
+		VolumeType: volume.VolumeType,
+		Status:     "starting",
+		EventID:    eventID,
+	}
+	if err := db.GetManager().TenantServiceVolumeBackupDao().AddModel(backup); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("create volume backup", err)
+	}
+	err = v.mqClient.SendBuilderTopic(mqclient.TaskStruct{
+		TaskType: "backup_volume",
+		TaskBody: map[string]interface{}{
+			"backup_id": backup.BackupID,
+			"event_id":  eventID,
+		},
+		Topic: mqclient.BuilderTopic,
+	})
+	if err != nil {
+		logrus.Errorf("send 'backup_volume' task: %v", err)
+		if err := db.GetManager().TenantServiceVolumeBackupDao().DeleteByBackupID(backup.BackupID); err != nil {
+			logrus.Warningf("delete volume backup %s: %v", backup.BackupID, err)
+		}
+		return nil, util.CreateAPIHandleError(500, fmt.Errorf("send backup task: %v", err))
+	}
+	return backup, nil
+}
+
+//ListBackups lists the backups of the volume, the latest first
+func (v *VolumeBackupAction) ListBackups(serviceID, volumeName string) ([]*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError) {
+	backups, err := db.GetManager().TenantServiceVolumeBackupDao().ListByServiceVolume(serviceID, volumeName)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("list volume backups", err)
+	}
+	return backups, nil
+}
+
+//DeleteBackup deletes the backup and its archive in the object storage
+func (v *VolumeBackupAction) DeleteBackup(serviceID, volumeName, backupID, eventID string) *util.APIHandleError {
+	backup, err := db.GetManager().TenantServiceVolumeBackupDao().GetByBackupID(backupID)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get volume backup", err)
+	}
+	if backup.ServiceID != serviceID || backup.VolumeName != volumeName {
+		return util.CreateAPIHandleErrorf(404, "volume backup %s not found", backupID)
+	}
+	if backup.Status == "starting" {
+		return util.CreateAPIHandleErrorf(400, "volume backup %s is not complete", backupID)
+	}
+	err = v.mqClient.SendBuilderTopic(mqclient.TaskStruct{
+		TaskType: "delete_volume_backup",
+		TaskBody: map[string]interface{}{
+			"backup_id": backupID,
+			"event_id":  eventID,
+		},
+		Topic: mqclient.BuilderTopic,
+	})
+	if err != nil {
+		logrus.Errorf("send 'delete_volume_backup' task: %v", err)
+		return util.CreateAPIHandleError(500, fmt.Errorf("send delete task: %v", err))
+	}
+	return nil
+}
+
+//RestoreBackup restores the data of the backup into the volume. The backup can be one of
+//another component of the tenant, then the data are cloned.
+func (v *VolumeBackupAction) RestoreBackup(serviceID, volumeName, eventID string, req *api_model.VolumeRestoreReq) *util.APIHandleError {
+	volume, apiErr := getBackupVolume(serviceID, volumeName)
+	if apiErr != nil {
+		return apiErr
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get component", err)
+	}
+	backup, err := db.GetManager().TenantServiceVolumeBackupDao().GetByBackupID(req.BackupID)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get volume backup", err)
+	}
+	if backup.TenantID != service.TenantID {
+		return util.CreateAPIHandleErrorf(404, "volume backup %s not found", req.BackupID)
+	}
+	if backup.Status != "success" {
+		return util.CreateAPIHandleErrorf(400, "volume backup %s is %s", req.BackupID, backup.Status)
+	}
+	if backup.VolumeType != volume.VolumeType {
+		return util.CreateAPIHandleErrorf(400, "can not restore a %s volume backup into a %s volume", backup.VolumeType, volume.VolumeType)
+	}
+	if !req.Force {
+		status := v.statusCli.GetStatus(serviceID)
+		if status != v1.CLOSED && status != v1.UNDEPLOY {
+			return util.CreateAPIHandleErrorf(400, "the component must be closed before restoring, the status is %s", status)
+		}
+	}
+	err = v.mqClient.SendBuilderTopic(mqclient.TaskStruct{
+		TaskType: "restore_volume",
+		TaskBody: map[string]interface{}{
+			"backup_id":   backup.BackupID,
+			"service_id":  serviceID,
+			"volume_name": volume.VolumeName,
+			"event_id":    eventID,
+		},
+		Topic: mqclient.BuilderTopic,
+	})
+	if err != nil {
+		logrus.Errorf("send 'restore_volume' task: %v", err)
+		return util.CreateAPIHandleError(500, fmt.Errorf("send restore task: %v", err))
+	}
+	return nil
+}
+
+//GetBackupPolicy returns the backup policy of the volume, a disabled one if it is not set
+func (v *VolumeBackupAction) GetBackupPolicy(serviceID, volumeName string) (*dbmodel.TenantServiceVolumeBackupPolicy, *util.APIHandleError) {
+	if _, apiErr := getBackupVolume(serviceID, volumeName); apiErr != nil {
+		return nil, apiErr
+	}
+	policy, err := db.GetManager().TenantServiceVolumeBackupPolicyDao().GetByServiceVolume(serviceID, volumeName)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &dbmodel.TenantServiceVolumeBackupPolicy{ServiceID: serviceID, VolumeName: volumeName}, nil
+		}
+		return nil, util.CreateAPIHandleErrorFromDBError("get volume backup policy", err)
+	}
+	return policy, nil
+}
+
+//UpdBackupPolicy creates or updates the backup policy of the volume
+func (v *VolumeBackupAction) UpdBackupPolicy(req *api_model.VolumeBackupPolicyReq) *util.APIHandleError {
+	if _, apiErr := getBackupVolume(req.ServiceID, req.VolumeName); apiErr != nil {
+		return apiErr
+	}
+	policy, err := db.GetManager().TenantServiceVolumeBackupPolicyDao().GetByServiceVolume(req.ServiceID, req.VolumeName)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return util.CreateAPIHandleErrorFromDBError("get volume backup policy", err)
+		}
+		policy = &dbmodel.TenantServiceVolumeBackupPolicy{
+			ServiceID:  req.ServiceID,
+			VolumeName: req.VolumeName,
+		}
+	}
+	policy.Enable = req.Enable
+	policy.Schedule = req.Schedule
+	policy.TimeZone = req.TimeZone
+	policy.Retain = req.Retain
+	policy.RetainDays = req.RetainDays
+	if policy.ID == 0 {
+		err = db.GetManager().TenantServiceVolumeBackupPolicyDao().AddModel(policy)
+	} else {
+		err = db.GetManager().TenantServiceVolumeBackupPolicyDao().UpdateModel(policy)
+	}
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("update volume backup policy", err)
+	}
+	return nil
+}
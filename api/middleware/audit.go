@@ -0,0 +1,111 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/rbac"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/tidwall/gjson"
+)
+
+//maxAuditBodyHead the size of the request body kept to find the operator
+const maxAuditBodyHead = 64 * 1024
+
+//auditBody digests the request body while the handler reads it
+type auditBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	head bytes.Buffer
+}
+
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.hash.Write(p[:n])
+		if rest := maxAuditBodyHead - b.head.Len(); rest > 0 {
+			if rest > n {
+				rest = n
+			}
+			b.head.Write(p[:rest])
+		}
+	}
+	return n, err
+}
+
+//Audit records the mutating requests in the audit log, the targets of the request are filled in by the authorization
+func Audit(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		record := &dbmodel.AuditLog{Method: r.Method, Path: r.URL.Path}
+		body := &auditBody{ReadCloser: r.Body, hash: sha256.New()}
+		if r.Body != nil {
+			r.Body = body
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			// the handler may not read the whole body, the digest covers all of it
+			if r.Body != nil {
+				io.Copy(ioutil.Discard, body)
+			}
+			record.RequestDigest = hex.EncodeToString(body.hash.Sum(nil))
+			record.Operator = gjson.GetBytes(body.head.Bytes(), "operator").String()
+			record.StatusCode = ww.Status()
+			record.LatencyMS = time.Since(start).Milliseconds()
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				record.Route = rctx.RoutePattern()
+			}
+			if principal := GetPrincipal(r); principal != nil {
+				record.Principal, record.TokenID = principal.Name, principal.TokenID
+			}
+			handler.GetAuditHandler().Record(record)
+		}()
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), ContextKey("audit"), record)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+//auditTarget fills the target of the request in its audit log
+func auditTarget(r *http.Request, target rbac.Target) {
+	record, ok := r.Context().Value(ContextKey("audit")).(*dbmodel.AuditLog)
+	if !ok {
+		return
+	}
+	record.TenantID, record.AppID = target.TenantID, target.AppID
+	if service, ok := r.Context().Value(ContextKey("service")).(*dbmodel.TenantServices); ok {
+		record.ServiceID = service.ServiceID
+	}
+}
@@ -121,6 +121,49 @@ func InitApplication(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+//InitEvent puts the tenant and the service of the requested events into the context, so that
+//they can be authorized. The event is given by the eventID path param or by the target query.
+func InitEvent(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		var tenantID, serviceID string
+		if eventID := chi.URLParam(r, "eventID"); eventID != "" {
+			event, err := db.GetManager().ServiceEventDao().GetEventByEventID(eventID)
+			if err != nil {
+				if err.Error() == gorm.ErrRecordNotFound.Error() {
+					httputil.ReturnError(r, w, 404, "cant find event")
+					return
+				}
+				logrus.Errorf("get event %s: %v", eventID, err)
+				httputil.ReturnError(r, w, 500, "get event failed")
+				return
+			}
+			tenantID, serviceID = event.TenantID, event.ServiceID
+		} else {
+			switch r.FormValue("target") {
+			case dbmodel.TargetTypeTenant:
+				tenantID = r.FormValue("target-id")
+			case dbmodel.TargetTypeService:
+				serviceID = r.FormValue("target-id")
+			}
+		}
+		// the events of unknown targets are only readable by the global principals
+		ctx := r.Context()
+		if serviceID != "" {
+			if service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID); err == nil {
+				ctx = context.WithValue(ctx, ContextKey("service"), service)
+				tenantID = service.TenantID
+			}
+		}
+		if tenantID != "" {
+			if tenant, err := db.GetManager().TenantDao().GetTenantByUUID(tenantID); err == nil {
+				ctx = context.WithValue(ctx, ContextKey("tenant"), tenant)
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
 //InitPlugin - implement plugin init middleware
 func InitPlugin(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
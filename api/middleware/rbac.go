@@ -0,0 +1,94 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/gridworkz/kato/api/rbac"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	httputil "github.com/gridworkz/kato/util/http"
+)
+
+//GetPrincipal returns the identity of the request, nil if the api is not authenticated
+func GetPrincipal(r *http.Request) *rbac.Principal {
+	principal, _ := r.Context().Value(ContextKey("principal")).(*rbac.Principal)
+	return principal
+}
+
+//Authorize checks the identity of the request has the permission on the resource of the route,
+//the reads are the GET, HEAD and OPTIONS requests, others mutate the resource
+func Authorize(resource rbac.Resource) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			action := rbac.ActionMutate
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				action = rbac.ActionRead
+			}
+			authorize(w, r, next, rbac.Permission{Resource: resource, Action: action})
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+//AuthorizeRead checks the identity of the request can read the resource, for the queries sent by POST
+func AuthorizeRead(resource rbac.Resource) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			authorize(w, r, next, rbac.Permission{Resource: resource, Action: rbac.ActionRead})
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func authorize(w http.ResponseWriter, r *http.Request, next http.Handler, perm rbac.Permission) {
+	target := requestTarget(r)
+	auditTarget(r, target)
+	principal := GetPrincipal(r)
+	if principal == nil {
+		next.ServeHTTP(w, r)
+		return
+	}
+	if err := principal.Authorize(perm, target); err != nil {
+		httputil.Return(r, w, http.StatusForbidden, httputil.ResponseBody{
+			Msg:  err.Error(),
+			Bean: map[string]string{"required_permission": perm.String()},
+		})
+		return
+	}
+	next.ServeHTTP(w, r)
+}
+
+//requestTarget returns the object of the request from the context set by the init middlewares
+func requestTarget(r *http.Request) rbac.Target {
+	target := rbac.Target{EnterpriseID: chi.URLParam(r, "enterprise_id")}
+	if tenant, ok := r.Context().Value(ContextKey("tenant")).(*dbmodel.Tenants); ok {
+		target.EnterpriseID, target.TenantID = tenant.EID, tenant.UUID
+	}
+	// the app is got by its id only, it may be not in the tenant of the path
+	if app, ok := r.Context().Value(ContextKey("application")).(*dbmodel.Application); ok {
+		target.EnterpriseID, target.TenantID, target.AppID = app.EID, app.TenantID, app.AppID
+	}
+	if service, ok := r.Context().Value(ContextKey("service")).(*dbmodel.TenantServices); ok {
+		target.AppID = service.AppID
+	}
+	return target
+}
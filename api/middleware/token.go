@@ -19,59 +19,32 @@
 package middleware
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
-	"io"
+	"encoding/hex"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/gridworkz/kato/api/handler"
+	"github.com/gridworkz/kato/api/rbac"
 	"github.com/gridworkz/kato/api/util"
 )
 
 //Token - simple token verification
 func Token(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv("TOKEN")
 		if strings.HasPrefix(r.RequestURI, "/docs") {
-			auth := r.Header.Get("Authorization")
-			if auth == "" {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-			auths := strings.SplitN(auth, " ", 2)
-			if len(auths) != 2 {
-				return
-			}
-			authMethod := auths[0]
-			authB64 := auths[1]
-			switch authMethod {
-			case "Basic":
-				authstr, err := base64.StdEncoding.DecodeString(authB64)
-				if err != nil {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				userPwd := strings.SplitN(string(authstr), ":", 2)
-				if len(userPwd) != 2 {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				username := userPwd[0]
-				password := userPwd[1]
-				if username == "gridworkz" && password == "gridworkz-api-test" {
-					next.ServeHTTP(w, r)
-					return
-				}
-			default:
-				io.WriteString(w, "Unauthorized!\n")
+			if password, ok := basicAuthPassword(r); ok && password == token {
+				next.ServeHTTP(w, r)
 				return
 			}
-			w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
+			w.Header().Set("WWW-Authenticate", `Basic realm="Kato API Docs"`)
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
-		token := os.Getenv("TOKEN")
 		t := r.Header.Get("Authorization")
 		if tt := strings.Split(t, " "); len(tt) == 2 {
 			if tt[1] == token {
@@ -85,53 +58,27 @@ func Token(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-//FullToken token api check
+//FullToken token api check, the named api tokens and the tokens of the console are accepted,
+//the identity of the token is put into the request context for the authorization of the routes
 func FullToken(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasPrefix(r.RequestURI, "/docs") {
-			auth := r.Header.Get("Authorization")
-			if auth == "" {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-			auths := strings.SplitN(auth, " ", 2)
-			if len(auths) != 2 {
-				return
-			}
-			authMethod := auths[0]
-			authB64 := auths[1]
-			switch authMethod {
-			case "Basic":
-				authstr, err := base64.StdEncoding.DecodeString(authB64)
-				if err != nil {
-					io.WriteString(w, "Unauthorized!\n")
+			// the docs accept any valid token as the password
+			if password, ok := basicAuthPassword(r); ok {
+				if principal := authenticate(password, r.RequestURI); principal != nil {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextKey("principal"), principal)))
 					return
 				}
-				userPwd := strings.SplitN(string(authstr), ":", 2)
-				if len(userPwd) != 2 {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				username := userPwd[0]
-				password := userPwd[1]
-				if username == "gridworkz" && password == "gridworkz-api-test" {
-					next.ServeHTTP(w, r)
-					return
-				}
-			default:
-				io.WriteString(w, "Unauthorized!\n")
-				return
 			}
-			w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
+			w.Header().Set("WWW-Authenticate", `Basic realm="Kato API Docs"`)
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 		//logrus.Debugf("request uri is %s", r.RequestURI)
 		t := r.Header.Get("Authorization")
 		if tt := strings.Split(t, " "); len(tt) == 2 {
-			if handler.GetTokenIdenHandler().CheckToken(tt[1], r.RequestURI) {
-				next.ServeHTTP(w, r)
+			if principal := authenticate(tt[1], r.RequestURI); principal != nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextKey("principal"), principal)))
 				return
 			}
 		}
@@ -140,3 +87,41 @@ func FullToken(next http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+//authenticate returns the identity of the token, nil if the token is invalid
+func authenticate(token, uri string) *rbac.Principal {
+	if strings.HasPrefix(token, handler.APITokenPrefix) {
+		principal, err := handler.GetAPITokenHandler().Authenticate(token)
+		if err != nil {
+			return nil
+		}
+		return principal
+	}
+	// the tokens of the console keep their full power, or the api prefixes of their range
+	if handler.GetTokenIdenHandler().CheckToken(token, uri) {
+		return &rbac.Principal{Name: "console", TokenID: consoleTokenID(token), Role: rbac.RoleAdmin, Scope: rbac.ScopeEnterprise}
+	}
+	return nil
+}
+
+//consoleTokenID the fingerprint of a console token, which tells the tokens apart without revealing them
+func consoleTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "console-" + hex.EncodeToString(sum[:4])
+}
+
+func basicAuthPassword(r *http.Request) (string, bool) {
+	auths := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(auths) != 2 || auths[0] != "Basic" {
+		return "", false
+	}
+	authstr, err := base64.StdEncoding.DecodeString(auths[1])
+	if err != nil {
+		return "", false
+	}
+	userPwd := strings.SplitN(string(authstr), ":", 2)
+	if len(userPwd) != 2 {
+		return "", false
+	}
+	return userPwd[1], true
+}
@@ -24,16 +24,11 @@ import dbmodel "github.com/gridworkz/kato/db/model"
 type AutoscalerRuleReq struct {
 	RuleID      string `json:"rule_id" validate:"rule_id|required"`
 	ServiceID   string
-	Enable      bool   `json:"enable" validate:"enable|required"`
-	XPAType     string `json:"xpa_type" validate:"xpa_type|required"`
-	MinReplicas int    `json:"min_replicas" validate:"min_replicas|required"`
-	MaxReplicas int    `json:"max_replicas" validate:"min_replicas|required"`
-	Metrics     []struct {
-		MetricsType       string `json:"metric_type"`
-		MetricsName       string `json:"metric_name"`
-		MetricTargetType  string `json:"metric_target_type"`
-		MetricTargetValue int    `json:"metric_target_value"`
-	} `json:"metrics"`
+	Enable      bool         `json:"enable" validate:"enable|required"`
+	XPAType     string       `json:"xpa_type" validate:"xpa_type|required"`
+	MinReplicas int          `json:"min_replicas" validate:"min_replicas|required"`
+	MaxReplicas int          `json:"max_replicas" validate:"min_replicas|required"`
+	Metrics     []RuleMetric `json:"metrics"`
 }
 
 // AutoscalerRuleResp -
@@ -52,6 +47,29 @@ type AutoscalerRuleResp struct {
 	} `json:"metrics"`
 }
 
+// AutoscalerScheduleReq scales the component to the replicas on schedule. For example,
+// "10 replicas weekdays 08:00-20:00, 2 otherwise" is made of two schedules:
+// "0 8 * * 1-5" with 10 replicas and "0 20 * * 1-5" with 2 replicas.
+type AutoscalerScheduleReq struct {
+	ScheduleID string `json:"schedule_id" validate:"schedule_id|required"`
+	ServiceID  string `json:"-"`
+	Enable     bool   `json:"enable"`
+	// cron expression with five fields: minute, hour, day of month, month and day of week
+	Schedule string `json:"schedule" validate:"schedule|required"`
+	// IANA time zone, like Asia/Shanghai. UTC is used if it is empty
+	TimeZone string `json:"time_zone"`
+	Replicas int    `json:"replicas"`
+}
+
+// IdlePolicyReq scales the component to 0 after idle_minutes without gateway traffic.
+// The component is started again by the first request through the gateway. Only the http traffic
+// of the gateway is counted, the requests from other components do not keep it running.
+type IdlePolicyReq struct {
+	ServiceID   string `json:"-"`
+	Enable      bool   `json:"enable"`
+	IdleMinutes int    `json:"idle_minutes"`
+}
+
 // AutoScalerRule -
 type AutoScalerRule struct {
 	RuleID      string       `json:"rule_id"`
@@ -80,6 +98,11 @@ type RuleMetric struct {
 	MetricsName       string `json:"metric_name"`
 	MetricTargetType  string `json:"metric_target_type"`
 	MetricTargetValue int    `json:"metric_target_value"`
+	// the label selector of pods, object and external metrics, like "queue=orders"
+	MetricSelector   string `json:"metric_selector,omitempty"`
+	ObjectKind       string `json:"object_kind,omitempty"`
+	ObjectName       string `json:"object_name,omitempty"`
+	ObjectAPIVersion string `json:"object_api_version,omitempty"`
 }
 
 // DbModel return database model
@@ -90,5 +113,9 @@ func (r RuleMetric) DbModel(ruleID string) *dbmodel.TenantServiceAutoscalerRuleM
 		MetricsName:       r.MetricsName,
 		MetricTargetType:  r.MetricTargetType,
 		MetricTargetValue: r.MetricTargetValue,
+		MetricSelector:    r.MetricSelector,
+		ObjectKind:        r.ObjectKind,
+		ObjectName:        r.ObjectName,
+		ObjectAPIVersion:  r.ObjectAPIVersion,
 	}
 }
@@ -18,6 +18,12 @@
 
 package model
 
+import (
+	"time"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
 //GetUserToken
 //swagger:parameters createToken
 type GetUserToken struct {
@@ -101,3 +107,46 @@ type APIManager struct {
 		Remark string `json:"remark" validate:"remark"`
 	}
 }
+
+//CreateAPITokenReq creates a named api token
+type CreateAPITokenReq struct {
+	Name string `json:"name" validate:"required,max=64"`
+	// Role viewer, developer or admin
+	Role string `json:"role" validate:"required,oneof=viewer developer admin"`
+	// Scope enterprise, tenant or app
+	Scope string `json:"scope" validate:"required,oneof=enterprise tenant app"`
+	// EnterpriseID limits the enterprise scope, all the enterprises when it is empty
+	EnterpriseID string `json:"enterprise_id"`
+	// TenantName is required by the tenant scope
+	TenantName string `json:"tenant_name"`
+	// AppID is required by the app scope
+	AppID string `json:"app_id"`
+	// ExpireDays the days the token is valid, 0 means it never expires
+	ExpireDays int `json:"expire_days" validate:"min=0"`
+}
+
+//APITokenInfo the created token, the token can not be got again
+type APITokenInfo struct {
+	*dbmodel.APIToken
+	Token string `json:"token"`
+}
+
+//AuditLogQuery the conditions of listing the audit logs, the empty ones are ignored
+type AuditLogQuery struct {
+	TenantID  string
+	ServiceID string
+	Principal string
+	Start     time.Time
+	End       time.Time
+	Page      int
+	PageSize  int
+}
+
+//AuditChainStatus the result of verifying the hash chain of the audit logs
+type AuditChainStatus struct {
+	Intact bool `json:"intact"`
+	// Verified the number of the logs verified before the chain breaks
+	Verified int64 `json:"verified"`
+	// BrokenSeq the seq of the first log which does not follow its predecessor
+	BrokenSeq int64 `json:"broken_seq,omitempty"`
+}
@@ -263,3 +263,57 @@ type SyncComponentReq struct {
 	Components         []*Component `json:"components"`
 	DeleteComponentIDs []string     `json:"delete_component_ids"`
 }
+
+// ApplyAppReq is the full desired state of the components of an application.
+// Components missing from the spec will be deleted, nil sections of a component are left untouched.
+type ApplyAppReq struct {
+	Components []*Component `json:"components" validate:"required"`
+}
+
+// component diff actions
+const (
+	DiffActionCreate = "create"
+	DiffActionUpdate = "update"
+	DiffActionDelete = "delete"
+	DiffActionNone   = "none"
+)
+
+// field change actions
+const (
+	ChangeActionAdd    = "add"
+	ChangeActionRemove = "remove"
+	ChangeActionModify = "modify"
+)
+
+// AppDiff is the difference between an application spec and the database.
+type AppDiff struct {
+	Applied    bool             `json:"applied"`
+	Components []*ComponentDiff `json:"components"`
+}
+
+// Changed reports whether applying the spec changes anything.
+func (a *AppDiff) Changed() bool {
+	for _, c := range a.Components {
+		if c.Action != DiffActionNone {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentDiff -
+type ComponentDiff struct {
+	ComponentID    string         `json:"component_id"`
+	ComponentAlias string         `json:"component_alias"`
+	Action         string         `json:"action"`
+	Changes        []*FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is a single changed entry of a component section, such as an env or a port.
+type FieldChange struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Action  string `json:"action"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
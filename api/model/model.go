@@ -569,6 +569,8 @@ type StatsInfo struct {
 	UUID string `json:"uuid"`
 	CPU  int    `json:"cpu"`
 	MEM  int    `json:"memory"`
+	// Quotas the usages of the tenant against its limits
+	Quotas []*QuotaUsage `json:"quotas,omitempty"`
 }
 
 //TotalStatsInfo total stats info
@@ -779,6 +781,14 @@ type BuildServiceStruct struct {
 		TenantName   string `json:"tenant_name"`
 		ServiceAlias string `json:"service_alias"`
 		Cmd          string `json:"cmd"`
+		// Priority of the build task in the builder queue, 0-9, the higher is built first
+		// in: body
+		// required: false
+		Priority int32 `json:"priority"`
+		// Unix timestamp, the build task is not started before it
+		// in: body
+		// required: false
+		NotBefore int64 `json:"not_before"`
 		//Used for cloud city code package creation
 		SlugInfo struct {
 			SlugPath    string `json:"slug_path"`
@@ -1501,7 +1511,7 @@ type ExportAppStruct struct {
 		EventID       string `json:"event_id"`
 		GroupKey string `json:"group_key"` // TODO consider removing
 		Version string `json:"version"` // TODO consider removing
-		Format        string `json:"format"`    // only kato-app/docker-compose
+		Format        string `json:"format"`    // only kato-app/docker-compose/helm-chart
 		GroupMetadata string `json:"group_metadata"`
 	}
 }
@@ -1605,6 +1615,14 @@ type ComponentBuildReq struct {
 	CodeInfo BuildCodeInfo `json:"code_info,omitempty"`
 	//Used for cloud city code package creation
 	SlugInfo BuildSlugInfo `json:"slug_info,omitempty"`
+	// Priority of the build task in the builder queue, 0-9, the higher is built first
+	// in: body
+	// required: false
+	Priority int32 `json:"priority"`
+	// Unix timestamp, the build task is not started before it
+	// in: body
+	// required: false
+	NotBefore int64 `json:"not_before"`
 	//tenantName
 	TenantName string `json:"-"`
 }
@@ -1664,6 +1682,9 @@ type ComponentUpgradeReq struct {
 	//UpgradeVersion The target version of the upgrade
 	//If empty, the same version is upgraded
 	UpgradeVersion string `json:"upgrade_version"`
+	//Strategy The release strategy, empty for rolling update in place.
+	//canary or blue-green, options such as steps=10,50,100 interval=2m max-error-rate=0.05
+	Strategy []string `json:"strategy"`
 }
 
 // GetEventID -
@@ -1708,6 +1729,7 @@ func (u *ComponentUpgradeReq) TaskBody(cpt *dbmodel.TenantServices) interface{}
 		ServiceID: cpt.ServiceID,
 		NewDeployVersion: u.UpgradeVersion,
 		EventID:          u.GetEventID(),
+		Strategy:         u.Strategy,
 		Configs:          u.Configs,
 	}
 }
@@ -1743,7 +1765,7 @@ type MQBody struct {
 	EventID   string `json:"event_id"`
 	GroupKey  string `json:"group_key"`
 	Version   string `json:"version"`
-	Format    string `json:"format"` // only kato-app/docker-compose
+	Format    string `json:"format"` // only kato-app/docker-compose/helm-chart
 	SourceDir string `json:"source_dir"`
 }
 
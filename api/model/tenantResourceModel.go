@@ -136,3 +136,28 @@ func (list TenantList) Paging(page, pageSize int) map[string]interface{} {
 		"total":    list.Len(),
 	}
 }
+
+//TenantQuota the limits of a tenant, 0 means unlimited
+type TenantQuota struct {
+	// LimitMemory the memory of the running components in MB, including their plugin sidecars
+	LimitMemory int `json:"limit_memory" validate:"min=0"`
+	// LimitCPU the cpu limits of the running components in millicores, including their plugin sidecars
+	LimitCPU int `json:"limit_cpu" validate:"min=0"`
+	// LimitStorage the total capacity of the volumes in GB
+	LimitStorage int `json:"limit_storage" validate:"min=0"`
+	// LimitComponents the number of the components
+	LimitComponents int `json:"limit_components" validate:"min=0"`
+	// LimitPods the number of the instances of the running components
+	LimitPods int `json:"limit_pods" validate:"min=0"`
+	// LimitGatewayRules the number of the http and tcp rules
+	LimitGatewayRules int `json:"limit_gateway_rules" validate:"min=0"`
+	// LimitBuildConcurrency the number of the builds running at the same time
+	LimitBuildConcurrency int `json:"limit_build_concurrency" validate:"min=0"`
+}
+
+//QuotaUsage the usage of a quota dimension
+type QuotaUsage struct {
+	Dimension string `json:"dimension"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+}
@@ -247,6 +247,12 @@ type UpdVolumeReq struct {
 	Mode        *int32 `json:"mode"`
 }
 
+// UpdVolumeCapacityReq is a value struct holding request for expanding volume.
+type UpdVolumeCapacityReq struct {
+	// the new capacity, it must be larger than the current one
+	VolumeCapacity int64 `json:"volume_capacity" validate:"volume_capacity|required|min:1"`
+}
+
 // VolumeWithStatusResp volume status
 type VolumeWithStatusResp struct {
 	ServiceID string `json:"service_id"`
@@ -285,3 +291,24 @@ type VolumeWithStatusStruct struct {
 	VolumeProviderName string `json:"volume_provider_name"`
 	Status             string `json:"status"`
 }
+
+// VolumeRestoreReq restores the data of a volume backup into the volume.
+// The backup can be one of another component, then the data are cloned into the volume.
+type VolumeRestoreReq struct {
+	BackupID string `json:"backup_id" validate:"backup_id|required"`
+	// Force restores the data even if the component is running
+	Force bool `json:"force"`
+}
+
+// VolumeBackupPolicyReq backups the data of the volume on schedule
+type VolumeBackupPolicyReq struct {
+	ServiceID  string `json:"-"`
+	VolumeName string `json:"-"`
+	Enable     bool   `json:"enable"`
+	Schedule   string `json:"schedule"`
+	TimeZone   string `json:"time_zone"`
+	// Retain is the number of the latest backups kept, 0 means no limit
+	Retain int `json:"retain"`
+	// RetainDays is the days the backups are kept, 0 means no limit
+	RetainDays int `json:"retain_days"`
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rbac
+
+import (
+	"fmt"
+)
+
+//Resource the kind of the objects an api operates on
+type Resource string
+
+//Resources, the routes of the region api are mapped onto them
+var (
+	//ResourceCluster the nodes, the resources and the settings of the region
+	ResourceCluster Resource = "cluster"
+	//ResourceEnterprise the tenants of an enterprise and the app market
+	ResourceEnterprise Resource = "enterprise"
+	//ResourceTenant the tenant and the objects under it, except the apps and the components
+	ResourceTenant Resource = "tenant"
+	//ResourceApp the app and its config groups
+	ResourceApp Resource = "app"
+	//ResourceService the component
+	ResourceService Resource = "service"
+	//ResourceToken the api tokens
+	ResourceToken Resource = "token"
+	//ResourceAudit the audit logs of the mutating api calls, they can only be read
+	ResourceAudit Resource = "audit"
+)
+
+//Action read or mutate
+type Action string
+
+var (
+	//ActionRead reads the objects
+	ActionRead Action = "read"
+	//ActionMutate creates, updates, deletes or operates the objects
+	ActionMutate Action = "mutate"
+)
+
+//Permission allows an action on a resource
+type Permission struct {
+	Resource Resource `json:"resource"`
+	Action   Action   `json:"action"`
+}
+
+func (p Permission) String() string {
+	return fmt.Sprintf("%s:%s", p.Resource, p.Action)
+}
+
+//Role a set of permissions
+type Role string
+
+var (
+	//RoleViewer reads all the resources except the tokens and the audit logs
+	RoleViewer Role = "viewer"
+	//RoleDeveloper reads all the resources and mutates the tenants, the apps and the components
+	RoleDeveloper Role = "developer"
+	//RoleAdmin does anything, including managing the tokens and reading the audit logs
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{RoleViewer: 1, RoleDeveloper: 2, RoleAdmin: 3}
+
+var rolePermissions = map[Role]map[Permission]struct{}{
+	RoleViewer: permissions(ActionRead, ResourceCluster, ResourceEnterprise, ResourceTenant, ResourceApp, ResourceService),
+	RoleDeveloper: merge(
+		permissions(ActionRead, ResourceCluster, ResourceEnterprise, ResourceTenant, ResourceApp, ResourceService),
+		permissions(ActionMutate, ResourceTenant, ResourceApp, ResourceService),
+	),
+	RoleAdmin: merge(
+		permissions(ActionRead, ResourceCluster, ResourceEnterprise, ResourceTenant, ResourceApp, ResourceService, ResourceToken, ResourceAudit),
+		permissions(ActionMutate, ResourceCluster, ResourceEnterprise, ResourceTenant, ResourceApp, ResourceService, ResourceToken),
+	),
+}
+
+func permissions(action Action, resources ...Resource) map[Permission]struct{} {
+	perms := make(map[Permission]struct{}, len(resources))
+	for _, resource := range resources {
+		perms[Permission{Resource: resource, Action: action}] = struct{}{}
+	}
+	return perms
+}
+
+func merge(sets ...map[Permission]struct{}) map[Permission]struct{} {
+	perms := make(map[Permission]struct{})
+	for _, set := range sets {
+		for perm := range set {
+			perms[perm] = struct{}{}
+		}
+	}
+	return perms
+}
+
+//Valid reports whether the role is defined
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+//Allows reports whether the role has the permission
+func (r Role) Allows(perm Permission) bool {
+	_, ok := rolePermissions[r][perm]
+	return ok
+}
+
+//Scope the range of the objects a token can access
+type Scope string
+
+var (
+	//ScopeEnterprise the objects of an enterprise, or of all the enterprises when no enterprise is given
+	ScopeEnterprise Scope = "enterprise"
+	//ScopeTenant the objects of a tenant
+	ScopeTenant Scope = "tenant"
+	//ScopeApp the objects of an app
+	ScopeApp Scope = "app"
+)
+
+//Valid reports whether the scope is defined
+func (s Scope) Valid() bool {
+	return s == ScopeEnterprise || s == ScopeTenant || s == ScopeApp
+}
+
+//Target the object a request operates on, the fields unknown to the route are empty
+type Target struct {
+	EnterpriseID string
+	TenantID     string
+	AppID        string
+}
+
+//Principal the identity of a request
+type Principal struct {
+	Name         string `json:"name"`
+	TokenID      string `json:"token_id,omitempty"`
+	Role         Role   `json:"role"`
+	Scope        Scope  `json:"scope"`
+	EnterpriseID string `json:"enterprise_id,omitempty"`
+	TenantID     string `json:"tenant_id,omitempty"`
+	AppID        string `json:"app_id,omitempty"`
+}
+
+//Global reports whether the principal accesses the objects of all the enterprises
+func (p *Principal) Global() bool {
+	return p.Scope == ScopeEnterprise && p.EnterpriseID == ""
+}
+
+//DeniedError the principal has not the permission on the target
+type DeniedError struct {
+	Principal string
+	Required  Permission
+	Reason    string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s is denied %s: %s", e.Principal, e.Required, e.Reason)
+}
+
+//Authorize checks the principal has the permission on the target
+func (p *Principal) Authorize(perm Permission, target Target) error {
+	if !p.Role.Allows(perm) {
+		return &DeniedError{Principal: p.Name, Required: perm, Reason: fmt.Sprintf("role %s does not allow it", p.Role)}
+	}
+	// the tokens are checked against the scope when they are managed
+	if perm.Resource == ResourceToken || p.inScope(perm.Resource, target) {
+		return nil
+	}
+	return &DeniedError{Principal: p.Name, Required: perm, Reason: fmt.Sprintf("the target is out of the %s scope", p.Scope)}
+}
+
+func (p *Principal) inScope(resource Resource, target Target) bool {
+	if p.Global() {
+		return true
+	}
+	switch p.Scope {
+	case ScopeEnterprise:
+		return resource != ResourceCluster && target.EnterpriseID == p.EnterpriseID
+	case ScopeTenant:
+		if resource != ResourceTenant && resource != ResourceApp && resource != ResourceService {
+			return false
+		}
+		return target.TenantID == p.TenantID
+	case ScopeApp:
+		if resource != ResourceApp && resource != ResourceService {
+			return false
+		}
+		return target.AppID == p.AppID
+	}
+	return false
+}
+
+//Covers reports whether the principal can grant the other one, the role of the other is not higher
+// and its scope is within the one of the principal
+func (p *Principal) Covers(other *Principal) bool {
+	if roleRanks[other.Role] > roleRanks[p.Role] {
+		return false
+	}
+	if p.Global() {
+		return true
+	}
+	switch p.Scope {
+	case ScopeEnterprise:
+		return other.EnterpriseID == p.EnterpriseID
+	case ScopeTenant:
+		return other.Scope != ScopeEnterprise && other.TenantID == p.TenantID
+	case ScopeApp:
+		return other.Scope == ScopeApp && other.AppID == p.AppID
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rbac
+
+import "testing"
+
+func TestAuthorize(t *testing.T) {
+	tenant := Target{EnterpriseID: "e1", TenantID: "t1"}
+	app := Target{EnterpriseID: "e1", TenantID: "t1", AppID: "a1"}
+	tests := []struct {
+		name      string
+		principal Principal
+		perm      Permission
+		target    Target
+		allowed   bool
+	}{
+		{name: "global admin", principal: Principal{Role: RoleAdmin, Scope: ScopeEnterprise}, perm: Permission{ResourceCluster, ActionMutate}, allowed: true},
+		{name: "enterprise admin on cluster", principal: Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e1"}, perm: Permission{ResourceCluster, ActionRead}},
+		{name: "enterprise admin on audit logs", principal: Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e1"}, perm: Permission{ResourceAudit, ActionRead}},
+		{name: "enterprise admin on its tenant", principal: Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e1"}, perm: Permission{ResourceTenant, ActionMutate}, target: tenant, allowed: true},
+		{name: "enterprise admin on other tenant", principal: Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e2"}, perm: Permission{ResourceTenant, ActionMutate}, target: tenant},
+		{name: "viewer reads", principal: Principal{Role: RoleViewer, Scope: ScopeTenant, TenantID: "t1"}, perm: Permission{ResourceService, ActionRead}, target: app, allowed: true},
+		{name: "viewer mutates", principal: Principal{Role: RoleViewer, Scope: ScopeTenant, TenantID: "t1"}, perm: Permission{ResourceService, ActionMutate}, target: app},
+		{name: "developer on its tenant", principal: Principal{Role: RoleDeveloper, Scope: ScopeTenant, TenantID: "t1"}, perm: Permission{ResourceTenant, ActionMutate}, target: tenant, allowed: true},
+		{name: "developer on other tenant", principal: Principal{Role: RoleDeveloper, Scope: ScopeTenant, TenantID: "t2"}, perm: Permission{ResourceTenant, ActionRead}, target: tenant},
+		{name: "developer on enterprise", principal: Principal{Role: RoleDeveloper, Scope: ScopeTenant, TenantID: "t1"}, perm: Permission{ResourceEnterprise, ActionRead}, target: tenant},
+		{name: "developer manages tokens", principal: Principal{Role: RoleDeveloper, Scope: ScopeEnterprise}, perm: Permission{ResourceToken, ActionMutate}},
+		{name: "app developer on its app", principal: Principal{Role: RoleDeveloper, Scope: ScopeApp, AppID: "a1"}, perm: Permission{ResourceService, ActionMutate}, target: app, allowed: true},
+		{name: "app developer on tenant", principal: Principal{Role: RoleDeveloper, Scope: ScopeApp, AppID: "a1"}, perm: Permission{ResourceTenant, ActionRead}, target: app},
+		{name: "app developer on other app", principal: Principal{Role: RoleDeveloper, Scope: ScopeApp, AppID: "a2"}, perm: Permission{ResourceApp, ActionRead}, target: app},
+	}
+	for _, tc := range tests {
+		err := tc.principal.Authorize(tc.perm, tc.target)
+		if (err == nil) != tc.allowed {
+			t.Errorf("%s: allowed %v, got %v", tc.name, tc.allowed, err)
+		}
+		if denied, ok := err.(*DeniedError); err != nil && (!ok || denied.Required != tc.perm) {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tenantAdmin := &Principal{Role: RoleAdmin, Scope: ScopeTenant, EnterpriseID: "e1", TenantID: "t1"}
+	tests := []struct {
+		name   string
+		p      *Principal
+		other  *Principal
+		covers bool
+	}{
+		{name: "global", p: &Principal{Role: RoleAdmin, Scope: ScopeEnterprise}, other: tenantAdmin, covers: true},
+		{name: "enterprise", p: &Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e1"}, other: tenantAdmin, covers: true},
+		{name: "other enterprise", p: &Principal{Role: RoleAdmin, Scope: ScopeEnterprise, EnterpriseID: "e2"}, other: tenantAdmin},
+		{name: "higher role", p: &Principal{Role: RoleDeveloper, Scope: ScopeEnterprise}, other: tenantAdmin},
+		{name: "app in tenant", p: tenantAdmin, other: &Principal{Role: RoleViewer, Scope: ScopeApp, TenantID: "t1", AppID: "a1"}, covers: true},
+		{name: "enterprise from tenant", p: tenantAdmin, other: &Principal{Role: RoleViewer, Scope: ScopeEnterprise, EnterpriseID: "e1", TenantID: "t1"}},
+		{name: "other app", p: &Principal{Role: RoleAdmin, Scope: ScopeApp, AppID: "a1"}, other: &Principal{Role: RoleViewer, Scope: ScopeApp, AppID: "a2"}},
+	}
+	for _, tc := range tests {
+		if got := tc.p.Covers(tc.other); got != tc.covers {
+			t.Errorf("%s: covers %v, got %v", tc.name, tc.covers, got)
+		}
+	}
+}
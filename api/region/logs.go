@@ -0,0 +1,97 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package region
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/api/util"
+	eventdb "github.com/gridworkz/kato/eventlog/db"
+	utilhttp "github.com/gridworkz/kato/util/http"
+)
+
+//LogSearchOption options of the log search
+type LogSearchOption struct {
+	//Kind service or event
+	Kind  string
+	Query string
+	Regex string
+	Level string
+	//ServiceAliases filters the services of the tenant
+	ServiceAliases []string
+	Since          time.Time
+	Until          time.Time
+	Offset         int
+	Limit          int
+}
+
+func (o LogSearchOption) values() url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("kind", o.Kind)
+	set("query", o.Query)
+	set("regex", o.Regex)
+	set("level", o.Level)
+	set("service_alias", strings.Join(o.ServiceAliases, ","))
+	if !o.Since.IsZero() {
+		values.Set("since", strconv.FormatInt(o.Since.Unix(), 10))
+	}
+	if !o.Until.IsZero() {
+		values.Set("until", strconv.FormatInt(o.Until.Unix(), 10))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return values
+}
+
+func (r *regionImpl) searchLogs(prefix string, opt LogSearchOption) ([]*eventdb.SearchResult, int, *util.APIHandleError) {
+	var results []*eventdb.SearchResult
+	var decode utilhttp.ResponseBody
+	decode.List = &results
+	code, err := r.DoRequest(prefix+"/logs/search?"+opt.values().Encode(), "GET", nil, &decode)
+	if err != nil {
+		return nil, 0, util.CreateAPIHandleError(code, err)
+	}
+	if code != 200 {
+		return nil, 0, util.CreateAPIHandleError(code, fmt.Errorf("search logs code %d: %s", code, decode.Msg))
+	}
+	return results, decode.ListAllNumber, nil
+}
+
+//SearchLogs search the logs of the services in the tenant
+func (t *tenant) SearchLogs(opt LogSearchOption) ([]*eventdb.SearchResult, int, *util.APIHandleError) {
+	return t.searchLogs(t.prefix, opt)
+}
+
+//SearchLogs search the logs of the service
+func (s *services) SearchLogs(opt LogSearchOption) ([]*eventdb.SearchResult, int, *util.APIHandleError) {
+	opt.ServiceAliases = nil
+	return s.searchLogs(s.prefix, opt)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/gridworkz/kato/api/model"
 	"github.com/gridworkz/kato/api/util"
 	dbmodel "github.com/gridworkz/kato/db/model"
+	eventdb "github.com/gridworkz/kato/eventlog/db"
 	coreutil "github.com/gridworkz/kato/util"
 	utilhttp "github.com/gridworkz/kato/util/http"
 )
@@ -43,6 +44,10 @@ type ServiceInterface interface {
 	Stop(eventID string) (string, *util.APIHandleError)
 	Start(eventID string) (string, *util.APIHandleError)
 	EventLog(eventID, level string) ([]*model.MessageData, *util.APIHandleError)
+	SearchLogs(opt LogSearchOption) ([]*eventdb.SearchResult, int, *util.APIHandleError)
+	VolumeBackups(volumeName string) ([]*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError)
+	BackupVolume(volumeName string) (*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError)
+	RestoreVolume(volumeName, backupID string, force bool) *util.APIHandleError
 }
 
 func (s *services) Pods() ([]*podInfo, *util.APIHandleError) {
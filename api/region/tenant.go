@@ -22,6 +22,7 @@ import (
 	"path"
 
 	"github.com/gridworkz/kato/api/util"
+	eventdb "github.com/gridworkz/kato/eventlog/db"
 	dbmodel "github.com/gridworkz/kato/db/model"
 	utilhttp "github.com/gridworkz/kato/util/http"
 	"github.com/mitchellh/mapstructure"
@@ -40,6 +41,7 @@ type TenantInterface interface {
 	List() ([]*dbmodel.Tenants, *util.APIHandleError)
 	Delete() *util.APIHandleError
 	Services(serviceAlias string) ServiceInterface
+	SearchLogs(opt LogSearchOption) ([]*eventdb.SearchResult, int, *util.APIHandleError)
 	// DefineSources(ss *api_model.SourceSpec) DefineSourcesInterface
 	// DefineCloudAuth(gt *api_model.GetUserToken) DefineCloudAuthInterface
 }
@@ -0,0 +1,82 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package region
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/util"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	utilhttp "github.com/gridworkz/kato/util/http"
+)
+
+//TokenInterface manages the named api tokens
+type TokenInterface interface {
+	List() ([]*dbmodel.APIToken, *util.APIHandleError)
+	Create(req *model.CreateAPITokenReq) (*model.APITokenInfo, *util.APIHandleError)
+	Revoke(name string) *util.APIHandleError
+}
+
+func (r *regionImpl) Tokens() TokenInterface {
+	return &tokens{prefix: "/v2/tokens", regionImpl: *r}
+}
+
+type tokens struct {
+	regionImpl
+	prefix string
+}
+
+func (t *tokens) List() ([]*dbmodel.APIToken, *util.APIHandleError) {
+	var list []*dbmodel.APIToken
+	var decode utilhttp.ResponseBody
+	decode.List = &list
+	code, err := t.DoRequest(t.prefix, "GET", nil, &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	return list, handleAPIResult(code, decode)
+}
+
+func (t *tokens) Create(req *model.CreateAPITokenReq) (*model.APITokenInfo, *util.APIHandleError) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(400, fmt.Errorf("marshal token request: %v", err))
+	}
+	info := model.APITokenInfo{APIToken: &dbmodel.APIToken{}}
+	var decode utilhttp.ResponseBody
+	decode.Bean = &info
+	code, err := t.DoRequest(t.prefix, "POST", bytes.NewBuffer(body), &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	return &info, handleAPIResult(code, decode)
+}
+
+func (t *tokens) Revoke(name string) *util.APIHandleError {
+	var decode utilhttp.ResponseBody
+	code, err := t.DoRequest(t.prefix+"/"+url.PathEscape(name), "DELETE", nil, &decode)
+	if err != nil {
+		return handleErrAndCode(err, code)
+	}
+	return handleAPIResult(code, decode)
+}
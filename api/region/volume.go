@@ -0,0 +1,72 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package region
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/api/util"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	utilhttp "github.com/gridworkz/kato/util/http"
+)
+
+func (s *services) volumePrefix(volumeName string) string {
+	return s.prefix + "/volumes/" + url.PathEscape(volumeName)
+}
+
+//VolumeBackups lists the backups of the volume data, the latest first
+func (s *services) VolumeBackups(volumeName string) ([]*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError) {
+	var backups []*dbmodel.TenantServiceVolumeBackup
+	var decode utilhttp.ResponseBody
+	decode.List = &backups
+	code, err := s.DoRequest(s.volumePrefix(volumeName)+"/backups", "GET", nil, &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	return backups, handleAPIResult(code, decode)
+}
+
+//BackupVolume backups the data of the volume to the object storage
+func (s *services) BackupVolume(volumeName string) (*dbmodel.TenantServiceVolumeBackup, *util.APIHandleError) {
+	var backup dbmodel.TenantServiceVolumeBackup
+	var decode utilhttp.ResponseBody
+	decode.Bean = &backup
+	code, err := s.DoRequest(s.volumePrefix(volumeName)+"/backups", "POST", bytes.NewBufferString("{}"), &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	return &backup, handleAPIResult(code, decode)
+}
+
+//RestoreVolume restores the data of the backup into the volume, the backup can be one of another component
+func (s *services) RestoreVolume(volumeName, backupID string, force bool) *util.APIHandleError {
+	body, err := json.Marshal(model.VolumeRestoreReq{BackupID: backupID, Force: force})
+	if err != nil {
+		return util.CreateAPIHandleError(400, fmt.Errorf("marshal restore request: %v", err))
+	}
+	var decode utilhttp.ResponseBody
+	code, err := s.DoRequest(s.volumePrefix(volumeName)+"/restore", "POST", bytes.NewBuffer(body), &decode)
+	if err != nil {
+		return handleErrAndCode(err, code)
+	}
+	return handleAPIResult(code, decode)
+}
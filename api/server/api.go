@@ -43,6 +43,7 @@ import (
 	"github.com/gridworkz/kato/api/api_routers/license"
 	"github.com/gridworkz/kato/api/metric"
 	"github.com/gridworkz/kato/api/proxy"
+	"github.com/gridworkz/kato/api/rbac"
 
 	"github.com/gridworkz/kato/api/api_routers/cloud"
 	"github.com/gridworkz/kato/api/api_routers/version2"
@@ -112,6 +113,8 @@ func (m *Manager) SetMiddleware() {
 	if os.Getenv("TOKEN") != "" {
 		r.Use(apimiddleware.FullToken)
 	}
+	//audit the mutating requests
+	r.Use(apimiddleware.Audit)
 	//simple api version
 	r.Use(apimiddleware.APIVersion)
 	r.Use(apimiddleware.Proxy)
@@ -159,13 +162,14 @@ func (m *Manager) Run() {
 	m.r.Mount("/cloud", cloud.Routes())
 	m.r.Mount("/", doc.Routes())
 	m.r.Mount("/license", license.Routes())
+	cluster := m.r.With(apimiddleware.Authorize(rbac.ResourceCluster))
 	//compatible with the old version of docker
-	m.r.Get("/v1/etcd/event-log/instances", m.EventLogInstance)
+	cluster.Get("/v1/etcd/event-log/instances", m.EventLogInstance)
 
-	m.r.Get("/kubernetes/dashboard", m.KuberntesDashboardAPI)
+	cluster.Get("/kubernetes/dashboard", m.KuberntesDashboardAPI)
 	//prometheus single node agent
-	m.r.Get("/api/v1/query", m.PrometheusAPI)
-	m.r.Get("/api/v1/query_range", m.PrometheusAPI)
+	cluster.Get("/api/v1/query", m.PrometheusAPI)
+	cluster.Get("/api/v1/query_range", m.PrometheusAPI)
 	//enable websocket service and file service to the browser
 	go func() {
 		websocketRouter := chi.NewRouter()
@@ -45,6 +45,10 @@ func init() {
 	buildcreaters[code.Nodejs] = slugBuilder
 	buildcreaters[code.Golang] = slugBuilder
 	buildcreaters[code.OSS] = slugBuilder
+	buildcreaters[code.Rust] = slugBuilder
+	buildcreaters[code.Elixir] = slugBuilder
+	buildcreaters[code.Deno] = slugBuilder
+	buildcreaters[code.Kotlin] = slugBuilder
 }
 
 var buildcreaters map[code.Lang]CreaterBuild
@@ -66,6 +70,12 @@ var ImageMediumType MediumType = "image"
 //SlugMediumType slug type
 var SlugMediumType MediumType = "slug"
 
+//DockerImageBuilder builds the images with the docker daemon
+var DockerImageBuilder = "docker"
+
+//OCIImageBuilder assembles the images into oci layouts and pushes them without the docker daemon
+var OCIImageBuilder = "oci"
+
 //Response build result
 type Response struct {
 	MediumPath string
@@ -100,6 +110,11 @@ type Request struct {
 	ExtraHosts    []string
 	HostAlias     []HostAlias
 	Ctx           context.Context
+
+	// ImageBuilder the backend building the images, docker or oci
+	ImageBuilder string
+	// OCILayoutPath the dir of the oci layouts of the oci image builder
+	OCILayoutPath string
 }
 
 // HostAlias holds the mapping between IP and hostnames that will be injected as an entry in the
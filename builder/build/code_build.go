@@ -118,6 +118,9 @@ func (s *slugBuild) writeRunDockerfile(sourceDir, packageName string, envs map[s
 //buildRunnerImage Wrap slug in the runner image
 func (s *slugBuild) buildRunnerImage(slugPackage string) (string, error) {
 	imageName := fmt.Sprintf("%s/%s:%s", builder.REGISTRYDOMAIN, s.re.ServiceID, s.re.DeployVersion)
+	if s.re.ImageBuilder == OCIImageBuilder {
+		return s.buildRunnerImageOCI(slugPackage, imageName)
+	}
 	cacheDir := path.Join(path.Dir(slugPackage), "."+s.re.DeployVersion)
 	if err := util.CheckAndCreateDir(cacheDir); err != nil {
 		return "", fmt.Errorf("create cache package dir failure %s", err.Error())
@@ -360,6 +363,10 @@ func (s *slugBuild) runBuildJob(re *Request) error {
 		envs = append(envs, corev1.EnvVar{Name: "PACKAGE_DOWNLOAD_USER", Value: re.CodeSouceInfo.User})
 		envs = append(envs, corev1.EnvVar{Name: "PACKAGE_DOWNLOAD_PASS", Value: re.CodeSouceInfo.Password})
 	}
+	// the buildpack set by the build envs takes precedence
+	if buildpack := code.GetBuildpack(re.Lang); buildpack != "" && re.BuildEnvs["BUILDPACK_URL"] == "" {
+		envs = append(envs, corev1.EnvVar{Name: "BUILDPACK_URL", Value: buildpack})
+	}
 	var mavenSettingName string
 	for k, v := range re.BuildEnvs {
 		if k == "MAVEN_SETTING_NAME" {
@@ -41,12 +41,15 @@ type dockerfileBuild struct {
 func (d *dockerfileBuild) Build(re *Request) (*Response, error) {
 	filepath := path.Join(re.SourceDir, "Dockerfile")
 	re.Logger.Info("Start parse Dockerfile", map[string]string{"step": "builder-exector"})
-	_, err := sources.ParseFile(filepath)
+	commands, err := sources.ParseFile(filepath)
 	if err != nil {
 		logrus.Error("parse dockerfile error.", err.Error())
 		re.Logger.Error(fmt.Sprintf("Parse dockerfile error"), map[string]string{"step": "builder-exector"})
 		return nil, err
 	}
+	if re.ImageBuilder == OCIImageBuilder {
+		return d.buildOCI(re, commands)
+	}
 	buildImageName := CreateImageName(re.ServiceID, re.DeployVersion)
 
 	buildOptions := types.ImageBuildOptions{
@@ -61,6 +61,10 @@ func netcoreBuilder() (Build, error) {
 }
 
 func (d *netcoreBuild) Build(re *Request) (*Response, error) {
+	if re.ImageBuilder == OCIImageBuilder {
+		re.Logger.Error("The .NetCore build requires the docker image builder", map[string]string{"step": "builder-exector", "status": "failure"})
+		return nil, &ErrOCIUnsupported{Reason: "the .NetCore build runs commands"}
+	}
 	defer d.clear()
 	d.dockercli = re.DockerClient
 	d.logger = re.Logger
@@ -0,0 +1,380 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package build
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gridworkz/kato/builder"
+	"github.com/gridworkz/kato/builder/oci"
+	"github.com/gridworkz/kato/builder/sources"
+	"github.com/sirupsen/logrus"
+)
+
+//runnerUserID the uid and gid of the user rain in the runner image
+const runnerUserID = 200
+
+//OpenOCILayout opens the layout of a build in the layout path, the blobs of the base images are
+//shared by the builds through a cache layout. The returned func removes the layout of the build.
+func OpenOCILayout(layoutPath, name string) (*oci.Layout, func(), error) {
+	cache, err := oci.OpenLayout(path.Join(layoutPath, "cache"), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open oci layout cache: %v", err)
+	}
+	layout, err := oci.OpenLayout(path.Join(layoutPath, "builds", name), cache)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open oci layout: %v", err)
+	}
+	return layout, func() {
+		if err := os.RemoveAll(layout.Dir()); err != nil {
+			logrus.Warningf("remove oci layout %s: %v", layout.Dir(), err)
+		}
+	}, nil
+}
+
+//pushOCIImage pushes the image of the layout to the local registry
+func pushOCIImage(layout *oci.Layout, imageName string, base *oci.Remote) error {
+	target, err := oci.NewRemote(imageName, builder.REGISTRYUSER, builder.REGISTRYPASS)
+	if err != nil {
+		return err
+	}
+	return target.Push(layout, imageName, base)
+}
+
+//buildRunnerImageOCI adds the slug package to the runner image without the docker daemon
+func (s *slugBuild) buildRunnerImageOCI(slugPackage, imageName string) (string, error) {
+	layout, remove, err := OpenOCILayout(s.re.OCILayoutPath, s.re.ServiceID+"-"+s.re.DeployVersion)
+	if err != nil {
+		return "", err
+	}
+	defer remove()
+	base, err := oci.NewRemote(builder.RUNNERIMAGENAME, builder.REGISTRYUSER, builder.REGISTRYPASS)
+	if err != nil {
+		return "", err
+	}
+	img, err := base.Pull(layout, "")
+	if err != nil {
+		return "", fmt.Errorf("pull image %s: %v", builder.RUNNERIMAGENAME, err)
+	}
+	logrus.Infof("pull image %s successfully.", builder.RUNNERIMAGENAME)
+	slug := oci.LayerFile{Source: slugPackage, Path: "/tmp/slug/slug.tgz", UID: runnerUserID, GID: runnerUserID}
+	if err := img.AddLayer([]oci.LayerFile{slug}, "COPY slug.tgz /tmp/slug/slug.tgz"); err != nil {
+		return "", fmt.Errorf("add slug layer: %v", err)
+	}
+	img.SetEnv("CODE_COMMIT_HASH", s.re.Commit.Hash)
+	img.SetEnv("CODE_COMMIT_USER", s.re.Commit.User)
+	img.SetEnv("CODE_COMMIT_MESSAGE", s.re.Commit.Message)
+	img.SetEnv("VERSION", s.re.DeployVersion)
+	if _, err := img.Save(imageName); err != nil {
+		return "", err
+	}
+	s.re.Logger.Info("build image of new version success, will push to local registry", map[string]string{"step": "builder-exector"})
+	if err := pushOCIImage(layout, imageName, base); err != nil {
+		s.re.Logger.Error("push image failure", map[string]string{"step": "builder-exector"})
+		logrus.Errorf("push image error: %s", err.Error())
+		return "", err
+	}
+	s.re.Logger.Info("push image of new version success", map[string]string{"step": "builder-exector"})
+	return imageName, nil
+}
+
+//ErrOCIUnsupported the build requires the docker image builder
+type ErrOCIUnsupported struct {
+	Reason string
+}
+
+func (e *ErrOCIUnsupported) Error() string {
+	return fmt.Sprintf("%s, which requires the docker image builder", e.Reason)
+}
+
+//buildOCI assembles the image of the dockerfile without the docker daemon, the dockerfile must
+//have a single stage, and can not RUN commands.
+func (d *dockerfileBuild) buildOCI(re *Request, commands []sources.Command) (*Response, error) {
+	imageName := CreateImageName(re.ServiceID, re.DeployVersion)
+	layout, remove, err := OpenOCILayout(re.OCILayoutPath, re.ServiceID+"-"+re.DeployVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer remove()
+	args := make(map[string]string)
+	for k, v := range GetARGs(re.BuildEnvs) {
+		args[k] = *v
+	}
+	assembler := &dockerfileAssembler{layout: layout, contextDir: re.SourceDir, buildArgs: args}
+	if err := assembler.assemble(commands); err != nil {
+		re.Logger.Error(fmt.Sprintf("build image %s failure: %v", imageName, err), map[string]string{"step": "builder-exector", "status": "failure"})
+		return nil, err
+	}
+	if _, err := assembler.image.Save(imageName); err != nil {
+		return nil, err
+	}
+	re.Logger.Info("The image build is successful and starts pushing the image to the repository", map[string]string{"step": "builder-exector"})
+	if err := pushOCIImage(layout, imageName, assembler.base); err != nil {
+		re.Logger.Error("Push image failure", map[string]string{"step": "builder-exector"})
+		logrus.Errorf("push image error: %s", err.Error())
+		return nil, err
+	}
+	re.Logger.Info("The image is pushed to the warehouse successfully", map[string]string{"step": "builder-exector"})
+	return &Response{
+		MediumPath: imageName,
+		MediumType: ImageMediumType,
+	}, nil
+}
+
+//dockerfileAssembler assembles the image of a dockerfile
+type dockerfileAssembler struct {
+	layout     *oci.Layout
+	contextDir string
+	buildArgs  map[string]string
+	image      *oci.Image
+	base       *oci.Remote
+	// the args and the envs for the substitution
+	vars map[string]string
+	// the cmd of the base image is reset by ENTRYPOINT
+	cmdSet bool
+}
+
+func (a *dockerfileAssembler) assemble(commands []sources.Command) error {
+	a.vars = make(map[string]string)
+	for _, cmd := range commands {
+		if err := a.apply(cmd); err != nil {
+			return fmt.Errorf("line %d: %v", cmd.StartLine, err)
+		}
+	}
+	if a.image == nil {
+		return fmt.Errorf("no FROM instruction")
+	}
+	return nil
+}
+
+func (a *dockerfileAssembler) expand(value string) string {
+	return os.Expand(value, func(name string) string {
+		return a.vars[name]
+	})
+}
+
+func (a *dockerfileAssembler) apply(cmd sources.Command) error {
+	if cmd.Cmd != "from" && cmd.Cmd != "arg" && a.image == nil {
+		return fmt.Errorf("%s before FROM", strings.ToUpper(cmd.Cmd))
+	}
+	values := make([]string, len(cmd.Value))
+	for i := range cmd.Value {
+		values[i] = a.expand(cmd.Value[i])
+	}
+	if a.image != nil && cmd.Cmd != "copy" && cmd.Cmd != "add" {
+		defer a.image.AddHistory(cmd.Original)
+	}
+	config := func() *oci.ContainerConfig {
+		return &a.image.Config.Config
+	}
+	switch cmd.Cmd {
+	case "from":
+		if a.image != nil {
+			return &ErrOCIUnsupported{Reason: "the multi-stage dockerfile"}
+		}
+		return a.from(values)
+	case "arg":
+		for _, arg := range values {
+			kv := strings.SplitN(arg, "=", 2)
+			if v, ok := a.buildArgs[kv[0]]; ok {
+				a.vars[kv[0]] = v
+			} else if len(kv) == 2 {
+				a.vars[kv[0]] = kv[1]
+			}
+		}
+	case "env":
+		for i := 0; i+1 < len(values); i += 2 {
+			a.vars[values[i]] = values[i+1]
+			a.image.SetEnv(values[i], values[i+1])
+		}
+	case "label":
+		if config().Labels == nil {
+			config().Labels = make(map[string]string)
+		}
+		for i := 0; i+1 < len(values); i += 2 {
+			config().Labels[values[i]] = values[i+1]
+		}
+	case "maintainer":
+		a.image.Config.Author = strings.Join(values, " ")
+	case "expose":
+		if config().ExposedPorts == nil {
+			config().ExposedPorts = make(map[string]struct{})
+		}
+		for _, port := range values {
+			if !strings.Contains(port, "/") {
+				port += "/tcp"
+			}
+			config().ExposedPorts[port] = struct{}{}
+		}
+	case "volume":
+		if config().Volumes == nil {
+			config().Volumes = make(map[string]struct{})
+		}
+		for _, volume := range values {
+			config().Volumes[volume] = struct{}{}
+		}
+	case "workdir":
+		if len(values) > 0 {
+			config().WorkingDir = a.absPath(values[0])
+		}
+	case "user":
+		config().User = strings.Join(values, "")
+	case "stopsignal":
+		config().StopSignal = strings.Join(values, "")
+	case "cmd":
+		config().Cmd = a.commandLine(cmd, values)
+		a.cmdSet = true
+	case "entrypoint":
+		config().Entrypoint = a.commandLine(cmd, values)
+		if !a.cmdSet {
+			config().Cmd = nil
+		}
+	case "copy", "add":
+		return a.copy(cmd, values)
+	case "healthcheck":
+		// the health checks of the components are defined by their probes
+		logrus.Warningf("HEALTHCHECK of the dockerfile is ignored by the oci image builder")
+	default:
+		return &ErrOCIUnsupported{Reason: fmt.Sprintf("the %s instruction", strings.ToUpper(cmd.Cmd))}
+	}
+	return nil
+}
+
+func (a *dockerfileAssembler) from(values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("FROM requires an image")
+	}
+	if values[0] == "scratch" {
+		a.image = oci.NewImage(a.layout)
+		return nil
+	}
+	user, pass := builder.GetImageUserInfoV2(values[0], "", "")
+	base, err := oci.NewRemote(values[0], user, pass)
+	if err != nil {
+		return err
+	}
+	img, err := base.Pull(a.layout, "")
+	if err != nil {
+		return fmt.Errorf("pull image %s: %v", values[0], err)
+	}
+	// the ONBUILD triggers can not be run without the docker daemon
+	if len(img.Config.Config.OnBuild) > 0 {
+		return &ErrOCIUnsupported{Reason: fmt.Sprintf("the ONBUILD triggers of image %s", values[0])}
+	}
+	for _, env := range img.Config.Config.Env {
+		if kv := strings.SplitN(env, "=", 2); len(kv) == 2 {
+			a.vars[kv[0]] = kv[1]
+		}
+	}
+	a.image, a.base = img, base
+	return nil
+}
+
+// commandLine returns the exec form of CMD or ENTRYPOINT
+func (a *dockerfileAssembler) commandLine(cmd sources.Command, values []string) []string {
+	if cmd.Json {
+		return values
+	}
+	return []string{"/bin/sh", "-c", strings.Join(values, " ")}
+}
+
+// absPath returns the path in the image, relative to the working dir
+func (a *dockerfileAssembler) absPath(p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	workdir := a.image.Config.Config.WorkingDir
+	if workdir == "" {
+		workdir = "/"
+	}
+	return path.Join(workdir, p)
+}
+
+func (a *dockerfileAssembler) copy(cmd sources.Command, values []string) error {
+	var uid, gid int
+	for _, flag := range cmd.Flags {
+		switch {
+		case strings.HasPrefix(flag, "--chown="):
+			owner := strings.SplitN(strings.TrimPrefix(flag, "--chown="), ":", 2)
+			if _, err := fmt.Sscanf(owner[0], "%d", &uid); err != nil {
+				return &ErrOCIUnsupported{Reason: "the user names of --chown"}
+			}
+			gid = uid
+			if len(owner) == 2 {
+				if _, err := fmt.Sscanf(owner[1], "%d", &gid); err != nil {
+					return &ErrOCIUnsupported{Reason: "the group names of --chown"}
+				}
+			}
+		default:
+			return &ErrOCIUnsupported{Reason: fmt.Sprintf("the flag %s", flag)}
+		}
+	}
+	if len(values) < 2 {
+		return fmt.Errorf("%s requires a source and a destination", strings.ToUpper(cmd.Cmd))
+	}
+	dest := values[len(values)-1]
+	destIsDir := strings.HasSuffix(dest, "/") || len(values) > 2
+	dest = a.absPath(dest)
+	var files []oci.LayerFile
+	for _, src := range values[:len(values)-1] {
+		if strings.Contains(src, "://") {
+			return &ErrOCIUnsupported{Reason: "the remote sources of ADD"}
+		}
+		if cmd.Cmd == "add" && isArchive(src) {
+			return &ErrOCIUnsupported{Reason: "the archives extracted by ADD"}
+		}
+		matches, err := filepath.Glob(filepath.Join(a.contextDir, filepath.Clean("/"+src)))
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("%s: no such file or directory", src)
+		}
+		destIsDir = destIsDir || len(matches) > 1
+		for _, match := range matches {
+			// the sources are followed like docker does, but never out of the build context
+			source, err := oci.ResolveUnder(a.contextDir, match)
+			if err != nil {
+				return fmt.Errorf("%s: %v", src, err)
+			}
+			info, err := os.Stat(source)
+			if err != nil {
+				return err
+			}
+			target := dest
+			if !info.IsDir() && destIsDir {
+				target = path.Join(dest, filepath.Base(match))
+			}
+			files = append(files, oci.LayerFile{Source: source, Path: target, Root: a.contextDir, UID: uid, GID: gid})
+		}
+	}
+	return a.image.AddLayer(files, cmd.Original)
+}
+
+func isArchive(name string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -91,6 +91,9 @@ func (t *Manager) Start(errchan chan error) error {
 					if err != nil {
 						logrus.Error(err)
 					}
+					if err := db.GetManager().BuildCacheDao().DeleteByBuildVersion(v.ServiceID, v.BuildVersion); err != nil {
+						logrus.Error(err)
+					}
 					if err := db.GetManager().VersionInfoDao().DeleteVersionInfo(v); err != nil {
 						logrus.Error(err)
 						continue
@@ -102,6 +105,9 @@ func (t *Manager) Start(errchan chan error) error {
 					if err := os.Remove(filePath); err != nil {
 						logrus.Error(err)
 					}
+					if err := db.GetManager().BuildCacheDao().DeleteByBuildVersion(v.ServiceID, v.BuildVersion); err != nil {
+						logrus.Error(err)
+					}
 					if err := db.GetManager().VersionInfoDao().DeleteVersionInfo(v); err != nil {
 						logrus.Error(err)
 						continue
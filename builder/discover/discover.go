@@ -78,6 +78,11 @@ func (t *TaskManager) Start(errChan chan error) error {
 func (t *TaskManager) callback(task *pb.TaskMessage) {
 	ctx, cancel := context.WithCancel(t.ctx)
 	defer cancel()
+	// the returned task is enqueued again, ack the current delivery first
+	hostName, _ := os.Hostname()
+	if _, err := t.client.Ack(ctx, &pb.AckRequest{Topic: t.config.Topic, TaskId: task.TaskId, ClientHost: hostName + "-builder"}); err != nil {
+		logrus.Warningf("ack task %s failure %s", task.TaskId, err.Error())
+	}
 	_, err := t.client.Enqueue(ctx, &pb.EnqueueRequest{
 		Topic:   client.BuilderTopic,
 		Message: task,
@@ -91,41 +96,35 @@ func (t *TaskManager) callback(task *pb.TaskMessage) {
 //Do it
 func (t *TaskManager) Do(errChan chan error) {
 	hostName, _ := os.Hostname()
+	req := &pb.SubscribeRequest{
+		Topic:       t.config.Topic,
+		ConsumerId:  hostName + "-builder",
+		MaxInFlight: int32(t.exec.GetMaxConcurrentTask()),
+	}
 	for {
 		select {
 		case <-t.discoverCtx.Done():
 			return
 		default:
-			ctx, cancel := context.WithCancel(t.discoverCtx)
-			data, err := t.client.Dequeue(ctx, &pb.DequeueRequest{Topic: t.config.Topic, ClientHost: hostName + "-builder"})
-			cancel()
-			if err != nil {
-				if grpc1.ErrorDesc(err) == context.DeadlineExceeded.Error() {
-					logrus.Warn(err.Error())
-					continue
+			err := t.client.SubscribeTopic(t.discoverCtx, req, func(data *pb.TaskMessage) {
+				if err := t.exec.AddTask(data); err != nil {
+					t.callbackChan <- data
+					logrus.Error("add task error:", err.Error())
 				}
+			})
+			if err != nil {
 				if grpc1.ErrorDesc(err) == "context canceled" {
-					logrus.Warn("grpc dequeue context canceled")
+					logrus.Warn("grpc subscribe context canceled")
 					healthStatus["status"] = "unusual"
-					healthStatus["info"] = "grpc dequeue context canceled"
+					healthStatus["info"] = "grpc subscribe context canceled"
 					return
 				}
-				if grpc1.ErrorDesc(err) == "context timeout" {
-					logrus.Warn(err.Error())
-					continue
-				}
 				if strings.Contains(err.Error(), "there is no connection available") {
-					errChan <- fmt.Errorf("message dequeue failure %s", err.Error())
+					errChan <- fmt.Errorf("message subscribe failure %s", err.Error())
 					return
 				}
-				logrus.Errorf("message dequeue failure %s, will retry", err.Error())
+				logrus.Errorf("message subscribe failure %s, will retry", err.Error())
 				time.Sleep(time.Second * 2)
-				continue
-			}
-			err = t.exec.AddTask(data)
-			if err != nil {
-				t.callbackChan <- data
-				logrus.Error("add task error:", err.Error())
 			}
 		}
 	}
@@ -0,0 +1,140 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package exector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gridworkz/kato/builder"
+	"github.com/gridworkz/kato/builder/build"
+	"github.com/gridworkz/kato/builder/sources"
+	"github.com/gridworkz/kato/cmd"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// the build envs which do not change the build result
+var buildCacheIgnoreEnvs = map[string]struct{}{
+	"NO_CACHE": {},
+	"REPARSE":  {},
+}
+
+//buildCacheKey returns the digest of the inputs of a source code build
+func buildCacheKey(repoURL, commit, lang, runtime string, envs map[string]string, images ...string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "repo=%s\ncommit=%s\nlang=%s\nruntime=%s\n", repoURL, commit, lang, runtime)
+	keys := make([]string, 0, len(envs))
+	for k := range envs {
+		if _, ok := buildCacheIgnoreEnvs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "env=%s=%s\n", k, envs[k])
+	}
+	for _, image := range images {
+		fmt.Fprintf(h, "image=%s\n", image)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//buildCacheKey returns the cache key of the build, it is empty if the build can not be cached
+func (i *SourceCodeBuildItem) buildCacheKey() string {
+	if i.commit.Hash == "" {
+		return ""
+	}
+	repoURL := i.RepoInfo.RepostoryURL
+	if i.RepoInfo.BuildPath != "" {
+		repoURL += "?dir=" + i.RepoInfo.BuildPath
+	}
+	var images []string
+	for _, name := range []string{builder.BUILDERIMAGENAME, builder.RUNNERIMAGENAME} {
+		image := i.buildImageIdentity(name)
+		if image == "" {
+			return ""
+		}
+		images = append(images, image)
+	}
+	return buildCacheKey(repoURL, i.commit.Hash, i.Lang, i.Runtime, i.BuildEnvs, images...)
+}
+
+//buildImageIdentity returns what identifies the content of the builder or runner image,
+//the image names are not tagged, so the name alone does not change when the image is upgraded.
+//It is the local image id, or the kato version the image is released with, empty if neither is known
+func (i *SourceCodeBuildItem) buildImageIdentity(name string) string {
+	if i.DockerClient != nil {
+		if ins, err := sources.ImageInspectWithRaw(i.DockerClient, name); err == nil && ins.ID != "" {
+			return name + "@" + ins.ID
+		}
+	}
+	if version := cmd.GetVersion(); version != "" {
+		return name + "@kato-" + version
+	}
+	return ""
+}
+
+//getBuildCache returns the result of the former build of the same inputs, nil if there is none or it is gone
+func (i *SourceCodeBuildItem) getBuildCache(key string) *build.Response {
+	cache, err := db.GetManager().BuildCacheDao().GetByCacheKey(i.ServiceID, key)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.Warningf("get build cache of service %s: %v", i.ServiceID, err)
+		}
+		return nil
+	}
+	version, err := db.GetManager().VersionInfoDao().GetVersionByDeployVersion(cache.BuildVersion, i.ServiceID)
+	if err != nil || version.FinalStatus != "success" || version.DeliveredPath != cache.DeliveredPath {
+		return nil
+	}
+	res := &build.Response{MediumType: build.MediumType(cache.DeliveredType), MediumPath: cache.DeliveredPath}
+	if res.MediumType == build.SlugMediumType {
+		// the slug of the former version is removed with it, so the new version gets its own
+		packageName := fmt.Sprintf("%s/%s.tgz", i.TGZDir, i.DeployVersion)
+		if err := os.Link(cache.DeliveredPath, packageName); err != nil {
+			logrus.Warningf("link cached slug %s: %v", cache.DeliveredPath, err)
+			return nil
+		}
+		res.MediumPath = packageName
+	}
+	return res
+}
+
+//saveBuildCache records the result of the build, the cache points to the latest version of the same inputs
+func (i *SourceCodeBuildItem) saveBuildCache(key string, res *build.Response) {
+	cache := &dbmodel.BuildCache{
+		CacheKey:      key,
+		ServiceID:     i.ServiceID,
+		BuildVersion:  i.DeployVersion,
+		RepoURL:       i.RepoInfo.RepostoryURL,
+		CodeVersion:   i.commit.Hash,
+		Lang:          i.Lang,
+		DeliveredType: string(res.MediumType),
+		DeliveredPath: res.MediumPath,
+	}
+	if err := db.GetManager().BuildCacheDao().AddModel(cache); err != nil {
+		logrus.Warningf("save build cache of service %s: %v", i.ServiceID, err)
+	}
+}
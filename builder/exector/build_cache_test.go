@@ -0,0 +1,42 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package exector
+
+import "testing"
+
+func TestBuildCacheKey(t *testing.T) {
+	envs := map[string]string{"PROCFILE": "web: app", "RUNTIMES": "1.15"}
+	key := buildCacheKey("https://github.com/a/b.git", "abc", "Go", "", envs, "builder", "runner")
+	same := buildCacheKey("https://github.com/a/b.git", "abc", "Go", "", map[string]string{"RUNTIMES": "1.15", "PROCFILE": "web: app", "NO_CACHE": "true"}, "builder", "runner")
+	if key != same {
+		t.Errorf("the order of the envs and the ignored envs change the key")
+	}
+	others := []string{
+		buildCacheKey("https://github.com/a/c.git", "abc", "Go", "", envs, "builder", "runner"),
+		buildCacheKey("https://github.com/a/b.git", "abd", "Go", "", envs, "builder", "runner"),
+		buildCacheKey("https://github.com/a/b.git", "abc", "Node.js", "", envs, "builder", "runner"),
+		buildCacheKey("https://github.com/a/b.git", "abc", "Go", "", map[string]string{"PROCFILE": "web: app"}, "builder", "runner"),
+		buildCacheKey("https://github.com/a/b.git", "abc", "Go", "", envs, "builder:v2", "runner"),
+	}
+	for i, other := range others {
+		if other == key {
+			t.Errorf("case %d: want a different key", i)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/gridworkz/kato/builder"
 	"github.com/gridworkz/kato/builder/build"
+	"github.com/gridworkz/kato/builder/oci"
 	"github.com/gridworkz/kato/builder/sources"
 	"github.com/gridworkz/kato/db"
 	"github.com/gridworkz/kato/event"
@@ -50,6 +51,8 @@ type ImageBuildItem struct {
 	HubPassword   string
 	Action        string
 	Configs       map[string]gjson.Result `json:"configs"`
+	ImageBuilder  string
+	OCILayoutPath string
 }
 
 //NewImageBuildItem
@@ -74,6 +77,9 @@ func NewImageBuildItem(in []byte) *ImageBuildItem {
 
 //Run
 func (i *ImageBuildItem) Run(timeout time.Duration) error {
+	if i.ImageBuilder == build.OCIImageBuilder {
+		return i.copyImage()
+	}
 	user, pass := builder.GetImageUserInfoV2(i.Image, i.HubUser, i.HubPassword)
 	_, err := sources.ImagePull(i.DockerClient, i.Image, user, pass, i.Logger, 30)
 	if err != nil {
@@ -111,6 +117,35 @@ func (i *ImageBuildItem) Run(timeout time.Duration) error {
 	return nil
 }
 
+//copyImage copies the image to the local registry without the docker daemon
+func (i *ImageBuildItem) copyImage() error {
+	localImageURL := build.CreateImageName(i.ServiceID, i.DeployVersion)
+	layout, remove, err := build.OpenOCILayout(i.OCILayoutPath, i.ServiceID+"-"+i.DeployVersion)
+	if err != nil {
+		return err
+	}
+	defer remove()
+	user, pass := builder.GetImageUserInfoV2(i.Image, i.HubUser, i.HubPassword)
+	src, err := oci.NewRemote(i.Image, user, pass)
+	if err == nil {
+		var dst *oci.Remote
+		if dst, err = oci.NewRemote(localImageURL, builder.REGISTRYUSER, builder.REGISTRYPASS); err == nil {
+			err = oci.Copy(layout, src, dst)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("copy image %s to %s error: %s", i.Image, localImageURL, err.Error())
+		i.Logger.Error(fmt.Sprintf("copy image %s to the local registry failure", i.Image), map[string]string{"step": "builder-exector", "status": "failure"})
+		return err
+	}
+	if err := i.StorageVersionInfo(localImageURL); err != nil {
+		logrus.Errorf("storage version info error, ignor it: %s", err.Error())
+		i.Logger.Error("failed to update app version information", map[string]string{"step": "builder-exector", "status": "failure"})
+		return err
+	}
+	return nil
+}
+
 //StorageVersionInfo
 func (i *ImageBuildItem) StorageVersionInfo(imageURL string) error {
 	version, err := db.GetManager().VersionInfoDao().GetVersionByDeployVersion(i.DeployVersion, i.ServiceID)
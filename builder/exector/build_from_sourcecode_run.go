@@ -74,6 +74,8 @@ type SourceCodeBuildItem struct {
 	commit        Commit
 	Configs       map[string]gjson.Result `json:"configs"`
 	Ctx           context.Context
+	ImageBuilder  string
+	OCILayoutPath string
 }
 
 //Commit code Commit
@@ -206,6 +208,22 @@ func (i *SourceCodeBuildItem) Run(timeout time.Duration) error {
 		}
 		i.Lang = string(lang)
 	}
+	i.setKatofileBuildArgs(rbi)
+
+	cacheKey := i.buildCacheKey()
+	if _, ok := i.BuildEnvs["NO_CACHE"]; ok {
+		i.Logger.Info("Build cache is disabled, build from the source code", map[string]string{"step": "build-cache"})
+	} else if cacheKey != "" {
+		if res := i.getBuildCache(cacheKey); res != nil {
+			i.Logger.Info(fmt.Sprintf("Build cache hit, reuse the build result %s and skip the build", res.MediumPath), map[string]string{"step": "build-cache"})
+			if err := i.UpdateBuildVersionInfo(res); err != nil {
+				return err
+			}
+			i.saveBuildCache(cacheKey, res)
+			return nil
+		}
+		i.Logger.Info("Build cache miss, build from the source code", map[string]string{"step": "build-cache"})
+	}
 
 	i.Logger.Info("pull or clone code successfully, start code build", map[string]string{"step": "codee-version"})
 	res, err := i.codeBuild()
@@ -220,9 +238,29 @@ func (i *SourceCodeBuildItem) Run(timeout time.Duration) error {
 	if err := i.UpdateBuildVersionInfo(res); err != nil {
 		return err
 	}
+	if cacheKey != "" {
+		i.saveBuildCache(cacheKey, res)
+	}
 	return nil
 }
 
+//setKatofileBuildArgs adds the build args declared in the katofile, the build envs set by the user take precedence
+func (i *SourceCodeBuildItem) setKatofileBuildArgs(rbi *sources.RepostoryBuildInfo) {
+	rbdfile, err := code.ReadKatoFile(rbi.GetCodeBuildAbsPath())
+	if err != nil || len(rbdfile.BuildArgs) == 0 {
+		return
+	}
+	prefix := ""
+	if code.Lang(i.Lang) == code.Dockerfile {
+		prefix = "ARG_"
+	}
+	for k, v := range rbdfile.BuildArgs {
+		if _, ok := i.BuildEnvs[prefix+k]; !ok {
+			i.BuildEnvs[prefix+k] = v
+		}
+	}
+}
+
 func (i *SourceCodeBuildItem) codeBuild() (*build.Response, error) {
 	codeBuild, err := build.GetBuild(code.Lang(i.Lang))
 	if err != nil {
@@ -258,6 +296,8 @@ func (i *SourceCodeBuildItem) codeBuild() (*build.Response, error) {
 		HostAlias:     hostAlias,
 		Ctx:           i.Ctx,
 		GRDataPVCName: i.GRDataPVCName,
+		ImageBuilder:  i.ImageBuilder,
+		OCILayoutPath: i.OCILayoutPath,
 		CachePVCName:  i.CachePVCName,
 		CacheMode:     i.CacheMode,
 		CachePath:     i.CachePath,
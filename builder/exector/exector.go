@@ -21,6 +21,7 @@ package exector
 import (
 	"context"
 	"fmt"
+	"os"
 	"runtime/debug"
 	"sync"
 	"time"
@@ -201,6 +202,7 @@ func (e *exectorManager) runTask(f func(task *pb.TaskMessage), task *pb.TaskMess
 	}
 	f(task)
 	e.runningTask.Delete(task.TaskId)
+	e.ackTask(task)
 	logrus.Infof("Build task %s is completed", task.TaskId)
 }
 func (e *exectorManager) runTaskWithErr(f func(task *pb.TaskMessage) error, task *pb.TaskMessage, concurrencyControl bool) {
@@ -216,8 +218,24 @@ func (e *exectorManager) runTaskWithErr(f func(task *pb.TaskMessage) error, task
 		logrus.Errorf("run builder task failure %s", err.Error())
 	}
 	e.runningTask.Delete(task.TaskId)
+	e.ackTask(task)
 	logrus.Infof("Build task %s is completed", task.TaskId)
 }
+
+//ackTask tells mq the task is handled, an unacked task is delivered again after the visibility timeout
+func (e *exectorManager) ackTask(task *pb.TaskMessage) {
+	if e.mqClient == nil {
+		return
+	}
+	hostName, _ := os.Hostname()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_, err := e.mqClient.Ack(ctx, &pb.AckRequest{Topic: e.cfg.Topic, TaskId: task.TaskId, ClientHost: hostName + "-builder"})
+	if err != nil {
+		logrus.Warningf("ack build task %s failure %s", task.TaskId, err.Error())
+	}
+}
+
 func (e *exectorManager) RunTask(task *pb.TaskMessage) {
 	switch task.TaskType {
 	case "build_from_image":
@@ -276,6 +294,8 @@ func (e *exectorManager) exec(task *pb.TaskMessage) error {
 func (e *exectorManager) buildFromImage(task *pb.TaskMessage) {
 	i := NewImageBuildItem(task.TaskBody)
 	i.DockerClient = e.DockerClient
+	i.ImageBuilder = e.cfg.ImageBuilder
+	i.OCILayoutPath = e.cfg.OCILayoutPath
 	i.Logger.Info("Start with the image build application task", map[string]string{"step": "builder-exector", "status": "starting"})
 	defer event.GetManager().ReleaseLogger(i.Logger)
 	defer func() {
@@ -333,6 +353,8 @@ func (e *exectorManager) buildFromSourceCode(task *pb.TaskMessage) {
 	i.GRDataPVCName = e.cfg.GRDataPVCName
 	i.CacheMode = e.cfg.CacheMode
 	i.CachePath = e.cfg.CachePath
+	i.ImageBuilder = e.cfg.ImageBuilder
+	i.OCILayoutPath = e.cfg.OCILayoutPath
 	i.Logger.Info("Build app version from source code start", map[string]string{"step": "builder-exector", "status": "starting"})
 	start := time.Now()
 	defer event.GetManager().ReleaseLogger(i.Logger)
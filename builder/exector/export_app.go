@@ -42,7 +42,7 @@ import (
 
 var re = regexp.MustCompile(`\s`)
 
-//ExportApp Export app to specified format(kato-app or dockercompose)
+//ExportApp Export app to specified format(kato-app, dockercompose or helm-chart)
 type ExportApp struct {
 	EventID      string `json:"event_id"`
 	Format       string `json:"format"`
@@ -101,6 +101,13 @@ func (i *ExportApp) Run(timeout time.Duration) error {
 			i.updateStatus("failed", "")
 			return err
 		}
+	} else if i.Format == "helm-chart" {
+		re, err = i.exportHelmChart(*ram)
+		if err != nil {
+			logrus.Errorf("export helm chart package failure %s", err.Error())
+			i.updateStatus("failed", "")
+			return err
+		}
 	} else {
 		return errors.New("Unsupported the format: " + i.Format)
 	}
@@ -0,0 +1,531 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package exector
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gridworkz/kato-oam/pkg/export"
+	"github.com/gridworkz/kato-oam/pkg/ram/v1alpha1"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/util"
+	"sigs.k8s.io/yaml"
+)
+
+//helmWorkload is a component prepared for the chart templates
+type helmWorkload struct {
+	Name         string
+	Stateful     bool
+	Ports        []helmServicePort
+	ConfigGroups []string
+	ConfigFiles  []helmMount
+	Volumes      []helmMount
+}
+
+type helmServicePort struct {
+	ServiceName   string
+	ContainerPort int
+	Protocol      string
+	HTTP          bool
+	Outer         bool
+}
+
+type helmMount struct {
+	Name      string
+	Key       string
+	MountPath string
+	Memory    bool
+}
+
+var (
+	helmNameInvalid  = regexp.MustCompile(`[^a-z0-9-]+`)
+	helmKeyInvalid   = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+	helmChartSemver  = regexp.MustCompile(`^v?(\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?)$`)
+	helmChartVersion = regexp.MustCompile(`^v?(\d+\.\d+)$`)
+)
+
+//helmName converts s to a dns label, empty if nothing is left
+func helmName(s string) string {
+	name := strings.Trim(helmNameInvalid.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(name) > 40 {
+		name = strings.Trim(name[:40], "-")
+	}
+	return name
+}
+
+//helmChartSemVersion returns the chart version for the app version, helm requires semver
+func helmChartSemVersion(version string) string {
+	if m := helmChartSemver.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+	if m := helmChartVersion.FindStringSubmatch(version); m != nil {
+		return m[1] + ".0"
+	}
+	return "0.1.0"
+}
+
+func kubeProtocol(protocol string) string {
+	if strings.ToLower(protocol) == "udp" {
+		return "UDP"
+	}
+	return "TCP"
+}
+
+//exportHelmChart export app to a helm chart for plain kubernetes clusters.
+//The images are not packaged, the chart refers to the shared images which the cluster must be able to pull
+func (i *ExportApp) exportHelmChart(ram v1alpha1.KatoApplicationConfig) (*export.Result, error) {
+	chartName := helmName(ram.AppName)
+	if chartName == "" {
+		chartName = "kato-app"
+	}
+	files, err := helmChartFiles(chartName, &ram)
+	if err != nil {
+		return nil, err
+	}
+	chartDir := path.Join(i.SourceDir, chartName)
+	for name, content := range files {
+		filePath := path.Join(chartDir, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filePath, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+	packageName := fmt.Sprintf("%s-%s.tgz", chartName, helmChartSemVersion(ram.AppVersion))
+	packagePath := path.Join(i.SourceDir, packageName)
+	if err := packageHelmChart(chartDir, chartName, packagePath); err != nil {
+		return nil, err
+	}
+	i.Logger.Info(fmt.Sprintf("Export helm chart %s success", packageName), map[string]string{"step": "export-helm-chart"})
+	return &export.Result{PackageName: packageName, PackagePath: packagePath}, nil
+}
+
+func packageHelmChart(chartDir, chartName, packagePath string) error {
+	file, err := os.Create(packagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	if err := util.TarDir(tw, chartDir, chartName); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+//helmChartFiles generates the files of the chart, keyed by the path in the chart directory.
+//Components become deployments or statefulsets, ports services, outer http ports ingresses,
+//config groups and config files configmaps and the other volumes persistent volume claims.
+//Images, pull secrets, replicas, resources, envs, config group items, volume sizes and ingress hosts can be set in values.yaml.
+func helmChartFiles(chartName string, ram *v1alpha1.KatoApplicationConfig) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	componentValues := make(map[string]interface{})
+	configGroupValues := make(map[string]interface{})
+	ingressHosts := make(map[string]string)
+	var ingressPorts []helmServicePort
+
+	names := make(map[string]string)
+	used := make(map[string]bool)
+	for idx, c := range ram.Components {
+		name := helmName(c.ServiceCname)
+		if name == "" || used[name] {
+			name = helmName(c.ServiceAlias)
+		}
+		if name == "" || used[name] {
+			name = fmt.Sprintf("component-%d", idx)
+		}
+		used[name] = true
+		names[c.ServiceKey] = name
+	}
+
+	groupsByComponent := make(map[string][]string)
+	var configGroups []string
+	for _, group := range ram.AppConfigGroups {
+		groupName := helmName(chartName + "-" + group.Name)
+		configGroups = append(configGroups, groupName)
+		configGroupValues[groupName] = group.ConfigItems
+		for _, key := range group.ComponentKeys {
+			if name, ok := names[key]; ok {
+				groupsByComponent[name] = append(groupsByComponent[name], groupName)
+			}
+		}
+	}
+
+	var images []string
+	var templates bytes.Buffer
+	for _, c := range ram.Components {
+		name := names[c.ServiceKey]
+		workload := helmWorkload{
+			Name:         name,
+			Stateful:     dbmodel.ServiceType(c.ExtendMethod).IsState(),
+			ConfigGroups: groupsByComponent[name],
+		}
+		for _, port := range c.Ports {
+			serviceName := helmName(port.K8sServiceName)
+			if serviceName == "" {
+				serviceName = fmt.Sprintf("%s-%d", name, port.ContainerPort)
+			}
+			protocol := strings.ToLower(port.Protocol)
+			sp := helmServicePort{
+				ServiceName:   serviceName,
+				ContainerPort: port.ContainerPort,
+				Protocol:      kubeProtocol(protocol),
+				HTTP:          protocol == "http" || protocol == "https",
+				Outer:         port.IsOuterService,
+			}
+			if sp.HTTP && sp.Outer {
+				ingressHosts[serviceName] = ""
+				ingressPorts = append(ingressPorts, sp)
+			}
+			workload.Ports = append(workload.Ports, sp)
+		}
+
+		env := make(map[string]string)
+		for _, e := range append(c.ServiceConnectInfoMapList, c.Envs...) {
+			env[e.AttrName] = fmt.Sprint(e.AttrValue)
+		}
+		persistence := make(map[string]interface{})
+		for idx, v := range c.ServiceVolumeMapList {
+			mount := helmMount{
+				Name:      fmt.Sprintf("volume-%d", idx),
+				Key:       strings.Trim(helmKeyInvalid.ReplaceAllString(v.VolumeName, "-"), "-"),
+				MountPath: v.VolumeMountPath,
+			}
+			switch dbmodel.VolumeType(v.VolumeType) {
+			case dbmodel.ConfigFileVolumeType:
+				files[path.Join("files", name, mount.Key)] = []byte(v.FileContent)
+				workload.ConfigFiles = append(workload.ConfigFiles, mount)
+			case dbmodel.MemoryFSVolumeType:
+				mount.Memory = true
+				workload.Volumes = append(workload.Volumes, mount)
+			default:
+				size := v.VolumeCapacity
+				if size <= 0 {
+					size = 1
+				}
+				persistence[mount.Key] = map[string]interface{}{"size": fmt.Sprintf("%dGi", size), "storageClass": ""}
+				workload.Volumes = append(workload.Volumes, mount)
+			}
+		}
+
+		image := c.ShareImage
+		if image == "" {
+			image = c.Image
+		}
+		images = append(images, image)
+		replicas := c.ExtendMethodRule.MinNode
+		if replicas <= 0 {
+			replicas = 1
+		}
+		resources := map[string]interface{}{}
+		if c.Memory > 0 {
+			resources["limits"] = map[string]interface{}{"memory": fmt.Sprintf("%dMi", c.Memory)}
+			resources["requests"] = map[string]interface{}{"memory": fmt.Sprintf("%dMi", c.Memory)}
+		}
+		if c.CPU > 0 {
+			limits, _ := resources["limits"].(map[string]interface{})
+			if limits == nil {
+				limits = map[string]interface{}{}
+				resources["limits"] = limits
+			}
+			limits["cpu"] = fmt.Sprintf("%dm", c.CPU)
+		}
+		componentValues[name] = map[string]interface{}{
+			"image":       image,
+			"replicas":    replicas,
+			"resources":   resources,
+			"env":         env,
+			"persistence": persistence,
+		}
+
+		if err := helmWorkloadTemplate.Execute(&templates, workload); err != nil {
+			return nil, err
+		}
+		files[path.Join("templates", name+".yaml")] = append([]byte(nil), templates.Bytes()...)
+		templates.Reset()
+	}
+
+	if len(configGroups) > 0 {
+		sort.Strings(configGroups)
+		if err := helmConfigGroupTemplate.Execute(&templates, configGroups); err != nil {
+			return nil, err
+		}
+		files["templates/configgroups.yaml"] = append([]byte(nil), templates.Bytes()...)
+		templates.Reset()
+	}
+	if len(ingressPorts) > 0 {
+		if err := helmIngressTemplate.Execute(&templates, ingressPorts); err != nil {
+			return nil, err
+		}
+		files["templates/ingress.yaml"] = append([]byte(nil), templates.Bytes()...)
+		templates.Reset()
+	}
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"imagePullSecrets": []interface{}{},
+		"components":       componentValues,
+		"configGroups":     configGroupValues,
+		"ingress": map[string]interface{}{
+			"enabled":   len(ingressPorts) > 0,
+			"className": "",
+			"hosts":     ingressHosts,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["values.yaml"] = values
+	chart, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        chartName,
+		"description": fmt.Sprintf("Helm chart of the Kato application %s", ram.AppName),
+		"type":        "application",
+		"version":     helmChartSemVersion(ram.AppVersion),
+		"appVersion":  ram.AppVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["Chart.yaml"] = chart
+	if err := helmReadmeTemplate.Execute(&templates, map[string]interface{}{"Name": ram.AppName, "Images": images}); err != nil {
+		return nil, err
+	}
+	files["README.md"] = append([]byte(nil), templates.Bytes()...)
+	return files, nil
+}
+
+//the chart templates are generated with [[ ]] so that the helm actions {{ }} are kept as they are
+func newHelmTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Delims("[[", "]]").Funcs(template.FuncMap{
+		"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+	}).Parse(text))
+}
+
+var helmWorkloadTemplate = newHelmTemplate("workload", `{{- $c := index .Values.components "[[ .Name ]]" }}
+apiVersion: apps/v1
+kind: [[ if .Stateful ]]StatefulSet[[ else ]]Deployment[[ end ]]
+metadata:
+  name: [[ .Name ]]
+  labels:
+    app.kubernetes.io/name: [[ .Name ]]
+    app.kubernetes.io/instance: {{ .Release.Name }}
+spec:
+  replicas: {{ $c.replicas }}
+[[- if .Stateful ]]
+  serviceName: [[ .Name ]]
+[[- end ]]
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: [[ .Name ]]
+      app.kubernetes.io/instance: {{ .Release.Name }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: [[ .Name ]]
+        app.kubernetes.io/instance: {{ .Release.Name }}
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      containers:
+        - name: [[ .Name ]]
+          image: {{ $c.image | quote }}
+[[- if .Ports ]]
+          ports:
+[[- range .Ports ]]
+            - containerPort: [[ .ContainerPort ]]
+              protocol: [[ .Protocol ]]
+[[- end ]]
+[[- end ]]
+          env:
+            {{- range $k, $v := $c.env }}
+            - name: {{ $k | quote }}
+              value: {{ $v | quote }}
+            {{- end }}
+[[- if .ConfigGroups ]]
+          envFrom:
+[[- range .ConfigGroups ]]
+            - configMapRef:
+                name: [[ . ]]
+[[- end ]]
+[[- end ]]
+          resources:
+            {{- toYaml $c.resources | nindent 12 }}
+[[- if or .ConfigFiles .Volumes ]]
+          volumeMounts:
+[[- range .ConfigFiles ]]
+            - name: config-files
+              mountPath: [[ quote .MountPath ]]
+              subPath: [[ .Key ]]
+[[- end ]]
+[[- range .Volumes ]]
+            - name: [[ .Name ]]
+              mountPath: [[ quote .MountPath ]]
+[[- end ]]
+[[- end ]]
+[[- $name := .Name ]]
+[[- $stateful := .Stateful ]]
+      volumes:
+[[- if .ConfigFiles ]]
+        - name: config-files
+          configMap:
+            name: [[ .Name ]]-files
+[[- end ]]
+[[- range .Volumes ]]
+[[- if .Memory ]]
+        - name: [[ .Name ]]
+          emptyDir:
+            medium: Memory
+[[- else if not $stateful ]]
+        - name: [[ .Name ]]
+          persistentVolumeClaim:
+            claimName: [[ $name ]]-[[ .Key ]]
+[[- end ]]
+[[- end ]]
+[[- if .Stateful ]]
+  volumeClaimTemplates:
+[[- range .Volumes ]]
+[[- if not .Memory ]]
+    - metadata:
+        name: [[ .Name ]]
+      spec:
+        accessModes: ["ReadWriteOnce"]
+        {{- with (index $c.persistence "[[ .Key ]]").storageClass }}
+        storageClassName: {{ . }}
+        {{- end }}
+        resources:
+          requests:
+            storage: {{ (index $c.persistence "[[ .Key ]]").size }}
+[[- end ]]
+[[- end ]]
+[[- else ]]
+[[- range .Volumes ]]
+[[- if not .Memory ]]
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: [[ $name ]]-[[ .Key ]]
+spec:
+  accessModes: ["ReadWriteOnce"]
+  {{- with (index $c.persistence "[[ .Key ]]").storageClass }}
+  storageClassName: {{ . }}
+  {{- end }}
+  resources:
+    requests:
+      storage: {{ (index $c.persistence "[[ .Key ]]").size }}
+[[- end ]]
+[[- end ]]
+[[- end ]]
+[[- if .ConfigFiles ]]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [[ .Name ]]-files
+data:
+[[- range .ConfigFiles ]]
+  [[ .Key ]]: {{ .Files.Get "files/[[ $name ]]/[[ .Key ]]" | quote }}
+[[- end ]]
+[[- end ]]
+[[- range .Ports ]]
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: [[ .ServiceName ]]
+spec:
+  selector:
+    app.kubernetes.io/name: [[ $name ]]
+    app.kubernetes.io/instance: {{ $.Release.Name }}
+  ports:
+    - port: [[ .ContainerPort ]]
+      targetPort: [[ .ContainerPort ]]
+      protocol: [[ .Protocol ]]
+[[- end ]]
+`)
+
+var helmConfigGroupTemplate = newHelmTemplate("configgroups", `[[- range . ]]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [[ . ]]
+data:
+  {{- range $k, $v := index .Values.configGroups "[[ . ]]" }}
+  {{ $k }}: {{ $v | quote }}
+  {{- end }}
+[[- end ]]
+`)
+
+var helmIngressTemplate = newHelmTemplate("ingress", `{{- if .Values.ingress.enabled }}
+[[- range . ]]
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: [[ .ServiceName ]]
+spec:
+  {{- with $.Values.ingress.className }}
+  ingressClassName: {{ . }}
+  {{- end }}
+  rules:
+    - http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: [[ .ServiceName ]]
+                port:
+                  number: [[ .ContainerPort ]]
+      {{- with index $.Values.ingress.hosts "[[ .ServiceName ]]" }}
+      host: {{ . | quote }}
+      {{- end }}
+[[- end ]]
+{{- end }}
+`)
+
+var helmReadmeTemplate = newHelmTemplate("readme", `# [[ .Name ]]
+
+The chart is exported from the Kato application [[ .Name ]] to run it on a kubernetes cluster without Kato.
+
+## Images
+
+The images are not contained in the chart, they must be pulled by the cluster from the registry they were shared to.
+Set imagePullSecrets in values.yaml if the registry requires authentication, or push the images
+to another registry and set components.<name>.image in values.yaml.
+
+[[ range .Images ]]- [[ . ]]
+[[ end ]]`)
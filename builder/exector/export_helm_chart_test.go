@@ -0,0 +1,187 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package exector
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/gridworkz/kato-oam/pkg/ram/v1alpha1"
+	yamlv2 "gopkg.in/yaml.v2"
+	"sigs.k8s.io/yaml"
+)
+
+func TestHelmChartSemVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"1.2.3":      "1.2.3",
+		"v2.0.1-rc1": "2.0.1-rc1",
+		"1.2":        "1.2.0",
+		"latest":     "0.1.0",
+		"":           "0.1.0",
+	} {
+		if got := helmChartSemVersion(version); got != want {
+			t.Errorf("%q: want %s, got %s", version, want, got)
+		}
+	}
+}
+
+func TestHelmChartFiles(t *testing.T) {
+	ram := &v1alpha1.KatoApplicationConfig{
+		AppName:    "My Shop",
+		AppVersion: "1.0",
+		Components: []*v1alpha1.Component{
+			{
+				ServiceKey:   "k1",
+				ServiceCname: "Web",
+				ExtendMethod: "stateless_multiple",
+				ShareImage:   "hub/web:1",
+				Memory:       512,
+				Ports:        []v1alpha1.ComponentPort{{ContainerPort: 80, Protocol: "http", IsOuterService: true}},
+				Envs:         []v1alpha1.ComponentEnv{{AttrName: "MODE", AttrValue: "prod"}},
+				ServiceVolumeMapList: []v1alpha1.ComponentVolume{
+					{VolumeName: "nginx.conf", VolumeType: "config-file", VolumeMountPath: "/etc/nginx/nginx.conf", FileContent: "{{ raw }}"},
+					{VolumeName: "data", VolumeType: "share-file", VolumeMountPath: "/data", VolumeCapacity: 5},
+				},
+			},
+			{
+				ServiceKey:   "k2",
+				ServiceCname: "db",
+				ExtendMethod: "state_singleton",
+				Image:        "mysql:5.7",
+				Ports:        []v1alpha1.ComponentPort{{ContainerPort: 3306, Protocol: "mysql", K8sServiceName: "mysql"}},
+				ServiceVolumeMapList: []v1alpha1.ComponentVolume{
+					{VolumeName: "data", VolumeType: "share-file", VolumeMountPath: "/var/lib/mysql"},
+				},
+			},
+		},
+		AppConfigGroups: []*v1alpha1.AppConfigGroup{{Name: "common", ConfigItems: map[string]string{"TZ": "UTC"}, ComponentKeys: []string{"k1"}}},
+	}
+	files, err := helmChartFiles("my-shop", ram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Chart.yaml", "values.yaml", "README.md", "templates/web.yaml", "templates/db.yaml",
+		"templates/configgroups.yaml", "templates/ingress.yaml", "files/web/nginx.conf"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("want file %s", name)
+		}
+	}
+	if string(files["files/web/nginx.conf"]) != "{{ raw }}" {
+		t.Errorf("config file content should be kept out of the templates")
+	}
+	if readme := string(files["README.md"]); !strings.Contains(readme, "- hub/web:1") || !strings.Contains(readme, "- mysql:5.7") {
+		t.Errorf("want the images listed in the readme:\n%s", readme)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(files["values.yaml"], &values); err != nil {
+		t.Fatal(err)
+	}
+	values["imagePullSecrets"] = []interface{}{map[string]interface{}{"name": "regcred"}}
+	values["ingress"].(map[string]interface{})["hosts"].(map[string]interface{})["web-80"] = "shop.example.com"
+
+	manifests := make(map[string]map[interface{}]interface{})
+	for _, name := range []string{"templates/web.yaml", "templates/db.yaml", "templates/configgroups.yaml", "templates/ingress.yaml"} {
+		out := renderHelmTemplate(t, name, files, values)
+		for _, doc := range strings.Split(out, "\n---") {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			var manifest map[interface{}]interface{}
+			if err := yamlv2.Unmarshal([]byte(doc), &manifest); err != nil {
+				t.Fatalf("%s renders invalid yaml: %v\n%s", name, err, doc)
+			}
+			metadata, _ := manifest["metadata"].(map[interface{}]interface{})
+			manifests[fmt.Sprintf("%v/%v", manifest["kind"], metadata["name"])] = manifest
+		}
+	}
+	for _, name := range []string{"Deployment/web", "StatefulSet/db", "PersistentVolumeClaim/web-data", "ConfigMap/web-files",
+		"ConfigMap/my-shop-common", "Service/web-80", "Service/mysql", "Ingress/web-80"} {
+		if _, ok := manifests[name]; !ok {
+			t.Errorf("want manifest %s", name)
+		}
+	}
+	web := manifests["Deployment/web"]
+	podSpec := web["spec"].(map[interface{}]interface{})["template"].(map[interface{}]interface{})["spec"].(map[interface{}]interface{})
+	container := podSpec["containers"].([]interface{})[0].(map[interface{}]interface{})
+	if container["image"] != "hub/web:1" {
+		t.Errorf("want image hub/web:1, got %v", container["image"])
+	}
+	if env := container["env"].([]interface{})[0].(map[interface{}]interface{}); env["name"] != "MODE" || env["value"] != "prod" {
+		t.Errorf("unexpected env %v", env)
+	}
+	if limits := container["resources"].(map[interface{}]interface{})["limits"].(map[interface{}]interface{}); limits["memory"] != "512Mi" {
+		t.Errorf("unexpected limits %v", limits)
+	}
+	if secret := podSpec["imagePullSecrets"].([]interface{})[0].(map[interface{}]interface{}); secret["name"] != "regcred" {
+		t.Errorf("unexpected image pull secret %v", secret)
+	}
+	claim := manifests["PersistentVolumeClaim/web-data"]["spec"].(map[interface{}]interface{})["resources"].(map[interface{}]interface{})
+	if storage := claim["requests"].(map[interface{}]interface{})["storage"]; storage != "5Gi" {
+		t.Errorf("want storage 5Gi, got %v", storage)
+	}
+	if data := manifests["ConfigMap/web-files"]["data"].(map[interface{}]interface{}); data["nginx.conf"] != "{{ raw }}" {
+		t.Errorf("unexpected config file %v", data)
+	}
+	if data := manifests["ConfigMap/my-shop-common"]["data"].(map[interface{}]interface{}); data["TZ"] != "UTC" {
+		t.Errorf("unexpected config group %v", data)
+	}
+	rule := manifests["Ingress/web-80"]["spec"].(map[interface{}]interface{})["rules"].([]interface{})[0].(map[interface{}]interface{})
+	if rule["host"] != "shop.example.com" {
+		t.Errorf("want ingress host shop.example.com, got %v", rule["host"])
+	}
+}
+
+type helmChartTestFiles map[string][]byte
+
+func (f helmChartTestFiles) Get(name string) string {
+	return string(f[name])
+}
+
+//renderHelmTemplate renders the chart template the way helm does, with stubs of the sprig functions the chart uses
+func renderHelmTemplate(t *testing.T, name string, files map[string][]byte, values map[string]interface{}) string {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"quote": func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		"toYaml": func(v interface{}) string {
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strings.TrimSuffix(string(out), "\n")
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.Replace(s, "\n", "\n"+pad, -1)
+		},
+	}).Parse(string(files[name]))
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, map[string]interface{}{
+		"Values":  values,
+		"Release": map[string]interface{}{"Name": "shop"},
+		"Files":   helmChartTestFiles(files),
+	}); err != nil {
+		t.Fatalf("render %s: %v", name, err)
+	}
+	return out.String()
+}
@@ -0,0 +1,623 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package exector
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/gridworkz/kato/builder/cloudos"
+	"github.com/gridworkz/kato/cmd/builder/option"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/event"
+	"github.com/gridworkz/kato/util"
+)
+
+const (
+	backupVolume       = "backup_volume"
+	restoreVolume      = "restore_volume"
+	deleteVolumeBackup = "delete_volume_backup"
+)
+
+func init() {
+	RegisterWorker(backupVolume, newVolumeBackupCreater(backupVolume))
+	RegisterWorker(restoreVolume, newVolumeBackupCreater(restoreVolume))
+	RegisterWorker(deleteVolumeBackup, newVolumeBackupCreater(deleteVolumeBackup))
+}
+
+//VolumeBackup backups the data of a component volume to the object storage as a tar.gz archive,
+//restores a backup into a volume or deletes a backup.
+//The data of each pod of a state component are in a dir named by the pod in the archive.
+type VolumeBackup struct {
+	BackupID string `json:"backup_id"`
+	// ServiceID and VolumeName are the volume the backup is restored into
+	ServiceID  string `json:"service_id"`
+	VolumeName string `json:"volume_name"`
+	EventID    string `json:"event_id"`
+	Logger     event.Logger
+
+	action     string
+	kubeClient kubernetes.Interface
+	cfg        option.Config
+	backup     *dbmodel.TenantServiceVolumeBackup
+}
+
+func newVolumeBackupCreater(action string) func(in []byte, m *exectorManager) (TaskWorker, error) {
+	return func(in []byte, m *exectorManager) (TaskWorker, error) {
+		eventID := gjson.GetBytes(in, "event_id").String()
+		v := &VolumeBackup{
+			Logger:     event.GetManager().GetLogger(eventID),
+			action:     action,
+			kubeClient: m.KubeClient,
+			cfg:        m.cfg,
+		}
+		if err := ffjson.Unmarshal(in, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+//Run
+func (v *VolumeBackup) Run(timeout time.Duration) error {
+	backup, err := db.GetManager().TenantServiceVolumeBackupDao().GetByBackupID(v.BackupID)
+	if err != nil {
+		return fmt.Errorf("get volume backup %s: %v", v.BackupID, err)
+	}
+	v.backup = backup
+	cloudoser, err := v.newCloudOS()
+	if err != nil {
+		return err
+	}
+	switch v.action {
+	case backupVolume:
+		if backup.Status != "starting" {
+			logrus.Infof("volume backup %s is %s, skip it", backup.BackupID, backup.Status)
+			return nil
+		}
+		return v.runBackup(cloudoser)
+	case restoreVolume:
+		return v.runRestore(cloudoser)
+	default:
+		return v.deleteBackup(cloudoser, backup)
+	}
+}
+
+func (v *VolumeBackup) newCloudOS() (cloudos.CloudOSer, error) {
+	if v.cfg.BackupProvider == "" {
+		return nil, fmt.Errorf("the object storage of volume backups is not configured")
+	}
+	provider, err := cloudos.Str2S3Provider(v.cfg.BackupProvider)
+	if err != nil {
+		return nil, err
+	}
+	return cloudos.New(&cloudos.Config{
+		ProviderType: provider,
+		Endpoint:     v.cfg.BackupEndpoint,
+		AccessKey:    v.cfg.BackupAccessKey,
+		SecretKey:    v.cfg.BackupSecretKey,
+		UseSSL:       v.cfg.BackupUseSSL,
+		BucketName:   v.cfg.BackupBucketName,
+	})
+}
+
+//archivePath returns a temporary file in the share storage, the archives can be larger than the local disk
+func archivePath(name string) string {
+	_, sharePath := GetVolumeDir()
+	return path.Join(sharePath, "volume-backups", name+".tar.gz")
+}
+
+//workloadName returns the statefulset name of a state component, empty for a stateless one
+func workloadName(service *dbmodel.TenantServices) string {
+	if !service.IsState() {
+		return ""
+	}
+	if service.ServiceName != "" {
+		return service.ServiceName
+	}
+	return service.ServiceAlias
+}
+
+func (v *VolumeBackup) runBackup(cloudoser cloudos.CloudOSer) error {
+	backup := v.backup
+	volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(backup.ServiceID, backup.VolumeName)
+	if err != nil {
+		return fmt.Errorf("get volume %s: %v", backup.VolumeName, err)
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(backup.ServiceID)
+	if err != nil {
+		return fmt.Errorf("get component %s: %v", backup.ServiceID, err)
+	}
+	backup.WorkloadName = workloadName(service)
+	v.Logger.Info(fmt.Sprintf("Start backup the data of volume %s", volume.VolumeName), map[string]string{"step": "backup-volume", "status": "starting"})
+
+	archive := archivePath(backup.BackupID)
+	if err := util.CheckAndCreateDir(path.Dir(archive)); err != nil {
+		return err
+	}
+	defer os.Remove(archive)
+	if err := v.writeArchive(archive, volume); err != nil {
+		return err
+	}
+	backup.Size = util.GetFileSize(archive)
+	backup.ObjectKey = fmt.Sprintf("volume-backups/%s/%s/%s.tar.gz", backup.ServiceID, backup.VolumeName, backup.BackupID)
+	v.Logger.Info(fmt.Sprintf("Upload the archive of %d bytes", backup.Size), map[string]string{"step": "backup-volume", "status": "running"})
+	if err := cloudoser.PutObject(backup.ObjectKey, archive); err != nil {
+		return fmt.Errorf("object key: %s; error putting object: %v", backup.ObjectKey, err)
+	}
+	backup.Status = "success"
+	if err := db.GetManager().TenantServiceVolumeBackupDao().UpdateModel(backup); err != nil {
+		return fmt.Errorf("update volume backup %s: %v", backup.BackupID, err)
+	}
+	v.pruneBackups(cloudoser)
+	v.Logger.Info(fmt.Sprintf("Backup the data of volume %s success", volume.VolumeName), event.GetLastLoggerOption())
+	return nil
+}
+
+func (v *VolumeBackup) writeArchive(archive string, volume *dbmodel.TenantServiceVolume) error {
+	file, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	switch volume.VolumeType {
+	case dbmodel.ShareFileVolumeType.String():
+		if _, err := os.Stat(volume.HostPath); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			v.Logger.Info("The volume has no data yet", map[string]string{"step": "backup-volume", "status": "running"})
+		} else if err := util.TarDir(tw, volume.HostPath, ""); err != nil {
+			return fmt.Errorf("archive %s: %v", volume.HostPath, err)
+		}
+	case dbmodel.LocalVolumeType.String():
+		if err := v.archiveLocalVolume(tw, volume); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("the data of %s volumes can not be backed up", volume.VolumeType)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+//localVolume is the data dir of a pod on a node
+type localVolume struct {
+	podName string
+	nodeIP  string
+	path    string
+}
+
+//listLocalVolumes lists the data dirs of the local volume, they are kept after the component is closed
+func (v *VolumeBackup) listLocalVolumes(serviceID, volumeName string) ([]localVolume, error) {
+	pvs, err := v.kubeClient.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("service_id=%s,volume_name=%s", serviceID, volumeName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list persistent volumes: %v", err)
+	}
+	var volumes []localVolume
+	seen := make(map[string]bool)
+	for _, pv := range pvs.Items {
+		if pv.Spec.HostPath == nil || pv.Spec.ClaimRef == nil || seen[pv.Spec.HostPath.Path] {
+			continue
+		}
+		hostname := pvHostname(&pv)
+		if hostname == "" {
+			continue
+		}
+		nodeIP, err := v.nodeIP(hostname)
+		if err != nil {
+			return nil, err
+		}
+		seen[pv.Spec.HostPath.Path] = true
+		volumes = append(volumes, localVolume{
+			podName: podNameOfClaim(pv.Spec.ClaimRef.Name),
+			nodeIP:  nodeIP,
+			path:    pv.Spec.HostPath.Path,
+		})
+	}
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("the local volume %s is not created on any node, start the component first", volumeName)
+	}
+	return volumes, nil
+}
+
+//podNameOfClaim returns the pod name of the volume claim of a statefulset, such as manual12-name-0
+func podNameOfClaim(claimName string) string {
+	names := strings.SplitN(claimName, "-", 2)
+	if len(names) == 2 {
+		return names[1]
+	}
+	return claimName
+}
+
+func pvHostname(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == "kubernetes.io/hostname" && len(expression.Values) > 0 {
+				return expression.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func (v *VolumeBackup) nodeIP(hostname string) (string, error) {
+	nodes, err := v.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: "kubernetes.io/hostname=" + hostname,
+	})
+	if err != nil {
+		return "", fmt.Errorf("list nodes: %v", err)
+	}
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				return address.Address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the ip of node %s is not found", hostname)
+}
+
+//archiveLocalVolume gets the data of each pod from the node agent and writes them in the dirs named by the pods
+func (v *VolumeBackup) archiveLocalVolume(tw *tar.Writer, volume *dbmodel.TenantServiceVolume) error {
+	volumes, err := v.listLocalVolumes(volume.ServiceID, volume.VolumeName)
+	if err != nil {
+		return err
+	}
+	for _, lv := range volumes {
+		v.Logger.Info(fmt.Sprintf("Archive the data of pod %s", lv.podName), map[string]string{"step": "backup-volume", "status": "running"})
+		if err := archiveFromNode(tw, lv); err != nil {
+			return fmt.Errorf("archive the data of pod %s: %v", lv.podName, err)
+		}
+	}
+	return nil
+}
+
+func archiveFromNode(tw *tar.Writer, lv localVolume) error {
+	res, err := http.Get(fmt.Sprintf("http://%s:6100/v2/localvolumes/archive?path=%s", lv.nodeIP, url.QueryEscape(lv.path)))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil
+	}
+	if res.StatusCode != 200 {
+		return fmt.Errorf("node %s returns code %d", lv.nodeIP, res.StatusCode)
+	}
+	gr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(lv.podName, header.Name)
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+//mapPodName maps the pod name of the workload from to the pod of the workload to with the same ordinal
+func mapPodName(name, from, to string) (string, bool) {
+	if !strings.HasPrefix(name, from+"-") {
+		return "", false
+	}
+	ordinal := strings.TrimPrefix(name, from+"-")
+	if _, err := strconv.Atoi(ordinal); err != nil {
+		return "", false
+	}
+	return to + "-" + ordinal, true
+}
+
+//podDirMapper maps the pod dirs of the archive of the workload from to the pod dirs of the workload to
+func podDirMapper(from, to string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		if from == to {
+			return name, true
+		}
+		names := strings.SplitN(name, "/", 2)
+		podName, ok := mapPodName(names[0], from, to)
+		if !ok {
+			return "", false
+		}
+		names[0] = podName
+		return strings.Join(names, "/"), true
+	}
+}
+
+func (v *VolumeBackup) runRestore(cloudoser cloudos.CloudOSer) error {
+	backup := v.backup
+	volume, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(v.ServiceID, v.VolumeName)
+	if err != nil {
+		return fmt.Errorf("get volume %s: %v", v.VolumeName, err)
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(v.ServiceID)
+	if err != nil {
+		return fmt.Errorf("get component %s: %v", v.ServiceID, err)
+	}
+	target := workloadName(service)
+	if (backup.WorkloadName == "") != (target == "") {
+		return fmt.Errorf("can not restore the backup of a %s component into a %s component", stateName(backup.WorkloadName), stateName(target))
+	}
+	v.Logger.Info(fmt.Sprintf("Start restore backup %s into volume %s", backup.BackupID, volume.VolumeName), map[string]string{"step": "restore-volume", "status": "starting"})
+
+	archive := archivePath(v.EventID)
+	if err := util.CheckAndCreateDir(path.Dir(archive)); err != nil {
+		return err
+	}
+	defer os.Remove(archive)
+	if err := cloudoser.GetObject(backup.ObjectKey, archive); err != nil {
+		return fmt.Errorf("object key: %s; error getting object: %v", backup.ObjectKey, err)
+	}
+	switch volume.VolumeType {
+	case dbmodel.ShareFileVolumeType.String():
+		err = restoreShareVolume(archive, volume.HostPath, podDirMapper(backup.WorkloadName, target))
+	case dbmodel.LocalVolumeType.String():
+		err = v.restoreLocalVolume(archive, volume, backup.WorkloadName, target)
+	default:
+		err = fmt.Errorf("the data of %s volumes can not be restored", volume.VolumeType)
+	}
+	if err != nil {
+		return err
+	}
+	v.Logger.Info(fmt.Sprintf("Restore backup %s into volume %s success", backup.BackupID, volume.VolumeName), event.GetLastLoggerOption())
+	return nil
+}
+
+func stateName(workloadName string) string {
+	if workloadName == "" {
+		return "stateless"
+	}
+	return "state"
+}
+
+func openArchive(archive string) (*tar.Reader, func(), error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return tar.NewReader(gr), func() {
+		gr.Close()
+		file.Close()
+	}, nil
+}
+
+//restoreShareVolume replaces the data of the volume only if the archive is extracted completely
+func restoreShareVolume(archive, hostPath string, mapName func(string) (string, bool)) error {
+	if hostPath == "" {
+		return fmt.Errorf("the host path of the volume is empty")
+	}
+	tr, closeArchive, err := openArchive(archive)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+	restorePath := hostPath + ".restoring"
+	if err := os.RemoveAll(restorePath); err != nil {
+		return err
+	}
+	defer os.RemoveAll(restorePath)
+	if err := util.UnTarDir(tr, restorePath, mapName); err != nil {
+		return fmt.Errorf("extract the archive: %v", err)
+	}
+	return util.ReplaceDir(hostPath, restorePath)
+}
+
+//restoreLocalVolume sends the data of each pod to the node agent of the pod
+func (v *VolumeBackup) restoreLocalVolume(archive string, volume *dbmodel.TenantServiceVolume, from, to string) error {
+	volumes, err := v.listLocalVolumes(volume.ServiceID, volume.VolumeName)
+	if err != nil {
+		return err
+	}
+	for _, lv := range volumes {
+		source, ok := mapPodName(lv.podName, to, from)
+		if !ok {
+			continue
+		}
+		v.Logger.Info(fmt.Sprintf("Restore the data of pod %s", lv.podName), map[string]string{"step": "restore-volume", "status": "running"})
+		if err := restoreToNode(archive, source, lv); err != nil {
+			return fmt.Errorf("restore the data of pod %s: %v", lv.podName, err)
+		}
+	}
+	return nil
+}
+
+//restoreToNode streams the files in the dir podName of the archive to the node agent
+func restoreToNode(archive, podName string, lv localVolume) error {
+	tr, closeArchive, err := openArchive(archive)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(filterArchive(tr, podName+"/", pw))
+	}()
+	defer pr.Close()
+	res, err := http.Post(fmt.Sprintf("http://%s:6100/v2/localvolumes/restore?path=%s", lv.nodeIP, url.QueryEscape(lv.path)), "application/gzip", pr)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("node %s returns code %d", lv.nodeIP, res.StatusCode)
+	}
+	return nil
+}
+
+//filterArchive writes the files with the prefix in tr to w as a tar.gz archive, the prefix is trimmed
+func filterArchive(tr *tar.Reader, prefix string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(header.Name, prefix) || header.Name == prefix {
+			continue
+		}
+		header.Name = strings.TrimPrefix(header.Name, prefix)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+//expiredBackups returns the backups out of the retention, the backups are sorted from the latest.
+//The running backups are never expired.
+func expiredBackups(backups []*dbmodel.TenantServiceVolumeBackup, retain, retainDays int, now time.Time) []*dbmodel.TenantServiceVolumeBackup {
+	var expired []*dbmodel.TenantServiceVolumeBackup
+	var kept int
+	for _, backup := range backups {
+		if backup.Status == "starting" {
+			continue
+		}
+		if (retain > 0 && kept >= retain) || (retainDays > 0 && backup.CreatedAt.Before(now.AddDate(0, 0, -retainDays))) {
+			expired = append(expired, backup)
+			continue
+		}
+		kept++
+	}
+	return expired
+}
+
+//pruneBackups deletes the backups of the volume out of the retention of its backup policy
+func (v *VolumeBackup) pruneBackups(cloudoser cloudos.CloudOSer) {
+	policy, err := db.GetManager().TenantServiceVolumeBackupPolicyDao().GetByServiceVolume(v.backup.ServiceID, v.backup.VolumeName)
+	if err != nil {
+		// no policy, the backups are kept
+		return
+	}
+	backups, err := db.GetManager().TenantServiceVolumeBackupDao().ListByServiceVolume(v.backup.ServiceID, v.backup.VolumeName)
+	if err != nil {
+		logrus.Warningf("list backups of volume %s: %v", v.backup.VolumeName, err)
+		return
+	}
+	for _, backup := range expiredBackups(backups, policy.Retain, policy.RetainDays, time.Now()) {
+		if err := v.deleteBackup(cloudoser, backup); err != nil {
+			logrus.Warningf("delete expired volume backup %s: %v", backup.BackupID, err)
+			continue
+		}
+		logrus.Infof("expired volume backup %s is deleted", backup.BackupID)
+	}
+}
+
+func (v *VolumeBackup) deleteBackup(cloudoser cloudos.CloudOSer, backup *dbmodel.TenantServiceVolumeBackup) error {
+	if backup.ObjectKey != "" {
+		if err := cloudoser.DeleteObject(backup.ObjectKey); err != nil {
+			return fmt.Errorf("object key: %s; error deleting object: %v", backup.ObjectKey, err)
+		}
+	}
+	if err := db.GetManager().TenantServiceVolumeBackupDao().DeleteByBackupID(backup.BackupID); err != nil {
+		return err
+	}
+	if v.action == deleteVolumeBackup {
+		v.Logger.Info(fmt.Sprintf("Delete volume backup %s success", backup.BackupID), event.GetLastLoggerOption())
+	}
+	return nil
+}
+
+//Stop
+func (v *VolumeBackup) Stop() error {
+	return nil
+}
+
+//Name return worker name
+func (v *VolumeBackup) Name() string {
+	return v.action
+}
+
+//GetLogger
+func (v *VolumeBackup) GetLogger() event.Logger {
+	return v.Logger
+}
+
+//ErrorCallBack if run error will callback
+func (v *VolumeBackup) ErrorCallBack(err error) {
+	if err == nil {
+		return
+	}
+	logrus.Errorf("%s %s failure %s", v.action, v.BackupID, err)
+	v.Logger.Error(err.Error(), event.GetCallbackLoggerOption())
+	if v.action != backupVolume || v.backup == nil {
+		return
+	}
+	v.backup.Status = "failure"
+	v.backup.Message = err.Error()
+	if err := db.GetManager().TenantServiceVolumeBackupDao().UpdateModel(v.backup); err != nil {
+		logrus.Errorf("update volume backup %s: %v", v.BackupID, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package exector
+
+import (
+	"testing"
+	"time"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
+func TestPodDirMapper(t *testing.T) {
+	tests := []struct {
+		from, to, name, want string
+		ok                   bool
+	}{
+		{from: "", to: "", name: "data/a.txt", want: "data/a.txt", ok: true},
+		{from: "mysql", to: "mysql", name: "mysql-0/a.txt", want: "mysql-0/a.txt", ok: true},
+		{from: "mysql", to: "mysql-clone", name: "mysql-1/a.txt", want: "mysql-clone-1/a.txt", ok: true},
+		{from: "mysql", to: "mysql-clone", name: "mysql-1/", want: "mysql-clone-1/", ok: true},
+		{from: "mysql", to: "mysql-clone", name: "mysql-x/a.txt"},
+		{from: "mysql", to: "mysql-clone", name: "other-0/a.txt"},
+	}
+	for _, tc := range tests {
+		got, ok := podDirMapper(tc.from, tc.to)(tc.name)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("map %s from %s to %s: expected %s %v, got %s %v", tc.name, tc.from, tc.to, tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestPodNameOfClaim(t *testing.T) {
+	if name := podNameOfClaim("manual12-mysql-clone-0"); name != "mysql-clone-0" {
+		t.Errorf("expected mysql-clone-0, got %s", name)
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	now := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+	newBackup := func(id, status string, days int) *dbmodel.TenantServiceVolumeBackup {
+		backup := &dbmodel.TenantServiceVolumeBackup{BackupID: id, Status: status}
+		backup.CreatedAt = now.AddDate(0, 0, -days)
+		return backup
+	}
+	backups := []*dbmodel.TenantServiceVolumeBackup{
+		newBackup("running", "starting", 0),
+		newBackup("b1", "success", 1),
+		newBackup("b2", "failure", 2),
+		newBackup("b3", "success", 3),
+		newBackup("b4", "success", 10),
+	}
+	tests := []struct {
+		retain, retainDays int
+		want               []string
+	}{
+		{want: nil},
+		{retain: 2, want: []string{"b3", "b4"}},
+		{retainDays: 7, want: []string{"b4"}},
+		{retain: 3, retainDays: 2, want: []string{"b3", "b4"}},
+	}
+	for _, tc := range tests {
+		var got []string
+		for _, backup := range expiredBackups(backups, tc.retain, tc.retainDays, now) {
+			got = append(got, backup.BackupID)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("retain %d, retain days %d: expected %v, got %v", tc.retain, tc.retainDays, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("retain %d, retain days %d: expected %v, got %v", tc.retain, tc.retainDays, tc.want, got)
+				break
+			}
+		}
+	}
+}
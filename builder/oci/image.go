@@ -0,0 +1,154 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package oci
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Image an image assembled in a layout, from scratch or from a base image
+type Image struct {
+	layout *Layout
+	Config ImageConfig
+	Layers []Descriptor
+	// the dirs in the layers, which are not created again by the new layers
+	dirs map[string]struct{}
+}
+
+// NewImage creates an empty image in the layout
+func NewImage(layout *Layout) *Image {
+	return &Image{
+		layout: layout,
+		Config: ImageConfig{
+			Architecture: runtime.GOARCH,
+			OS:           "linux",
+			RootFS:       RootFS{Type: "layers"},
+		},
+	}
+}
+
+// AddLayer adds the files as a new layer
+func (img *Image) AddLayer(files []LayerFile, createdBy string) error {
+	if img.dirs == nil {
+		dirs := make(map[string]struct{})
+		for _, layer := range img.Layers {
+			f, err := img.layout.OpenBlob(layer.Digest)
+			if err != nil {
+				return err
+			}
+			err = layerDirs(f, dirs)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		img.dirs = dirs
+	}
+	desc, diffID, err := img.layout.WriteLayer(files, img.dirs)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	img.Layers = append(img.Layers, desc)
+	img.Config.RootFS.DiffIDs = append(img.Config.RootFS.DiffIDs, diffID)
+	img.Config.History = append(img.Config.History, History{Created: &now, CreatedBy: createdBy})
+	return nil
+}
+
+// AddHistory records a change of the config, such as ENV, which does not add a layer
+func (img *Image) AddHistory(createdBy string) {
+	now := time.Now().UTC()
+	img.Config.History = append(img.Config.History, History{Created: &now, CreatedBy: createdBy, EmptyLayer: true})
+}
+
+// SetEnv sets the environment variable, it replaces the one of the same name
+func (img *Image) SetEnv(name, value string) {
+	env := name + "="
+	for i, e := range img.Config.Config.Env {
+		if strings.HasPrefix(e, env) {
+			img.Config.Config.Env[i] = env + value
+			return
+		}
+	}
+	img.Config.Config.Env = append(img.Config.Config.Env, env+value)
+}
+
+// Save writes the config and the manifest of the image into the layout, and tags it with the ref name
+func (img *Image) Save(ref string) (Descriptor, error) {
+	now := time.Now().UTC()
+	img.Config.Created = &now
+	configDesc, _, err := img.layout.WriteJSON(MediaTypeImageConfig, img.Config)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        configDesc,
+	}
+	for _, layer := range img.Layers {
+		layer.MediaType = ociLayerMediaType(layer.MediaType)
+		manifest.Layers = append(manifest.Layers, layer)
+	}
+	desc, _, err := img.layout.WriteJSON(MediaTypeImageManifest, manifest)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return desc, img.layout.Tag(desc, ref)
+}
+
+// ociLayerMediaType converts the media types of the docker layers, their formats are the same
+func ociLayerMediaType(mediaType string) string {
+	switch mediaType {
+	case "application/vnd.docker.image.rootfs.diff.tar.gzip":
+		return MediaTypeImageLayerGzip
+	case "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip":
+		return "application/vnd.oci.image.layer.nondistributable.v1.tar+gzip"
+	}
+	return mediaType
+}
+
+// loadImage loads the image of the manifest in the layout
+func loadImage(layout *Layout, manifest *Manifest) (*Image, error) {
+	body, err := layout.ReadBlob(manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	img := &Image{layout: layout, Layers: manifest.Layers}
+	if err := json.Unmarshal(body, &img.Config); err != nil {
+		return nil, err
+	}
+	if img.Config.RootFS.Type == "" {
+		img.Config.RootFS.Type = "layers"
+	}
+	return img, nil
+}
+
+// manifestBlobs returns the digests of the config and the layers
+func manifestBlobs(manifest *Manifest) []digest.Digest {
+	blobs := []digest.Digest{manifest.Config.Digest}
+	for _, layer := range manifest.Layers {
+		blobs = append(blobs, layer.Digest)
+	}
+	return blobs
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+func TestImageSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "slug.tgz"), []byte("slug"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	layout, err := OpenLayout(filepath.Join(dir, "layout"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := NewImage(layout)
+	if err := img.AddLayer([]LayerFile{{Source: src, Path: "/tmp/slug", UID: 200, GID: 200}}, "COPY slug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := img.AddLayer([]LayerFile{{Source: src, Path: "/tmp/other"}}, "COPY other"); err != nil {
+		t.Fatal(err)
+	}
+	img.SetEnv("VERSION", "1")
+	img.SetEnv("VERSION", "2")
+	if _, err := img.Save("app:v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := layout.Resolve("app:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := layout.ReadBlob(desc.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Layers) != 2 {
+		t.Fatalf("want 2 layers, got %d", len(manifest.Layers))
+	}
+	body, err = layout.ReadBlob(manifest.Config.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config ImageConfig
+	if err := json.Unmarshal(body, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Config.Env) != 1 || config.Config.Env[0] != "VERSION=2" {
+		t.Errorf("unexpected env %v", config.Config.Env)
+	}
+
+	wantNames := [][]string{
+		{"tmp/", "tmp/slug/", "tmp/slug/slug.tgz"},
+		{"tmp/other/", "tmp/other/slug.tgz"},
+	}
+	for i, layer := range manifest.Layers {
+		names, diffID := readLayer(t, layout, layer.Digest)
+		if diffID != config.RootFS.DiffIDs[i] {
+			t.Errorf("layer %d: diff id %s, want %s", i, config.RootFS.DiffIDs[i], diffID)
+		}
+		if len(names) != len(wantNames[i]) {
+			t.Errorf("layer %d: entries %v, want %v", i, names, wantNames[i])
+			continue
+		}
+		for j := range names {
+			if names[j] != wantNames[i][j] {
+				t.Errorf("layer %d: entries %v, want %v", i, names, wantNames[i])
+				break
+			}
+		}
+	}
+}
+
+func readLayer(t *testing.T, layout *Layout, dig digest.Digest) ([]string, digest.Digest) {
+	f, err := layout.OpenBlob(dig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digester := digest.Canonical.Digester()
+	tr := tar.NewReader(io.TeeReader(gz, digester.Hash()))
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	// the padding after the end of the archive is part of the diff id
+	io.Copy(digester.Hash(), gz)
+	return names, digester.Digest()
+}
+
+func TestResolveUnder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a.txt", filepath.Join(dir, "inside")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/", filepath.Join(dir, "root")); err != nil {
+		t.Fatal(err)
+	}
+	if real, err := ResolveUnder(dir, filepath.Join(dir, "inside")); err != nil || filepath.Base(real) != "a.txt" {
+		t.Errorf("want a.txt in the context, got %s %v", real, err)
+	}
+	if _, err := ResolveUnder(dir, filepath.Join(dir, "root", "etc")); err == nil {
+		t.Errorf("want error for a link out of the context")
+	}
+
+	layout, err := OpenLayout(filepath.Join(dir, "layout"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := layout.WriteLayer([]LayerFile{{Source: filepath.Join(dir, "root", "etc"), Path: "/etc", Root: dir}}, nil); err == nil {
+		t.Errorf("want error for a layer source out of the root")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// LayerFile a file or a dir added to a layer
+type LayerFile struct {
+	// Source the local file or dir
+	Source string
+	// Path the path in the image, the contents of a dir are added under it
+	Path string
+	// Root if set, the source and the files in it must stay under the dir after resolving symbolic links
+	Root string
+	UID  int
+	GID  int
+}
+
+// WriteLayer writes the files as a gzipped tar layer, returns its descriptor and its diff id,
+// the digest of the uncompressed tar. The missing parent dirs, the ones not in exists, are
+// created with mode 0755, and the dirs written are added to exists.
+func (l *Layout) WriteLayer(files []LayerFile, exists map[string]struct{}) (Descriptor, digest.Digest, error) {
+	diffID := digest.Canonical.Digester()
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		tw := tar.NewWriter(io.MultiWriter(gz, diffID.Hash()))
+		err := writeTar(tw, files, exists)
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	dig, size, err := l.WriteBlob(pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return Descriptor{}, "", err
+	}
+	return Descriptor{MediaType: MediaTypeImageLayerGzip, Digest: dig, Size: size}, diffID.Digest(), nil
+}
+
+func writeTar(tw *tar.Writer, files []LayerFile, exists map[string]struct{}) error {
+	written := exists
+	if written == nil {
+		written = make(map[string]struct{})
+	}
+	for _, file := range files {
+		target := strings.TrimPrefix(path.Clean("/"+file.Path), "/")
+		if err := writeParents(tw, path.Dir(target), written); err != nil {
+			return err
+		}
+		err := filepath.Walk(file.Source, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			// the symbolic links are written as links, the other entries must not be reached through one
+			if file.Root != "" && info.Mode()&os.ModeSymlink == 0 {
+				if _, err := ResolveUnder(file.Root, p); err != nil {
+					return err
+				}
+			}
+			rel, err := filepath.Rel(file.Source, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(target, filepath.ToSlash(rel))
+			if err := writeEntry(tw, p, name, info, file.UID, file.GID); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				written[name] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveUnder resolves the symbolic links of p and returns the real path, it fails
+// if the real path is not under the real path of root
+func ResolveUnder(root, p string) (string, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(realRoot, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is out of %s", p, root)
+	}
+	return real, nil
+}
+
+// writeParents writes the missing parent dirs of the name
+func writeParents(tw *tar.Writer, dir string, written map[string]struct{}) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+	if _, ok := written[dir]; ok {
+		return nil
+	}
+	if err := writeParents(tw, path.Dir(dir), written); err != nil {
+		return err
+	}
+	written[dir] = struct{}{}
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+	})
+}
+
+func writeEntry(tw *tar.Writer, source, name string, info os.FileInfo, uid, gid int) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(source); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	header.Uid, header.Gid = uid, gid
+	header.Uname, header.Gname = "", ""
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// layerDirs returns the dirs in the gzipped tar layer
+func layerDirs(r io.Reader, dirs map[string]struct{}) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(path.Clean("/"+header.Name), "/"), "/")
+		if header.Typeflag == tar.TypeDir || header.Typeflag == tar.TypeSymlink {
+			dirs[name] = struct{}{}
+		}
+		// the parents exist too
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package oci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// ErrRefNotFound the image is not in the layout
+var ErrRefNotFound = fmt.Errorf("image not found in the layout")
+
+// Layout an OCI image layout in a dir
+type Layout struct {
+	dir string
+	// cache the layout holding the blobs shared by the builds, such as the layers of the base images
+	cache *Layout
+	// guards index.json
+	lock sync.Mutex
+}
+
+// OpenLayout opens the layout in the dir, it is created if not exists
+func OpenLayout(dir string, cache *Layout) (*Layout, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", string(digest.Canonical)), 0755); err != nil {
+		return nil, err
+	}
+	layoutFile := filepath.Join(dir, "oci-layout")
+	if _, err := os.Stat(layoutFile); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(layoutFile, []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644); err != nil {
+			return nil, err
+		}
+	}
+	l := &Layout{dir: dir, cache: cache}
+	if _, err := os.Stat(l.indexPath()); os.IsNotExist(err) {
+		if err := l.writeIndex(&Index{SchemaVersion: 2, Manifests: []Descriptor{}}); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Dir returns the dir of the layout
+func (l *Layout) Dir() string {
+	return l.dir
+}
+
+func (l *Layout) indexPath() string {
+	return filepath.Join(l.dir, "index.json")
+}
+
+func (l *Layout) blobPath(dig digest.Digest) string {
+	return filepath.Join(l.dir, "blobs", dig.Algorithm().String(), dig.Hex())
+}
+
+// HasBlob checks if the blob is in the layout
+func (l *Layout) HasBlob(dig digest.Digest) bool {
+	_, err := os.Stat(l.blobPath(dig))
+	return err == nil
+}
+
+// OpenBlob opens the blob, the caller must close it
+func (l *Layout) OpenBlob(dig digest.Digest) (*os.File, error) {
+	return os.Open(l.blobPath(dig))
+}
+
+// ReadBlob reads the whole blob
+func (l *Layout) ReadBlob(dig digest.Digest) ([]byte, error) {
+	return ioutil.ReadFile(l.blobPath(dig))
+}
+
+// WriteBlob writes the content as a blob, returns its digest and size
+func (l *Layout) WriteBlob(r io.Reader) (digest.Digest, int64, error) {
+	tmp, err := ioutil.TempFile(filepath.Join(l.dir, "blobs"), ".tmp-")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(io.MultiWriter(tmp, digester.Hash()), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	dig := digester.Digest()
+	if err := os.Rename(tmp.Name(), l.blobPath(dig)); err != nil {
+		return "", 0, err
+	}
+	return dig, size, nil
+}
+
+// WriteJSON writes the value as a json blob
+func (l *Layout) WriteJSON(mediaType string, v interface{}) (Descriptor, []byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	dig, size, err := l.WriteBlob(bytes.NewReader(body))
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	return Descriptor{MediaType: mediaType, Digest: dig, Size: size}, body, nil
+}
+
+// fetchBlob makes sure the blob is in the layout, it is linked from the cache,
+// or downloaded into the cache by fetch.
+func (l *Layout) fetchBlob(dig digest.Digest, fetch func() (io.ReadCloser, error)) error {
+	if l.HasBlob(dig) {
+		return nil
+	}
+	target := l
+	if l.cache != nil {
+		target = l.cache
+	}
+	if !target.HasBlob(dig) {
+		content, err := fetch()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		written, _, err := target.WriteBlob(content)
+		if err != nil {
+			return err
+		}
+		if written != dig {
+			os.Remove(target.blobPath(written))
+			return fmt.Errorf("blob %s: digest mismatch, got %s", dig, written)
+		}
+	}
+	if target == l {
+		return nil
+	}
+	return linkFile(target.blobPath(dig), l.blobPath(dig))
+}
+
+// Tag adds the manifest to index.json with the ref name, it replaces the manifest of the same ref name.
+func (l *Layout) Tag(desc Descriptor, ref string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	index, err := l.readIndex()
+	if err != nil {
+		return err
+	}
+	manifests := index.Manifests[:0]
+	for _, m := range index.Manifests {
+		if m.Annotations[AnnotationRefName] != ref {
+			manifests = append(manifests, m)
+		}
+	}
+	desc.Annotations = map[string]string{AnnotationRefName: ref}
+	index.Manifests = append(manifests, desc)
+	return l.writeIndex(index)
+}
+
+// Resolve returns the descriptor of the manifest with the ref name
+func (l *Layout) Resolve(ref string) (Descriptor, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	index, err := l.readIndex()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	for _, m := range index.Manifests {
+		if m.Annotations[AnnotationRefName] == ref {
+			return m, nil
+		}
+	}
+	return Descriptor{}, ErrRefNotFound
+}
+
+func (l *Layout) readIndex() (*Index, error) {
+	body, err := ioutil.ReadFile(l.indexPath())
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("parse index.json: %v", err)
+	}
+	return &index, nil
+}
+
+func (l *Layout) writeIndex(index *Index) error {
+	body, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	tmp := l.indexPath() + ".tmp"
+	if err := ioutil.WriteFile(tmp, body, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.indexPath())
+}
+
+// linkFile hard links the file, it is copied if the link is not possible
+func linkFile(src, dst string) error {
+	if err := os.Link(src, dst); err == nil || os.IsExist(err) {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package oci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"runtime"
+
+	"github.com/docker/distribution/reference"
+	"github.com/gridworkz/kato/builder/sources/registry"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+)
+
+// Remote an image in a registry
+type Remote struct {
+	reg        *registry.Registry
+	Domain     string
+	Repository string
+	// the tag or the digest
+	Reference string
+}
+
+// NewRemote connects to the registry of the image
+func NewRemote(image, user, password string) (*Remote, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("parse image name %s: %v", image, err)
+	}
+	r := &Remote{Domain: reference.Domain(named), Repository: reference.Path(named)}
+	if digested, ok := named.(reference.Digested); ok {
+		r.Reference = digested.Digest().String()
+	} else if tagged, ok := reference.TagNameOnly(named).(reference.Tagged); ok {
+		r.Reference = tagged.Tag()
+	}
+	domain := r.Domain
+	if domain == "docker.io" {
+		domain = "registry-1.docker.io"
+	}
+	if r.reg, err = registry.NewAuthorized(domain, user, password, false); err != nil {
+		logrus.Debugf("new registry client failure %s, try insecure", err.Error())
+		if r.reg, err = registry.NewAuthorized(domain, user, password, true); err != nil {
+			return nil, fmt.Errorf("connect registry %s: %v", domain, err)
+		}
+	}
+	return r, nil
+}
+
+// String returns the image name
+func (r *Remote) String() string {
+	if _, err := digest.Parse(r.Reference); err == nil {
+		return fmt.Sprintf("%s/%s@%s", r.Domain, r.Repository, r.Reference)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Domain, r.Repository, r.Reference)
+}
+
+// Pull pulls the image into the layout. The manifest is kept as it is and tagged with
+// the ref name if it is not empty, the multi-platform images are resolved to the current platform.
+func (r *Remote) Pull(layout *Layout, ref string) (*Image, error) {
+	body, mediaType, err := r.manifest(r.Reference)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest of %s: %v", r, err)
+	}
+	for _, dig := range manifestBlobs(&manifest) {
+		dig := dig
+		err := layout.fetchBlob(dig, func() (io.ReadCloser, error) {
+			return r.reg.DownloadBlob(r.Repository, dig)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pull blob %s of %s: %v", dig, r, err)
+		}
+	}
+	if ref != "" {
+		dig, size, err := layout.WriteBlob(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if err := layout.Tag(Descriptor{MediaType: mediaType, Digest: dig, Size: size}, ref); err != nil {
+			return nil, err
+		}
+	}
+	return loadImage(layout, &manifest)
+}
+
+// manifest gets the image manifest of the reference, returns it and its media type
+func (r *Remote) manifest(ref string) ([]byte, string, error) {
+	body, contentType, err := r.reg.ManifestRaw(r.Repository, ref,
+		MediaTypeImageManifest, MediaTypeDockerManifest, MediaTypeImageIndex, MediaTypeDockerManifestList)
+	if err != nil {
+		return nil, "", fmt.Errorf("get manifest of %s: %v", r, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		// the media type of the content
+		var versioned struct {
+			MediaType string `json:"mediaType"`
+		}
+		json.Unmarshal(body, &versioned)
+		mediaType = versioned.MediaType
+	}
+	switch mediaType {
+	case MediaTypeImageManifest, MediaTypeDockerManifest:
+		return body, mediaType, nil
+	case MediaTypeImageIndex, MediaTypeDockerManifestList:
+		var index Index
+		if err := json.Unmarshal(body, &index); err != nil {
+			return nil, "", fmt.Errorf("parse manifest list of %s: %v", r, err)
+		}
+		for _, m := range index.Manifests {
+			if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+				return r.manifest(m.Digest.String())
+			}
+		}
+		return nil, "", fmt.Errorf("image %s has no manifest for linux/%s", r, runtime.GOARCH)
+	}
+	return nil, "", fmt.Errorf("image %s: unsupported manifest type %q", r, mediaType)
+}
+
+// Push pushes the image of the ref name in the layout. The blobs of the images in mountFrom,
+// which are in the same registry, are mounted instead of uploaded.
+func (r *Remote) Push(layout *Layout, ref string, mountFrom ...*Remote) error {
+	desc, err := layout.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	body, err := layout.ReadBlob(desc.Digest)
+	if err != nil {
+		return err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return err
+	}
+	for _, dig := range manifestBlobs(&manifest) {
+		if err := r.pushBlob(layout, dig, mountFrom); err != nil {
+			return fmt.Errorf("push blob %s to %s: %v", dig, r, err)
+		}
+	}
+	if err := r.reg.PutManifestRaw(r.Repository, r.Reference, desc.MediaType, body); err != nil {
+		return fmt.Errorf("put manifest of %s: %v", r, err)
+	}
+	return nil
+}
+
+func (r *Remote) pushBlob(layout *Layout, dig digest.Digest, mountFrom []*Remote) error {
+	if ok, err := r.reg.HasBlob(r.Repository, dig); err == nil && ok {
+		return nil
+	}
+	for _, from := range mountFrom {
+		if from == nil || from.Domain != r.Domain || from.Repository == r.Repository {
+			continue
+		}
+		if ok, err := r.reg.MountBlob(r.Repository, from.Repository, dig); err == nil && ok {
+			return nil
+		}
+	}
+	return r.reg.UploadBlobFile(r.Repository, dig, layout.blobPath(dig))
+}
+
+// Copy copies the image from src to dst through the layout, without changing its manifest
+func Copy(layout *Layout, src, dst *Remote) error {
+	ref := dst.String()
+	if _, err := src.Pull(layout, ref); err != nil {
+		return err
+	}
+	return dst.Push(layout, ref, src)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package code
+
+import (
+	"path"
+	"sort"
+
+	"github.com/gridworkz/kato/util"
+)
+
+//Detector detects whether the source code is written in the language
+type Detector interface {
+	Lang() Lang
+	// Detect returns the confidence from 0 to 100, 0 means the code is not written in the language
+	Detect(homepath string) int
+}
+
+type funcDetector struct {
+	lang   Lang
+	detect func(homepath string) int
+}
+
+func (f *funcDetector) Lang() Lang {
+	return f.lang
+}
+
+func (f *funcDetector) Detect(homepath string) int {
+	return f.detect(homepath)
+}
+
+//NewDetector creates a detector of the language with the detect function
+func NewDetector(lang Lang, detect func(homepath string) int) Detector {
+	return &funcDetector{lang: lang, detect: detect}
+}
+
+var detectors []Detector
+
+//RegisterDetector registers the detector, the one registered earlier wins the same confidence
+func RegisterDetector(detector Detector) {
+	detectors = append(detectors, detector)
+}
+
+//LangCandidate a language the code may be written in
+type LangCandidate struct {
+	Lang       Lang `json:"language"`
+	Confidence int  `json:"confidence"`
+}
+
+//DetectLangs returns the languages the code may be written in, the most likely first
+func DetectLangs(homepath string) ([]LangCandidate, error) {
+	if ok, _ := util.FileExists(homepath); !ok {
+		return nil, ErrCodeDirNotExist
+	}
+	//Determine whether there is a code
+	if ok := util.IsHaveFile(homepath); !ok {
+		return nil, ErrCodeNotExist
+	}
+	var candidates []LangCandidate
+	for _, detector := range detectors {
+		if confidence := detector.Detect(homepath); confidence > 0 {
+			candidates = append(candidates, LangCandidate{Lang: detector.Lang(), Confidence: confidence})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrCodeUnableIdentify
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	return candidates, nil
+}
+
+//fileConfidence returns the confidence if one of the files exists in the dir
+func fileConfidence(homepath string, confidence int, names ...string) int {
+	for _, name := range names {
+		if ok, _ := util.FileExists(path.Join(homepath, name)); ok {
+			return confidence
+		}
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package code
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDetectLangs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []Lang
+	}{
+		{"rust", []string{"Cargo.toml", "Cargo.lock"}, []Lang{Rust}},
+		{"elixir", []string{"mix.exs"}, []Lang{Elixir}},
+		{"deno", []string{"deno.json", "main.ts"}, []Lang{Deno}},
+		{"deno convention", []string{"deps.ts", "mod.ts"}, []Lang{Deno}},
+		{"kotlin", []string{"build.gradle.kts", "gradlew"}, []Lang{Kotlin, Gradle}},
+		{"gradle", []string{"build.gradle", "gradlew"}, []Lang{Gradle}},
+		{"node with deps.ts", []string{"package.json", "deps.ts"}, []Lang{Nodejs}},
+		{"monorepo", []string{"Dockerfile", "Cargo.toml", "package.json", "index.html"}, []Lang{Dockerfile, Nodejs, Rust, Static}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "detect")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, file := range tc.files {
+				if err := ioutil.WriteFile(path.Join(dir, file), []byte("\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			candidates, err := DetectLangs(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candidates) != len(tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, candidates)
+			}
+			for i, candidate := range candidates {
+				if candidate.Lang != tc.want[i] {
+					t.Errorf("candidate %d: want %s, got %s", i, tc.want[i], candidate.Lang)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectLangsUnableIdentify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "detect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(path.Join(dir, "README.md"), []byte("\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DetectLangs(dir); err != ErrCodeUnableIdentify {
+		t.Errorf("want %v, got %v", ErrCodeUnableIdentify, err)
+	}
+	if lang, err := GetLangType(dir); lang != NO || err != ErrCodeUnableIdentify {
+		t.Errorf("want no lang, got %s %v", lang, err)
+	}
+}
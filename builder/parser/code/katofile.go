@@ -15,17 +15,18 @@
 // PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
 // FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
 // ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
-
 package code
 
 import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"strings"
 
 	"github.com/gridworkz/kato/util"
 	"github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 //KatoFileConfig - source code configuration file
@@ -36,6 +37,15 @@ type KatoFileConfig struct {
 	Envs      map[string]interface{} `yaml:"envs"`
 	Cmd       string                 `yaml:"cmd"`
 	Services  []*Service             `yaml:"services"`
+
+	// BuildArgs the args of the dockerfile, or the build envs of the other languages
+	BuildArgs map[string]string `yaml:"build_args"`
+	Probes    []*Probe          `yaml:"probes"`
+	Volumes   []*Volume         `yaml:"volumes"`
+	Resources *Resources        `yaml:"resources"`
+
+	// unknown the unknown fields, which are ignored
+	unknown []string
 }
 
 // Service contains
@@ -43,6 +53,17 @@ type Service struct {
 	Name  string            `yaml:"name"`
 	Ports []Port            `yaml:"ports"`
 	Envs  map[string]string `yaml:"envs"`
+
+	// BuildPath the dir of a service which is not a module of the detected language
+	BuildPath string            `yaml:"buildpath"`
+	Language  string            `yaml:"language"`
+	Cmd       string            `yaml:"cmd"`
+	BuildArgs map[string]string `yaml:"build_args"`
+	Probes    []*Probe          `yaml:"probes"`
+	Volumes   []*Volume         `yaml:"volumes"`
+	Resources *Resources        `yaml:"resources"`
+	// Dependencies the names of the other services in the katofile
+	Dependencies []string `yaml:"dependencies"`
 }
 
 //Port
@@ -51,6 +72,80 @@ type Port struct {
 	Protocol string `yaml:"protocol"`
 }
 
+//Probe health check, see TenantServiceProbe
+type Probe struct {
+	// liveness or readiness
+	Mode string `yaml:"mode"`
+	// http or tcp
+	Scheme             string            `yaml:"scheme"`
+	Path               string            `yaml:"path"`
+	Port               int               `yaml:"port"`
+	HTTPHeader         map[string]string `yaml:"http_header"`
+	InitialDelaySecond int               `yaml:"initial_delay_second"`
+	PeriodSecond       int               `yaml:"period_second"`
+	TimeoutSecond      int               `yaml:"timeout_second"`
+	FailureThreshold   int               `yaml:"failure_threshold"`
+	SuccessThreshold   int               `yaml:"success_threshold"`
+	// ignore, readiness or liveness, the same as the mode by default
+	FailureAction string `yaml:"failure_action"`
+}
+
+//Volume persistent volume
+type Volume struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+	// share-file by default
+	Type string `yaml:"type"`
+	// Capacity in GB, 0 means unlimited
+	Capacity int64 `yaml:"capacity"`
+}
+
+//Resources resource requests, in kubernetes quantities. eg: memory: 512Mi, cpu: 500m
+type Resources struct {
+	Memory string `yaml:"memory"`
+	CPU    string `yaml:"cpu"`
+}
+
+//MemoryMB returns the memory request in MB
+func (r *Resources) MemoryMB() int {
+	if r == nil || r.Memory == "" {
+		return 0
+	}
+	quantity, err := resource.ParseQuantity(r.Memory)
+	if err != nil {
+		return 0
+	}
+	return int(quantity.Value() / 1024 / 1024)
+}
+
+//MilliCPU returns the cpu request in millicores
+func (r *Resources) MilliCPU() int {
+	if r == nil || r.CPU == "" {
+		return 0
+	}
+	quantity, err := resource.ParseQuantity(r.CPU)
+	if err != nil {
+		return 0
+	}
+	return int(quantity.MilliValue())
+}
+
+//KatoFileError an error of the katofile schema
+type KatoFileError struct {
+	// eg: services[0].probes[1].port
+	Field   string
+	Message string
+	// Negligible the katofile can still be used
+	Negligible bool
+}
+
+func (e *KatoFileError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
 //ReadKatoFile - read cloud help code configuration
 func ReadKatoFile(homepath string) (*KatoFileConfig, error) {
 	if ok, _ := util.FileExists(path.Join(homepath, "katofile")); !ok {
@@ -66,5 +161,160 @@ func ReadKatoFile(homepath string) (*KatoFileConfig, error) {
 		logrus.Error("marshal kato file error,", err.Error())
 		return nil, fmt.Errorf("marshal kato file error")
 	}
+	// the unknown fields are most likely typos
+	var strict KatoFileConfig
+	if err := yaml.UnmarshalStrict(body, &strict); err != nil {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			rbdfile.unknown = typeErr.Errors
+		}
+	}
 	return &rbdfile, nil
 }
+
+//Validate validates the katofile against its schema
+func (k *KatoFileConfig) Validate() []*KatoFileError {
+	var errs []*KatoFileError
+	add := func(negligible bool, field, format string, a ...interface{}) {
+		errs = append(errs, &KatoFileError{Field: field, Message: fmt.Sprintf(format, a...), Negligible: negligible})
+	}
+	for _, unknown := range k.unknown {
+		add(true, "", "%s", unknown)
+	}
+	validatePorts(add, "ports", k.Ports)
+	validateBuildArgs(add, "build_args", k.BuildArgs)
+	validateProbes(add, "probes", k.Probes)
+	validateVolumes(add, "volumes", k.Volumes)
+	validateResources(add, "resources", k.Resources)
+
+	names := make(map[string]struct{}, len(k.Services))
+	for i, svc := range k.Services {
+		field := fmt.Sprintf("services[%d]", i)
+		if svc == nil || svc.Name == "" {
+			add(false, field+".name", "is required")
+			continue
+		}
+		if _, ok := names[svc.Name]; ok {
+			add(false, field+".name", "duplicate service %s", svc.Name)
+		}
+		names[svc.Name] = struct{}{}
+	}
+	for i, svc := range k.Services {
+		if svc == nil || svc.Name == "" {
+			continue
+		}
+		field := fmt.Sprintf("services[%d]", i)
+		if strings.HasPrefix(svc.BuildPath, "/") || strings.Contains(svc.BuildPath, "..") {
+			add(false, field+".buildpath", "must be a relative path in the repository")
+		}
+		validatePorts(add, field+".ports", svc.Ports)
+		validateBuildArgs(add, field+".build_args", svc.BuildArgs)
+		validateProbes(add, field+".probes", svc.Probes)
+		validateVolumes(add, field+".volumes", svc.Volumes)
+		validateResources(add, field+".resources", svc.Resources)
+		for j, dep := range svc.Dependencies {
+			depField := fmt.Sprintf("%s.dependencies[%d]", field, j)
+			if dep == svc.Name {
+				add(false, depField, "a service can not depend on itself")
+			} else if _, ok := names[dep]; !ok {
+				add(false, depField, "service %s is not defined in the katofile", dep)
+			}
+		}
+	}
+	return errs
+}
+
+type addErrorFunc func(negligible bool, field, format string, a ...interface{})
+
+func validatePorts(add addErrorFunc, field string, ports []Port) {
+	for i, port := range ports {
+		if port.Port < 1 || port.Port > 65535 {
+			add(false, fmt.Sprintf("%s[%d].port", field, i), "must be between 1 and 65535")
+		}
+	}
+}
+
+func validateBuildArgs(add addErrorFunc, field string, args map[string]string) {
+	for name := range args {
+		if name == "" || strings.ContainsAny(name, " =") {
+			add(false, field, "invalid arg name %q", name)
+		}
+	}
+}
+
+func validateProbes(add addErrorFunc, field string, probes []*Probe) {
+	modes := make(map[string]struct{})
+	for i, probe := range probes {
+		probeField := fmt.Sprintf("%s[%d]", field, i)
+		if probe == nil {
+			add(false, probeField, "is empty")
+			continue
+		}
+		switch probe.Mode {
+		case "liveness", "readiness":
+			if _, ok := modes[probe.Mode]; ok {
+				add(false, probeField+".mode", "duplicate %s probe", probe.Mode)
+			}
+			modes[probe.Mode] = struct{}{}
+		default:
+			add(false, probeField+".mode", "must be liveness or readiness")
+		}
+		switch probe.Scheme {
+		case "tcp":
+		case "http":
+			if !strings.HasPrefix(probe.Path, "/") {
+				add(false, probeField+".path", "must start with /")
+			}
+		default:
+			add(false, probeField+".scheme", "must be http or tcp")
+		}
+		if probe.Port < 1 || probe.Port > 65535 {
+			add(false, probeField+".port", "must be between 1 and 65535")
+		}
+		switch probe.FailureAction {
+		case "", "ignore", "readiness", "liveness":
+		default:
+			add(false, probeField+".failure_action", "must be ignore, readiness or liveness")
+		}
+		if probe.InitialDelaySecond < 0 || probe.PeriodSecond < 0 || probe.TimeoutSecond < 0 ||
+			probe.FailureThreshold < 0 || probe.SuccessThreshold < 0 {
+			add(false, probeField, "the seconds and thresholds can not be negative")
+		}
+	}
+}
+
+func validateVolumes(add addErrorFunc, field string, volumes []*Volume) {
+	paths := make(map[string]struct{})
+	for i, volume := range volumes {
+		volumeField := fmt.Sprintf("%s[%d]", field, i)
+		if volume == nil {
+			add(false, volumeField, "is empty")
+			continue
+		}
+		if !strings.HasPrefix(volume.Path, "/") {
+			add(false, volumeField+".path", "must be an absolute path")
+		}
+		if _, ok := paths[volume.Path]; ok {
+			add(false, volumeField+".path", "duplicate volume path %s", volume.Path)
+		}
+		paths[volume.Path] = struct{}{}
+		if volume.Capacity < 0 {
+			add(false, volumeField+".capacity", "can not be negative")
+		}
+	}
+}
+
+func validateResources(add addErrorFunc, field string, resources *Resources) {
+	if resources == nil {
+		return
+	}
+	if resources.Memory != "" {
+		if quantity, err := resource.ParseQuantity(resources.Memory); err != nil || quantity.Sign() <= 0 {
+			add(false, field+".memory", "invalid memory %q", resources.Memory)
+		}
+	}
+	if resources.CPU != "" {
+		if quantity, err := resource.ParseQuantity(resources.CPU); err != nil || quantity.Sign() <= 0 {
+			add(false, field+".cpu", "invalid cpu %q", resources.CPU)
+		}
+	}
+}
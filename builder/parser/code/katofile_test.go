@@ -19,7 +19,12 @@
 package code
 
 import (
+	"io/ioutil"
+	"path"
+	"strings"
 	"testing"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestReadKatoFile(t *testing.T) {
@@ -28,4 +33,72 @@ func TestReadKatoFile(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Log(rbdfile)
+	if errs := rbdfile.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, but returned %v", errs)
+	}
+	svc := rbdfile.Services[1]
+	if svc.Resources.MemoryMB() != 512 || svc.Resources.MilliCPU() != 500 {
+		t.Errorf("Expected 512MB memory and 500m cpu, but returned %d and %d", svc.Resources.MemoryMB(), svc.Resources.MilliCPU())
+	}
+}
+
+func TestKatoFileValidate(t *testing.T) {
+	body := `
+services:
+- name: web
+  probes:
+  - mode: liveness
+    scheme: http
+    path: health
+    port: 80
+  - mode: liveness
+    scheme: cmd
+    port: 0
+  volumes:
+  - path: data
+  resources:
+    memory: lots
+  dependencies:
+  - web
+  - db
+`
+	var rbdfile KatoFileConfig
+	if err := yaml.Unmarshal([]byte(body), &rbdfile); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"services[0].probes[0].path",
+		"services[0].probes[1].mode",
+		"services[0].probes[1].scheme",
+		"services[0].probes[1].port",
+		"services[0].volumes[0].path",
+		"services[0].resources.memory",
+		"services[0].dependencies[0]",
+		"services[0].dependencies[1]",
+	}
+	errs := rbdfile.Validate()
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, but returned %v", len(want), errs)
+	}
+	for i, err := range errs {
+		if err.Field != want[i] || err.Negligible {
+			t.Errorf("Expected error of %s, but returned %v", want[i], err)
+		}
+	}
+}
+
+func TestKatoFileUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	body := "language: Node.js\nbuild_arg:\n  A: b\n"
+	if err := ioutil.WriteFile(path.Join(dir, "katofile"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rbdfile, err := ReadKatoFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := rbdfile.Validate()
+	if len(errs) != 1 || !errs[0].Negligible || !strings.Contains(errs[0].Message, "build_arg") {
+		t.Errorf("Expected a negligible error of the unknown field, but returned %v", errs)
+	}
 }
@@ -20,28 +20,34 @@ package code
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"strings"
 
 	"github.com/gridworkz/kato/util"
 )
 
 func init() {
-	checkFuncList = append(checkFuncList, dockerfile)
-	checkFuncList = append(checkFuncList, javaJar)
-	checkFuncList = append(checkFuncList, javaWar)
-	checkFuncList = append(checkFuncList, javaMaven)
-	checkFuncList = append(checkFuncList, php)
-	checkFuncList = append(checkFuncList, python)
-	checkFuncList = append(checkFuncList, nodeJSStatic)
-	checkFuncList = append(checkFuncList, nodejs)
-	checkFuncList = append(checkFuncList, ruby)
-	checkFuncList = append(checkFuncList, static)
-	checkFuncList = append(checkFuncList, clojure)
-	checkFuncList = append(checkFuncList, golang)
-	checkFuncList = append(checkFuncList, gradle)
-	checkFuncList = append(checkFuncList, grails)
-	checkFuncList = append(checkFuncList, scala)
-	checkFuncList = append(checkFuncList, netcore)
+	RegisterDetector(NewDetector(Dockerfile, dockerfile))
+	RegisterDetector(NewDetector(JavaJar, javaJar))
+	RegisterDetector(NewDetector(JaveWar, javaWar))
+	RegisterDetector(NewDetector(JavaMaven, javaMaven))
+	RegisterDetector(NewDetector(PHP, php))
+	RegisterDetector(NewDetector(Python, python))
+	RegisterDetector(NewDetector(NodeJSStatic, nodeJSStatic))
+	RegisterDetector(NewDetector(Nodejs, nodejs))
+	RegisterDetector(NewDetector(Ruby, ruby))
+	RegisterDetector(NewDetector(Static, static))
+	RegisterDetector(NewDetector(Clojure, clojure))
+	RegisterDetector(NewDetector(Golang, golang))
+	RegisterDetector(NewDetector(Gradle, gradle))
+	RegisterDetector(NewDetector(Grails, grails))
+	RegisterDetector(NewDetector(Scala, scala))
+	RegisterDetector(NewDetector(NetCore, netcore))
+	RegisterDetector(NewDetector(Rust, rust))
+	RegisterDetector(NewDetector(Elixir, elixir))
+	RegisterDetector(NewDetector(Deno, deno))
+	RegisterDetector(NewDetector(Kotlin, kotlin))
 }
 
 //ErrCodeNotExist Code is empty error
@@ -118,190 +124,159 @@ var NetCore Lang = ".NetCore"
 //OSS Lang
 var OSS Lang = "OSS"
 
-//GetLangType check code lang
-func GetLangType(homepath string) (Lang, error) {
-	if ok, _ := util.FileExists(homepath); !ok {
-		return NO, ErrCodeDirNotExist
-	}
-	//Determine whether there is a code
-	if ok := util.IsHaveFile(homepath); !ok {
-		return NO, ErrCodeNotExist
-	}
-	//Get a certain language
-	for _, check := range checkFuncList {
-		if lang := check(homepath); lang != NO {
-			return lang, nil
-		}
-	}
-	//Get possible languages
-	//Unrecognized
-	return NO, ErrCodeUnableIdentify
-}
+//Scala Lang
+var Scala Lang = "Scala"
 
-type langTypeFunc func(homepath string) Lang
+//Rust Lang
+var Rust Lang = "Rust"
 
-var checkFuncList []langTypeFunc
+//Elixir Lang
+var Elixir Lang = "Elixir"
 
-func dockerfile(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "Dockerfile")); !ok {
-		return NO
-	}
-	return Dockerfile
+//Deno Lang
+var Deno Lang = "Deno"
+
+//Kotlin Lang, built with the gradle kotlin dsl
+var Kotlin Lang = "Kotlin"
+
+//buildpacks of the languages which are not contained in the builder image,
+//they are pinned so that an upstream change does not change the build of the same commit
+var buildpacks = map[Lang]string{
+	Rust:   "https://github.com/emk/heroku-buildpack-rust#v1.0.0",
+	Elixir: "https://github.com/HashNuke/heroku-buildpack-elixir#v1",
+	Deno:   "https://github.com/chibat/heroku-buildpack-deno#v1.0.0",
+	Kotlin: "https://github.com/heroku/heroku-buildpack-gradle#v38",
 }
-func python(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "requirements.txt")); ok {
-		return Python
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "setup.py")); ok {
-		return Python
+
+//GetBuildpack returns the buildpack to build the language, it is empty if the builder image supports the language.
+//The pinned buildpack can be replaced by the env <LANG>_BUILDPACK_URL of the builder, such as RUST_BUILDPACK_URL
+func GetBuildpack(lang Lang) string {
+	buildpack, ok := buildpacks[lang]
+	if !ok {
+		return ""
 	}
-	if ok, _ := util.FileExists(path.Join(homepath, "Pipfile")); ok {
-		return Python
+	if url := os.Getenv(strings.ToUpper(string(lang)) + "_BUILDPACK_URL"); url != "" {
+		return url
 	}
-	return NO
+	return buildpack
 }
-func ruby(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "Gemfile")); ok {
-		return Ruby
+
+//GetLangType check code lang
+func GetLangType(homepath string) (Lang, error) {
+	candidates, err := DetectLangs(homepath)
+	if err != nil {
+		return NO, err
 	}
-	return NO
+	return candidates[0].Lang, nil
+}
+
+func dockerfile(homepath string) int {
+	return fileConfidence(homepath, 100, "Dockerfile")
 }
-func php(homepath string) Lang {
+func python(homepath string) int {
+	return fileConfidence(homepath, 85, "requirements.txt", "setup.py", "Pipfile")
+}
+func ruby(homepath string) int {
+	return fileConfidence(homepath, 85, "Gemfile")
+}
+func php(homepath string) int {
 	if ok, _ := util.FileExists(path.Join(homepath, "composer.json")); ok {
-		return PHP
+		return 85
 	}
 	if ok := util.SearchFile(homepath, "index.php", 2); ok {
-		return PHP
+		return 50
 	}
-	return NO
+	return 0
 }
-func javaMaven(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.xml")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.atom")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.clj")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.groovy")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.rb")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.scala")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.yaml")); ok {
-		return JavaMaven
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "pom.yml")); ok {
-		return JavaMaven
-	}
-	return NO
+func javaMaven(homepath string) int {
+	return fileConfidence(homepath, 85, "pom.xml", "pom.atom", "pom.clj", "pom.groovy", "pom.rb", "pom.scala", "pom.yaml", "pom.yml")
 }
-func javaWar(homepath string) Lang {
+func javaWar(homepath string) int {
 	if ok := util.FileExistsWithSuffix(homepath, ".war"); ok {
-		return JaveWar
+		return 75
 	}
-	return NO
+	return 0
 }
 
 //javaJar Procfile must be defined
-func javaJar(homepath string) Lang {
+func javaJar(homepath string) int {
 	if ok := util.FileExistsWithSuffix(homepath, ".jar"); ok {
-		return JavaJar
+		return 70
 	}
-	return NO
+	return 0
 }
-func nodejs(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "package.json")); ok {
-		return Nodejs
-	}
-	return NO
+func nodejs(homepath string) int {
+	return fileConfidence(homepath, 85, "package.json")
 }
-func nodeJSStatic(homepath string) Lang {
+func nodeJSStatic(homepath string) int {
 	if ok, _ := util.FileExists(path.Join(homepath, "package.json")); ok {
-		if ok, _ := util.FileExists(path.Join(homepath, "nodestatic.json")); ok {
-			return NodeJSStatic
-		}
+		return fileConfidence(homepath, 90, "nodestatic.json")
 	}
-	return NO
+	return 0
 }
 
-func static(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "index.html")); ok {
-		return Static
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "index.htm")); ok {
-		return Static
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "static.json")); ok {
-		return Static
-	}
-	return NO
+func static(homepath string) int {
+	return fileConfidence(homepath, 40, "index.html", "index.htm", "static.json")
 }
 
-func clojure(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "project.clj")); ok {
-		return Clojure
-	}
-	return NO
+func clojure(homepath string) int {
+	return fileConfidence(homepath, 85, "project.clj")
 }
-func golang(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "go.mod")); ok {
-		return Golang
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "Gopkg.lock")); ok {
-		return Golang
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "Godeps", "Godeps.json")); ok {
-		return Golang
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "vendor", "vendor.json")); ok {
-		return Golang
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "glide.yaml")); ok {
-		return Golang
+func golang(homepath string) int {
+	if confidence := fileConfidence(homepath, 85, "go.mod", "go.work", "Gopkg.lock", path.Join("Godeps", "Godeps.json"), path.Join("vendor", "vendor.json"), "glide.yaml"); confidence > 0 {
+		return confidence
 	}
 	if ok := util.FileExistsWithSuffix(path.Join(homepath, "src"), ".go"); ok {
-		return Golang
+		return 60
 	}
-	return NO
+	return 0
 }
-func gradle(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "build.gradle")); ok {
-		return Gradle
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "gradlew")); ok {
-		return Gradle
-	}
-	if ok, _ := util.FileExists(path.Join(homepath, "settings.gradle")); ok {
-		return Gradle
+func gradle(homepath string) int {
+	if confidence := fileConfidence(homepath, 85, "build.gradle", "settings.gradle"); confidence > 0 {
+		return confidence
 	}
-	return NO
+	// the wrapper is used by the kotlin dsl too
+	return fileConfidence(homepath, 60, "gradlew")
 }
-func grails(homepath string) Lang {
-	if ok, _ := util.FileExists(path.Join(homepath, "grails-app")); ok {
-		return Grails
-	}
-	return NO
+func grails(homepath string) int {
+	return fileConfidence(homepath, 90, "grails-app")
 }
 
 //netcore
-func netcore(homepath string) Lang {
+func netcore(homepath string) int {
 	if ok := util.FileExistsWithSuffix(homepath, ".sln"); ok {
-		return NetCore
+		return 85
 	}
 	if ok := util.FileExistsWithSuffix(homepath, ".csproj"); ok {
-		return NetCore
+		return 85
 	}
-	return NO
+	return 0
 }
 
 //Not currently supported
-func scala(homepath string) Lang {
-	return NO
+func scala(homepath string) int {
+	return 0
+}
+
+func rust(homepath string) int {
+	return fileConfidence(homepath, 85, "Cargo.toml")
+}
+
+func elixir(homepath string) int {
+	return fileConfidence(homepath, 85, "mix.exs")
+}
+
+func deno(homepath string) int {
+	if confidence := fileConfidence(homepath, 90, "deno.json", "deno.jsonc"); confidence > 0 {
+		return confidence
+	}
+	// the entry files of the deno convention, node.js projects define package.json
+	if ok, _ := util.FileExists(path.Join(homepath, "package.json")); ok {
+		return 0
+	}
+	return fileConfidence(homepath, 50, "deps.ts", "mod.ts")
+}
+
+func kotlin(homepath string) int {
+	return fileConfidence(homepath, 90, "build.gradle.kts", "settings.gradle.kts")
 }
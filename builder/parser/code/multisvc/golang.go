@@ -0,0 +1,169 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"bufio"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gridworkz/kato/builder/parser/types"
+	"github.com/gridworkz/kato/util"
+)
+
+// golang is an implementation of ServiceInterface for the go modules with several main packages
+type golang struct {
+}
+
+// NewGolang creates a new ServiceInterface for go
+func NewGolang() ServiceInterface {
+	return &golang{}
+}
+
+// ListModules lists the main packages of the modules used by go.work, or of the root module
+func (g *golang) ListModules(homepath string) ([]*types.Service, error) {
+	modules, err := parseGoWork(path.Join(homepath, "go.work"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		modules = []string{"."}
+	}
+	mains := make(map[string]struct{})
+	for _, module := range modules {
+		for _, dir := range listMainPackages(homepath, module) {
+			mains[dir] = struct{}{}
+		}
+	}
+	var dirs []string
+	for dir := range mains {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	var res []*types.Service
+	for _, dir := range dirs {
+		// the binary is named after the dir of the main package
+		binary := path.Base(dir)
+		if dir == "." {
+			binary = path.Base(filepath.ToSlash(homepath))
+		}
+		res = append(res, &types.Service{
+			ID:    util.NewUUID(),
+			Name:  dir,
+			Cname: binary,
+			Envs: map[string]*types.Env{
+				"BUILD_GO_INSTALL_PACKAGE_SPEC": {
+					Name:  "BUILD_GO_INSTALL_PACKAGE_SPEC",
+					Value: "./" + strings.TrimPrefix(dir, "./"),
+				},
+				"BUILD_PROCFILE": {
+					Name:  "BUILD_PROCFILE",
+					Value: "web: " + binary,
+				},
+			},
+		})
+	}
+	return res, nil
+}
+
+// parseGoWork returns the module dirs of the use directives in go.work
+func parseGoWork(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var dirs []string
+	var inBlock bool
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			dirs = append(dirs, path.Clean(strings.Trim(fields[0], `"`)))
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) > 1:
+			dirs = append(dirs, path.Clean(strings.Trim(fields[1], `"`)))
+		}
+	}
+	return dirs, scanner.Err()
+}
+
+// listMainPackages returns the dirs, relative to homepath, of the main packages in the module
+func listMainPackages(homepath, module string) []string {
+	var dirs []string
+	root := path.Join(homepath, module)
+	filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if p != root {
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			// a nested module is not a part of this module
+			if ok, _ := util.FileExists(path.Join(p, "go.mod")); ok {
+				return filepath.SkipDir
+			}
+		}
+		if isMainPackage(p) {
+			if rel, err := filepath.Rel(homepath, p); err == nil {
+				dirs = append(dirs, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return dirs
+}
+
+// isMainPackage checks if the go files in dir declare the main package
+func isMainPackage(dir string) bool {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") || strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path.Join(dir, file.Name()), nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		return f.Name.Name == "main"
+	}
+	return false
+}
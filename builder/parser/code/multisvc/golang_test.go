@@ -0,0 +1,78 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGolang_ListModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.work":                     "go 1.18\n\nuse (\n\t./api // the api\n\t./tools\n)\nuse ./worker\n",
+		"api/go.mod":                  "module example.com/api\n",
+		"api/cmd/server/main.go":      "package main\n\nfunc main() {}\n",
+		"api/cmd/server/main_test.go": "package main_test\n",
+		"api/internal/db/db.go":       "package db\n",
+		"worker/go.mod":               "module example.com/worker\n",
+		"worker/main.go":              "// Command worker\npackage main\n\nfunc main() {}\n",
+		"worker/vendor/x/main.go":     "package main\n",
+		"other/main.go":               "package main\n",
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewGolang().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"api/cmd/server": "./api/cmd/server",
+		"worker":         "./worker",
+	}
+	if len(services) != len(want) {
+		t.Fatalf("Expected %d modules, but returned %d", len(want), len(services))
+	}
+	for _, svc := range services {
+		if spec := svc.Envs["BUILD_GO_INSTALL_PACKAGE_SPEC"].Value; spec != want[svc.Name] {
+			t.Errorf("Module %s: expected package spec %q, but returned %q", svc.Name, want[svc.Name], spec)
+		}
+		if procfile := svc.Envs["BUILD_PROCFILE"].Value; procfile != "web: "+svc.Cname {
+			t.Errorf("Module %s: unexpected procfile %q", svc.Name, procfile)
+		}
+	}
+}
+
+func TestGolang_ListModulesCmd(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod":             "module example.com/app\n",
+		"cmd/api/main.go":    "package main\n",
+		"cmd/worker/main.go": "package main\n",
+		"cmd/tool/go.mod":    "module example.com/tool\n",
+		"cmd/tool/main.go":   "package main\n",
+		"pkg/model/model.go": "package model\n",
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewGolang().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 || services[0].Name != "cmd/api" || services[1].Name != "cmd/worker" {
+		t.Errorf("Expected cmd/api and cmd/worker, but returned %v", services)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gridworkz/kato/builder/parser/types"
+	"github.com/gridworkz/kato/util"
+)
+
+var (
+	// include 'a', ':b:c' or include("a", ":b:c")
+	gradleIncludeRe = regexp.MustCompile(`^\s*include\b\s*\(?(.*?)\)?\s*$`)
+	gradleQuotedRe  = regexp.MustCompile(`["']([^"']+)["']`)
+	// id 'application', id("application"), apply plugin: 'application' or `application`
+	gradleApplicationRe = regexp.MustCompile("(?m)(id\\s*\\(?\\s*[\"']application[\"']|apply\\s+plugin\\s*:\\s*[\"']application[\"']|^\\s*`?application`?\\s*$)")
+)
+
+// gradle is an implementation of ServiceInterface for the gradle multi-project builds
+type gradle struct {
+}
+
+// NewGradle creates a new ServiceInterface for gradle
+func NewGradle() ServiceInterface {
+	return &gradle{}
+}
+
+// gradleProject represents a subproject in settings.gradle
+type gradleProject struct {
+	// eg: :services:api
+	Path string
+	// eg: services/api
+	Dir string
+}
+
+// ListModules lists the subprojects which are spring boot or java applications
+func (g *gradle) ListModules(homepath string) ([]*types.Service, error) {
+	projects, err := parseGradleSettings(homepath)
+	if err != nil {
+		return nil, err
+	}
+	var res []*types.Service
+	for _, project := range projects {
+		task, procfile := gradleProjectStart(homepath, project)
+		if task == "" {
+			continue
+		}
+		res = append(res, &types.Service{
+			ID:        util.NewUUID(),
+			Name:      project.Dir,
+			Cname:     path.Base(project.Dir),
+			Packaging: "jar",
+			Envs: map[string]*types.Env{
+				"BUILD_GRADLE_TASK": {
+					Name:  "BUILD_GRADLE_TASK",
+					Value: task,
+				},
+				"BUILD_PROCFILE": {
+					Name:  "BUILD_PROCFILE",
+					Value: procfile,
+				},
+			},
+		})
+	}
+	return res, nil
+}
+
+// parseGradleSettings returns the subprojects included in settings.gradle(.kts)
+func parseGradleSettings(homepath string) ([]*gradleProject, error) {
+	var body []byte
+	var err error
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if body, err = ioutil.ReadFile(path.Join(homepath, name)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var projects []*gradleProject
+	seen := make(map[string]struct{})
+	for _, line := range strings.Split(string(body), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		match := gradleIncludeRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, quoted := range gradleQuotedRe.FindAllStringSubmatch(match[1], -1) {
+			projectPath := ":" + strings.TrimPrefix(quoted[1], ":")
+			if _, ok := seen[projectPath]; ok {
+				continue
+			}
+			seen[projectPath] = struct{}{}
+			projects = append(projects, &gradleProject{
+				Path: projectPath,
+				Dir:  strings.Replace(strings.TrimPrefix(projectPath, ":"), ":", "/", -1),
+			})
+		}
+	}
+	return projects, nil
+}
+
+// gradleProjectStart returns the gradle task building the subproject and its start command,
+// the task is empty if the subproject is a library.
+func gradleProjectStart(homepath string, project *gradleProject) (task, procfile string) {
+	var body []byte
+	var err error
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		if body, err = ioutil.ReadFile(path.Join(homepath, project.Dir, name)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", ""
+	}
+	name := path.Base(project.Dir)
+	switch {
+	case strings.Contains(string(body), "org.springframework.boot"):
+		return fmt.Sprintf("%s:bootJar", project.Path),
+			fmt.Sprintf("web: java $JAVA_OPTS -jar %s/build/libs/*.jar", project.Dir)
+	case gradleApplicationRe.Match(body):
+		return fmt.Sprintf("%s:installDist", project.Path),
+			fmt.Sprintf("web: %s/build/install/%s/bin/%s", project.Dir, name, name)
+	}
+	return "", ""
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGradle_ListModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"settings.gradle.kts":           "rootProject.name = \"shop\"\n// include(\"old\")\ninclude(\"api\", \":services:billing\")\ninclude(\"common\")\n",
+		"api/build.gradle.kts":          "plugins {\n    id(\"org.springframework.boot\") version \"2.7.0\"\n}\n",
+		"services/billing/build.gradle": "plugins {\n    id 'java'\n    id 'application'\n}\n",
+		"common/build.gradle.kts":       "plugins {\n    `java-library`\n}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewGradle().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 modules, but returned %d", len(services))
+	}
+	want := []struct {
+		name, task, procfile string
+	}{
+		{"api", ":api:bootJar", "web: java $JAVA_OPTS -jar api/build/libs/*.jar"},
+		{"services/billing", ":services:billing:installDist", "web: services/billing/build/install/billing/bin/billing"},
+	}
+	for i, w := range want {
+		svc := services[i]
+		if svc.Name != w.name || svc.BuildPath != "" {
+			t.Errorf("Expected module %s, but returned %s", w.name, svc.Name)
+		}
+		if task := svc.Envs["BUILD_GRADLE_TASK"].Value; task != w.task {
+			t.Errorf("Module %s: expected task %q, but returned %q", w.name, w.task, task)
+		}
+		if procfile := svc.Envs["BUILD_PROCFILE"].Value; procfile != w.procfile {
+			t.Errorf("Module %s: expected procfile %q, but returned %q", w.name, w.procfile, procfile)
+		}
+	}
+}
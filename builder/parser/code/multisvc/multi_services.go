@@ -33,6 +33,12 @@ func NewMultiServiceI(lang string) ServiceInterface {
 	switch lang {
 	case "Java-maven":
 		return NewMaven()
+	case "Node.js":
+		return NewNodeJS()
+	case "Go":
+		return NewGolang()
+	case "Gradle", "Kotlin":
+		return NewGradle()
 	}
 	return nil
 }
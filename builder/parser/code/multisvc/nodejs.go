@@ -0,0 +1,238 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gridworkz/kato/builder/parser/types"
+	"github.com/gridworkz/kato/util"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// nodejs is an implementation of ServiceInterface for npm, yarn and pnpm workspaces.
+type nodejs struct {
+}
+
+// NewNodeJS creates a new ServiceInterface for node.js workspaces
+func NewNodeJS() ServiceInterface {
+	return &nodejs{}
+}
+
+// packageJSON represents the fields of package.json used to find the workspaces
+type packageJSON struct {
+	Name       string            `json:"name"`
+	Scripts    map[string]string `json:"scripts"`
+	Workspaces json.RawMessage   `json:"workspaces"`
+}
+
+// pnpmWorkspace represents pnpm-workspace.yaml
+type pnpmWorkspace struct {
+	Packages []string `yaml:"packages"`
+}
+
+// ListModules lists the workspace packages which can be started
+func (n *nodejs) ListModules(homepath string) ([]*types.Service, error) {
+	root, err := parsePackageJSON(path.Join(homepath, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := workspacePatterns(homepath, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	manager := packageManager(homepath)
+	var res []*types.Service
+	for _, dir := range matchWorkspaces(homepath, patterns) {
+		pkg, err := parsePackageJSON(path.Join(homepath, dir, "package.json"))
+		if err != nil {
+			logrus.Warningf("workspace: %s; error parsing package.json: %v", dir, err)
+			continue
+		}
+		// the packages without a start script are libraries
+		if pkg.Scripts["start"] == "" {
+			continue
+		}
+		name := pkg.Name
+		if name == "" {
+			name = path.Base(dir)
+		}
+		res = append(res, &types.Service{
+			ID:    util.NewUUID(),
+			Name:  dir,
+			Cname: path.Base(name),
+			Envs: map[string]*types.Env{
+				"BUILD_PROCFILE": {
+					Name:  "BUILD_PROCFILE",
+					Value: workspaceProcfile(manager, name, dir),
+				},
+			},
+		})
+	}
+	return res, nil
+}
+
+func parsePackageJSON(filename string) (*packageJSON, error) {
+	body, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(body, &pkg); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path.Base(filename), err)
+	}
+	return &pkg, nil
+}
+
+// workspacePatterns returns the workspace globs of pnpm-workspace.yaml or package.json
+func workspacePatterns(homepath string, root *packageJSON) ([]string, error) {
+	if body, err := ioutil.ReadFile(path.Join(homepath, "pnpm-workspace.yaml")); err == nil {
+		var ws pnpmWorkspace
+		if err := yaml.Unmarshal(body, &ws); err != nil {
+			return nil, fmt.Errorf("parse pnpm-workspace.yaml: %v", err)
+		}
+		return ws.Packages, nil
+	}
+	if len(root.Workspaces) == 0 {
+		return nil, nil
+	}
+	// "workspaces" is either a list of globs or an object with packages(yarn)
+	var patterns []string
+	if err := json.Unmarshal(root.Workspaces, &patterns); err == nil {
+		return patterns, nil
+	}
+	var ws struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(root.Workspaces, &ws); err != nil {
+		return nil, fmt.Errorf("parse workspaces of package.json: %v", err)
+	}
+	return ws.Packages, nil
+}
+
+// matchWorkspaces returns the sorted dirs, relative to homepath, matching the workspace globs.
+// A "**" matches the dirs of any depth, and the globs starting with "!" exclude dirs.
+func matchWorkspaces(homepath string, patterns []string) []string {
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./"))
+			continue
+		}
+		if pattern != "" {
+			includes = append(includes, pattern)
+		}
+	}
+	matched := make(map[string]struct{})
+	filepath.Walk(homepath, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if info.Name() == "node_modules" || (p != homepath && strings.HasPrefix(info.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(homepath, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !matchAny(includes, rel) || matchAny(excludes, rel) {
+			return nil
+		}
+		if _, err := os.Stat(path.Join(p, "package.json")); err == nil {
+			matched[rel] = struct{}{}
+		}
+		return nil
+	})
+	var dirs []string
+	for dir := range matched {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func matchAny(patterns []string, dir string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(strings.TrimSuffix(pattern, "/"), "/"), strings.Split(dir, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches the path segments, "**" matches zero or more segments
+func matchGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], segments[1:])
+}
+
+// packageManager returns the package manager according to the lock file
+func packageManager(homepath string) string {
+	if ok, _ := util.FileExists(path.Join(homepath, "pnpm-lock.yaml")); ok {
+		return "pnpm"
+	}
+	if ok, _ := util.FileExists(path.Join(homepath, "pnpm-workspace.yaml")); ok {
+		return "pnpm"
+	}
+	if ok, _ := util.FileExists(path.Join(homepath, "yarn.lock")); ok {
+		return "yarn"
+	}
+	return "npm"
+}
+
+// workspaceProcfile returns the start command of the workspace package,
+// which is run in the root of the repository.
+func workspaceProcfile(manager, name, dir string) string {
+	switch manager {
+	case "pnpm":
+		return fmt.Sprintf("web: pnpm --filter %s start", name)
+	case "yarn":
+		return fmt.Sprintf("web: yarn workspace %s start", name)
+	default:
+		return fmt.Sprintf("web: npm start --workspace=%s", dir)
+	}
+}
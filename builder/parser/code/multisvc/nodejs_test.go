@@ -0,0 +1,114 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package multi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "multisvc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		filename := path.Join(dir, name)
+		if err := os.MkdirAll(path.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNodeJS_ListModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"package.json":                         `{"name": "root", "workspaces": {"packages": ["apps/*", "libs/**", "!libs/legacy"]}}`,
+		"yarn.lock":                            "",
+		"apps/web/package.json":                `{"name": "@shop/web", "scripts": {"start": "next start"}}`,
+		"apps/api/package.json":                `{"name": "@shop/api", "scripts": {"start": "node index.js"}}`,
+		"apps/docs/README.md":                  "",
+		"libs/ui/package.json":                 `{"name": "@shop/ui", "scripts": {"build": "tsc"}}`,
+		"libs/jobs/worker/package.json":        `{"name": "worker", "scripts": {"start": "node worker.js"}}`,
+		"libs/legacy/package.json":             `{"name": "legacy", "scripts": {"start": "node legacy.js"}}`,
+		"apps/web/node_modules/x/package.json": `{"name": "x", "scripts": {"start": "x"}}`,
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewNodeJS().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"apps/api":         "web: yarn workspace @shop/api start",
+		"apps/web":         "web: yarn workspace @shop/web start",
+		"libs/jobs/worker": "web: yarn workspace worker start",
+	}
+	if len(services) != len(want) {
+		t.Fatalf("Expected %d modules, but returned %d", len(want), len(services))
+	}
+	for _, svc := range services {
+		if svc.BuildPath != "" {
+			t.Errorf("Module %s: expected to build from the workspace root, but returned %s", svc.Name, svc.BuildPath)
+		}
+		if procfile := svc.Envs["BUILD_PROCFILE"].Value; procfile != want[svc.Name] {
+			t.Errorf("Module %s: expected procfile %q, but returned %q", svc.Name, want[svc.Name], procfile)
+		}
+	}
+}
+
+func TestNodeJS_ListModulesPnpm(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"package.json":            `{"name": "root"}`,
+		"pnpm-workspace.yaml":     "packages:\n  - 'services/*'\n",
+		"services/a/package.json": `{"name": "a", "scripts": {"start": "node a.js"}}`,
+		"services/b/package.json": `{"name": "b", "scripts": {"start": "node b.js"}}`,
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewNodeJS().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 modules, but returned %d", len(services))
+	}
+	if procfile := services[0].Envs["BUILD_PROCFILE"].Value; procfile != "web: pnpm --filter a start" {
+		t.Errorf("Unexpected procfile %q", procfile)
+	}
+}
+
+func TestNodeJS_ListModulesWithoutWorkspaces(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"package.json": `{"name": "app", "scripts": {"start": "node index.js"}}`,
+	})
+	defer os.RemoveAll(dir)
+
+	services, err := NewNodeJS().ListModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Errorf("Expected no modules, but returned %d", len(services))
+	}
+}
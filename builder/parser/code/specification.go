@@ -19,6 +19,7 @@
 package code
 
 import (
+	"fmt"
 	"io/ioutil"
 	"path"
 	"strings"
@@ -47,6 +48,8 @@ func init() {
 	specification[NodeJSStatic] = nodeCheck
 	specification[Nodejs] = nodeCheck
 	specification[Golang] = golangCheck
+	specification[Rust] = procfileCheck(Rust)
+	specification[Deno] = procfileCheck(Deno)
 }
 
 //CheckCodeSpecification
@@ -110,6 +113,23 @@ func javaMavenCheck(buildPath string) Specification {
 	return common()
 }
 
+//procfileCheck the buildpack of the language does not know the start command, Procfile must be defined
+func procfileCheck(lang Lang) func(buildPath string) Specification {
+	return func(buildPath string) Specification {
+		procfile, spec := checkProcfile(buildPath)
+		if spec != nil {
+			return *spec
+		}
+		if !procfile {
+			return Specification{
+				Conform:   false,
+				Noconform: map[string]string{fmt.Sprintf("Recognized as %s language, Procfile file is not defined", lang): "The main directory defines the Procfile file to specify the start command, reference format:\n web: start command"},
+			}
+		}
+		return common()
+	}
+}
+
 //checkProcfile
 func checkProcfile(buildPath string) (bool, *Specification) {
 	if ok, _ := util.FileExists(path.Join(buildPath, "Procfile")); !ok {
@@ -0,0 +1,235 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package parser
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gridworkz/kato/builder/parser/code"
+	"github.com/gridworkz/kato/builder/parser/types"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/util"
+)
+
+//validateKatofile reports the schema errors of the katofile, returns false if the katofile can not be used
+func (d *SourceCodeParse) validateKatofile(rbdfile *code.KatoFileConfig) bool {
+	valid := true
+	for _, err := range rbdfile.Validate() {
+		errType := FatalError
+		if err.Negligible {
+			errType = NegligibleError
+		} else {
+			valid = false
+		}
+		d.errappend(ErrorAndSolve(errType, fmt.Sprintf("The katofile is invalid, %s", err.Error()),
+			"You can refer to the documentation to configure this file to define application properties"))
+	}
+	return valid
+}
+
+//addKatofileServices adds the services which are only declared in the katofile with their build paths
+func (d *SourceCodeParse) addKatofileServices(rbdfile *code.KatoFileConfig, buildPath string) {
+	d.katoServices = make(map[string]*code.Service, len(rbdfile.Services))
+	exists := make(map[string]struct{}, len(d.services))
+	for _, svc := range d.services {
+		exists[svc.Name] = struct{}{}
+	}
+	for _, svc := range rbdfile.Services {
+		d.katoServices[svc.Name] = svc
+		if _, ok := exists[svc.Name]; ok || svc.BuildPath == "" {
+			continue
+		}
+		lang := code.Lang(svc.Language)
+		if lang == "" {
+			candidates, err := code.DetectLangs(path.Join(buildPath, svc.BuildPath))
+			if err != nil || candidates[0].Lang == code.NO {
+				d.errappend(ErrorAndSolve(NegligibleError, fmt.Sprintf("The language of service %s is not recognized", svc.Name),
+					"Please set the language of the service in the katofile"))
+				continue
+			}
+			lang = candidates[0].Lang
+		}
+		item := &types.Service{
+			ID:        util.NewUUID(),
+			Name:      svc.Name,
+			Cname:     path.Base(svc.Name),
+			BuildPath: svc.BuildPath,
+			Envs:      make(map[string]*types.Env),
+			Ports:     make(map[int]*types.Port),
+		}
+		for k, v := range rbdfile.Envs {
+			item.Envs[k] = &types.Env{Name: k, Value: fmt.Sprintf("%v", v)}
+		}
+		for k, v := range svc.Envs {
+			item.Envs[k] = &types.Env{Name: k, Value: v}
+		}
+		for _, env := range katofileBuildArgs(lang, rbdfile.BuildArgs) {
+			item.Envs[env.Name] = env
+		}
+		ports := append([]code.Port{}, rbdfile.Ports...)
+		for _, port := range append(ports, svc.Ports...) {
+			if port.Protocol == "" {
+				port.Protocol = GetPortProtocol(port.Port)
+			}
+			item.Ports[port.Port] = &types.Port{ContainerPort: port.Port, Protocol: port.Protocol}
+		}
+		if d.serviceLangs == nil {
+			d.serviceLangs = make(map[string]code.Lang)
+		}
+		d.serviceLangs[svc.Name] = lang
+		d.services = append(d.services, item)
+	}
+	if len(d.services) > 1 {
+		d.isMulti = true
+	}
+}
+
+//applyKatofileService applies the katofile definition of the service to its service info
+func applyKatofileService(info *ServiceInfo, svc *code.Service) {
+	for _, env := range katofileBuildArgs(info.Lang, svc.BuildArgs) {
+		envs := info.Envs[:0:0]
+		for _, e := range info.Envs {
+			if e.Name != env.Name {
+				envs = append(envs, e)
+			}
+		}
+		info.Envs = append(envs, *env)
+	}
+	if len(svc.Probes) > 0 {
+		info.Probes = katofileProbes(svc.Probes)
+	}
+	if len(svc.Volumes) > 0 {
+		volumes := make(map[string]*types.Volume)
+		for i := range info.Volumes {
+			volumes[info.Volumes[i].VolumePath] = &info.Volumes[i]
+		}
+		for k, v := range katofileVolumes(svc.Volumes) {
+			volumes[k] = v
+		}
+		paths := make([]string, 0, len(volumes))
+		for volumePath := range volumes {
+			paths = append(paths, volumePath)
+		}
+		sort.Strings(paths)
+		info.Volumes = nil
+		for _, volumePath := range paths {
+			info.Volumes = append(info.Volumes, *volumes[volumePath])
+		}
+	}
+	if memory := svc.Resources.MemoryMB(); memory > 0 {
+		info.Memory = memory
+	}
+	if cpu := svc.Resources.MilliCPU(); cpu > 0 {
+		info.CPU = cpu
+	}
+	if len(svc.Dependencies) > 0 {
+		info.DependServices = svc.Dependencies
+	}
+	if svc.Cmd != "" {
+		info.Args = strings.Split(svc.Cmd, " ")
+	}
+}
+
+//katofileBuildArgs returns the build args as the build envs,
+//they are the args of the dockerfile, or the build envs of the other languages.
+func katofileBuildArgs(lang code.Lang, args map[string]string) []*types.Env {
+	prefix := "BUILD_"
+	if lang == code.Dockerfile {
+		prefix = "BUILD_ARG_"
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var envs []*types.Env
+	for _, k := range keys {
+		envs = append(envs, &types.Env{Name: prefix + k, Value: args[k]})
+	}
+	return envs
+}
+
+//katofileProbes converts the probes of the katofile to TenantServiceProbe
+func katofileProbes(probes []*code.Probe) []*dbmodel.TenantServiceProbe {
+	var res []*dbmodel.TenantServiceProbe
+	for _, probe := range probes {
+		isUsed := 1
+		p := &dbmodel.TenantServiceProbe{
+			ProbeID:            util.NewUUID(),
+			Mode:               probe.Mode,
+			Scheme:             probe.Scheme,
+			Path:               probe.Path,
+			Port:               probe.Port,
+			InitialDelaySecond: probe.InitialDelaySecond,
+			PeriodSecond:       probe.PeriodSecond,
+			TimeoutSecond:      probe.TimeoutSecond,
+			FailureThreshold:   probe.FailureThreshold,
+			SuccessThreshold:   probe.SuccessThreshold,
+			FailureAction:      probe.FailureAction,
+			IsUsed:             &isUsed,
+		}
+		var headers []string
+		for k, v := range probe.HTTPHeader {
+			headers = append(headers, k+"="+v)
+		}
+		sort.Strings(headers)
+		p.HTTPHeader = strings.Join(headers, ",")
+		// the same defaults as the table tenant_services_probe
+		if p.InitialDelaySecond == 0 {
+			p.InitialDelaySecond = 4
+		}
+		if p.PeriodSecond == 0 {
+			p.PeriodSecond = 3
+		}
+		if p.TimeoutSecond == 0 {
+			p.TimeoutSecond = 5
+		}
+		if p.FailureThreshold == 0 {
+			p.FailureThreshold = 3
+		}
+		if p.SuccessThreshold == 0 {
+			p.SuccessThreshold = 1
+		}
+		if p.FailureAction == "" {
+			p.FailureAction = p.Mode
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+//katofileVolumes converts the volumes of the katofile, the key is the volume path
+func katofileVolumes(volumes []*code.Volume) map[string]*types.Volume {
+	res := make(map[string]*types.Volume, len(volumes))
+	for _, volume := range volumes {
+		volumeType := volume.Type
+		if volumeType == "" {
+			volumeType = dbmodel.ShareFileVolumeType.String()
+		}
+		res[volume.Path] = &types.Volume{
+			VolumePath:     volume.Path,
+			VolumeType:     volumeType,
+			VolumeName:     volume.Name,
+			VolumeCapacity: volume.Capacity,
+		}
+	}
+	return res
+}
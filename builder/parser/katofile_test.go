@@ -0,0 +1,72 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package parser
+
+import (
+	"testing"
+
+	"github.com/gridworkz/kato/builder/parser/code"
+	"github.com/gridworkz/kato/builder/parser/types"
+)
+
+func TestKatofileProbes(t *testing.T) {
+	probes := katofileProbes([]*code.Probe{
+		{Mode: "readiness", Scheme: "http", Path: "/health", Port: 8080, HTTPHeader: map[string]string{"b": "2", "a": "1"}},
+		{Mode: "liveness", Scheme: "tcp", Port: 8080, PeriodSecond: 10, FailureAction: "ignore"},
+	})
+	readiness, liveness := probes[0], probes[1]
+	if readiness.HTTPHeader != "a=1,b=2" {
+		t.Errorf("Expected http header a=1,b=2, but returned %s", readiness.HTTPHeader)
+	}
+	if readiness.FailureAction != "readiness" || readiness.InitialDelaySecond != 4 || readiness.TimeoutSecond != 5 {
+		t.Errorf("Expected the default values, but returned %+v", readiness)
+	}
+	if liveness.PeriodSecond != 10 || liveness.FailureAction != "ignore" || *liveness.IsUsed != 1 {
+		t.Errorf("Unexpected liveness probe %+v", liveness)
+	}
+}
+
+func TestKatofileBuildArgs(t *testing.T) {
+	args := map[string]string{"VERSION": "1.0"}
+	if envs := katofileBuildArgs(code.Dockerfile, args); envs[0].Name != "BUILD_ARG_VERSION" {
+		t.Errorf("Expected BUILD_ARG_VERSION for dockerfile, but returned %s", envs[0].Name)
+	}
+	if envs := katofileBuildArgs(code.Nodejs, args); envs[0].Name != "BUILD_VERSION" {
+		t.Errorf("Expected BUILD_VERSION for nodejs, but returned %s", envs[0].Name)
+	}
+}
+
+func TestApplyKatofileServiceVolumes(t *testing.T) {
+	info := &ServiceInfo{Volumes: []types.Volume{{VolumePath: "/logs", VolumeType: "share-file"}, {VolumePath: "/data", VolumeType: "share-file"}}}
+	applyKatofileService(info, &code.Service{Volumes: []*code.Volume{
+		{Path: "/data", Type: "local", Capacity: 5},
+		{Path: "/cache", Type: "memoryfs"},
+	}})
+	want := []string{"/cache", "/data", "/logs"}
+	if len(info.Volumes) != len(want) {
+		t.Fatalf("Expected %d volumes, but returned %d", len(want), len(info.Volumes))
+	}
+	for i, path := range want {
+		if info.Volumes[i].VolumePath != path {
+			t.Errorf("Expected volume %d to be %s, but returned %s", i, path, info.Volumes[i].VolumePath)
+		}
+	}
+	if info.Volumes[1].VolumeType != "local" || info.Volumes[1].VolumeCapacity != 5 {
+		t.Errorf("Expected the katofile to override the volume /data, but returned %+v", info.Volumes[1])
+	}
+}
@@ -191,6 +191,16 @@ type ServiceInfo struct {
 	Name      string `json:"name,omitempty"`  // module name
 	Cname     string `json:"cname,omitempty"` // service cname
 	Packaging string `json:"packaging,omitempty"`
+	BuildPath string `json:"build_path,omitempty"` // module dir in the repository
+
+	// LangCandidates all the languages detected, the most likely first
+	LangCandidates []code.LangCandidate `json:"language_candidates,omitempty"`
+
+	// Probes the health checks declared in the katofile
+	Probes []*dbmodel.TenantServiceProbe `json:"probes,omitempty"`
+
+	// CPU the cpu request in millicores
+	CPU int `json:"cpu,omitempty"`
 }
 
 //GetServiceInfo
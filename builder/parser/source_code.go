@@ -57,12 +57,21 @@ type SourceCodeParse struct {
 	logger  event.Logger
 	Lang    code.Lang
 
+	langCandidates []code.LangCandidate
+
 	Runtime      bool `json:"runtime"`
 	Dependencies bool `json:"dependencies"`
 	Procfile     bool `json:"procfile"`
 
 	isMulti  bool
 	services []*types.Service
+
+	probes []*model.TenantServiceProbe
+	cpu    int
+	// the services defined in the katofile
+	katoServices map[string]*code.Service
+	// the languages of the services only declared in the katofile
+	serviceLangs map[string]code.Lang
 }
 
 //CreateSourceCodeParse create parser
@@ -275,6 +284,9 @@ func (d *SourceCodeParse) Parse() ParseErrorList {
 			d.errappend(ErrorAndSolve(NegligibleError, "The katofile definition format is wrong", "You can refer to the documentation to configure this file to define application properties"))
 		}
 	}
+	if rbdfileConfig != nil && !d.validateKatofile(rbdfileConfig) {
+		return d.errors
+	}
 	//Judgment target directory
 	var buildPath = buildInfo.GetCodeBuildAbsPath()
 	//Parse the code type
@@ -282,7 +294,7 @@ func (d *SourceCodeParse) Parse() ParseErrorList {
 	if rbdfileConfig != nil && rbdfileConfig.Language != "" {
 		lang = code.Lang(rbdfileConfig.Language)
 	} else {
-		lang, err = code.GetLangType(buildPath)
+		candidates, err := code.DetectLangs(buildPath)
 		if err != nil {
 			if err == code.ErrCodeDirNotExist {
 				d.errappend(ErrorAndSolve(FatalError, "The source directory does not exist", "Failed to get code task, please contact customer service"))
@@ -293,6 +305,9 @@ func (d *SourceCodeParse) Parse() ParseErrorList {
 			}
 			return d.errors
 		}
+		// the code of a monorepo may be detected as several languages
+		lang = candidates[0].Lang
+		d.langCandidates = candidates
 	}
 	d.Lang = lang
 	if lang == code.NO {
@@ -399,11 +414,13 @@ func (d *SourceCodeParse) Parse() ParseErrorList {
 				}
 			}
 		}
-
-		if rbdfileConfig != nil && d.isMulti {
-			rbdfileConfig.Envs = nil
-			rbdfileConfig.Ports = nil
-		}
+	}
+	if rbdfileConfig != nil {
+		d.addKatofileServices(rbdfileConfig, buildPath)
+	}
+	if rbdfileConfig != nil && d.isMulti {
+		rbdfileConfig.Envs = nil
+		rbdfileConfig.Ports = nil
 	}
 
 	if rbdfileConfig != nil {
@@ -424,6 +441,19 @@ func (d *SourceCodeParse) Parse() ParseErrorList {
 		if rbdfileConfig.Cmd != "" {
 			d.args = strings.Split(rbdfileConfig.Cmd, " ")
 		}
+		for _, env := range katofileBuildArgs(lang, rbdfileConfig.BuildArgs) {
+			d.envs[env.Name] = env
+		}
+		if len(rbdfileConfig.Probes) > 0 {
+			d.probes = katofileProbes(rbdfileConfig.Probes)
+		}
+		for k, v := range katofileVolumes(rbdfileConfig.Volumes) {
+			d.volumes[k] = v
+		}
+		if memory := rbdfileConfig.Resources.MemoryMB(); memory > 0 {
+			d.memory = memory
+		}
+		d.cpu = rbdfileConfig.Resources.MilliCPU()
 	}
 	return d.errors
 }
@@ -448,7 +478,7 @@ func ReadRbdConfigAndLang(buildInfo *sources.RepostoryBuildInfo) (*code.KatoFile
 
 func getRecommendedMemory(lang code.Lang) int {
 	//java recommended 1024
-	if lang == code.JavaJar || lang == code.JavaMaven || lang == code.JaveWar || lang == code.Gradle {
+	if lang == code.JavaJar || lang == code.JavaMaven || lang == code.JaveWar || lang == code.Gradle || lang == code.Kotlin {
 		return 1024
 	}
 	if lang == code.Python {
@@ -535,6 +565,9 @@ func (d *SourceCodeParse) GetServiceInfo() []ServiceInfo {
 		ServiceType: model.ServiceTypeStatelessMultiple.String(),
 		OS:          runtime.GOOS,
 	}
+	serviceInfo.LangCandidates = d.langCandidates
+	serviceInfo.Probes = d.probes
+	serviceInfo.CPU = d.cpu
 	var res []ServiceInfo
 	if d.isMulti && d.services != nil && len(d.services) > 0 {
 		for idx := range d.services {
@@ -544,12 +577,30 @@ func (d *SourceCodeParse) GetServiceInfo() []ServiceInfo {
 			info.Name = svc.Name
 			info.Cname = svc.Cname
 			info.Packaging = svc.Packaging
+			info.BuildPath = svc.BuildPath
+			baseEnvs := serviceInfo.Envs
+			if lang, ok := d.serviceLangs[svc.Name]; ok {
+				// the service only declared in the katofile does not share the build envs of the repository
+				info.Lang = lang
+				info.Memory = getRecommendedMemory(lang)
+				baseEnvs = nil
+			}
+			// the envs of the module override the ones of the repository
+			info.Envs = nil
+			for _, env := range baseEnvs {
+				if _, ok := svc.Envs[env.Name]; !ok {
+					info.Envs = append(info.Envs, env)
+				}
+			}
 			for i := range svc.Envs {
 				info.Envs = append(info.Envs, *svc.Envs[i])
 			}
 			for i := range svc.Ports {
 				info.Ports = append(info.Ports, *svc.Ports[i])
 			}
+			if katoService := d.katoServices[svc.Name]; katoService != nil {
+				applyKatofileService(&info, katoService)
+			}
 			res = append(res, info)
 		}
 	} else {
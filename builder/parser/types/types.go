@@ -24,6 +24,7 @@ type Service struct {
 	Name      string          `json:"name"`  // module name
 	Cname     string          `json:"cname"` // service cname
 	Packaging string          `json:"packaging"`
+	BuildPath string          `json:"build_path,omitempty"` // module dir in the repository, empty to build from the repository root
 	Envs      map[string]*Env `json:"envs,omitempty"`
 	Ports     map[int]*Port   `json:"ports,omitempty"`
 }
@@ -36,8 +37,10 @@ type Port struct {
 
 //Volume -
 type Volume struct {
-	VolumePath string `json:"volume_path"`
-	VolumeType string `json:"volume_type"`
+	VolumePath     string `json:"volume_path"`
+	VolumeType     string `json:"volume_type"`
+	VolumeName     string `json:"volume_name,omitempty"`
+	VolumeCapacity int64  `json:"volume_capacity,omitempty"` // GB
 }
 
 //Env env desc
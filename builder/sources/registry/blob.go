@@ -0,0 +1,173 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package registry
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// HasBlob checks if the blob exists in the repository
+func (registry *Registry) HasBlob(repository string, dig digest.Digest) (bool, error) {
+	blobURL := registry.url("/v2/%s/blobs/%s", repository, dig)
+	registry.Logf("registry.blob.head url=%s repository=%s digest=%s", blobURL, repository, dig)
+
+	resp, err := registry.Client.Head(blobURL)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		if statusErr, ok := err.(*HttpStatusError); ok && statusErr.Response.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, &HttpStatusError{Response: resp}
+}
+
+// DownloadBlob downloads the blob, the caller must close the content
+func (registry *Registry) DownloadBlob(repository string, dig digest.Digest) (io.ReadCloser, error) {
+	url := registry.url("/v2/%s/blobs/%s", repository, dig)
+	registry.Logf("registry.blob.download url=%s repository=%s digest=%s", url, repository, dig)
+
+	resp, err := registry.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &HttpStatusError{Response: resp, Body: body}
+	}
+	return resp.Body, nil
+}
+
+// MountBlob mounts the blob of another repository in the same registry,
+// returns false if the registry does not mount it.
+func (registry *Registry) MountBlob(repository, from string, dig digest.Digest) (bool, error) {
+	url := registry.url("/v2/%s/blobs/uploads/?mount=%s&from=%s", repository, dig, from)
+	registry.Logf("registry.blob.mount url=%s repository=%s from=%s digest=%s", url, repository, from, dig)
+
+	resp, err := registry.Client.Post(url, "application/octet-stream", nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return true, nil
+	case http.StatusAccepted:
+		// an upload is started instead, cancel it
+		if location, err := registry.location(resp); err == nil {
+			if req, err := http.NewRequest("DELETE", location, nil); err == nil {
+				if resp, err := registry.Client.Do(req); err == nil {
+					resp.Body.Close()
+				}
+			}
+		}
+		return false, nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	return false, &HttpStatusError{Response: resp, Body: body}
+}
+
+// UploadBlobFile uploads the file as the blob in a single request
+func (registry *Registry) UploadBlobFile(repository string, dig digest.Digest, filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	uploadURL := registry.url("/v2/%s/blobs/uploads/", repository)
+	registry.Logf("registry.blob.upload url=%s repository=%s digest=%s", uploadURL, repository, dig)
+
+	resp, err := registry.Client.Post(uploadURL, "application/octet-stream", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return &HttpStatusError{Response: resp}
+	}
+	location, err := registry.location(resp)
+	if err != nil {
+		return err
+	}
+	putURL, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+	query := putURL.Query()
+	query.Set("digest", dig.String())
+	putURL.RawQuery = query.Encode()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	req, err := http.NewRequest("PUT", putURL.String(), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	// the token transport resends the body
+	req.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(filename)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = registry.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &HttpStatusError{Response: resp, Body: body}
+	}
+	return nil
+}
+
+// location returns the absolute url of the upload location
+func (registry *Registry) location(resp *http.Response) (string, error) {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("no upload location in the response")
+	}
+	base, err := url.Parse(registry.URL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
@@ -161,3 +161,52 @@ func (registry *Registry) PutManifest(repository, reference string, signedManife
 	}
 	return err
 }
+
+// ManifestRaw gets the manifest in one of the accepted media types, returns the body and its media type.
+func (registry *Registry) ManifestRaw(repository, reference string, accept ...string) ([]byte, string, error) {
+	url := registry.url("/v2/%s/manifests/%s", repository, reference)
+	registry.Logf("registry.manifest.get url=%s repository=%s reference=%s", url, repository, reference)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, mediaType := range accept {
+		req.Header.Add("Accept", mediaType)
+	}
+	resp, err := registry.Client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", &HttpStatusError{Response: resp, Body: body}
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// PutManifestRaw puts the manifest of the given media type, such as an OCI image manifest.
+func (registry *Registry) PutManifestRaw(repository, reference, mediaType string, manifest []byte) error {
+	url := registry.url("/v2/%s/manifests/%s", repository, reference)
+	registry.Logf("registry.manifest.put url=%s repository=%s reference=%s", url, repository, reference)
+
+	req, err := http.NewRequest("PUT", url, bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mediaType)
+	resp, err := registry.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &HttpStatusError{Response: resp, Body: body}
+	}
+	return nil
+}
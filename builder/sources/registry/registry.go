@@ -65,7 +65,7 @@ type Registry struct {
  */
 func New(registryURL, username, password string) (*Registry, error) {
 	transport := http.DefaultTransport
-	return newFromTransport(registryURL, username, password, transport, Log)
+	return newFromTransport(registryURL, username, password, transport, Log, false)
 }
 
 //NewInsecure new insecure skip verify tls client
@@ -80,7 +80,21 @@ func NewInsecure(registryURL, username, password string) (*Registry, error) {
 		},
 	}
 
-	return newFromTransport(registryURL, username, password, transport, Log)
+	return newFromTransport(registryURL, username, password, transport, Log, false)
+}
+
+//NewAuthorized new registry client which always answers the auth challenges of the registry,
+//the anonymous pulls from the public registries such as docker hub require a bearer token too.
+func NewAuthorized(registryURL, username, password string, insecure bool) (*Registry, error) {
+	transport := http.DefaultTransport
+	if insecure {
+		transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+	return newFromTransport(registryURL, username, password, transport, Log, true)
 }
 
 // WrapTransport returns an existing http.RoundTripper such as http.DefaultTransport,
@@ -105,7 +119,7 @@ func WrapTransport(transport http.RoundTripper, url, username, password string)
 	return errorTransport
 }
 
-func newFromTransport(registryURL, username, password string, transport http.RoundTripper, logf LogfCallback) (*Registry, error) {
+func newFromTransport(registryURL, username, password string, transport http.RoundTripper, logf LogfCallback, authorized bool) (*Registry, error) {
 	url := strings.TrimSuffix(registryURL, "/")
 	containsScheme := strings.HasPrefix(url, "http")
 	if !containsScheme {
@@ -113,7 +127,7 @@ func newFromTransport(registryURL, username, password string, transport http.Rou
 		url = fmt.Sprintf("https://%s", registryURL)
 	}
 
-	if username != "" {
+	if username != "" || authorized {
 		transport = WrapTransport(transport, url, username, password)
 	}
 	registry := &Registry{
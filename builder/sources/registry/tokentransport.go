@@ -88,6 +88,14 @@ func (t *TokenTransport) auth(authService *authService) (string, *http.Response,
 
 func (t *TokenTransport) retry(req *http.Request, token string) (*http.Response, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	// the body was read by the unauthorized request
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
 	resp, err := t.Transport.RoundTrip(req)
 	return resp, err
 }
@@ -55,6 +55,14 @@ type Config struct {
 	CachePVCName         string
 	CacheMode            string
 	CachePath            string
+	BackupProvider       string
+	BackupEndpoint       string
+	BackupAccessKey      string
+	BackupSecretKey      string
+	BackupUseSSL         bool
+	BackupBucketName     string
+	ImageBuilder         string
+	OCILayoutPath        string
 }
 
 //Builder server
@@ -98,6 +106,14 @@ func (a *Builder) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.CachePVCName, "pvc-cache-name", "cache", "pvc name of cache")
 	fs.StringVar(&a.CacheMode, "cache-mode", "sharefile", "volume cache mount type, can be hostpath and sharefile, default is sharefile, which mount using pvc")
 	fs.StringVar(&a.CachePath, "cache-path", "/cache", "volume cache mount path, when cache-mode using hostpath, default path is /cache")
+	fs.StringVar(&a.BackupProvider, "backup.provider", "", "object storage provider of the volume backups, s3 or alioss")
+	fs.StringVar(&a.BackupEndpoint, "backup.endpoint", "", "object storage endpoint of the volume backups")
+	fs.StringVar(&a.BackupAccessKey, "backup.access-key", "", "object storage access key of the volume backups")
+	fs.StringVar(&a.BackupSecretKey, "backup.secret-key", "", "object storage secret key of the volume backups")
+	fs.BoolVar(&a.BackupUseSSL, "backup.use-ssl", false, "whether to access the object storage of the volume backups with ssl")
+	fs.StringVar(&a.BackupBucketName, "backup.bucket", "kato-volume-backups", "object storage bucket of the volume backups")
+	fs.StringVar(&a.ImageBuilder, "image-builder", "docker", "the backend building the images, docker or oci. oci assembles the images into oci layouts and pushes them without the docker daemon")
+	fs.StringVar(&a.OCILayoutPath, "oci-layout-path", "/cache/oci", "the dir of the oci layouts, the layers of the base images are cached in it")
 }
 
 //SetLog
@@ -118,5 +134,8 @@ func (a *Builder) CheckConfig() error {
 	if runtime.GOOS == "windows" {
 		a.Topic = "windows_builder"
 	}
+	if a.ImageBuilder != "docker" && a.ImageBuilder != "oci" {
+		return fmt.Errorf("image builder is only support `docker` and `oci`")
+	}
 	return nil
 }
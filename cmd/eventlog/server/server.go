@@ -109,6 +109,18 @@ func (s *LogServer) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&s.Conf.EventStore.DB.PoolSize, "db.pool.size", 3, "Data persistence db pool init size.")
 	fs.IntVar(&s.Conf.EventStore.DB.PoolMaxSize, "db.pool.maxsize", 10, "Data persistence db pool max size.")
 	fs.StringVar(&s.Conf.EventStore.DB.HomePath, "docker.log.homepath", "/grdata/logs/", "container log persistent home path")
+	fs.StringVar(&s.Conf.EventStore.DB.EventPlugin, "eventlog.persistence", "eventfile", "event log persistence plugin, eventfile or eventsqlite")
+	fs.StringVar(&s.Conf.EventStore.DB.DockerLogPlugin, "dockerlog.persistence", "file", "container log persistence plugin, file or sqlite")
+	fs.StringVar(&s.Conf.EventStore.DB.SQLitePath, "db.sqlite.path", "", "sqlite database file of the sqlite persistence plugins, default is eventlog.db in the log home path")
+	fs.IntVar(&s.Conf.EventStore.DB.SQLiteKeepDays, "db.sqlite.keep-days", 30, "the logs older than the days are deleted from the sqlite database, the logs not archived yet are kept")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.Provider, "archive.provider", "", "object storage provider of the log archive, s3 or alioss. archive is disabled if not set")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.Endpoint, "archive.endpoint", "", "object storage endpoint of the log archive")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.AccessKey, "archive.access-key", "", "object storage access key of the log archive")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.SecretKey, "archive.secret-key", "", "object storage secret key of the log archive")
+	fs.BoolVar(&s.Conf.EventStore.DB.Archive.UseSSL, "archive.use-ssl", false, "whether to access the object storage of the log archive with ssl")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.BucketName, "archive.bucket", "kato-logs", "object storage bucket of the log archive")
+	fs.StringVar(&s.Conf.EventStore.DB.Archive.Location, "archive.location", "", "object storage location of the log archive")
+	fs.IntVar(&s.Conf.EventStore.DB.Archive.KeepDays, "archive.keep-days", 7, "the logs older than the days are moved to the log archive")
 	fs.StringVar(&s.Conf.Entry.NewMonitorMessageServerConf.ListenerHost, "monitor.udp.host", "0.0.0.0", "receive new monitor udp server host")
 	fs.IntVar(&s.Conf.Entry.NewMonitorMessageServerConf.ListenerPort, "monitor.udp.port", 6166, "receive new monitor udp server port")
 	fs.StringVar(&s.Conf.Cluster.Discover.NodeID, "node-id", "", "the unique ID for this node.")
@@ -49,6 +49,7 @@ type Config struct {
 	EtcdCaFile   string
 	EtcdCertFile string
 	EtcdKeyFile  string
+	MQAPI        string
 	ListenPorts  ListenPorts
 	//this number should be, at maximum, the number of CPU cores on your system.
 	WorkerProcesses    int
@@ -115,6 +116,7 @@ func (g *GWServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&g.EtcdCaFile, "etcd-ca", "", "etcd tls ca file ")
 	fs.StringVar(&g.EtcdCertFile, "etcd-cert", "", "etcd tls cert file")
 	fs.StringVar(&g.EtcdKeyFile, "etcd-key", "", "etcd http tls cert key file")
+	fs.StringVar(&g.MQAPI, "mq-api", "127.0.0.1:6300", "acp_mq api, the wake tasks of idle components are sent to the worker by it")
 	// health check
 	fs.StringVar(&g.HealthPath, "health-path", "/healthz", "absolute path to the kubeconfig file")
 	fs.DurationVar(&g.HealthCheckTimeout, "health-check-timeout", 10, `Time limit, in seconds, for a probe to health-check-path to succeed.`)
@@ -40,6 +40,8 @@ import (
 	"github.com/gridworkz/kato/gateway/cluster"
 	"github.com/gridworkz/kato/gateway/controller"
 	"github.com/gridworkz/kato/gateway/metric"
+	"github.com/gridworkz/kato/gateway/waker"
+	"github.com/gridworkz/kato/mq/client"
 	"github.com/gridworkz/kato/util"
 
 	etcdutil "github.com/gridworkz/kato/util/etcd"
@@ -110,6 +112,14 @@ func Run(s *option.GWServer) error {
 	}
 	defer gwc.Close()
 
+	// wake up the idle components held by the openresty
+	mqClient, err := client.NewMqClient(etcdClientArgs, s.Config.MQAPI)
+	if err != nil {
+		return fmt.Errorf("create mq client: %v", err)
+	}
+	defer mqClient.Close()
+	go waker.New(s.ListenPorts.Status, mqClient).Run(ctx)
+
 	mux := chi.NewMux()
 	registerHealthz(gwc, mux)
 	registerMetrics(reg, mux)
@@ -36,6 +36,11 @@ type Config struct {
 	RunMode              string //http grpc
 	HostIP               string
 	HostName             string
+	Backend              string //etcd bolt memory
+	DataPath             string
+	VisibilityTimeout    int
+	TopicTimeouts        map[string]int
+	MaxRetry             int
 }
 
 //MQServer lb worker server
@@ -63,6 +68,11 @@ func (a *MQServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
 	fs.StringVar(&a.HostIP, "hostIP", "", "Current node Intranet IP")
 	fs.StringVar(&a.HostName, "hostName", "", "Current node host name")
+	fs.StringVar(&a.Backend, "backend", "etcd", "the message queue storage backend, etcd, bolt or memory")
+	fs.StringVar(&a.DataPath, "data-path", "/grdata/mq", "the data directory of the bolt message queue backend")
+	fs.IntVar(&a.VisibilityTimeout, "visibility-timeout", 3600, "seconds a dequeued task can stay unacked before it is requeued, 0 means the task is removed once dequeued")
+	fs.StringToIntVar(&a.TopicTimeouts, "topic-visibility-timeout", map[string]int{"builder": 7200, "windows_builder": 7200}, "the visibility timeout seconds of the topics, like builder=7200,worker=1800")
+	fs.IntVar(&a.MaxRetry, "max-retry", 3, "the max times a task is requeued before it is moved to the dead letter topic")
 }
 
 //SetLog
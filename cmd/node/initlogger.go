@@ -19,6 +19,9 @@
 package main
 
 import (
+	_ "github.com/gridworkz/kato/node/nodem/logger/httplog"
+	_ "github.com/gridworkz/kato/node/nodem/logger/loki"
 	_ "github.com/gridworkz/kato/node/nodem/logger/streamlog"
+	_ "github.com/gridworkz/kato/node/nodem/logger/syslog"
 	_ "github.com/gridworkz/kato/node/nodem/logger/testlog"
 )
@@ -35,6 +35,7 @@ import (
 	"github.com/gridworkz/kato/node/api/controller"
 	"github.com/gridworkz/kato/node/core/store"
 	"github.com/gridworkz/kato/node/kubecache"
+	"github.com/gridworkz/kato/node/localvolume"
 	"github.com/gridworkz/kato/node/masterserver"
 	"github.com/gridworkz/kato/node/nodem"
 	"github.com/gridworkz/kato/node/nodem/docker"
@@ -122,6 +123,10 @@ func Run(cfg *option.Conf) error {
 		if err = store.NewClient(ctx, cfg, etcdClientArgs); err != nil {
 			return fmt.Errorf("Connect to ETCD %s failed: %s", cfg.EtcdEndpoints, err)
 		}
+		// the loop images of the local volumes are not mounted after the node is restarted
+		if err := localvolume.Default().Remount(); err != nil {
+			logrus.Errorf("remount local volumes: %v", err)
+		}
 		errChan := make(chan error, 3)
 		if err := nodemanager.Start(errChan); err != nil {
 			return fmt.Errorf("start node manager failed: %s", err)
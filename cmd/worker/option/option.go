@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -40,6 +41,7 @@ type Config struct {
 	MysqlConnectionInfo     string
 	DBType                  string
 	PrometheusMetricPath    string
+	PrometheusEndpoint      string
 	EventLogServers         []string
 	KubeConfig              string
 	KubeAPIQPS              int
@@ -55,6 +57,7 @@ type Config struct {
 	LeaderElectionIdentity  string
 	RBDNamespace            string
 	GrdataPVCName           string
+	DriftCheckInterval      time.Duration
 	Helm                    Helm
 }
 
@@ -89,6 +92,7 @@ func (a *Worker) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.EtcdPrefix, "etcd-prefix", "/store", "the etcd data save key prefix ")
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
 	fs.StringVar(&a.Listen, "listen", ":6369", "prometheus listen host and port")
+	fs.StringVar(&a.PrometheusEndpoint, "prom-api", "rbd-monitor:9999", "The service DNS name of Prometheus api, used to watch the metrics of canary release")
 	fs.StringVar(&a.DBType, "db-type", "mysql", "db type mysql or etcd")
 	fs.StringVar(&a.MysqlConnectionInfo, "mysql", "root:admin@tcp(127.0.0.1:3306)/region", "mysql db connection info")
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"127.0.0.1:6366"}, "event log server address. simple lb")
@@ -105,6 +109,7 @@ func (a *Worker) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.LeaderElectionIdentity, "leader-election-identity", "", "Unique idenity of this attcher. Typically name of the pod where the attacher runs.")
 	fs.StringVar(&a.RBDNamespace, "rbd-system-namespace", "rbd-system", "rbd components kubernetes namespace")
 	fs.StringVar(&a.GrdataPVCName, "grdata-pvc-name", "rbd-cpt-grdata", "The name of grdata persistent volume claim")
+	fs.DurationVar(&a.DriftCheckInterval, "drift-check-interval", 5*time.Minute, "The interval to compare the components in the database with their live workloads")
 	fs.StringVar(&a.Helm.DataDir, "helm-data-dir", "helm-data-dir", "The data directory of Helm.")
 
 	if a.Helm.DataDir == "" {
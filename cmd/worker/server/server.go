@@ -24,10 +24,12 @@ import (
 	"syscall"
 
 	"github.com/eapache/channels"
+	"github.com/gridworkz/kato/api/client/prometheus"
 	"github.com/gridworkz/kato/cmd/worker/option"
 	"github.com/gridworkz/kato/db"
 	"github.com/gridworkz/kato/db/config"
 	"github.com/gridworkz/kato/event"
+	mqclient "github.com/gridworkz/kato/mq/client"
 	"github.com/gridworkz/kato/pkg/common"
 	"github.com/gridworkz/kato/pkg/generated/clientset/versioned"
 	etcdutil "github.com/gridworkz/kato/util/etcd"
@@ -105,11 +107,24 @@ func Run(s *option.Worker) error {
 	}
 
 	//step 5: create controller manager
-	controllerManager := controller.NewManager(cachestore, clientset, runtimeClient)
+	prometheusCli, err := prometheus.NewPrometheus(&prometheus.Options{
+		Endpoint: s.Config.PrometheusEndpoint,
+	})
+	if err != nil {
+		logrus.Errorf("create prometheus client error: %s", err.Error())
+		return err
+	}
+	controllerManager := controller.NewManager(cachestore, clientset, runtimeClient, prometheusCli)
 	defer controllerManager.Stop()
 
 	//step 6 : start runtime master
-	masterCon, err := master.NewMasterController(s.Config, cachestore, clientset, katoClient, restConfig)
+	mqClient, err := mqclient.NewMqClient(etcdClientArgs, s.Config.MQAPI)
+	if err != nil {
+		logrus.Errorf("new Mq client error, %v", err)
+		return err
+	}
+	defer mqClient.Close()
+	masterCon, err := master.NewMasterController(s.Config, cachestore, clientset, katoClient, restConfig, mqClient, prometheusCli)
 	if err != nil {
 		return err
 	}
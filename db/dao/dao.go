@@ -426,12 +426,21 @@ type EventDao interface {
 	GetEventsByTenantID(tenantID string, offset, limit int) ([]*model.ServiceEvent, int, error)
 	GetLastASyncEvent(target, targetID string) (*model.ServiceEvent, error)
 	UnfinishedEvents(target, targetID string, optTypes ...string) ([]*model.ServiceEvent, error)
+	CountUnfinishedEventsByTenant(tenantID, optType string, since time.Time) (int, error)
 	LatestFailurePodEvent(podName string) (*model.ServiceEvent, error)
 	UpdateReason(eventID string, reason string) error
 	SetEventStatus(ctx context.Context, status model.EventStatus) error
 	UpdateInBatch(events []*model.ServiceEvent) error
 }
 
+// BuildCacheDao -
+type BuildCacheDao interface {
+	Dao
+	GetByCacheKey(serviceID, cacheKey string) (*model.BuildCache, error)
+	DeleteByBuildVersion(serviceID, buildVersion string) error
+	DeleteByServiceID(serviceID string) error
+}
+
 // VersionInfoDao VersionInfoDao
 type VersionInfoDao interface {
 	Dao
@@ -459,6 +468,23 @@ type RegionUserInfoDao interface {
 	GetTokenByTokenID(token string) (*model.RegionUserInfo, error)
 }
 
+//APITokenDao -
+type APITokenDao interface {
+	Dao
+	GetByName(name string) (*model.APIToken, error)
+	GetByTokenHash(tokenHash string) (*model.APIToken, error)
+	List() ([]*model.APIToken, error)
+	DeleteByName(name string) error
+}
+
+//AuditLogDao the audit logs are only appended
+type AuditLogDao interface {
+	Append(log *model.AuditLog) error
+	ListAuditLogs(tenantID, serviceID, principal string, start, end time.Time, page, pageSize int) ([]*model.AuditLog, int64, error)
+	ListAuditLogsAfter(seq int64, limit int) ([]*model.AuditLog, error)
+	GetAuditLogBySeq(seq int64) (*model.AuditLog, error)
+}
+
 //RegionAPIClassDao RegionAPIClassDao
 type RegionAPIClassDao interface {
 	Dao
@@ -538,6 +564,7 @@ type TCPRuleDao interface {
 	DeleteByComponentPort(componentID string, port int) error
 	DeleteByComponentIDs(componentIDs []string) error
 	CreateOrUpdateTCPRuleInBatch(tcpRules []*model.TCPRule) error
+	ListByComponentIDs(componentIDs []string) ([]*model.TCPRule, error)
 }
 
 // EndpointsDao is an interface for defining method
@@ -592,6 +619,41 @@ type TenantServceAutoscalerRuleMetricsDao interface {
 	CreateOrUpdateScaleRuleMetricsInBatch(metrics []*model.TenantServiceAutoscalerRuleMetrics) error
 }
 
+// TenantServiceAutoscalerSchedulesDao -
+type TenantServiceAutoscalerSchedulesDao interface {
+	Dao
+	GetByScheduleID(scheduleID string) (*model.TenantServiceAutoscalerSchedules, error)
+	ListByServiceID(serviceID string) ([]*model.TenantServiceAutoscalerSchedules, error)
+	ListEnableOnes() ([]*model.TenantServiceAutoscalerSchedules, error)
+	DeleteByScheduleID(scheduleID string) error
+	DeleteByComponentIDs(componentIDs []string) error
+}
+
+// TenantServiceIdlePolicyDao -
+type TenantServiceIdlePolicyDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceIdlePolicy, error)
+	ListEnableOnes() ([]*model.TenantServiceIdlePolicy, error)
+	DeleteByComponentIDs(componentIDs []string) error
+}
+
+// TenantServiceVolumeBackupDao -
+type TenantServiceVolumeBackupDao interface {
+	Dao
+	GetByBackupID(backupID string) (*model.TenantServiceVolumeBackup, error)
+	ListByServiceVolume(serviceID, volumeName string) ([]*model.TenantServiceVolumeBackup, error)
+	DeleteByBackupID(backupID string) error
+}
+
+// TenantServiceVolumeBackupPolicyDao -
+type TenantServiceVolumeBackupPolicyDao interface {
+	Dao
+	GetByServiceVolume(serviceID, volumeName string) (*model.TenantServiceVolumeBackupPolicy, error)
+	ListEnableOnes() ([]*model.TenantServiceVolumeBackupPolicy, error)
+	DeleteByServiceVolume(serviceID, volumeName string) error
+	DeleteByComponentIDs(componentIDs []string) error
+}
+
 // TenantServiceScalingRecordsDao -
 type TenantServiceScalingRecordsDao interface {
 	Dao
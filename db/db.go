@@ -96,9 +96,14 @@ type Manager interface {
 
 	VersionInfoDao() dao.VersionInfoDao
 	VersionInfoDaoTransactions(db *gorm.DB) dao.VersionInfoDao
+	BuildCacheDao() dao.BuildCacheDao
+	BuildCacheDaoTransactions(db *gorm.DB) dao.BuildCacheDao
 
 	RegionUserInfoDao() dao.RegionUserInfoDao
 	RegionUserInfoDaoTransactions(db *gorm.DB) dao.RegionUserInfoDao
+	APITokenDao() dao.APITokenDao
+	APITokenDaoTransactions(db *gorm.DB) dao.APITokenDao
+	AuditLogDao() dao.AuditLogDao
 
 	RegionAPIClassDao() dao.RegionAPIClassDao
 	RegionAPIClassDaoTransactions(db *gorm.DB) dao.RegionAPIClassDao
@@ -130,6 +135,14 @@ type Manager interface {
 	TenantServceAutoscalerRulesDaoTransactions(db *gorm.DB) dao.TenantServceAutoscalerRulesDao
 	TenantServceAutoscalerRuleMetricsDao() dao.TenantServceAutoscalerRuleMetricsDao
 	TenantServceAutoscalerRuleMetricsDaoTransactions(db *gorm.DB) dao.TenantServceAutoscalerRuleMetricsDao
+	TenantServiceAutoscalerSchedulesDao() dao.TenantServiceAutoscalerSchedulesDao
+	TenantServiceAutoscalerSchedulesDaoTransactions(db *gorm.DB) dao.TenantServiceAutoscalerSchedulesDao
+	TenantServiceIdlePolicyDao() dao.TenantServiceIdlePolicyDao
+	TenantServiceIdlePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceIdlePolicyDao
+	TenantServiceVolumeBackupDao() dao.TenantServiceVolumeBackupDao
+	TenantServiceVolumeBackupDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupDao
+	TenantServiceVolumeBackupPolicyDao() dao.TenantServiceVolumeBackupPolicyDao
+	TenantServiceVolumeBackupPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupPolicyDao
 	TenantServiceScalingRecordsDao() dao.TenantServiceScalingRecordsDao
 	TenantServiceScalingRecordsDaoTransactions(db *gorm.DB) dao.TenantServiceScalingRecordsDao
 
@@ -886,6 +886,76 @@ func (mr *MockManagerMockRecorder) VersionInfoDaoTransactions(db interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VersionInfoDaoTransactions", reflect.TypeOf((*MockManager)(nil).VersionInfoDaoTransactions), db)
 }
 
+// BuildCacheDao mocks base method
+func (m *MockManager) BuildCacheDao() dao.BuildCacheDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildCacheDao")
+	ret0, _ := ret[0].(dao.BuildCacheDao)
+	return ret0
+}
+
+// BuildCacheDao indicates an expected call of BuildCacheDao
+func (mr *MockManagerMockRecorder) BuildCacheDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildCacheDao", reflect.TypeOf((*MockManager)(nil).BuildCacheDao))
+}
+
+// BuildCacheDaoTransactions mocks base method
+func (m *MockManager) BuildCacheDaoTransactions(db *gorm.DB) dao.BuildCacheDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildCacheDaoTransactions", db)
+	ret0, _ := ret[0].(dao.BuildCacheDao)
+	return ret0
+}
+
+// BuildCacheDaoTransactions indicates an expected call of BuildCacheDaoTransactions
+func (mr *MockManagerMockRecorder) BuildCacheDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildCacheDaoTransactions", reflect.TypeOf((*MockManager)(nil).BuildCacheDaoTransactions), db)
+}
+
+// APITokenDao mocks base method
+func (m *MockManager) APITokenDao() dao.APITokenDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APITokenDao")
+	ret0, _ := ret[0].(dao.APITokenDao)
+	return ret0
+}
+
+// APITokenDao indicates an expected call of APITokenDao
+func (mr *MockManagerMockRecorder) APITokenDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APITokenDao", reflect.TypeOf((*MockManager)(nil).APITokenDao))
+}
+
+// APITokenDaoTransactions mocks base method
+func (m *MockManager) APITokenDaoTransactions(db *gorm.DB) dao.APITokenDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APITokenDaoTransactions", db)
+	ret0, _ := ret[0].(dao.APITokenDao)
+	return ret0
+}
+
+// APITokenDaoTransactions indicates an expected call of APITokenDaoTransactions
+func (mr *MockManagerMockRecorder) APITokenDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APITokenDaoTransactions", reflect.TypeOf((*MockManager)(nil).APITokenDaoTransactions), db)
+}
+
+// AuditLogDao mocks base method
+func (m *MockManager) AuditLogDao() dao.AuditLogDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLogDao")
+	ret0, _ := ret[0].(dao.AuditLogDao)
+	return ret0
+}
+
+// AuditLogDao indicates an expected call of AuditLogDao
+func (mr *MockManagerMockRecorder) AuditLogDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLogDao", reflect.TypeOf((*MockManager)(nil).AuditLogDao))
+}
+
 // RegionUserInfoDao mocks base method
 func (m *MockManager) RegionUserInfoDao() dao.RegionUserInfoDao {
 	m.ctrl.T.Helper()
@@ -1250,6 +1320,118 @@ func (mr *MockManagerMockRecorder) TenantServceAutoscalerRuleMetricsDaoTransacti
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServceAutoscalerRuleMetricsDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServceAutoscalerRuleMetricsDaoTransactions), db)
 }
 
+// TenantServiceAutoscalerSchedulesDao mocks base method
+func (m *MockManager) TenantServiceAutoscalerSchedulesDao() dao.TenantServiceAutoscalerSchedulesDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceAutoscalerSchedulesDao")
+	ret0, _ := ret[0].(dao.TenantServiceAutoscalerSchedulesDao)
+	return ret0
+}
+
+// TenantServiceAutoscalerSchedulesDao indicates an expected call of TenantServiceAutoscalerSchedulesDao
+func (mr *MockManagerMockRecorder) TenantServiceAutoscalerSchedulesDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceAutoscalerSchedulesDao", reflect.TypeOf((*MockManager)(nil).TenantServiceAutoscalerSchedulesDao))
+}
+
+// TenantServiceAutoscalerSchedulesDaoTransactions mocks base method
+func (m *MockManager) TenantServiceAutoscalerSchedulesDaoTransactions(db *gorm.DB) dao.TenantServiceAutoscalerSchedulesDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceAutoscalerSchedulesDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceAutoscalerSchedulesDao)
+	return ret0
+}
+
+// TenantServiceAutoscalerSchedulesDaoTransactions indicates an expected call of TenantServiceAutoscalerSchedulesDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceAutoscalerSchedulesDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceAutoscalerSchedulesDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceAutoscalerSchedulesDaoTransactions), db)
+}
+
+// TenantServiceIdlePolicyDao mocks base method
+func (m *MockManager) TenantServiceIdlePolicyDao() dao.TenantServiceIdlePolicyDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceIdlePolicyDao")
+	ret0, _ := ret[0].(dao.TenantServiceIdlePolicyDao)
+	return ret0
+}
+
+// TenantServiceIdlePolicyDao indicates an expected call of TenantServiceIdlePolicyDao
+func (mr *MockManagerMockRecorder) TenantServiceIdlePolicyDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceIdlePolicyDao", reflect.TypeOf((*MockManager)(nil).TenantServiceIdlePolicyDao))
+}
+
+// TenantServiceIdlePolicyDaoTransactions mocks base method
+func (m *MockManager) TenantServiceIdlePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceIdlePolicyDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceIdlePolicyDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceIdlePolicyDao)
+	return ret0
+}
+
+// TenantServiceIdlePolicyDaoTransactions indicates an expected call of TenantServiceIdlePolicyDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceIdlePolicyDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceIdlePolicyDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceIdlePolicyDaoTransactions), db)
+}
+
+// TenantServiceVolumeBackupDao mocks base method
+func (m *MockManager) TenantServiceVolumeBackupDao() dao.TenantServiceVolumeBackupDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceVolumeBackupDao")
+	ret0, _ := ret[0].(dao.TenantServiceVolumeBackupDao)
+	return ret0
+}
+
+// TenantServiceVolumeBackupDao indicates an expected call of TenantServiceVolumeBackupDao
+func (mr *MockManagerMockRecorder) TenantServiceVolumeBackupDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceVolumeBackupDao", reflect.TypeOf((*MockManager)(nil).TenantServiceVolumeBackupDao))
+}
+
+// TenantServiceVolumeBackupDaoTransactions mocks base method
+func (m *MockManager) TenantServiceVolumeBackupDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceVolumeBackupDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceVolumeBackupDao)
+	return ret0
+}
+
+// TenantServiceVolumeBackupDaoTransactions indicates an expected call of TenantServiceVolumeBackupDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceVolumeBackupDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceVolumeBackupDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceVolumeBackupDaoTransactions), db)
+}
+
+// TenantServiceVolumeBackupPolicyDao mocks base method
+func (m *MockManager) TenantServiceVolumeBackupPolicyDao() dao.TenantServiceVolumeBackupPolicyDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceVolumeBackupPolicyDao")
+	ret0, _ := ret[0].(dao.TenantServiceVolumeBackupPolicyDao)
+	return ret0
+}
+
+// TenantServiceVolumeBackupPolicyDao indicates an expected call of TenantServiceVolumeBackupPolicyDao
+func (mr *MockManagerMockRecorder) TenantServiceVolumeBackupPolicyDao() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceVolumeBackupPolicyDao", reflect.TypeOf((*MockManager)(nil).TenantServiceVolumeBackupPolicyDao))
+}
+
+// TenantServiceVolumeBackupPolicyDaoTransactions mocks base method
+func (m *MockManager) TenantServiceVolumeBackupPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupPolicyDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantServiceVolumeBackupPolicyDaoTransactions", db)
+	ret0, _ := ret[0].(dao.TenantServiceVolumeBackupPolicyDao)
+	return ret0
+}
+
+// TenantServiceVolumeBackupPolicyDaoTransactions indicates an expected call of TenantServiceVolumeBackupPolicyDaoTransactions
+func (mr *MockManagerMockRecorder) TenantServiceVolumeBackupPolicyDaoTransactions(db interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantServiceVolumeBackupPolicyDaoTransactions", reflect.TypeOf((*MockManager)(nil).TenantServiceVolumeBackupPolicyDaoTransactions), db)
+}
+
 // TenantServiceScalingRecordsDao mocks base method
 func (m *MockManager) TenantServiceScalingRecordsDao() dao.TenantServiceScalingRecordsDao {
 	m.ctrl.T.Helper()
@@ -0,0 +1,89 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+//AuditLog a mutating call of the region api, the logs are chained by the hashes of their predecessors
+//so that a modified or removed log breaks the chain
+type AuditLog struct {
+	Model
+	Seq       int64  `gorm:"column:seq;unique_index" json:"seq"`
+	Principal string `gorm:"column:principal;size:64" json:"principal"`
+	TokenID   string `gorm:"column:token_id;size:64" json:"token_id"`
+	Operator  string `gorm:"column:operator;size:64" json:"operator"`
+	Method    string `gorm:"column:method;size:10" json:"method"`
+	Route     string `gorm:"column:route;size:256" json:"route"`
+	Path      string `gorm:"column:path;size:1024" json:"path"`
+	TenantID  string `gorm:"column:tenant_id;size:32;index:tenant_id" json:"tenant_id,omitempty"`
+	AppID     string `gorm:"column:app_id;size:32" json:"app_id,omitempty"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id,omitempty"`
+	// RequestDigest the sha256 of the request body
+	RequestDigest string `gorm:"column:request_digest;size:64" json:"request_digest"`
+	StatusCode    int    `gorm:"column:status_code" json:"status_code"`
+	LatencyMS     int64  `gorm:"column:latency_ms" json:"latency_ms"`
+	PrevHash      string `gorm:"column:prev_hash;size:64" json:"prev_hash"`
+	Hash          string `gorm:"column:hash;size:64" json:"hash"`
+}
+
+//TableName
+func (t *AuditLog) TableName() string {
+	return "region_audit_log"
+}
+
+//ComputeHash returns the hash of the log and its predecessor, the create time is taken in seconds
+//as the database may not keep a higher precision
+func (t *AuditLog) ComputeHash() string {
+	fields := []string{
+		fmt.Sprint(t.Seq),
+		fmt.Sprint(t.CreatedAt.Unix()),
+		t.Principal,
+		t.TokenID,
+		t.Operator,
+		t.Method,
+		t.Route,
+		t.Path,
+		t.TenantID,
+		t.AppID,
+		t.ServiceID,
+		t.RequestDigest,
+		fmt.Sprint(t.StatusCode),
+		fmt.Sprint(t.LatencyMS),
+		t.PrevHash,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+//VerifyAuditChain checks the logs, ordered by seq, follow the log of the prevSeq and prevHash.
+//It returns the seq of the first broken log, 0 if the chain is intact.
+func VerifyAuditChain(logs []*AuditLog, prevSeq int64, prevHash string) int64 {
+	for _, log := range logs {
+		if log.Seq != prevSeq+1 || log.PrevHash != prevHash || log.ComputeHash() != log.Hash {
+			return log.Seq
+		}
+		prevSeq, prevHash = log.Seq, log.Hash
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyAuditChain(t *testing.T) {
+	var logs []*AuditLog
+	prevHash := ""
+	for i := 1; i <= 3; i++ {
+		log := &AuditLog{Seq: int64(i), Principal: "ci", Method: "POST", Path: "/v2/tenants/a/services", StatusCode: 200, PrevHash: prevHash}
+		log.CreatedAt = time.Unix(int64(1600000000+i), 0)
+		log.Hash = log.ComputeHash()
+		prevHash = log.Hash
+		logs = append(logs, log)
+	}
+	if seq := VerifyAuditChain(logs, 0, ""); seq != 0 {
+		t.Fatalf("intact chain is broken at %d", seq)
+	}
+	// the precision of the create time does not matter
+	logs[0].CreatedAt = logs[0].CreatedAt.Add(300 * time.Millisecond)
+	if seq := VerifyAuditChain(logs, 0, ""); seq != 0 {
+		t.Fatalf("intact chain is broken at %d", seq)
+	}
+
+	logs[1].StatusCode = 403
+	if seq := VerifyAuditChain(logs, 0, ""); seq != 2 {
+		t.Errorf("modified log: want broken at 2, got %d", seq)
+	}
+	logs[1].StatusCode = 200
+	if seq := VerifyAuditChain([]*AuditLog{logs[0], logs[2]}, 0, ""); seq != 3 {
+		t.Errorf("removed log: want broken at 3, got %d", seq)
+	}
+	if seq := VerifyAuditChain(logs[1:], 1, logs[0].Hash); seq != 0 {
+		t.Errorf("chain from the middle is broken at %d", seq)
+	}
+}
+
+func TestAuditLogRoundTrip(t *testing.T) {
+	created := time.Unix(1600000000, int64(700*time.Millisecond))
+	log := &AuditLog{Seq: 1, Principal: "ci", Method: "DELETE", Path: "/v2/tenants/a", StatusCode: 200}
+	log.CreatedAt = created.Truncate(time.Second)
+	log.Hash = log.ComputeHash()
+	// the database rounds the time to the nearest second when it is stored
+	stored := *log
+	stored.CreatedAt = log.CreatedAt.Round(time.Second)
+	if seq := VerifyAuditChain([]*AuditLog{&stored}, 0, ""); seq != 0 {
+		t.Fatalf("stored log is broken at %d", seq)
+	}
+	// the untruncated time would be rounded up to the next second
+	stored.CreatedAt = created.Round(time.Second)
+	if seq := VerifyAuditChain([]*AuditLog{&stored}, 0, ""); seq != 1 {
+		t.Errorf("want the untruncated time to break the log, got %d", seq)
+	}
+}
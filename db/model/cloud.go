@@ -18,6 +18,8 @@
 
 package model
 
+import "time"
+
 //TableName
 func (t *RegionUserInfo) TableName() string {
 	return "user_region_info"
@@ -34,3 +36,23 @@ type RegionUserInfo struct {
 	CA             string `gorm:"column:ca;size:4096" json:"ca"`
 	Key            string `gorm:"column:key;size:4096" json:"key"`
 }
+
+//APIToken a named token of the region api, only the digest of the token is stored
+type APIToken struct {
+	Model
+	Name         string `gorm:"column:name;size:64;unique_index" json:"name"`
+	TokenHash    string `gorm:"column:token_hash;size:64;unique_index" json:"-"`
+	Role         string `gorm:"column:role;size:16" json:"role"`
+	Scope        string `gorm:"column:scope;size:16" json:"scope"`
+	EnterpriseID string `gorm:"column:eid;size:32" json:"enterprise_id"`
+	TenantID     string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	AppID        string `gorm:"column:app_id;size:32" json:"app_id"`
+	Creator      string `gorm:"column:creator;size:64" json:"creator"`
+	// ExpireTime the token never expires when it is nil
+	ExpireTime *time.Time `gorm:"column:expire_time" json:"expire_time,omitempty"`
+}
+
+//TableName
+func (t *APIToken) TableName() string {
+	return "region_api_token"
+}
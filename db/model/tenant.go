@@ -59,14 +59,22 @@ func (t TenantStatus) String() string {
 	return string(t)
 }
 
-//Tenants tenant information
+//Tenants tenant information, the limits of 0 are unlimited
 type Tenants struct {
 	Model
 	Name        string `gorm:"column:name;size:40;unique_index"`
 	UUID        string `gorm:"column:uuid;size:33;unique_index"`
 	EID         string `gorm:"column:eid"`
 	LimitMemory int    `gorm:"column:limit_memory"`
-	Status      string `gorm:"column:status;default:'normal'"`
+	// LimitCPU the cpu limit of the running components in millicores
+	LimitCPU int `gorm:"column:limit_cpu"`
+	// LimitStorage the total capacity of the volumes in GB
+	LimitStorage          int    `gorm:"column:limit_storage"`
+	LimitComponents       int    `gorm:"column:limit_components"`
+	LimitPods             int    `gorm:"column:limit_pods"`
+	LimitGatewayRules     int    `gorm:"column:limit_gateway_rules"`
+	LimitBuildConcurrency int    `gorm:"column:limit_build_concurrency"`
+	Status                string `gorm:"column:status;default:'normal'"`
 }
 
 //TableName returns the name of the tenant table
@@ -606,6 +614,12 @@ type TenantServiceAutoscalerRuleMetrics struct {
 	MetricsName       string `gorm:"column:metric_name;not null"`
 	MetricTargetType  string `gorm:"column:metric_target_type;not null"`
 	MetricTargetValue int    `gorm:"column:metric_target_value;not null"`
+	// MetricSelector is the label selector of pods, object and external metrics, like "queue=orders"
+	MetricSelector string `gorm:"column:metric_selector"`
+	// ObjectKind, ObjectName and ObjectAPIVersion describe the object of object metrics
+	ObjectKind       string `gorm:"column:object_kind"`
+	ObjectName       string `gorm:"column:object_name"`
+	ObjectAPIVersion string `gorm:"column:object_api_version"`
 }
 
 // TableName -
@@ -613,6 +627,83 @@ func (t *TenantServiceAutoscalerRuleMetrics) TableName() string {
 	return "tenant_services_autoscaler_rule_metrics"
 }
 
+// TenantServiceAutoscalerSchedules scales the component to the replicas on schedule
+type TenantServiceAutoscalerSchedules struct {
+	Model
+	ScheduleID string `gorm:"column:schedule_id;unique;size:32" json:"schedule_id"`
+	ServiceID  string `gorm:"column:service_id;size:32" json:"service_id"`
+	Enable     bool   `gorm:"column:enable" json:"enable"`
+	// Schedule is a cron expression with five fields, like "0 8 * * 1-5"
+	Schedule string `gorm:"column:schedule;not null" json:"schedule"`
+	// TimeZone is the IANA time zone of the schedule, like "Asia/Shanghai", UTC is used if it is empty
+	TimeZone string `gorm:"column:time_zone" json:"time_zone"`
+	Replicas int    `gorm:"column:replicas" json:"replicas"`
+}
+
+// TableName -
+func (t *TenantServiceAutoscalerSchedules) TableName() string {
+	return "tenant_services_autoscaler_schedules"
+}
+
+// TenantServiceIdlePolicy scales the component to 0 when it has no gateway traffic for a while
+type TenantServiceIdlePolicy struct {
+	Model
+	ServiceID string `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	Enable    bool   `gorm:"column:enable" json:"enable"`
+	// IdleMinutes is how long the component must receive no requests before it is scaled to 0
+	IdleMinutes int `gorm:"column:idle_minutes" json:"idle_minutes"`
+}
+
+// TableName -
+func (t *TenantServiceIdlePolicy) TableName() string {
+	return "tenant_services_idle_policy"
+}
+
+// TenantServiceVolumeBackup is an archive of the data of a component volume in the object storage
+type TenantServiceVolumeBackup struct {
+	Model
+	BackupID   string `gorm:"column:backup_id;unique;size:32" json:"backup_id"`
+	TenantID   string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID  string `gorm:"column:service_id;size:32" json:"service_id"`
+	VolumeName string `gorm:"column:volume_name;size:40" json:"volume_name"`
+	VolumeType string `gorm:"column:volume_type;size:64" json:"volume_type"`
+	// WorkloadName is the statefulset name of a state component, the data of each pod are in a dir named by the pod
+	WorkloadName string `gorm:"column:workload_name" json:"workload_name"`
+	// Status starting, success or failure
+	Status    string `gorm:"column:status;size:32" json:"status"`
+	ObjectKey string `gorm:"column:object_key" json:"object_key"`
+	Size      int64  `gorm:"column:size" json:"size"`
+	// Scheduled is true if the backup is created by the backup policy of the volume
+	Scheduled bool   `gorm:"column:scheduled" json:"scheduled"`
+	EventID   string `gorm:"column:event_id;size:32" json:"event_id"`
+	Message   string `gorm:"column:message;size:1023" json:"message"`
+}
+
+// TableName -
+func (t *TenantServiceVolumeBackup) TableName() string {
+	return "tenant_services_volume_backup"
+}
+
+// TenantServiceVolumeBackupPolicy backups the data of a component volume on schedule
+type TenantServiceVolumeBackupPolicy struct {
+	Model
+	ServiceID  string `gorm:"column:service_id;size:32;unique_index:service_volume" json:"service_id"`
+	VolumeName string `gorm:"column:volume_name;size:40;unique_index:service_volume" json:"volume_name"`
+	Enable     bool   `gorm:"column:enable" json:"enable"`
+	// Schedule is a standard cron expression
+	Schedule string `gorm:"column:schedule" json:"schedule"`
+	TimeZone string `gorm:"column:time_zone" json:"time_zone"`
+	// Retain is the number of the latest backups kept, 0 means no limit
+	Retain int `gorm:"column:retain" json:"retain"`
+	// RetainDays is the days the backups are kept, 0 means no limit
+	RetainDays int `gorm:"column:retain_days" json:"retain_days"`
+}
+
+// TableName -
+func (t *TenantServiceVolumeBackupPolicy) TableName() string {
+	return "tenant_services_volume_backup_policy"
+}
+
 // TenantServiceScalingRecords -
 type TenantServiceScalingRecords struct {
 	Model
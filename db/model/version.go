@@ -59,6 +59,25 @@ func (t *VersionInfo) TableName() string {
 	return "tenant_service_version"
 }
 
+//BuildCache maps the inputs of a source code build to the version which delivered its result
+type BuildCache struct {
+	Model
+	// CacheKey the digest of the repository url, the commit, the language, the build envs and the builder images
+	CacheKey      string `gorm:"column:cache_key;size:64;index:cache_key" json:"cache_key"`
+	ServiceID     string `gorm:"column:service_id;size:40" json:"service_id"`
+	BuildVersion  string `gorm:"column:build_version;size:40" json:"build_version"`
+	RepoURL       string `gorm:"column:repo_url;size:2047" json:"repo_url"`
+	CodeVersion   string `gorm:"column:code_version;size:40" json:"code_version"`
+	Lang          string `gorm:"column:lang;size:40" json:"lang"`
+	DeliveredType string `gorm:"column:delivered_type;size:40" json:"delivered_type"`
+	DeliveredPath string `gorm:"column:delivered_path;size:250" json:"delivered_path"`
+}
+
+//TableName
+func (t *BuildCache) TableName() string {
+	return "tenant_service_build_cache"
+}
+
 //CreateShareImage
 func (t *VersionInfo) CreateShareImage(hubURL, namespace, appVersion string) (string, error) {
 	_, err := reference.ParseAnyReference(t.DeliveredPath)
@@ -0,0 +1,106 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/gridworkz/kato/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+//AuditLogDaoImpl -
+type AuditLogDaoImpl struct {
+	DB *gorm.DB
+}
+
+//Append appends the log to the end of the chain, the last log is locked so that concurrent appends are serialized
+func (a *AuditLogDaoImpl) Append(log *model.AuditLog) error {
+	tx := a.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	var last model.AuditLog
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Order("seq desc").Limit(1).Find(&last).Error; err != nil && err != gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return err
+	}
+	log.Seq = last.Seq + 1
+	log.PrevHash = last.Hash
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	// mysql rounds the fraction of a second, the hashed time must be the stored one
+	log.CreatedAt = log.CreatedAt.Truncate(time.Second)
+	log.Hash = log.ComputeHash()
+	if err := tx.Create(log).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+//ListAuditLogs lists the logs matching the given conditions, newest first
+func (a *AuditLogDaoImpl) ListAuditLogs(tenantID, serviceID, principal string, start, end time.Time, page, pageSize int) ([]*model.AuditLog, int64, error) {
+	var logs []*model.AuditLog
+	offset := (page - 1) * pageSize
+
+	db := a.DB.Order("seq desc")
+	if tenantID != "" {
+		db = db.Where("tenant_id=?", tenantID)
+	}
+	if serviceID != "" {
+		db = db.Where("service_id=?", serviceID)
+	}
+	if principal != "" {
+		db = db.Where("principal=?", principal)
+	}
+	if !start.IsZero() {
+		db = db.Where("create_time >= ?", start)
+	}
+	if !end.IsZero() {
+		db = db.Where("create_time < ?", end)
+	}
+	var total int64
+	if err := db.Model(&model.AuditLog{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := db.Limit(pageSize).Offset(offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+//ListAuditLogsAfter lists at most limit logs following the given seq, in the chain order
+func (a *AuditLogDaoImpl) ListAuditLogsAfter(seq int64, limit int) ([]*model.AuditLog, error) {
+	var logs []*model.AuditLog
+	if err := a.DB.Where("seq > ?", seq).Order("seq asc").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+//GetAuditLogBySeq -
+func (a *AuditLogDaoImpl) GetAuditLogBySeq(seq int64) (*model.AuditLog, error) {
+	var log model.AuditLog
+	if err := a.DB.Where("seq = ?", seq).Find(&log).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gridworkz/kato/db/errors"
 	"github.com/gridworkz/kato/db/model"
 	"github.com/jinzhu/gorm"
 )
@@ -84,3 +85,56 @@ func (t *RegionUserInfoDaoImpl) GetALLTokenInValidityPeriod() ([]*model.RegionUs
 	}
 	return ruis, nil
 }
+
+//APITokenDaoImpl -
+type APITokenDaoImpl struct {
+	DB *gorm.DB
+}
+
+//AddModel -
+func (t *APITokenDaoImpl) AddModel(mo model.Interface) error {
+	token := mo.(*model.APIToken)
+	var old model.APIToken
+	if ok := t.DB.Where("name = ?", token.Name).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(token).Error
+	}
+	return errors.ErrRecordAlreadyExist
+}
+
+//UpdateModel -
+func (t *APITokenDaoImpl) UpdateModel(mo model.Interface) error {
+	token := mo.(*model.APIToken)
+	return t.DB.Save(token).Error
+}
+
+//GetByName -
+func (t *APITokenDaoImpl) GetByName(name string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := t.DB.Where("name = ?", name).Find(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+//GetByTokenHash -
+func (t *APITokenDaoImpl) GetByTokenHash(tokenHash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := t.DB.Where("token_hash = ?", tokenHash).Find(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+//List -
+func (t *APITokenDaoImpl) List() ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	if err := t.DB.Order("name").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+//DeleteByName -
+func (t *APITokenDaoImpl) DeleteByName(name string) error {
+	return t.DB.Where("name = ?", name).Delete(&model.APIToken{}).Error
+}
@@ -211,6 +211,16 @@ func (c *EventDaoImpl) UnfinishedEvents(target, targetID string, optTypes ...str
 	return result, nil
 }
 
+// CountUnfinishedEventsByTenant counts the events of the tenant started since the given time and not finished.
+func (c *EventDaoImpl) CountUnfinishedEventsByTenant(tenantID, optType string, since time.Time) (int, error) {
+	var count int
+	if err := c.DB.Model(&model.ServiceEvent{}).Where("tenant_id=? and opt_type=? and final_status=? and create_time>?", tenantID, optType, "", since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // LatestFailurePodEvent returns the latest failure pod event.
 func (c *EventDaoImpl) LatestFailurePodEvent(podName string) (*model.ServiceEvent, error) {
 	var event model.ServiceEvent
@@ -444,6 +444,15 @@ func (t *TCPRuleDaoTmpl) CreateOrUpdateTCPRuleInBatch(tcpRules []*model.TCPRule)
 	return nil
 }
 
+// ListByComponentIDs -
+func (t *TCPRuleDaoTmpl) ListByComponentIDs(componentIDs []string) ([]*model.TCPRule, error) {
+	var rules []*model.TCPRule
+	if err := t.DB.Where("service_id in (?) ", componentIDs).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
 // GwRuleConfigDaoImpl is a implementation of GwRuleConfigDao.
 type GwRuleConfigDaoImpl struct {
 	DB *gorm.DB
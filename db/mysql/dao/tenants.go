@@ -1893,6 +1893,10 @@ func (t *TenantServceAutoscalerRuleMetricsDaoImpl) UpdateOrCreate(metric *model.
 	} else {
 		old.MetricTargetType = metric.MetricTargetType
 		old.MetricTargetValue = metric.MetricTargetValue
+		old.MetricSelector = metric.MetricSelector
+		old.ObjectKind = metric.ObjectKind
+		old.ObjectName = metric.ObjectName
+		old.ObjectAPIVersion = metric.ObjectAPIVersion
 		if err := t.DB.Save(&old).Error; err != nil {
 			return err
 		}
@@ -1935,6 +1939,217 @@ func (t *TenantServceAutoscalerRuleMetricsDaoImpl) CreateOrUpdateScaleRuleMetric
 	return nil
 }
 
+// TenantServiceAutoscalerSchedulesDaoImpl -
+type TenantServiceAutoscalerSchedulesDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) AddModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceAutoscalerSchedules)
+	var old model.TenantServiceAutoscalerSchedules
+	if ok := t.DB.Where("schedule_id = ?", schedule.ScheduleID).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(schedule).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) UpdateModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceAutoscalerSchedules)
+	return t.DB.Save(schedule).Error
+}
+
+// GetByScheduleID -
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) GetByScheduleID(scheduleID string) (*model.TenantServiceAutoscalerSchedules, error) {
+	var schedule model.TenantServiceAutoscalerSchedules
+	if err := t.DB.Where("schedule_id=?", scheduleID).Find(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListByServiceID -
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) ListByServiceID(serviceID string) ([]*model.TenantServiceAutoscalerSchedules, error) {
+	var schedules []*model.TenantServiceAutoscalerSchedules
+	if err := t.DB.Where("service_id=?", serviceID).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ListEnableOnes lists the enabled schedules of all components
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) ListEnableOnes() ([]*model.TenantServiceAutoscalerSchedules, error) {
+	var schedules []*model.TenantServiceAutoscalerSchedules
+	if err := t.DB.Where("enable=?", true).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// DeleteByScheduleID -
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) DeleteByScheduleID(scheduleID string) error {
+	return t.DB.Where("schedule_id=?", scheduleID).Delete(&model.TenantServiceAutoscalerSchedules{}).Error
+}
+
+// DeleteByComponentIDs deletes schedules based on componentIDs
+func (t *TenantServiceAutoscalerSchedulesDaoImpl) DeleteByComponentIDs(componentIDs []string) error {
+	return t.DB.Where("service_id in (?)", componentIDs).Delete(&model.TenantServiceAutoscalerSchedules{}).Error
+}
+
+// TenantServiceIdlePolicyDaoImpl -
+type TenantServiceIdlePolicyDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceIdlePolicyDaoImpl) AddModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceIdlePolicy)
+	var old model.TenantServiceIdlePolicy
+	if ok := t.DB.Where("service_id = ?", policy.ServiceID).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(policy).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceIdlePolicyDaoImpl) UpdateModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceIdlePolicy)
+	return t.DB.Save(policy).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceIdlePolicyDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceIdlePolicy, error) {
+	var policy model.TenantServiceIdlePolicy
+	if err := t.DB.Where("service_id=?", serviceID).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListEnableOnes lists the enabled idle policies of all components
+func (t *TenantServiceIdlePolicyDaoImpl) ListEnableOnes() ([]*model.TenantServiceIdlePolicy, error) {
+	var policies []*model.TenantServiceIdlePolicy
+	if err := t.DB.Where("enable=?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteByComponentIDs deletes idle policies based on componentIDs
+func (t *TenantServiceIdlePolicyDaoImpl) DeleteByComponentIDs(componentIDs []string) error {
+	return t.DB.Where("service_id in (?)", componentIDs).Delete(&model.TenantServiceIdlePolicy{}).Error
+}
+
+// TenantServiceVolumeBackupDaoImpl -
+type TenantServiceVolumeBackupDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceVolumeBackupDaoImpl) AddModel(mo model.Interface) error {
+	backup := mo.(*model.TenantServiceVolumeBackup)
+	var old model.TenantServiceVolumeBackup
+	if ok := t.DB.Where("backup_id = ?", backup.BackupID).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(backup).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceVolumeBackupDaoImpl) UpdateModel(mo model.Interface) error {
+	backup := mo.(*model.TenantServiceVolumeBackup)
+	return t.DB.Save(backup).Error
+}
+
+// GetByBackupID -
+func (t *TenantServiceVolumeBackupDaoImpl) GetByBackupID(backupID string) (*model.TenantServiceVolumeBackup, error) {
+	var backup model.TenantServiceVolumeBackup
+	if err := t.DB.Where("backup_id=?", backupID).Find(&backup).Error; err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// ListByServiceVolume lists the backups of the volume, the latest first
+func (t *TenantServiceVolumeBackupDaoImpl) ListByServiceVolume(serviceID, volumeName string) ([]*model.TenantServiceVolumeBackup, error) {
+	var backups []*model.TenantServiceVolumeBackup
+	if err := t.DB.Where("service_id=? and volume_name=?", serviceID, volumeName).Order("create_time desc, ID desc").Find(&backups).Error; err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// DeleteByBackupID -
+func (t *TenantServiceVolumeBackupDaoImpl) DeleteByBackupID(backupID string) error {
+	return t.DB.Where("backup_id=?", backupID).Delete(&model.TenantServiceVolumeBackup{}).Error
+}
+
+// TenantServiceVolumeBackupPolicyDaoImpl -
+type TenantServiceVolumeBackupPolicyDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) AddModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceVolumeBackupPolicy)
+	var old model.TenantServiceVolumeBackupPolicy
+	if ok := t.DB.Where("service_id = ? and volume_name = ?", policy.ServiceID, policy.VolumeName).Find(&old).RecordNotFound(); ok {
+		if err := t.DB.Create(policy).Error; err != nil {
+			return err
+		}
+	} else {
+		return errors.ErrRecordAlreadyExist
+	}
+	return nil
+}
+
+// UpdateModel -
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) UpdateModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceVolumeBackupPolicy)
+	return t.DB.Save(policy).Error
+}
+
+// GetByServiceVolume -
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) GetByServiceVolume(serviceID, volumeName string) (*model.TenantServiceVolumeBackupPolicy, error) {
+	var policy model.TenantServiceVolumeBackupPolicy
+	if err := t.DB.Where("service_id=? and volume_name=?", serviceID, volumeName).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListEnableOnes lists the enabled backup policies of all volumes
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) ListEnableOnes() ([]*model.TenantServiceVolumeBackupPolicy, error) {
+	var policies []*model.TenantServiceVolumeBackupPolicy
+	if err := t.DB.Where("enable=?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteByServiceVolume -
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) DeleteByServiceVolume(serviceID, volumeName string) error {
+	return t.DB.Where("service_id=? and volume_name=?", serviceID, volumeName).Delete(&model.TenantServiceVolumeBackupPolicy{}).Error
+}
+
+// DeleteByComponentIDs deletes backup policies based on componentIDs
+func (t *TenantServiceVolumeBackupPolicyDaoImpl) DeleteByComponentIDs(componentIDs []string) error {
+	return t.DB.Where("service_id in (?)", componentIDs).Delete(&model.TenantServiceVolumeBackupPolicy{}).Error
+}
+
 // TenantServiceScalingRecordsDaoImpl -
 type TenantServiceScalingRecordsDaoImpl struct {
 	DB *gorm.DB
@@ -197,3 +197,45 @@ func (c *VersionInfoDaoImpl) ListVersionsByComponentIDs(componentIDs []string) (
 	}
 	return result, nil
 }
+
+//BuildCacheDaoImpl -
+type BuildCacheDaoImpl struct {
+	DB *gorm.DB
+}
+
+//AddModel adds the cache, it replaces the one of the same key
+func (c *BuildCacheDaoImpl) AddModel(mo model.Interface) error {
+	cache := mo.(*model.BuildCache)
+	var old model.BuildCache
+	if ok := c.DB.Where("service_id=? and cache_key=?", cache.ServiceID, cache.CacheKey).Find(&old).RecordNotFound(); ok {
+		return c.DB.Create(cache).Error
+	}
+	cache.ID = old.ID
+	cache.CreatedAt = old.CreatedAt
+	return c.DB.Save(cache).Error
+}
+
+//UpdateModel -
+func (c *BuildCacheDaoImpl) UpdateModel(mo model.Interface) error {
+	cache := mo.(*model.BuildCache)
+	return c.DB.Save(cache).Error
+}
+
+//GetByCacheKey -
+func (c *BuildCacheDaoImpl) GetByCacheKey(serviceID, cacheKey string) (*model.BuildCache, error) {
+	var cache model.BuildCache
+	if err := c.DB.Where("service_id=? and cache_key=?", serviceID, cacheKey).Find(&cache).Error; err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+//DeleteByBuildVersion deletes the caches pointing to the version
+func (c *BuildCacheDaoImpl) DeleteByBuildVersion(serviceID, buildVersion string) error {
+	return c.DB.Where("service_id=? and build_version=?", serviceID, buildVersion).Delete(&model.BuildCache{}).Error
+}
+
+//DeleteByServiceID -
+func (c *BuildCacheDaoImpl) DeleteByServiceID(serviceID string) error {
+	return c.DB.Where("service_id=?", serviceID).Delete(&model.BuildCache{}).Error
+}
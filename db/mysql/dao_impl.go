@@ -354,6 +354,20 @@ func (m *Manager) VersionInfoDaoTransactions(db *gorm.DB) dao.VersionInfoDao {
 	}
 }
 
+//BuildCacheDao -
+func (m *Manager) BuildCacheDao() dao.BuildCacheDao {
+	return &mysqldao.BuildCacheDaoImpl{
+		DB: m.db,
+	}
+}
+
+//BuildCacheDaoTransactions -
+func (m *Manager) BuildCacheDaoTransactions(db *gorm.DB) dao.BuildCacheDao {
+	return &mysqldao.BuildCacheDaoImpl{
+		DB: db,
+	}
+}
+
 //LocalSchedulerDao Local scheduling information
 func (m *Manager) LocalSchedulerDao() dao.LocalSchedulerDao {
 	return &mysqldao.LocalSchedulerDaoImpl{
@@ -375,6 +389,27 @@ func (m *Manager) RegionUserInfoDaoTransactions(db *gorm.DB) dao.RegionUserInfoD
 	}
 }
 
+//APITokenDao -
+func (m *Manager) APITokenDao() dao.APITokenDao {
+	return &mysqldao.APITokenDaoImpl{
+		DB: m.db,
+	}
+}
+
+//APITokenDaoTransactions -
+func (m *Manager) APITokenDaoTransactions(db *gorm.DB) dao.APITokenDao {
+	return &mysqldao.APITokenDaoImpl{
+		DB: db,
+	}
+}
+
+//AuditLogDao -
+func (m *Manager) AuditLogDao() dao.AuditLogDao {
+	return &mysqldao.AuditLogDaoImpl{
+		DB: m.db,
+	}
+}
+
 //RegionAPIClassDao RegionAPIClassDao
 func (m *Manager) RegionAPIClassDao() dao.RegionAPIClassDao {
 	return &mysqldao.RegionAPIClassDaoImpl{
@@ -606,6 +641,62 @@ func (m *Manager) TenantServceAutoscalerRuleMetricsDaoTransactions(db *gorm.DB)
 	}
 }
 
+// TenantServiceAutoscalerSchedulesDao -
+func (m *Manager) TenantServiceAutoscalerSchedulesDao() dao.TenantServiceAutoscalerSchedulesDao {
+	return &mysqldao.TenantServiceAutoscalerSchedulesDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceAutoscalerSchedulesDaoTransactions -
+func (m *Manager) TenantServiceAutoscalerSchedulesDaoTransactions(db *gorm.DB) dao.TenantServiceAutoscalerSchedulesDao {
+	return &mysqldao.TenantServiceAutoscalerSchedulesDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceIdlePolicyDao -
+func (m *Manager) TenantServiceIdlePolicyDao() dao.TenantServiceIdlePolicyDao {
+	return &mysqldao.TenantServiceIdlePolicyDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceIdlePolicyDaoTransactions -
+func (m *Manager) TenantServiceIdlePolicyDaoTransactions(db *gorm.DB) dao.TenantServiceIdlePolicyDao {
+	return &mysqldao.TenantServiceIdlePolicyDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceVolumeBackupDao -
+func (m *Manager) TenantServiceVolumeBackupDao() dao.TenantServiceVolumeBackupDao {
+	return &mysqldao.TenantServiceVolumeBackupDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceVolumeBackupDaoTransactions -
+func (m *Manager) TenantServiceVolumeBackupDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupDao {
+	return &mysqldao.TenantServiceVolumeBackupDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceVolumeBackupPolicyDao -
+func (m *Manager) TenantServiceVolumeBackupPolicyDao() dao.TenantServiceVolumeBackupPolicyDao {
+	return &mysqldao.TenantServiceVolumeBackupPolicyDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceVolumeBackupPolicyDaoTransactions -
+func (m *Manager) TenantServiceVolumeBackupPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeBackupPolicyDao {
+	return &mysqldao.TenantServiceVolumeBackupPolicyDaoImpl{
+		DB: db,
+	}
+}
+
 // TenantServiceScalingRecordsDao -
 func (m *Manager) TenantServiceScalingRecordsDao() dao.TenantServiceScalingRecordsDao {
 	return &mysqldao.TenantServiceScalingRecordsDaoImpl{
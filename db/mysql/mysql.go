@@ -124,7 +124,10 @@ func (m *Manager) RegisterTableModel() {
 	m.models = append(m.models, &model.CodeCheckResult{})
 	m.models = append(m.models, &model.ServiceEvent{})
 	m.models = append(m.models, &model.VersionInfo{})
+	m.models = append(m.models, &model.BuildCache{})
 	m.models = append(m.models, &model.RegionUserInfo{})
+	m.models = append(m.models, &model.APIToken{})
+	m.models = append(m.models, &model.AuditLog{})
 	m.models = append(m.models, &model.TenantServicesStreamPluginPort{})
 	m.models = append(m.models, &model.RegionAPIClass{})
 	m.models = append(m.models, &model.RegionProcotols{})
@@ -152,6 +155,10 @@ func (m *Manager) RegisterTableModel() {
 	// pod autoscaler
 	m.models = append(m.models, &model.TenantServiceAutoscalerRules{})
 	m.models = append(m.models, &model.TenantServiceAutoscalerRuleMetrics{})
+	m.models = append(m.models, &model.TenantServiceAutoscalerSchedules{})
+	m.models = append(m.models, &model.TenantServiceIdlePolicy{})
+	m.models = append(m.models, &model.TenantServiceVolumeBackup{})
+	m.models = append(m.models, &model.TenantServiceVolumeBackupPolicy{})
 	m.models = append(m.models, &model.TenantServiceScalingRecords{})
 	m.models = append(m.models, &model.TenantServiceMonitor{})
 }
@@ -45,6 +45,26 @@ type DBConf struct {
 	PoolSize    int
 	PoolMaxSize int
 	HomePath    string
+	//EventPlugin persistence plugin of event logs, eventfile or eventsqlite
+	EventPlugin string
+	//DockerLogPlugin persistence plugin of service logs, file or sqlite
+	DockerLogPlugin string
+	SQLitePath      string
+	//SQLiteKeepDays the messages older than the days are deleted from the sqlite database
+	SQLiteKeepDays int
+	Archive        ArchiveConf
+}
+
+// ArchiveConf object storage that keeps the logs older than KeepDays
+type ArchiveConf struct {
+	Provider   string
+	Endpoint   string
+	AccessKey  string
+	SecretKey  string
+	UseSSL     bool
+	BucketName string
+	Location   string
+	KeepDays   int
 }
 
 // WebSocketConf
@@ -0,0 +1,165 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package db
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gridworkz/kato/builder/cloudos"
+	"github.com/gridworkz/kato/eventlog/conf"
+)
+
+//ArchiveStore keeps the archived logs in object storage. Event logs are archived
+//as eventlog/<event id>.log in the eventfile format, and service logs are archived
+//as dockerlog/<service alias id>/<day>.log.gz in the daily zip format of the file plugin.
+type ArchiveStore struct {
+	cloudos.CloudOSer
+	KeepDays int
+}
+
+//NewArchiveStore returns nil if the archive provider is not configured
+func NewArchiveStore(c conf.ArchiveConf) (*ArchiveStore, error) {
+	if c.Provider == "" {
+		return nil, nil
+	}
+	provider, err := cloudos.Str2S3Provider(c.Provider)
+	if err != nil {
+		return nil, err
+	}
+	cloudoser, err := cloudos.New(&cloudos.Config{
+		ProviderType: provider,
+		Endpoint:     c.Endpoint,
+		AccessKey:    c.AccessKey,
+		SecretKey:    c.SecretKey,
+		UseSSL:       c.UseSSL,
+		BucketName:   c.BucketName,
+		Location:     c.Location,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create archive object storage failure %s", err.Error())
+	}
+	keepDays := c.KeepDays
+	if keepDays <= 0 {
+		keepDays = 7
+	}
+	return &ArchiveStore{CloudOSer: cloudoser, KeepDays: keepDays}, nil
+}
+
+//Before the messages before this time should be archived
+func (s *ArchiveStore) Before() time.Time {
+	return time.Now().AddDate(0, 0, -s.KeepDays)
+}
+
+//Upload uploads the local file to the archive and removes it
+func (s *ArchiveStore) Upload(objkey, filename string) error {
+	if err := s.PutObject(objkey, filename); err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+//Download downloads the archived object to a temp file, the caller should remove it after read
+func (s *ArchiveStore) Download(objkey string) (string, error) {
+	f, err := ioutil.TempFile("", "eventlog-archive-")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	if err := s.GetObject(objkey, f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func eventArchiveKey(eventID string) string {
+	return path.Join("eventlog", eventID+".log")
+}
+
+func dockerArchiveKey(aliasID, day string) string {
+	return path.Join("dockerlog", aliasID, day+".log.gz")
+}
+
+//dayName the day part of the daily log file name
+func dayName(t time.Time) string {
+	return fmt.Sprintf("%d-%d-%d", t.Year(), t.Month(), t.Day())
+}
+
+//readZipLines reads the lines of all files in the zip archive
+func readZipLines(filename string) ([]string, error) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var lines []string
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		fileLines, err := readLines(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fileLines...)
+	}
+	return lines, nil
+}
+
+//readLines reads the non-empty lines
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+//writeZipLines writes the lines to a zip archive with a single stdout.log file
+func writeZipLines(filename string, lines []string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	writer, err := zw.Create("stdout.log")
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := writer.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -22,6 +22,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"strconv"
@@ -35,7 +36,8 @@ import (
 
 //EventFilePlugin
 type EventFilePlugin struct {
-	HomePath string
+	HomePath     string
+	ArchiveStore *ArchiveStore
 }
 
 //SaveMessage
@@ -75,6 +77,7 @@ type MessageData struct {
 	Message  string `json:"message"`
 	Time     string `json:"time"`
 	Unixtime int64  `json:"utime"`
+	Level    string `json:"level,omitempty"`
 }
 
 //MessageDataList
@@ -85,16 +88,31 @@ func (a MessageDataList) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a MessageDataList) Less(i, j int) bool { return a[i].Unixtime <= a[j].Unixtime }
 
 //GetMessages
-func (m *EventFilePlugin) GetMessages(eventID, level string, length int) (interface{}, error) {
+func (m *EventFilePlugin) GetMessages(eventID, level string, query MessageQuery) (interface{}, error) {
 	var message MessageDataList
 	apath := path.Join(m.HomePath, "eventlog", eventID+".log")
 	if ok, err := util.FileExists(apath); !ok {
 		if err != nil {
 			logrus.Errorf("check file exist error %s", err.Error())
 		}
-		return message, nil
+		if m.ArchiveStore == nil {
+			return message, nil
+		}
+		archived, err := m.ArchiveStore.Download(eventArchiveKey(eventID))
+		if err != nil {
+			logrus.Debugf("event log %s is not archived: %s", eventID, err.Error())
+			return message, nil
+		}
+		defer os.Remove(archived)
+		apath = archived
 	}
-	eventFile, err := os.Open(apath)
+	return readEventLogFile(apath, level, query)
+}
+
+//readEventLogFile reads the messages of the level in the time range from the event log file
+func readEventLogFile(filename, level string, query MessageQuery) (MessageDataList, error) {
+	var message MessageDataList
+	eventFile, err := os.Open(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -118,21 +136,54 @@ func (m *EventFilePlugin) GetMessages(eventID, level string, length int) (interf
 				if len(info) == 3 {
 					timeunix := info[1]
 					unix, _ := strconv.ParseInt(timeunix, 10, 64)
+					if !query.contains(unix) {
+						continue
+					}
 					tm := time.Unix(unix, 0)
 					md := MessageData{
 						Message:  info[2],
 						Unixtime: unix,
 						Time:     tm.Format(time.RFC3339),
+						Level:    levelOfFlag(flag),
 					}
 					message = append(message, md)
-					if len(message) > length && length != 0 {
+					if query.Limit > 0 && len(message) >= query.Offset+query.Limit {
 						break
 					}
 				}
 			}
 		}
 	}
-	return message, nil
+	start, end := query.page(len(message))
+	return message[start:end], nil
+}
+
+//Archive moves the event log files not written since the keep days to the archive store
+func (m *EventFilePlugin) Archive() error {
+	if m.ArchiveStore == nil {
+		return nil
+	}
+	dir := eventutil.EventLogFilePath(m.HomePath)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	before := m.ArchiveStore.Before()
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".log") || !f.ModTime().Before(before) {
+			continue
+		}
+		eventID := strings.TrimSuffix(f.Name(), ".log")
+		if err := m.ArchiveStore.Upload(eventArchiveKey(eventID), path.Join(dir, f.Name())); err != nil {
+			logrus.Errorf("archive event log %s failure %s", eventID, err.Error())
+			continue
+		}
+		logrus.Debugf("event log %s is archived", eventID)
+	}
+	return nil
 }
 
 //CheckLevel
@@ -169,6 +220,18 @@ func GetTimeUnix(timeStr string) int64 {
 	return utime.Unix()
 }
 
+//levelOfFlag the level of the flag written by GetLevelFlag
+func levelOfFlag(flag byte) string {
+	switch flag {
+	case '1':
+		return "info"
+	case '2':
+		return "debug"
+	default:
+		return "error"
+	}
+}
+
 //GetLevelFlag
 func GetLevelFlag(level string) []byte {
 	switch level {
@@ -25,10 +25,12 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gridworkz/kato/util"
@@ -37,8 +39,12 @@ import (
 
 type filePlugin struct {
 	homePath string
+	archive  *ArchiveStore
 }
 
+//maxRangeDays the max days of the service logs read by a time range query
+const maxRangeDays = 31
+
 func (m *filePlugin) getStdFilePath(serviceID string) (string, error) {
 	apath := path.Join(m.homePath, GetServiceAliasID(serviceID))
 	_, err := os.Stat(apath)
@@ -102,8 +108,109 @@ func (m *filePlugin) SaveMessage(events []*EventLogMessage) error {
 	_, err = logfile.Write(body)
 	return err
 }
-func (m *filePlugin) GetMessages(serviceID, level string, length int) (interface{}, error) {
-	if length <= 0 {
+
+//GetMessages the page of service logs counts back from the newest line. The lines are
+//not timestamped, so a time range query selects the daily log files in the range.
+func (m *filePlugin) GetMessages(serviceID, level string, query MessageQuery) (interface{}, error) {
+	if !query.ranged() {
+		return m.tailMessages(serviceID, query)
+	}
+	filePathDir, err := m.getStdFilePath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	until := time.Now()
+	if query.Until > 0 && query.Until < until.Unix() {
+		until = time.Unix(query.Until, 0)
+	}
+	since := until.AddDate(0, 0, -maxRangeDays)
+	if query.Since > since.Unix() {
+		since = time.Unix(query.Since, 0)
+	}
+	var lines []string
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		dayLines, err := m.readDayLines(filePathDir, day)
+		if err != nil {
+			logrus.Warningf("read service log of %s failure %s", dayName(day), err.Error())
+			continue
+		}
+		lines = append(lines, dayLines...)
+	}
+	start, end := query.tailPage(len(lines))
+	return lines[start:end], nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+//readDayLines reads the service log lines of the day from the current log file,
+//the local daily log file or the archive
+func (m *filePlugin) readDayLines(dir string, day time.Time) ([]string, error) {
+	current := path.Join(dir, "stdout.log")
+	if info, err := os.Stat(current); err == nil && dayName(info.ModTime()) == dayName(day) {
+		f, err := os.Open(current)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readLines(f)
+	}
+	daily := path.Join(dir, dayName(day)+".log.gz")
+	if ok, _ := util.FileExists(daily); ok {
+		return readZipLines(daily)
+	}
+	if m.archive == nil || !day.Before(m.archive.Before()) {
+		return nil, nil
+	}
+	archived, err := m.archive.Download(dockerArchiveKey(path.Base(dir), dayName(day)))
+	if err != nil {
+		return nil, nil
+	}
+	defer os.Remove(archived)
+	return readZipLines(archived)
+}
+
+//Archive moves the daily log files older than the keep days to the archive store
+func (m *filePlugin) Archive() error {
+	if m.archive == nil {
+		return nil
+	}
+	dirs, err := ioutil.ReadDir(m.homePath)
+	if err != nil {
+		return err
+	}
+	before := m.archive.Before()
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == "eventlog" {
+			continue
+		}
+		files, err := ioutil.ReadDir(path.Join(m.homePath, dir.Name()))
+		if err != nil {
+			logrus.Errorf("list service log dir %s failure %s", dir.Name(), err.Error())
+			continue
+		}
+		for _, f := range files {
+			if !strings.HasSuffix(f.Name(), ".log.gz") {
+				continue
+			}
+			day := strings.TrimSuffix(f.Name(), ".log.gz")
+			theTime, err := time.ParseInLocation("2006-1-2", day, time.Local)
+			if err != nil || !theTime.Before(before) {
+				continue
+			}
+			if err := m.archive.Upload(dockerArchiveKey(dir.Name(), day), path.Join(m.homePath, dir.Name(), f.Name())); err != nil {
+				logrus.Errorf("archive service log %s/%s failure %s", dir.Name(), f.Name(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (m *filePlugin) tailMessages(serviceID string, query MessageQuery) (interface{}, error) {
+	length := query.Offset + query.Limit
+	if query.Limit <= 0 || length <= 0 {
 		return nil, nil
 	}
 	filePathDir, err := m.getStdFilePath(serviceID)
@@ -133,7 +240,8 @@ func (m *filePlugin) GetMessages(serviceID, level string, length int) (interface
 		}
 		lines = append(lines, string(line))
 	}
-	return lines, nil
+	start, end := query.tailPage(len(lines))
+	return lines[start:end], nil
 }
 
 func (m *filePlugin) Close() error {
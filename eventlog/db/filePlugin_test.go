@@ -47,12 +47,12 @@ func TestGetMessages(t *testing.T) {
 	f := filePlugin{
 		homePath: "./test",
 	}
-	logs, err := f.GetMessages("qwertyuiopasdfghjkl", "", 10)
+	logs, err := f.GetMessages("qwertyuiopasdfghjkl", "", MessageQuery{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
 	t.Log(logs)
-	logs, err = f.GetMessages("qwertyuiopasdfghjkl", "", -10)
+	logs, err = f.GetMessages("qwertyuiopasdfghjkl", "", MessageQuery{Limit: -10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,20 +28,92 @@ import (
 type Manager interface {
 	SaveMessage([]*EventLogMessage) error
 	Close() error
-	GetMessages(id, level string, length int) (interface{}, error)
+	GetMessages(id, level string, query MessageQuery) (interface{}, error)
+}
+
+//Archiver is implemented by the plugins that move the messages older than
+//the keep days of the archive store to object storage
+type Archiver interface {
+	Archive() error
+}
+
+//MessageQuery time range and page of the messages to get
+type MessageQuery struct {
+	//Since, Until unix seconds, zero means unbounded
+	Since int64
+	Until int64
+	//Offset, Limit page of the matched messages, zero limit means all
+	Offset int
+	Limit  int
+}
+
+func (q MessageQuery) ranged() bool {
+	return q.Since > 0 || q.Until > 0
+}
+
+func (q MessageQuery) contains(unix int64) bool {
+	if q.Since > 0 && unix < q.Since {
+		return false
+	}
+	if q.Until > 0 && unix > q.Until {
+		return false
+	}
+	return true
+}
+
+//page returns the bounds of the page in n matched messages, counted from the oldest
+func (q MessageQuery) page(n int) (int, int) {
+	start := q.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if q.Limit > 0 && start+q.Limit < n {
+		end = start + q.Limit
+	}
+	return start, end
+}
+
+//tailPage returns the bounds of the page in n matched messages, counted back from the newest
+func (q MessageQuery) tailPage(n int) (int, int) {
+	end := n
+	if q.Offset > 0 {
+		end = n - q.Offset
+	}
+	if end < 0 {
+		end = 0
+	}
+	start := 0
+	if q.Limit > 0 && end-q.Limit > 0 {
+		start = end - q.Limit
+	}
+	return start, end
 }
 
 //NewManager - create a storage manager
 func NewManager(conf conf.DBConf, log *logrus.Entry) (Manager, error) {
+	archive, err := NewArchiveStore(conf.Archive)
+	if err != nil {
+		return nil, err
+	}
 	switch conf.Type {
 	case "file":
 		return &filePlugin{
 			homePath: conf.HomePath,
+			archive:  archive,
 		}, nil
 	case "eventfile":
 		return &EventFilePlugin{
-			HomePath: conf.HomePath,
+			HomePath:     conf.HomePath,
+			ArchiveStore: archive,
 		}, nil
+	case "sqlite":
+		return newSQLitePlugin(conf, true, archive)
+	case "eventsqlite":
+		return newSQLitePlugin(conf, false, archive)
 	default:
 		return nil, fmt.Errorf("plugin not supported")
 	}
@@ -0,0 +1,205 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//SearchQuery query of the log search
+type SearchQuery struct {
+	//Query case-insensitive text the message contains
+	Query string
+	//Regex regular expression the message matches
+	Regex string
+	Level string
+	//IDs the services of the service logs, or the events of the event logs to search
+	IDs []string
+	//the time range and the page of the matched messages, the page counts back from the newest
+	MessageQuery
+}
+
+//SearchResult a matched log message
+type SearchResult struct {
+	ServiceID string `json:"service_id,omitempty"`
+	EventID   string `json:"event_id,omitempty"`
+	Level     string `json:"level,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Unixtime  int64  `json:"utime,omitempty"`
+	Message   string `json:"message"`
+}
+
+//Searcher is implemented by the plugins that support log search
+type Searcher interface {
+	//Search returns the page of the matched messages and the total number of matched messages
+	Search(query SearchQuery) ([]*SearchResult, int, error)
+}
+
+type matcher struct {
+	query string
+	regex *regexp.Regexp
+}
+
+func newMatcher(query SearchQuery) (*matcher, error) {
+	m := &matcher{query: strings.ToLower(query.Query)}
+	if query.Regex != "" {
+		regex, err := regexp.Compile(query.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %s", err.Error())
+		}
+		m.regex = regex
+	}
+	return m, nil
+}
+
+func (m *matcher) match(message string) bool {
+	if m.query != "" && !strings.Contains(strings.ToLower(message), m.query) {
+		return false
+	}
+	if m.regex != nil && !m.regex.MatchString(message) {
+		return false
+	}
+	return true
+}
+
+//searchRange the time range of the search, the last day if not set
+func (q SearchQuery) searchRange() MessageQuery {
+	r := MessageQuery{Since: q.Since, Until: q.Until}
+	if !r.ranged() {
+		r.Since = time.Now().Add(-24 * time.Hour).Unix()
+	}
+	return r
+}
+
+//page sorts the timestamped results and returns the page
+func (q SearchQuery) page(results []*SearchResult) ([]*SearchResult, int) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Unixtime < results[j].Unixtime
+	})
+	start, end := q.tailPage(len(results))
+	return results[start:end], len(results)
+}
+
+func searchEventMessages(eventID string, messages MessageDataList, m *matcher) []*SearchResult {
+	var results []*SearchResult
+	for _, message := range messages {
+		if !m.match(message.Message) {
+			continue
+		}
+		results = append(results, &SearchResult{
+			EventID:  eventID,
+			Level:    message.Level,
+			Time:     message.Time,
+			Unixtime: message.Unixtime,
+			Message:  message.Message,
+		})
+	}
+	return results
+}
+
+//Search searches the service logs of the days in the time range, the lines are not timestamped
+func (m *filePlugin) Search(query SearchQuery) ([]*SearchResult, int, error) {
+	matcher, err := newMatcher(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	var results []*SearchResult
+	for _, serviceID := range query.IDs {
+		lines, err := m.GetMessages(serviceID, "", query.searchRange())
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, line := range lines.([]string) {
+			if matcher.match(line) {
+				results = append(results, &SearchResult{ServiceID: serviceID, Message: line})
+			}
+		}
+	}
+	start, end := query.tailPage(len(results))
+	return results[start:end], len(results), nil
+}
+
+//Search searches the event logs in the time range
+func (m *EventFilePlugin) Search(query SearchQuery) ([]*SearchResult, int, error) {
+	matcher, err := newMatcher(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	var results []*SearchResult
+	for _, eventID := range query.IDs {
+		messages, err := m.GetMessages(eventID, query.Level, query.searchRange())
+		if err != nil {
+			return nil, 0, err
+		}
+		list, _ := messages.(MessageDataList)
+		results = append(results, searchEventMessages(eventID, list, matcher)...)
+	}
+	page, total := query.page(results)
+	return page, total, nil
+}
+
+//Search searches the messages in the time range, the text query is filtered by the database
+func (m *sqlitePlugin) Search(query SearchQuery) ([]*SearchResult, int, error) {
+	matcher, err := newMatcher(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(query.IDs) == 0 {
+		return nil, 0, nil
+	}
+	r := query.searchRange()
+	scope := m.conn.Table(m.table)
+	if m.dockerLog {
+		scope = scope.Where("service_id IN (?)", query.IDs)
+	} else {
+		scope = scope.Where("event_id IN (?) AND level IN (?)", query.IDs, visibleLevels(query.Level))
+	}
+	if r.Since > 0 {
+		scope = scope.Where("time>=?", r.Since)
+	}
+	if r.Until > 0 {
+		scope = scope.Where("time<=?", r.Until)
+	}
+	if query.Query != "" {
+		scope = scope.Where("message LIKE ?", "%"+query.Query+"%")
+	}
+	var records []*eventLogRecord
+	if err := scope.Order("time, id").Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	var results []*SearchResult
+	for _, r := range records {
+		if !matcher.match(r.Message) {
+			continue
+		}
+		results = append(results, &SearchResult{
+			ServiceID: r.ServiceID,
+			EventID:   r.EventID,
+			Level:     r.Level,
+			Time:      time.Unix(r.Time, 0).Format(time.RFC3339),
+			Unixtime:  r.Time,
+			Message:   r.Message,
+		})
+	}
+	page, total := query.page(results)
+	return page, total, nil
+}
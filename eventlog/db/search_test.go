@@ -0,0 +1,58 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEventFileSearch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	plugin := &EventFilePlugin{HomePath: dir}
+	now := time.Now().Format("2006-01-02T15:04:05.000")
+	for _, eventID := range []string{"event1", "event2"} {
+		if err := plugin.SaveMessage([]*EventLogMessage{
+			{EventID: eventID, Level: "info", Message: "pull image ok", Time: now},
+			{EventID: eventID, Level: "error", Message: "Connection refused by 10.0.0.1:3306", Time: now},
+			{EventID: eventID, Level: "debug", Message: "connection retry 1", Time: now},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, total, err := plugin.Search(SearchQuery{Query: "connection", IDs: []string{"event1", "event2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(results) != 2 || results[0].Level != "error" {
+		t.Fatalf("expected the 2 error messages, got %d %+v", total, results)
+	}
+	results, total, _ = plugin.Search(SearchQuery{Regex: `\d+\.\d+\.\d+\.\d+`, Level: "debug", IDs: []string{"event1"}})
+	if total != 1 || results[0].EventID != "event1" {
+		t.Fatalf("expected 1 message matches the regex, got %d", total)
+	}
+	if _, _, err := plugin.Search(SearchQuery{Regex: "(", IDs: []string{"event1"}}); err == nil {
+		t.Fatal("expected invalid regex error")
+	}
+}
@@ -0,0 +1,475 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/db"
+	"github.com/gridworkz/kato/eventlog/conf"
+	"github.com/gridworkz/kato/util"
+	"github.com/jinzhu/gorm"
+	// import sqlite dialect
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/sirupsen/logrus"
+)
+
+//eventLogRecord a message saved by the sqlite plugin
+type eventLogRecord struct {
+	ID        uint   `gorm:"column:id;primary_key"`
+	EventID   string `gorm:"column:event_id;size:64"`
+	ServiceID string `gorm:"column:service_id;size:64"`
+	Level     string `gorm:"column:level;size:10"`
+	Step      string `gorm:"column:step;size:64"`
+	Status    string `gorm:"column:status;size:20"`
+	Message   string `gorm:"column:message;type:text"`
+	Time      int64  `gorm:"column:time"`
+}
+
+//sqlitePlugin saves the event logs or service logs to a sqlite database
+type sqlitePlugin struct {
+	filename   string
+	table      string
+	dockerLog  bool
+	conn       *gorm.DB
+	archive    *ArchiveStore
+	keepDays   int
+	lock       sync.Mutex
+	serviceIDs map[string]string
+}
+
+func newSQLitePlugin(conf conf.DBConf, dockerLog bool, archive *ArchiveStore) (*sqlitePlugin, error) {
+	filename := conf.SQLitePath
+	if filename == "" {
+		filename = path.Join(conf.HomePath, "eventlog.db")
+	}
+	if err := util.CheckAndCreateDir(path.Dir(filename)); err != nil {
+		return nil, err
+	}
+	conn, err := openSQLite(filename)
+	if err != nil {
+		return nil, err
+	}
+	m := &sqlitePlugin{
+		filename:   filename,
+		table:      "event_log_messages",
+		dockerLog:  dockerLog,
+		conn:       conn,
+		archive:    archive,
+		keepDays:   conf.SQLiteKeepDays,
+		serviceIDs: make(map[string]string),
+	}
+	if dockerLog {
+		m.table = "service_log_messages"
+	}
+	if err := m.migrate(); err != nil {
+		closeSQLite(filename)
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *sqlitePlugin) migrate() error {
+	if err := m.conn.Table(m.table).AutoMigrate(&eventLogRecord{}).Error; err != nil {
+		return fmt.Errorf("migrate table %s failure %s", m.table, err.Error())
+	}
+	indexes := map[string][]string{
+		"idx_" + m.table + "_event_time":   {"event_id", "time"},
+		"idx_" + m.table + "_service_time": {"service_id", "time"},
+		"idx_" + m.table + "_level":        {"level"},
+		"idx_" + m.table + "_time":         {"time"},
+	}
+	for name, columns := range indexes {
+		if m.conn.Dialect().HasIndex(m.table, name) {
+			continue
+		}
+		if err := m.conn.Table(m.table).AddIndex(name, columns...).Error; err != nil {
+			return fmt.Errorf("create index %s failure %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+//SaveMessage
+func (m *sqlitePlugin) SaveMessage(events []*EventLogMessage) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var serviceID string
+	if !m.dockerLog {
+		serviceID = m.serviceID(events[0].EventID)
+	}
+	lastTime := time.Now().Unix()
+	tx := m.conn.Begin()
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		if e.Time != "" {
+			if logtime := GetTimeUnix(e.Time); logtime != 0 {
+				lastTime = logtime
+			}
+		}
+		record := &eventLogRecord{
+			EventID:   e.EventID,
+			ServiceID: serviceID,
+			Level:     levelName(e.Level),
+			Step:      e.Step,
+			Status:    e.Status,
+			Message:   e.Message,
+			Time:      lastTime,
+		}
+		if m.dockerLog {
+			record.EventID = ""
+			record.Level = ""
+			record.ServiceID = e.EventID
+			record.Message = string(e.Content)
+		}
+		if err := tx.Table(m.table).Create(record).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+//GetMessages returns MessageDataList of event logs or lines of service logs like the file plugins.
+//The page of service logs counts back from the newest line.
+func (m *sqlitePlugin) GetMessages(id, level string, query MessageQuery) (interface{}, error) {
+	if m.dockerLog {
+		return m.getServiceMessages(id, query)
+	}
+	scope := m.conn.Table(m.table).Where("event_id=? AND level IN (?)", id, visibleLevels(level))
+	if query.Since > 0 {
+		scope = scope.Where("time>=?", query.Since)
+	}
+	if query.Until > 0 {
+		scope = scope.Where("time<=?", query.Until)
+	}
+	if query.Offset > 0 {
+		// sqlite does not support offset without limit
+		scope = scope.Offset(query.Offset).Limit(math.MaxInt32)
+	}
+	if query.Limit > 0 {
+		scope = scope.Limit(query.Limit)
+	}
+	var records []*eventLogRecord
+	if err := scope.Order("time, id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 && m.archive != nil {
+		archived, err := m.archive.Download(eventArchiveKey(id))
+		if err != nil {
+			return MessageDataList{}, nil
+		}
+		defer os.Remove(archived)
+		return readEventLogFile(archived, level, query)
+	}
+	message := make(MessageDataList, 0, len(records))
+	for _, r := range records {
+		message = append(message, MessageData{
+			Message:  r.Message,
+			Unixtime: r.Time,
+			Time:     time.Unix(r.Time, 0).Format(time.RFC3339),
+			Level:    r.Level,
+		})
+	}
+	return message, nil
+}
+
+func (m *sqlitePlugin) getServiceMessages(serviceID string, query MessageQuery) (interface{}, error) {
+	if !query.ranged() && query.Limit <= 0 {
+		return nil, nil
+	}
+	scope := m.conn.Table(m.table).Where("service_id=?", serviceID)
+	if query.Since > 0 {
+		scope = scope.Where("time>=?", query.Since)
+	}
+	if query.Until > 0 {
+		scope = scope.Where("time<=?", query.Until)
+	}
+	if query.Limit > 0 {
+		scope = scope.Limit(query.Offset + query.Limit)
+	}
+	var records []*eventLogRecord
+	if err := scope.Order("id desc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		lines = append(lines, records[i].Message)
+	}
+	if query.ranged() && m.archive != nil && (query.Limit <= 0 || len(lines) < query.Offset+query.Limit) {
+		lines = append(m.archivedServiceLines(serviceID, query), lines...)
+	}
+	start, end := query.tailPage(len(lines))
+	return lines[start:end], nil
+}
+
+//archivedServiceLines reads the archived service logs of the days in the time range
+func (m *sqlitePlugin) archivedServiceLines(serviceID string, query MessageQuery) []string {
+	until := m.archive.Before()
+	if query.Until > 0 && query.Until < until.Unix() {
+		until = time.Unix(query.Until, 0)
+	}
+	since := until.AddDate(0, 0, -maxRangeDays)
+	if query.Since > since.Unix() {
+		since = time.Unix(query.Since, 0)
+	}
+	var lines []string
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		archived, err := m.archive.Download(dockerArchiveKey(GetServiceAliasID(serviceID), dayName(day)))
+		if err != nil {
+			continue
+		}
+		dayLines, err := readZipLines(archived)
+		os.Remove(archived)
+		if err != nil {
+			logrus.Warningf("read archived service log of %s failure %s", dayName(day), err.Error())
+			continue
+		}
+		lines = append(lines, dayLines...)
+	}
+	return lines
+}
+
+//Archive moves the messages older than the keep days of the archive store to it, and deletes the messages
+//older than the keep days of the plugin whether or not the archive store is set
+func (m *sqlitePlugin) Archive() error {
+	if m.archive != nil {
+		if err := m.archiveRecords(); err != nil {
+			return err
+		}
+	}
+	return m.deleteExpired(time.Now())
+}
+
+//deleteExpired deletes the messages older than the keep days, the messages the archive store keeps longer
+//are not archived yet and kept
+func (m *sqlitePlugin) deleteExpired(now time.Time) error {
+	if m.keepDays <= 0 {
+		return nil
+	}
+	before := now.AddDate(0, 0, -m.keepDays)
+	if m.archive != nil && m.archive.Before().Before(before) {
+		before = m.archive.Before()
+	}
+	db := m.conn.Table(m.table).Where("time<?", before.Unix()).Delete(eventLogRecord{})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected > 0 {
+		logrus.Infof("deleted %d messages before %s from %s", db.RowsAffected, before.Format(time.RFC3339), m.table)
+	}
+	return nil
+}
+
+func (m *sqlitePlugin) archiveRecords() error {
+	before := m.archive.Before().Unix()
+	column := "event_id"
+	if m.dockerLog {
+		column = "service_id"
+	}
+	var ids []string
+	if err := m.conn.Table(m.table).Where("time<?", before).Pluck("DISTINCT "+column, &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		var records []*eventLogRecord
+		if err := m.conn.Table(m.table).Where(column+"=? AND time<?", id, before).Order("time, id").Find(&records).Error; err != nil {
+			return err
+		}
+		var err error
+		if m.dockerLog {
+			err = m.archiveServiceLog(id, records)
+		} else {
+			err = m.archiveEventLog(id, records)
+		}
+		if err != nil {
+			logrus.Errorf("archive logs of %s failure %s", id, err.Error())
+			continue
+		}
+		if err := m.conn.Table(m.table).Where(column+"=? AND time<?", id, before).Delete(eventLogRecord{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//archiveEventLog appends the messages to the archived event log file
+func (m *sqlitePlugin) archiveEventLog(eventID string, records []*eventLogRecord) error {
+	objkey := eventArchiveKey(eventID)
+	filename, err := m.archive.Download(objkey)
+	if err != nil {
+		f, err := ioutil.TempFile("", "eventlog-archive-")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		filename = f.Name()
+	}
+	defer os.Remove(filename)
+	writeFile, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		writeFile.Write(GetLevelFlag(r.Level))
+		writeFile.Write([]byte(fmt.Sprintf("%d ", r.Time)))
+		writeFile.Write([]byte(r.Message))
+		writeFile.Write([]byte("\n"))
+	}
+	if err := writeFile.Close(); err != nil {
+		return err
+	}
+	return m.archive.Upload(objkey, filename)
+}
+
+//archiveServiceLog appends the messages to the archived daily log files of the service
+func (m *sqlitePlugin) archiveServiceLog(serviceID string, records []*eventLogRecord) error {
+	var days []string
+	lines := make(map[string][]string)
+	for _, r := range records {
+		day := dayName(time.Unix(r.Time, 0))
+		if _, ok := lines[day]; !ok {
+			days = append(days, day)
+		}
+		lines[day] = append(lines[day], r.Message)
+	}
+	aliasID := GetServiceAliasID(serviceID)
+	for _, day := range days {
+		objkey := dockerArchiveKey(aliasID, day)
+		dayLines := lines[day]
+		if archived, err := m.archive.Download(objkey); err == nil {
+			existing, err := readZipLines(archived)
+			os.Remove(archived)
+			if err != nil {
+				return err
+			}
+			dayLines = append(existing, dayLines...)
+		}
+		f, err := ioutil.TempFile("", "eventlog-archive-")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		defer os.Remove(f.Name())
+		if err := writeZipLines(f.Name(), dayLines); err != nil {
+			return err
+		}
+		if err := m.archive.Upload(objkey, f.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//serviceID returns the service id of the event, it is empty if the event is not found
+func (m *sqlitePlugin) serviceID(eventID string) string {
+	m.lock.Lock()
+	serviceID, ok := m.serviceIDs[eventID]
+	m.lock.Unlock()
+	if ok {
+		return serviceID
+	}
+	if manager := db.GetManager(); manager != nil {
+		if event, err := manager.ServiceEventDao().GetEventByEventID(eventID); err == nil {
+			serviceID = event.ServiceID
+		}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.serviceIDs) >= 10000 {
+		m.serviceIDs = make(map[string]string)
+	}
+	m.serviceIDs[eventID] = serviceID
+	return serviceID
+}
+
+//Close
+func (m *sqlitePlugin) Close() error {
+	return closeSQLite(m.filename)
+}
+
+//levelName the level saved, unknown levels are treated as error like the eventfile plugin
+func levelName(level string) string {
+	switch level {
+	case "info", "debug":
+		return level
+	default:
+		return "error"
+	}
+}
+
+//visibleLevels the saved levels visible in the query level, see CheckLevel
+func visibleLevels(level string) []string {
+	switch level {
+	case "error":
+		return []string{"error"}
+	case "debug":
+		return []string{"error", "info", "debug"}
+	default:
+		return []string{"error", "info"}
+	}
+}
+
+type sqliteConn struct {
+	conn *gorm.DB
+	refs int
+}
+
+//the event log plugin and the service log plugin share the connection of the same file
+var sqliteLock sync.Mutex
+var sqliteConns = make(map[string]*sqliteConn)
+
+func openSQLite(filename string) (*gorm.DB, error) {
+	sqliteLock.Lock()
+	defer sqliteLock.Unlock()
+	if c, ok := sqliteConns[filename]; ok {
+		c.refs++
+		return c.conn, nil
+	}
+	conn, err := gorm.Open("sqlite3", filename+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database %s failure %s", filename, err.Error())
+	}
+	conn.DB().SetMaxOpenConns(1)
+	sqliteConns[filename] = &sqliteConn{conn: conn, refs: 1}
+	return conn, nil
+}
+
+func closeSQLite(filename string) error {
+	sqliteLock.Lock()
+	defer sqliteLock.Unlock()
+	c, ok := sqliteConns[filename]
+	if !ok {
+		return nil
+	}
+	c.refs--
+	if c.refs > 0 {
+		return nil
+	}
+	delete(sqliteConns, filename)
+	return c.conn.Close()
+}
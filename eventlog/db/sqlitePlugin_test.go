@@ -0,0 +1,127 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/gridworkz/kato/eventlog/conf"
+)
+
+func TestSQLitePluginGetMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	plugin, err := newSQLitePlugin(conf.DBConf{SQLitePath: path.Join(dir, "eventlog.db")}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Close()
+	start := time.Now().Add(-time.Hour)
+	var events []*EventLogMessage
+	for i, level := range []string{"info", "debug", "error", "info", "info"} {
+		events = append(events, &EventLogMessage{
+			EventID: "event1",
+			Level:   level,
+			Message: level,
+			Time:    start.Add(time.Duration(i) * time.Minute).Format("2006-01-02T15:04:05.000"),
+		})
+	}
+	if err := plugin.SaveMessage(events); err != nil {
+		t.Fatal(err)
+	}
+	list, err := plugin.GetMessages("event1", "info", MessageQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages := list.(MessageDataList); len(messages) != 4 {
+		t.Fatalf("expected 4 info messages, got %d", len(messages))
+	}
+	list, _ = plugin.GetMessages("event1", "debug", MessageQuery{Offset: 1, Limit: 2})
+	messages := list.(MessageDataList)
+	if len(messages) != 2 || messages[0].Message != "debug" || messages[1].Message != "error" {
+		t.Fatalf("unexpected page %v", messages)
+	}
+	list, _ = plugin.GetMessages("event1", "debug", MessageQuery{Since: start.Add(3 * time.Minute).Unix()})
+	if messages := list.(MessageDataList); len(messages) != 2 {
+		t.Fatalf("expected 2 messages in the time range, got %d", len(messages))
+	}
+}
+
+func TestSQLitePluginDeleteExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	plugin, err := newSQLitePlugin(conf.DBConf{SQLitePath: path.Join(dir, "eventlog.db"), SQLiteKeepDays: 7}, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Close()
+	now := time.Now()
+	var logs []*EventLogMessage
+	for _, days := range []int{10, 8, 1} {
+		logs = append(logs, &EventLogMessage{
+			EventID: "service1",
+			Content: []byte("line"),
+			Time:    now.AddDate(0, 0, -days).Format("2006-01-02T15:04:05.000"),
+		})
+	}
+	for _, l := range logs {
+		if err := plugin.SaveMessage([]*EventLogMessage{l}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := plugin.Archive(); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	plugin.conn.Table(plugin.table).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected 1 message in the keep days, got %d", count)
+	}
+}
+
+func TestMessageQueryPage(t *testing.T) {
+	tests := []struct {
+		query      MessageQuery
+		start, end int
+		tailStart  int
+		tailEnd    int
+	}{
+		{MessageQuery{}, 0, 10, 0, 10},
+		{MessageQuery{Limit: 3}, 0, 3, 7, 10},
+		{MessageQuery{Offset: 2, Limit: 3}, 2, 5, 5, 8},
+		{MessageQuery{Offset: 12, Limit: 3}, 10, 10, 0, 0},
+	}
+	for _, test := range tests {
+		if start, end := test.query.page(10); start != test.start || end != test.end {
+			t.Errorf("page of %+v: expected [%d,%d), got [%d,%d)", test.query, test.start, test.end, start, end)
+		}
+		if start, end := test.query.tailPage(10); start != test.tailStart || end != test.tailEnd {
+			t.Errorf("tail page of %+v: expected [%d,%d), got [%d,%d)", test.query, test.tailStart, test.tailEnd, start, end)
+		}
+	}
+}
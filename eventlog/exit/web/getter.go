@@ -20,9 +20,13 @@ package web
 
 import (
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
+	katodb "github.com/gridworkz/kato/db"
+	"github.com/gridworkz/kato/eventlog/db"
 	httputil "github.com/gridworkz/kato/util/http"
 )
 
@@ -36,3 +40,112 @@ func (s *SocketServer) getDockerLogs(w http.ResponseWriter, r *http.Request) {
 	loglist := s.storemanager.GetDockerLogs(serviceID, rows)
 	httputil.ReturnSuccess(r, w, loglist)
 }
+
+//searchLogs search the persisted service logs or event logs of the services, the tenant or the events
+func (s *SocketServer) searchLogs(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	kind := values.Get("kind")
+	if kind == "" {
+		kind = "service"
+	}
+	if kind != "service" && kind != "event" {
+		httputil.ReturnError(r, w, 400, "kind must be service or event")
+		return
+	}
+	query := db.SearchQuery{
+		Query: values.Get("query"),
+		Regex: values.Get("regex"),
+		Level: values.Get("level"),
+	}
+	if query.Regex != "" {
+		if _, err := regexp.Compile(query.Regex); err != nil {
+			httputil.ReturnError(r, w, 400, "invalid regex: "+err.Error())
+			return
+		}
+	}
+	query.Since, _ = strconv.ParseInt(values.Get("since"), 10, 64)
+	query.Until, _ = strconv.ParseInt(values.Get("until"), 10, 64)
+	query.Offset, _ = strconv.Atoi(values.Get("offset"))
+	query.Limit, _ = strconv.Atoi(values.Get("limit"))
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	serviceIDs := splitValues(values["service_id"])
+	// the api proxies always scope the search by tenant or service
+	scoped := len(serviceIDs) > 0 || values.Get("tenant_id") != ""
+	if tenantID := values.Get("tenant_id"); tenantID != "" {
+		services, err := katodb.GetManager().TenantServiceDao().GetServicesByTenantID(tenantID)
+		if err != nil {
+			httputil.ReturnError(r, w, 500, "list services of tenant failure: "+err.Error())
+			return
+		}
+		// only the services of the tenant are searched
+		filter := make(map[string]bool)
+		for _, id := range append(serviceIDs, splitValues(values["service_alias"])...) {
+			filter[id] = true
+		}
+		serviceIDs = nil
+		for _, service := range services {
+			if len(filter) == 0 || filter[service.ServiceID] || filter[service.ServiceAlias] {
+				serviceIDs = append(serviceIDs, service.ServiceID)
+			}
+		}
+	}
+	eventServices := make(map[string]string)
+	if kind == "service" {
+		query.IDs = serviceIDs
+	} else {
+		eventIDs := make(map[string]bool)
+		for _, eventID := range splitValues(values["event_id"]) {
+			eventIDs[eventID] = true
+		}
+		if !scoped {
+			for eventID := range eventIDs {
+				query.IDs = append(query.IDs, eventID)
+			}
+		}
+		for _, serviceID := range serviceIDs {
+			events, err := katodb.GetManager().ServiceEventDao().GetEventByServiceID(serviceID)
+			if err != nil {
+				httputil.ReturnError(r, w, 500, "list events of service failure: "+err.Error())
+				return
+			}
+			for _, event := range events {
+				// event_id only narrows the events of the scoped services
+				if len(eventIDs) > 0 && !eventIDs[event.EventID] {
+					continue
+				}
+				query.IDs = append(query.IDs, event.EventID)
+				eventServices[event.EventID] = serviceID
+			}
+		}
+	}
+	if len(query.IDs) == 0 {
+		httputil.ReturnList(r, w, 0, 1, []*db.SearchResult{})
+		return
+	}
+	results, total, err := s.storemanager.SearchLogs(kind, query)
+	if err != nil {
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	for _, result := range results {
+		if result.ServiceID == "" {
+			result.ServiceID = eventServices[result.EventID]
+		}
+	}
+	httputil.ReturnList(r, w, total, query.Offset/query.Limit+1, results)
+}
+
+//splitValues splits the comma separated query values
+func splitValues(values []string) []string {
+	var re []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				re = append(re, v)
+			}
+		}
+	}
+	return re
+}
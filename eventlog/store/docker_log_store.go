@@ -271,7 +271,7 @@ func (h *dockerLogStore) GetHistoryMessage(eventID string, length int) (re []str
 		}
 		return 0
 	}()
-	result, err := h.filePlugin.GetMessages(eventID, "", filelength)
+	result, err := h.filePlugin.GetMessages(eventID, "", db.MessageQuery{Limit: filelength})
 	if result == nil || err != nil {
 		return re
 	}
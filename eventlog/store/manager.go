@@ -53,6 +53,7 @@ type Manager interface {
 	PubMessageChan() chan [][]byte
 	DockerLogMessageChan() chan []byte
 	GetDockerLogs(serviceID string, length int) []string
+	SearchLogs(kind string, query db.SearchQuery) ([]*db.SearchResult, int, error)
 	MonitorMessageChan() chan [][]byte
 	WebSocketMessageChan(mode, eventID, subID string) chan *db.EventLogMessage
 	NewMonitorMessageChan() chan []byte
@@ -67,12 +68,18 @@ type Manager interface {
 
 //NewManager
 func NewManager(conf conf.EventStoreConf, log *logrus.Entry) (Manager, error) {
-	conf.DB.Type = "eventfile"
+	conf.DB.Type = conf.DB.EventPlugin
+	if conf.DB.Type == "" {
+		conf.DB.Type = "eventfile"
+	}
 	dbPlugin, err := db.NewManager(conf.DB, log)
 	if err != nil {
 		return nil, err
 	}
-	conf.DB.Type = "file"
+	conf.DB.Type = conf.DB.DockerLogPlugin
+	if conf.DB.Type == "" {
+		conf.DB.Type = "file"
+	}
 	filePlugin, err := db.NewManager(conf.DB, log)
 	if err != nil {
 		return nil, err
@@ -271,7 +278,14 @@ func (s *storeManager) Run() error {
 // clean event log that before 30 days message in every 24h
 func (s *storeManager) cleanLog() {
 	coreutil.Exec(s.context, func() error {
-		//do something
+		//move old logs to the archive before clean
+		for _, plugin := range []db.Manager{s.dbPlugin, s.filePlugin} {
+			if archiver, ok := plugin.(db.Archiver); ok {
+				if err := archiver.Archive(); err != nil {
+					logrus.Errorf("archive history log error. %s", err.Error())
+				}
+			}
+		}
 		pathname := s.conf.DB.HomePath
 		logrus.Infof("start clean history service log %s", pathname)
 		files, err := coreutil.GetFileList(pathname, 2)
@@ -525,3 +539,16 @@ func (s *storeManager) Error() chan error {
 func (s *storeManager) GetDockerLogs(serviceID string, length int) []string {
 	return s.dockerLogStore.GetHistoryMessage(serviceID, length)
 }
+
+//SearchLogs search the persisted service logs or event logs, kind is service or event
+func (s *storeManager) SearchLogs(kind string, query db.SearchQuery) ([]*db.SearchResult, int, error) {
+	plugin := s.filePlugin
+	if kind == "event" {
+		plugin = s.dbPlugin
+	}
+	searcher, ok := plugin.(db.Searcher)
+	if !ok {
+		return nil, 0, fmt.Errorf("the persistence plugin of %s logs does not support search", kind)
+	}
+	return searcher.Search(query)
+}
@@ -117,6 +117,8 @@ type Location struct {
 	//PathRewrite if true, path will not passed to the upstream
 	PathRewrite   bool
 	NameCondition map[string]*v1.Condition
+	//WakeServiceID the idle component to wake up before proxying the requests
+	WakeServiceID string
 
 	// Proxy contains information about timeouts and buffer sizes
 	// to be used in connections against endpoints
@@ -234,6 +234,7 @@ func (o *OrService) getNgxServer(conf *v1.Config) (l7srv []*model.Server, l4srv
 				Rewrite:                        loc.Rewrite,
 				PathRewrite:                    false,
 				DisableProxyPass:               loc.DisableProxyPass,
+				WakeServiceID:                  loc.WakeServiceID,
 			}
 			server.Locations = append(server.Locations, location)
 		}
@@ -123,6 +123,10 @@ func buildLuaHeaderRouter(input interface{}) string {
 			out = append(out, priority[i])
 		}
 	}
+	if loc.WakeServiceID != "" {
+		// hold the request until the idle component is woken up
+		out = append(out, fmt.Sprintf("\t\t\twake.call(\"%s\")", loc.WakeServiceID))
+	}
 
 	out = append(out, "\t\t}")
 
@@ -42,6 +42,7 @@ import (
 	"github.com/gridworkz/kato/gateway/util"
 	v1 "github.com/gridworkz/kato/gateway/v1"
 	coreutil "github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/util/constants"
 	istroe "github.com/gridworkz/kato/util/ingress-nginx/ingress/controller/store"
 	ik8s "github.com/gridworkz/kato/util/ingress-nginx/k8s"
 	"github.com/sirupsen/logrus"
@@ -603,6 +604,9 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 						// the first ingress proxy takes effect
 						location.Proxy = anns.Proxy
 					}
+					if isIdle(ing) {
+						location.WakeServiceID = anns.Labels["service_id"]
+					}
 					// If their ServiceName is the same, then the new one will overwrite the old one.
 					nameCondition := &v1.Condition{}
 					var backendName string
@@ -728,6 +732,10 @@ func (s *k8sStore) ingressIsValid(ing *networkingv1.Ingress) bool {
 		logrus.Errorf("Cant not convert %v to %v", reflect.TypeOf(item), reflect.TypeOf(endpoint))
 		return false
 	}
+	if ing.Spec.DefaultBackend == nil && isIdle(ing) {
+		// the component is scaled to 0 by its idle policy, keep the rule to wake it up
+		return true
+	}
 	if len(endpoint.Subsets) == 0 {
 		logrus.Debugf("Endpoints(%s) is empty, ignore it", endpointKey)
 		return false
@@ -740,6 +748,10 @@ func (s *k8sStore) ingressIsValid(ing *networkingv1.Ingress) bool {
 	return true
 }
 
+func isIdle(ing *networkingv1.Ingress) bool {
+	return ing.Annotations[constants.IdleAnnotation] == "true"
+}
+
 func hasReadyAddresses(endpoints *corev1.Endpoints) bool {
 	for _, ep := range endpoints.Subsets {
 		if len(ep.Addresses) > 0 {
@@ -47,6 +47,10 @@ type Location struct {
 	// +optional
 	Proxy            proxy.Config `json:"proxy,omitempty"`
 	DisableProxyPass bool
+	// WakeServiceID is the id of the idle component behind the location, the requests
+	// wake it up and wait until it is ready
+	// +optional
+	WakeServiceID string
 }
 
 // Condition is the condition that the traffic can reach the specified backend
@@ -80,6 +84,10 @@ func (l *Location) Equals(c *Location) bool {
 		return false
 	}
 
+	if l.WakeServiceID != c.WakeServiceID {
+		return false
+	}
+
 	return true
 }
 
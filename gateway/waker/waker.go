@@ -0,0 +1,129 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gridworkz/kato/mq/client"
+	"github.com/gridworkz/kato/worker/discover/model"
+	"github.com/sirupsen/logrus"
+)
+
+//resendInterval the worker is still waking the component up within it
+var resendInterval = time.Second * 30
+
+//Waker sends the wake requests of the idle components to the workers. The requests
+//are recorded by the openresty when it holds the first requests of the components.
+type Waker struct {
+	statusPort int
+	mqClient   client.MQClient
+	interval   time.Duration
+	// the last time the wake task of the component is sent
+	sent map[string]time.Time
+}
+
+//New create a waker
+func New(statusPort int, mqClient client.MQClient) *Waker {
+	return &Waker{
+		statusPort: statusPort,
+		mqClient:   mqClient,
+		interval:   time.Second,
+		sent:       make(map[string]time.Time),
+	}
+}
+
+//Run takes the wake requests from the openresty until the context is done
+func (w *Waker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			serviceIDs, err := w.pending()
+			if err != nil {
+				logrus.Debugf("get wake requests: %v", err)
+				continue
+			}
+			for _, serviceID := range w.due(serviceIDs, now) {
+				w.wake(serviceID)
+			}
+		}
+	}
+}
+
+//pending the components whose requests are held by the openresty
+func (w *Waker) pending() ([]string, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/wake", w.statusPort))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var serviceIDs []string
+	if err := json.Unmarshal(body, &serviceIDs); err != nil {
+		return nil, err
+	}
+	return serviceIDs, nil
+}
+
+//due filters out the components whose wake tasks are sent in the resend interval
+func (w *Waker) due(serviceIDs []string, now time.Time) []string {
+	for serviceID, last := range w.sent {
+		if now.Sub(last) >= resendInterval {
+			delete(w.sent, serviceID)
+		}
+	}
+	var re []string
+	for _, serviceID := range serviceIDs {
+		if _, ok := w.sent[serviceID]; ok {
+			continue
+		}
+		w.sent[serviceID] = now
+		re = append(re, serviceID)
+	}
+	return re
+}
+
+func (w *Waker) wake(serviceID string) {
+	err := w.mqClient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "wake",
+		TaskBody: model.WakeTaskBody{ServiceID: serviceID},
+		Topic:    client.WorkerTopic,
+	})
+	if err != nil {
+		logrus.Errorf("send wake task of component %s: %v", serviceID, err)
+		// the request is recorded again while it is held, retry it then
+		delete(w.sent, serviceID)
+		return
+	}
+	logrus.Infof("wake idle component %s", serviceID)
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDue(t *testing.T) {
+	w := New(18080, nil)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	if got := w.due([]string{"a", "b"}, now); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("expect [a b], got %v", got)
+	}
+	// the worker is waking them up
+	if got := w.due([]string{"a", "c"}, now.Add(time.Second)); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Fatalf("expect [c], got %v", got)
+	}
+	// still held after the resend interval, send it again
+	if got := w.due([]string{"a"}, now.Add(resendInterval)); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("expect [a], got %v", got)
+	}
+	if _, ok := w.sent["b"]; ok {
+		t.Fatalf("expect b to be forgotten")
+	}
+}
@@ -15,6 +15,7 @@ require (
 	github.com/bitly/go-simplejson v0.5.0
 	github.com/bluebreezecf/opentsdb-goclient v0.0.0-20190921120552-796138372df3
 	github.com/cockroachdb/cmux v0.0.0-20170110192607-30d10be49292 // indirect
+	github.com/coreos/bbolt v1.3.2
 	github.com/coreos/etcd v3.3.17+incompatible
 	github.com/coreos/prometheus-operator v0.41.1
 	github.com/creack/pty v1.1.11 // indirect
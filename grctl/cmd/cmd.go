@@ -43,6 +43,9 @@ func GetCmds() []cli.Command {
 	cmds = append(cmds, NewCmdGateway())
 	cmds = append(cmds, NewCmdEnvoy())
 	cmds = append(cmds, NewCmdConfig())
+	cmds = append(cmds, NewCmdLogs())
+	cmds = append(cmds, NewCmdVolume())
+	cmds = append(cmds, NewCmdToken())
 	return cmds
 }
 
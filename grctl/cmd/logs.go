@@ -0,0 +1,144 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/api/region"
+	"github.com/gridworkz/kato/grctl/clients"
+	"github.com/urfave/cli"
+)
+
+//NewCmdLogs logs cmd
+func NewCmdLogs() cli.Command {
+	c := cli.Command{
+		Name:  "logs",
+		Usage: "about application logs，grctl logs -h",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name: "search",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:     "tenantAlias,t",
+						Value:    "",
+						Usage:    "Specify the tenant alias",
+						FilePath: GetTenantNamePath(),
+					},
+					cli.StringSliceFlag{
+						Name:  "service,s",
+						Usage: "Specify the service alias, all services of the tenant are searched if not set",
+					},
+					cli.StringFlag{
+						Name:  "kind",
+						Value: "service",
+						Usage: "the logs to search, service or event",
+					},
+					cli.StringFlag{
+						Name:  "regex,r",
+						Usage: "regular expression the log matches",
+					},
+					cli.StringFlag{
+						Name:  "level",
+						Usage: "the level of event logs, error, info or debug",
+					},
+					cli.StringFlag{
+						Name:  "since",
+						Value: "24h",
+						Usage: "search the logs since the time, a duration like 2h or a RFC3339 time",
+					},
+					cli.StringFlag{
+						Name:  "until",
+						Usage: "search the logs until the time, a duration like 2h or a RFC3339 time",
+					},
+					cli.IntFlag{
+						Name:  "offset",
+						Usage: "skip the newest matched logs",
+					},
+					cli.IntFlag{
+						Name:  "limit",
+						Value: 100,
+						Usage: "the max number of matched logs to show",
+					},
+				},
+				Usage: "Search the persisted logs. For example <grctl logs search -t gridworkz -s gr2a2e1b \"connection refused\">",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return searchLogs(c)
+				},
+			},
+		},
+	}
+	return c
+}
+
+//parseLogTime parses a duration before now or a RFC3339 time
+func parseLogTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func searchLogs(c *cli.Context) error {
+	tenantName := c.String("tenantAlias")
+	if tenantName == "" {
+		showError("tenant alias can not be empty")
+	}
+	since, err := parseLogTime(c.String("since"))
+	if err != nil {
+		showError(fmt.Sprintf("invalid since %s", err.Error()))
+	}
+	until, err := parseLogTime(c.String("until"))
+	if err != nil {
+		showError(fmt.Sprintf("invalid until %s", err.Error()))
+	}
+	opt := region.LogSearchOption{
+		Kind:           c.String("kind"),
+		Query:          strings.Join(c.Args(), " "),
+		Regex:          c.String("regex"),
+		Level:          c.String("level"),
+		ServiceAliases: c.StringSlice("service"),
+		Since:          since,
+		Until:          until,
+		Offset:         c.Int("offset"),
+		Limit:          c.Int("limit"),
+	}
+	results, total, apierr := clients.RegionClient.Tenants(tenantName).SearchLogs(opt)
+	if apierr != nil {
+		showError(apierr.Error())
+	}
+	for _, result := range results {
+		source := result.ServiceID
+		if result.EventID != "" {
+			source = result.EventID
+		}
+		if result.Level != "" {
+			fmt.Printf("[%s](%s) %s: %s\n", strings.ToUpper(result.Level), result.Time, source, result.Message)
+			continue
+		}
+		fmt.Printf("%s: %s\n", source, result.Message)
+	}
+	fmt.Printf("%d of %d matched logs\n", len(results), total)
+	return nil
+}
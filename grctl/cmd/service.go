@@ -219,7 +219,7 @@ func getEventLog(c *cli.Context) error {
 		logdb := &eventdb.EventFilePlugin{
 			HomePath: constants.GrdataLogPath,
 		}
-		list, err := logdb.GetMessages(eventID, "debug", 0)
+		list, err := logdb.GetMessages(eventID, "debug", eventdb.MessageQuery{})
 		if err != nil {
 			return err
 		}
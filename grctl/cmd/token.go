@@ -0,0 +1,146 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gridworkz/kato/api/model"
+	"github.com/gridworkz/kato/grctl/clients"
+	"github.com/gridworkz/kato/util/termtables"
+	"github.com/urfave/cli"
+)
+
+//NewCmdToken token cmd
+func NewCmdToken() cli.Command {
+	c := cli.Command{
+		Name:  "token",
+		Usage: "manage the named tokens of the region api，grctl token -h",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name: "create",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "name,n",
+						Usage: "Specify the token name",
+					},
+					cli.StringFlag{
+						Name:  "role,r",
+						Value: "viewer",
+						Usage: "Specify the role, viewer, developer or admin",
+					},
+					cli.StringFlag{
+						Name:  "scope,s",
+						Value: "tenant",
+						Usage: "Specify the scope, enterprise, tenant or app",
+					},
+					cli.StringFlag{
+						Name:  "eid",
+						Usage: "Specify the enterprise id of the enterprise scope, all the enterprises if it is empty",
+					},
+					cli.StringFlag{
+						Name:  "tenantAlias,t",
+						Usage: "Specify the tenant alias of the tenant scope",
+					},
+					cli.StringFlag{
+						Name:  "app,a",
+						Usage: "Specify the app id of the app scope",
+					},
+					cli.IntFlag{
+						Name:  "expire-days",
+						Usage: "Specify the days the token is valid, it never expires if it is 0",
+					},
+				},
+				Usage: "Create a token. For example <grctl token create -n ci -r developer -s tenant -t gridworkz>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return createToken(c)
+				},
+			},
+			cli.Command{
+				Name:  "list",
+				Usage: "List the tokens. For example <grctl token list>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return listTokens(c)
+				},
+			},
+			cli.Command{
+				Name:  "revoke",
+				Usage: "Revoke a token. For example <grctl token revoke ci>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return revokeToken(c)
+				},
+			},
+		},
+	}
+	return c
+}
+
+func createToken(c *cli.Context) error {
+	req := &model.CreateAPITokenReq{
+		Name:         c.String("name"),
+		Role:         c.String("role"),
+		Scope:        c.String("scope"),
+		EnterpriseID: c.String("eid"),
+		TenantName:   c.String("tenantAlias"),
+		AppID:        c.String("app"),
+		ExpireDays:   c.Int("expire-days"),
+	}
+	if req.Name == "" {
+		showError("token name can not be empty")
+	}
+	info, err := clients.RegionClient.Tokens().Create(req)
+	if err != nil {
+		showError(err.Error())
+	}
+	fmt.Printf("Token %s is created, it can not be shown again:\n%s\n", info.Name, info.Token)
+	return nil
+}
+
+func listTokens(c *cli.Context) error {
+	tokens, err := clients.RegionClient.Tokens().List()
+	if err != nil {
+		showError(err.Error())
+	}
+	table := termtables.CreateTable()
+	table.AddHeaders("Name", "Role", "Scope", "EnterpriseID", "TenantID", "AppID", "Creator", "ExpireTime")
+	for _, token := range tokens {
+		expireTime := "never"
+		if token.ExpireTime != nil {
+			expireTime = token.ExpireTime.Format("2006-01-02 15:04:05")
+		}
+		table.AddRow(token.Name, token.Role, token.Scope, token.EnterpriseID, token.TenantID, token.AppID, token.Creator, expireTime)
+	}
+	fmt.Println(table.Render())
+	return nil
+}
+
+func revokeToken(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		showError("token name can not be empty")
+	}
+	if err := clients.RegionClient.Tokens().Revoke(name); err != nil {
+		showError(err.Error())
+	}
+	showSuccessMsg(fmt.Sprintf("token %s is revoked", name))
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gridworkz/kato/grctl/clients"
+	"github.com/gridworkz/kato/util/termtables"
+	"github.com/urfave/cli"
+)
+
+func volumeFlags(flags ...cli.Flag) []cli.Flag {
+	return append([]cli.Flag{
+		cli.StringFlag{
+			Name:     "tenantAlias,t",
+			Value:    "",
+			Usage:    "Specify the tenant alias",
+			FilePath: GetTenantNamePath(),
+		},
+		cli.StringFlag{
+			Name:  "service,s",
+			Usage: "Specify the service alias",
+		},
+		cli.StringFlag{
+			Name:  "volume,v",
+			Usage: "Specify the volume name",
+		},
+	}, flags...)
+}
+
+//NewCmdVolume volume cmd
+func NewCmdVolume() cli.Command {
+	c := cli.Command{
+		Name:  "volume",
+		Usage: "about the data of application volumes，grctl volume -h",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:  "backup",
+				Flags: volumeFlags(),
+				Usage: "Backup the volume data to the object storage. For example <grctl volume backup -t gridworkz -s gr2a2e1b -v data>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return backupVolume(c)
+				},
+			},
+			cli.Command{
+				Name:  "backups",
+				Flags: volumeFlags(),
+				Usage: "List the backups of the volume data. For example <grctl volume backups -t gridworkz -s gr2a2e1b -v data>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return listVolumeBackups(c)
+				},
+			},
+			cli.Command{
+				Name: "restore",
+				Flags: volumeFlags(
+					cli.StringFlag{
+						Name:  "backup,b",
+						Usage: "Specify the backup id, it can be a backup of another service of the tenant",
+					},
+					cli.BoolFlag{
+						Name:  "force",
+						Usage: "restore the data even if the service is running",
+					},
+				),
+				Usage: "Restore a backup into the volume. For example <grctl volume restore -t gridworkz -s gr2a2e1b -v data -b 8a3f...>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return restoreVolume(c)
+				},
+			},
+		},
+	}
+	return c
+}
+
+func volumeArgs(c *cli.Context) (string, string, string) {
+	tenantName := c.String("tenantAlias")
+	serviceAlias := c.String("service")
+	volumeName := c.String("volume")
+	if tenantName == "" || serviceAlias == "" || volumeName == "" {
+		showError("tenant alias, service alias and volume name can not be empty")
+	}
+	return tenantName, serviceAlias, volumeName
+}
+
+func backupVolume(c *cli.Context) error {
+	tenantName, serviceAlias, volumeName := volumeArgs(c)
+	backup, err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).BackupVolume(volumeName)
+	if err != nil {
+		showError(err.Error())
+	}
+	fmt.Printf("Backup %s is created, event id: %s\n", backup.BackupID, backup.EventID)
+	return nil
+}
+
+func listVolumeBackups(c *cli.Context) error {
+	tenantName, serviceAlias, volumeName := volumeArgs(c)
+	backups, err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).VolumeBackups(volumeName)
+	if err != nil {
+		showError(err.Error())
+	}
+	table := termtables.CreateTable()
+	table.AddHeaders("BackupID", "Status", "Size", "Scheduled", "CreateTime", "Message")
+	for _, backup := range backups {
+		table.AddRow(backup.BackupID, backup.Status, backup.Size, backup.Scheduled, backup.CreatedAt.Format("2006-01-02 15:04:05"), backup.Message)
+	}
+	fmt.Println(table.Render())
+	return nil
+}
+
+func restoreVolume(c *cli.Context) error {
+	tenantName, serviceAlias, volumeName := volumeArgs(c)
+	backupID := c.String("backup")
+	if backupID == "" {
+		showError("backup id can not be empty")
+	}
+	if err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).RestoreVolume(volumeName, backupID, c.Bool("force")); err != nil {
+		showError(err.Error())
+	}
+	showSuccessMsg(fmt.Sprintf("restoring backup %s into volume %s", backupID, volumeName))
+	return nil
+}
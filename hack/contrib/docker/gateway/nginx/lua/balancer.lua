@@ -18,6 +18,8 @@ local IMPLEMENTATIONS = {
 local _M = {}
 -- save all backend balancer data
 local balancers = {}
+-- the number of endpoints of every backend
+local peer_counts = {}
 
 -- measured in seconds
 -- for an Nginx worker to pick up the new list of upstream peers
@@ -77,6 +79,7 @@ local function sync_backends()
   local backends_data = config.get_backends_data()
   if not backends_data then
     balancers = {}
+    peer_counts = {}
     return
   end
 
@@ -87,10 +90,13 @@ local function sync_backends()
   end
 
   local balancers_to_keep = {}
+  local new_peer_counts = {}
   for _, new_backend in ipairs(new_backends) do
     sync_backend(new_backend)
     balancers_to_keep[new_backend.name] = balancers[new_backend.name]
+    new_peer_counts[new_backend.name] = #(new_backend.endpoints or {})
   end
+  peer_counts = new_peer_counts
 
   for backend_name, _ in pairs(balancers) do
     if not balancers_to_keep[backend_name] then
@@ -107,6 +113,15 @@ function _M.init_worker()
   end
 end
 
+-- has_peers returns whether the backend of the request has endpoints,
+-- set sync to pick up the backends without waiting for the sync timer
+function _M.has_peers(sync)
+  if sync then
+    sync_backends()
+  end
+  return (peer_counts[ngx.var.target] or 0) > 0
+end
+
 function _M.rewrite()
   local balancer = get_balancer()
   if not balancer then
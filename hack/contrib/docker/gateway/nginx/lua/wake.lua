@@ -0,0 +1,49 @@
+local json = require("cjson")
+
+local wake_requests = ngx.shared.wake_requests
+
+local _M = {}
+
+-- measured in seconds
+-- how long a request is held while the idle component is started
+local WAKE_TIMEOUT = 90
+local WAIT_INTERVAL = 0.5
+
+-- call holds the request of the idle component until its backend has peers.
+-- the gateway controller takes the recorded wake requests and sends them to the worker
+function _M.call(service_id)
+  if balancer.has_peers(false) then
+    return
+  end
+
+  local deadline = ngx.now() + WAKE_TIMEOUT
+  while ngx.now() < deadline do
+    -- record it on every round, the controller resends the requests that are not served yet
+    wake_requests:set(service_id, ngx.now(), WAKE_TIMEOUT)
+    ngx.sleep(WAIT_INTERVAL)
+    if balancer.has_peers(true) then
+      return
+    end
+  end
+
+  ngx.log(ngx.WARN, "timeout waiting for the idle component " .. service_id)
+  ngx.status = ngx.HTTP_SERVICE_UNAVAILABLE
+  return ngx.exit(ngx.status)
+end
+
+-- list returns and removes the components waiting to be woken up
+function _M.list()
+  local service_ids = wake_requests:get_keys(0)
+  for _, service_id in ipairs(service_ids) do
+    wake_requests:delete(service_id)
+  end
+
+  ngx.status = ngx.HTTP_OK
+  if #service_ids == 0 then
+    ngx.print("[]")
+    return
+  end
+  ngx.print(json.encode(service_ids))
+end
+
+return _M
@@ -53,6 +53,7 @@ type Manager struct {
 	conf      option.Config
 	server    Server
 	actionMQ  mq.ActionMQ
+	tracker   *mq.Tracker
 }
 type Server interface {
 	Server() error
@@ -97,12 +98,18 @@ func (h *grpcServer) Close() error {
 //NewManager
 func NewManager(c option.Config) (*Manager, error) {
 	ctx, cancel := context.WithCancel(context.Background())
-	actionMQ := mq.NewActionMQ(ctx, c)
+	actionMQ, err := mq.NewActionMQ(ctx, c)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	tracker := mq.NewTracker(ctx, actionMQ, c)
 	manager := &Manager{
 		ctx:      ctx,
 		cancel:   cancel,
 		conf:     c,
 		actionMQ: actionMQ,
+		tracker:  tracker,
 	}
 	go func() {
 		manager.Prometheus()
@@ -115,6 +122,7 @@ func NewManager(c option.Config) (*Manager, error) {
 		wsContainer := restful.NewContainer()
 		server := &http.Server{Addr: fmt.Sprintf(":%d", c.APIPort), Handler: wsContainer}
 		controller.Register(wsContainer, actionMQ)
+		controller.RegisterDeadLetter(wsContainer, actionMQ)
 		manager.container = wsContainer
 		manager.server = &httpServer{server}
 		manager.doc()
@@ -126,7 +134,9 @@ func NewManager(c option.Config) (*Manager, error) {
 			return nil, err
 		}
 		s := grpc.NewServer()
-		grpcserver.RegisterServer(s, actionMQ)
+		grpcserver.RegisterServer(s, actionMQ, tracker)
+		// dead letter api is served with the metrics endpoint
+		controller.RegisterDeadLetter(restful.DefaultContainer, actionMQ)
 		// Register reflection service on gRPC server.
 		reflection.Register(s)
 		manager.server = &grpcServer{
@@ -146,6 +156,7 @@ func (m *Manager) Start(errChan chan error) {
 	if err != nil {
 		errChan <- err
 	}
+	m.tracker.Start()
 	go func() {
 		if err := m.server.Server(); err != nil {
 			logrus.Error("mq api listen error.", err.Error())
@@ -176,6 +187,7 @@ func (m *Manager) doc() {
 //Stop
 func (m *Manager) Stop() error {
 	logrus.Info("api server is stoping.")
+	m.tracker.Stop()
 	m.cancel()
 	//m.server.Close()
 	return m.actionMQ.Stop()
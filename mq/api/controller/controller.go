@@ -21,7 +21,9 @@ package controller
 import (
 	"context"
 	"io/ioutil"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gridworkz/kato/mq/api/mq"
 
@@ -55,6 +57,8 @@ func (u MQSource) Register(container *restful.Container) {
 		Doc("send a task message to the topic queue").
 		Operation("enqueue").
 		Param(ws.PathParameter("topic", "queue topic name").DataType("string")).
+		Param(ws.QueryParameter("priority", "higher priority is dequeued first, 0-9").DataType("int")).
+		Param(ws.QueryParameter("not_before", "unix timestamp, the task is not dequeued before it").DataType("int")).
 		Reads(discovermodel.Task{}).
 		Returns(201, "Message sent successfully", ResponseType{}).
 		ReturnsError(400, "The message format is wrong", ResponseType{}))
@@ -153,9 +157,16 @@ func (u *MQSource) enqueue(request *restful.Request, response *restful.Response)
 		return
 	}
 
+	var opt mq.EnqueueOption
+	if priority, err := strconv.Atoi(request.QueryParameter("priority")); err == nil {
+		opt.Priority = priority
+	}
+	if notBefore, err := strconv.ParseInt(request.QueryParameter("not_before"), 10, 64); err == nil && notBefore > 0 {
+		opt.NotBefore = time.Unix(notBefore, 0)
+	}
 	ctx, cancel := context.WithCancel(request.Request.Context())
 	defer cancel()
-	err = u.mq.Enqueue(ctx, topic, string(body))
+	err = u.mq.Enqueue(ctx, topic, string(body), opt)
 	if err != nil {
 		NewFaliResponse(500, "enqueue error."+err.Error(), "Message queue error", response)
 		return
@@ -0,0 +1,145 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gridworkz/kato/mq/api/grpc/pb"
+	"github.com/gridworkz/kato/mq/api/mq"
+
+	restful "github.com/emicklei/go-restful"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+//RegisterDeadLetter register the dead letter api
+func RegisterDeadLetter(container *restful.Container, mq mq.ActionMQ) {
+	DeadLetterSource{mq}.Register(container)
+}
+
+//DeadLetterSource view and replay the tasks which exceed the max retry
+type DeadLetterSource struct {
+	mq mq.ActionMQ
+}
+
+//Register
+func (d DeadLetterSource) Register(container *restful.Container) {
+	ws := new(restful.WebService)
+	ws.Path("/deadletter").
+		Doc("dead letter interface").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/{topic}").To(d.list).
+		// docs
+		Doc("list the dead letter tasks of the topic").
+		Operation("listDeadLetter").
+		Param(ws.PathParameter("topic", "queue topic name").DataType("string")).
+		Param(ws.QueryParameter("limit", "max number of tasks, default 100").DataType("int")).
+		Writes(ResponseType{
+			Body: ResponseBody{
+				List: []interface{}{pb.TaskMessage{}},
+			},
+		}))
+
+	ws.Route(ws.POST("/{topic}/replay").To(d.replay).
+		// docs
+		Doc("move the dead letter tasks back to the topic").
+		Operation("replayDeadLetter").
+		Param(ws.PathParameter("topic", "queue topic name").DataType("string")).
+		Param(ws.QueryParameter("task_id", "only replay the task with this id").DataType("string")).
+		Returns(200, "The number of replayed tasks", ResponseType{}))
+	container.Add(ws)
+}
+
+func (d *DeadLetterSource) list(request *restful.Request, response *restful.Response) {
+	topic := request.PathParameter("topic")
+	if topic == "" || !d.mq.TopicIsExist(topic) {
+		NewFaliResponse(400, "topic can not be empty or topic is not define", "The subject cannot be empty or the current subject is not registered", response)
+		return
+	}
+	limit := 100
+	if l, err := strconv.Atoi(request.QueryParameter("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	values, err := d.mq.Peek(mq.DeadLetterTopic(topic), limit)
+	if err != nil {
+		NewFaliResponse(500, "list dead letter error."+err.Error(), "Error reading dead letter queue", response)
+		return
+	}
+	var list []interface{}
+	for _, value := range values {
+		var task pb.TaskMessage
+		if err := proto.Unmarshal([]byte(value), &task); err != nil {
+			logrus.Warningf("dead letter task of topic %s format is illegal %s", topic, err.Error())
+			continue
+		}
+		list = append(list, &task)
+	}
+	NewSuccessResponse(nil, list, response)
+}
+
+func (d *DeadLetterSource) replay(request *restful.Request, response *restful.Response) {
+	topic := request.PathParameter("topic")
+	if topic == "" || !d.mq.TopicIsExist(topic) {
+		NewFaliResponse(400, "topic can not be empty or topic is not define", "The subject cannot be empty or the current subject is not registered", response)
+		return
+	}
+	taskID := request.QueryParameter("task_id")
+	deadLetter := mq.DeadLetterTopic(topic)
+	var replayed int
+	// only the tasks present now are handled, the skipped tasks are put back to the dead letter topic
+	size := d.mq.MessageQueueSize(deadLetter)
+	for i := int64(0); i < size; i++ {
+		ctx, cancel := context.WithTimeout(request.Request.Context(), time.Second*3)
+		value, err := d.mq.Dequeue(ctx, deadLetter)
+		cancel()
+		if err != nil {
+			break
+		}
+		var task pb.TaskMessage
+		if err := proto.Unmarshal([]byte(value), &task); err != nil {
+			logrus.Warningf("drop illegal dead letter task of topic %s: %s", topic, err.Error())
+			continue
+		}
+		target := topic
+		if taskID != "" && task.TaskId != taskID {
+			target = deadLetter
+		} else {
+			task.Retry = 0
+			task.NotBefore = 0
+			if message, err := proto.Marshal(&task); err == nil {
+				value = string(message)
+			}
+		}
+		if err := d.mq.Enqueue(context.Background(), target, value, mq.TaskEnqueueOption(&task)); err != nil {
+			logrus.Errorf("replay dead letter task %s failure %s", task.TaskId, err.Error())
+			NewFaliResponse(500, "replay dead letter error."+err.Error(), "Replay dead letter task error", response)
+			return
+		}
+		if target == topic {
+			replayed++
+		}
+	}
+	logrus.Infof("replay %d dead letter tasks to topic %s", replayed, topic)
+	NewSuccessResponse(map[string]int{"replayed": replayed}, nil, response)
+}
@@ -28,6 +28,9 @@ type TaskMessage struct {
 	TaskBody             []byte   `protobuf:"bytes,3,opt,name=task_body,json=taskBody,proto3" json:"task_body,omitempty"`
 	CreateTime           string   `protobuf:"bytes,4,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
 	User                 string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	Retry                int32    `protobuf:"varint,6,opt,name=retry,proto3" json:"retry,omitempty"`
+	Priority             int32    `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	NotBefore            int64    `protobuf:"varint,8,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -93,9 +96,32 @@ func (m *TaskMessage) GetUser() string {
 	return ""
 }
 
+func (m *TaskMessage) GetRetry() int32 {
+	if m != nil {
+		return m.Retry
+	}
+	return 0
+}
+
+func (m *TaskMessage) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *TaskMessage) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
 type EnqueueRequest struct {
 	Topic                string       `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
 	Message              *TaskMessage `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Priority             int32        `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	NotBefore            int64        `protobuf:"varint,4,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -140,6 +166,20 @@ func (m *EnqueueRequest) GetMessage() *TaskMessage {
 	return nil
 }
 
+func (m *EnqueueRequest) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *EnqueueRequest) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
 type DequeueRequest struct {
 	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
 	ClientHost           string   `protobuf:"bytes,2,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
@@ -187,6 +227,179 @@ func (m *DequeueRequest) GetClientHost() string {
 	return ""
 }
 
+type SubscribeRequest struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	ConsumerId           string   `protobuf:"bytes,2,opt,name=consumer_id,json=consumerId,proto3" json:"consumer_id,omitempty"`
+	MaxInFlight          int32    `protobuf:"varint,3,opt,name=max_in_flight,json=maxInFlight,proto3" json:"max_in_flight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()    {}
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33c57e4bae7b9afd, []int{3}
+}
+
+func (m *SubscribeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeRequest.Unmarshal(m, b)
+}
+func (m *SubscribeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeRequest.Merge(m, src)
+}
+func (m *SubscribeRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeRequest.Size(m)
+}
+func (m *SubscribeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeRequest proto.InternalMessageInfo
+
+func (m *SubscribeRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *SubscribeRequest) GetConsumerId() string {
+	if m != nil {
+		return m.ConsumerId
+	}
+	return ""
+}
+
+func (m *SubscribeRequest) GetMaxInFlight() int32 {
+	if m != nil {
+		return m.MaxInFlight
+	}
+	return 0
+}
+
+type AckRequest struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	TaskId               string   `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ClientHost           string   `protobuf:"bytes,3,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AckRequest) Reset()         { *m = AckRequest{} }
+func (m *AckRequest) String() string { return proto.CompactTextString(m) }
+func (*AckRequest) ProtoMessage()    {}
+func (*AckRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33c57e4bae7b9afd, []int{4}
+}
+
+func (m *AckRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AckRequest.Unmarshal(m, b)
+}
+func (m *AckRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AckRequest.Marshal(b, m, deterministic)
+}
+func (m *AckRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AckRequest.Merge(m, src)
+}
+func (m *AckRequest) XXX_Size() int {
+	return xxx_messageInfo_AckRequest.Size(m)
+}
+func (m *AckRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AckRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AckRequest proto.InternalMessageInfo
+
+func (m *AckRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *AckRequest) GetTaskId() string {
+	if m != nil {
+		return m.TaskId
+	}
+	return ""
+}
+
+func (m *AckRequest) GetClientHost() string {
+	if m != nil {
+		return m.ClientHost
+	}
+	return ""
+}
+
+type NackRequest struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	TaskId               string   `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ClientHost           string   `protobuf:"bytes,3,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NackRequest) Reset()         { *m = NackRequest{} }
+func (m *NackRequest) String() string { return proto.CompactTextString(m) }
+func (*NackRequest) ProtoMessage()    {}
+func (*NackRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33c57e4bae7b9afd, []int{5}
+}
+
+func (m *NackRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NackRequest.Unmarshal(m, b)
+}
+func (m *NackRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NackRequest.Marshal(b, m, deterministic)
+}
+func (m *NackRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NackRequest.Merge(m, src)
+}
+func (m *NackRequest) XXX_Size() int {
+	return xxx_messageInfo_NackRequest.Size(m)
+}
+func (m *NackRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NackRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NackRequest proto.InternalMessageInfo
+
+func (m *NackRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *NackRequest) GetTaskId() string {
+	if m != nil {
+		return m.TaskId
+	}
+	return ""
+}
+
+func (m *NackRequest) GetClientHost() string {
+	if m != nil {
+		return m.ClientHost
+	}
+	return ""
+}
+
+func (m *NackRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
 type TaskReply struct {
 	Status               string   `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -200,7 +413,7 @@ func (m *TaskReply) Reset()         { *m = TaskReply{} }
 func (m *TaskReply) String() string { return proto.CompactTextString(m) }
 func (*TaskReply) ProtoMessage()    {}
 func (*TaskReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33c57e4bae7b9afd, []int{3}
+	return fileDescriptor_33c57e4bae7b9afd, []int{6}
 }
 
 func (m *TaskReply) XXX_Unmarshal(b []byte) error {
@@ -252,7 +465,7 @@ func (m *TopicRequest) Reset()         { *m = TopicRequest{} }
 func (m *TopicRequest) String() string { return proto.CompactTextString(m) }
 func (*TopicRequest) ProtoMessage()    {}
 func (*TopicRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33c57e4bae7b9afd, []int{4}
+	return fileDescriptor_33c57e4bae7b9afd, []int{7}
 }
 
 func (m *TopicRequest) XXX_Unmarshal(b []byte) error {
@@ -277,6 +490,9 @@ func init() {
 	proto.RegisterType((*TaskMessage)(nil), "pb.TaskMessage")
 	proto.RegisterType((*EnqueueRequest)(nil), "pb.EnqueueRequest")
 	proto.RegisterType((*DequeueRequest)(nil), "pb.DequeueRequest")
+	proto.RegisterType((*SubscribeRequest)(nil), "pb.SubscribeRequest")
+	proto.RegisterType((*AckRequest)(nil), "pb.AckRequest")
+	proto.RegisterType((*NackRequest)(nil), "pb.NackRequest")
 	proto.RegisterType((*TaskReply)(nil), "pb.TaskReply")
 	proto.RegisterType((*TopicRequest)(nil), "pb.TopicRequest")
 }
@@ -284,28 +500,40 @@ func init() {
 func init() { proto.RegisterFile("message.proto", fileDescriptor_33c57e4bae7b9afd) }
 
 var fileDescriptor_33c57e4bae7b9afd = []byte{
-	// 336 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xcb, 0x4e, 0xc3, 0x30,
-	0x10, 0x45, 0x9b, 0x3e, 0x12, 0x32, 0x7d, 0x80, 0x46, 0x08, 0xac, 0xb2, 0xa0, 0xf2, 0xaa, 0x08,
-	0x29, 0x42, 0xe5, 0x0f, 0x10, 0x08, 0x58, 0xb0, 0x68, 0x14, 0xd6, 0x55, 0xd2, 0x8c, 0x20, 0xea,
-	0xc3, 0x6e, 0xed, 0x2c, 0xf2, 0x21, 0x6c, 0xf8, 0x5a, 0x64, 0x3b, 0xad, 0x5a, 0xd8, 0xb0, 0xf3,
-	0xcc, 0xcd, 0xcc, 0x9c, 0x7b, 0x15, 0xe8, 0xaf, 0x48, 0xa9, 0xf4, 0x83, 0x22, 0xb9, 0x15, 0x5a,
-	0x60, 0x53, 0x66, 0xfc, 0xcb, 0x83, 0x6e, 0x92, 0xaa, 0xc5, 0x9b, 0x53, 0xf0, 0x12, 0x02, 0x9d,
-	0xaa, 0xc5, 0xac, 0xc8, 0x99, 0x37, 0xf2, 0xc6, 0x61, 0xec, 0x9b, 0xf2, 0x35, 0xc7, 0x2b, 0x08,
-	0xad, 0xa0, 0x2b, 0x49, 0xac, 0x69, 0xa5, 0x13, 0xd3, 0x48, 0x2a, 0x49, 0x7b, 0x31, 0x13, 0x79,
-	0xc5, 0x5a, 0x23, 0x6f, 0xdc, 0x73, 0xe2, 0x83, 0xc8, 0x2b, 0xbc, 0x86, 0xee, 0x7c, 0x4b, 0xa9,
-	0xa6, 0x99, 0x2e, 0x56, 0xc4, 0xda, 0x76, 0x16, 0x5c, 0x2b, 0x29, 0x56, 0x84, 0x08, 0xed, 0x52,
-	0xd1, 0x96, 0x75, 0xac, 0x62, 0xdf, 0x7c, 0x0a, 0x83, 0xa7, 0xf5, 0xa6, 0xa4, 0x92, 0x62, 0xda,
-	0x94, 0xa4, 0x34, 0x9e, 0x43, 0x47, 0x0b, 0x59, 0xcc, 0x6b, 0x2e, 0x57, 0xe0, 0x0d, 0x04, 0xb5,
-	0x29, 0x0b, 0xd5, 0x9d, 0x9c, 0x46, 0x32, 0x8b, 0x0e, 0x1c, 0xc5, 0x3b, 0x9d, 0x3f, 0xc3, 0xe0,
-	0x91, 0xfe, 0xb1, 0xd2, 0xf0, 0x2e, 0x0b, 0x5a, 0xeb, 0xd9, 0xa7, 0x50, 0xba, 0xf6, 0x0a, 0xae,
-	0xf5, 0x22, 0x94, 0xe6, 0xef, 0x10, 0x9a, 0x03, 0x31, 0xc9, 0x65, 0x85, 0x17, 0xe0, 0x2b, 0x9d,
-	0xea, 0x52, 0xed, 0xf2, 0x72, 0x15, 0xb2, 0x63, 0xb0, 0x70, 0xcf, 0x61, 0x26, 0xec, 0x21, 0xc5,
-	0x5a, 0xa3, 0x96, 0x4d, 0xd8, 0x56, 0x7c, 0x00, 0xbd, 0xc4, 0xbc, 0x6a, 0xba, 0xc9, 0xb7, 0xe7,
-	0xee, 0x4c, 0x0d, 0x32, 0x46, 0x10, 0xd4, 0x81, 0x20, 0x1a, 0x8b, 0xc7, 0xe9, 0x0c, 0xfb, 0x3b,
-	0xdb, 0x96, 0x8a, 0x37, 0xf0, 0x16, 0x7c, 0xbb, 0x4d, 0xe1, 0x99, 0x95, 0x0e, 0x36, 0xff, 0xfd,
-	0xf8, 0x0e, 0x82, 0x3a, 0x1a, 0xb7, 0xfc, 0x38, 0xa7, 0xe1, 0xef, 0x4c, 0x79, 0x23, 0xf3, 0xed,
-	0x2f, 0x74, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0xa0, 0x5f, 0x1b, 0x28, 0x53, 0x02, 0x00, 0x00,
+	// 525 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xdd, 0x6a, 0xdb, 0x4c,
+	0x10, 0x8d, 0x2c, 0x5b, 0xb6, 0xc6, 0xb1, 0xbf, 0x30, 0x84, 0x7c, 0xc2, 0xa5, 0xd4, 0xe8, 0xa2,
+	0xb8, 0x14, 0x4c, 0x48, 0xa1, 0xf7, 0x09, 0xe9, 0x8f, 0x2f, 0x1a, 0xa8, 0xea, 0xde, 0x15, 0xc4,
+	0xca, 0x9e, 0x24, 0x8b, 0x2d, 0xad, 0xb2, 0xbb, 0x82, 0xe8, 0x1d, 0xfa, 0x18, 0x7d, 0xb6, 0x3e,
+	0x47, 0xd9, 0x95, 0xec, 0xf8, 0xa7, 0x98, 0xdc, 0xf4, 0x6e, 0xcf, 0x9c, 0xf5, 0xd9, 0x33, 0x33,
+	0xc7, 0x82, 0x5e, 0x4a, 0x4a, 0xb1, 0x3b, 0x1a, 0xe7, 0x52, 0x68, 0x81, 0x8d, 0x3c, 0x09, 0x7f,
+	0x3b, 0xd0, 0x9d, 0x32, 0xb5, 0xf8, 0x52, 0x31, 0xf8, 0x3f, 0xb4, 0x35, 0x53, 0x8b, 0x98, 0xcf,
+	0x03, 0x67, 0xe8, 0x8c, 0xfc, 0xc8, 0x33, 0x70, 0x32, 0xc7, 0x17, 0xe0, 0x5b, 0x42, 0x97, 0x39,
+	0x05, 0x0d, 0x4b, 0x75, 0x4c, 0x61, 0x5a, 0xe6, 0xb4, 0x26, 0x13, 0x31, 0x2f, 0x03, 0x77, 0xe8,
+	0x8c, 0x8e, 0x2b, 0xf2, 0x4a, 0xcc, 0x4b, 0x7c, 0x05, 0xdd, 0x99, 0x24, 0xa6, 0x29, 0xd6, 0x3c,
+	0xa5, 0xa0, 0x69, 0x7f, 0x0b, 0x55, 0x69, 0xca, 0x53, 0x42, 0x84, 0x66, 0xa1, 0x48, 0x06, 0x2d,
+	0xcb, 0xd8, 0x33, 0x9e, 0x42, 0x4b, 0x92, 0x96, 0x65, 0xe0, 0x0d, 0x9d, 0x51, 0x2b, 0xaa, 0x00,
+	0x0e, 0xa0, 0x93, 0x4b, 0x2e, 0x24, 0xd7, 0x65, 0xd0, 0xb6, 0xc4, 0x1a, 0xe3, 0x4b, 0x80, 0x4c,
+	0xe8, 0x38, 0xa1, 0x5b, 0x21, 0x29, 0xe8, 0x0c, 0x9d, 0x91, 0x1b, 0xf9, 0x99, 0xd0, 0x57, 0xb6,
+	0x10, 0xfe, 0x74, 0xa0, 0xff, 0x21, 0x7b, 0x28, 0xa8, 0xa0, 0x88, 0x1e, 0x0a, 0x52, 0xda, 0xbc,
+	0xa1, 0x45, 0xce, 0x67, 0x75, 0xa7, 0x15, 0xc0, 0x37, 0xd0, 0xae, 0xc7, 0x64, 0xdb, 0xec, 0x5e,
+	0xfc, 0x37, 0xce, 0x93, 0xf1, 0xc6, 0x8c, 0xa2, 0x15, 0xbf, 0x65, 0xc7, 0x3d, 0x68, 0xa7, 0xb9,
+	0x6b, 0xe7, 0x13, 0xf4, 0xaf, 0xe9, 0x19, 0x6e, 0xcc, 0xf0, 0x96, 0x9c, 0x32, 0x1d, 0xdf, 0x0b,
+	0xa5, 0xeb, 0xc1, 0x43, 0x55, 0xfa, 0x2c, 0x94, 0x0e, 0x53, 0x38, 0xf9, 0x56, 0x24, 0x6a, 0x26,
+	0x79, 0xf2, 0x0c, 0x29, 0x91, 0xa9, 0x22, 0x25, 0x69, 0xd6, 0xbb, 0x92, 0xaa, 0x4b, 0x93, 0x39,
+	0x86, 0xd0, 0x4b, 0xd9, 0x63, 0xcc, 0xb3, 0xf8, 0x76, 0xc9, 0xef, 0xee, 0x75, 0xdd, 0x53, 0x37,
+	0x65, 0x8f, 0x93, 0xec, 0xa3, 0x2d, 0x85, 0x3f, 0x00, 0x2e, 0x67, 0x8b, 0xc3, 0x0f, 0x6d, 0x64,
+	0xa8, 0xb1, 0x95, 0xa1, 0x9d, 0x66, 0xdc, 0xbd, 0x66, 0x0a, 0xe8, 0xde, 0xb0, 0x7f, 0x26, 0x8f,
+	0x67, 0xe0, 0x49, 0x62, 0x4a, 0x64, 0x75, 0x08, 0x6b, 0x14, 0x7e, 0x07, 0xdf, 0xec, 0x37, 0xa2,
+	0x7c, 0x59, 0x9a, 0x4b, 0x4a, 0x33, 0x5d, 0xa8, 0xd5, 0x1f, 0xa0, 0x42, 0x18, 0x6c, 0xe7, 0xc2,
+	0x7f, 0x8a, 0xc1, 0x19, 0x78, 0xd6, 0x99, 0x0a, 0xdc, 0xa1, 0x6b, 0xfd, 0x58, 0x14, 0xf6, 0xe1,
+	0x78, 0x6a, 0x4e, 0x75, 0x3b, 0x17, 0xbf, 0x1a, 0xd5, 0x3b, 0x5f, 0xcd, 0xda, 0x71, 0x0c, 0xed,
+	0x3a, 0x8f, 0x88, 0x26, 0x61, 0xdb, 0xe1, 0x1c, 0xf4, 0x56, 0xa9, 0xb3, 0xae, 0xc2, 0x23, 0x7c,
+	0x0b, 0x9e, 0x55, 0x53, 0x78, 0x62, 0xa9, 0x0d, 0xe5, 0xfd, 0xcb, 0xe7, 0xd0, 0xbe, 0xa6, 0x0d,
+	0xf1, 0xed, 0xac, 0x0d, 0x76, 0x23, 0x1d, 0x1e, 0xe1, 0x6b, 0x70, 0x2f, 0x67, 0x0b, 0xec, 0x1b,
+	0xe6, 0x69, 0xc3, 0xfb, 0xca, 0x23, 0x68, 0x9a, 0x15, 0xa1, 0x95, 0xb8, 0x61, 0x07, 0x6e, 0xbe,
+	0x07, 0x7f, 0x9d, 0x4c, 0x3c, 0x35, 0xec, 0x6e, 0x50, 0xff, 0xe2, 0xe3, 0xdc, 0x49, 0x3c, 0xfb,
+	0x75, 0x7a, 0xf7, 0x67, 0x00, 0xc6, 0xd4, 0x6f, 0xac, 0xae, 0x04, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -323,6 +551,9 @@ type TaskQueueClient interface {
 	Enqueue(ctx context.Context, in *EnqueueRequest, opts ...grpc.CallOption) (*TaskReply, error)
 	Topics(ctx context.Context, in *TopicRequest, opts ...grpc.CallOption) (*TaskReply, error)
 	Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*TaskMessage, error)
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (TaskQueue_SubscribeClient, error)
 }
 
 type taskQueueClient struct {
@@ -360,11 +591,64 @@ func (c *taskQueueClient) Dequeue(ctx context.Context, in *DequeueRequest, opts
 	return out, nil
 }
 
+func (c *taskQueueClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, "/pb.TaskQueue/Ack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskQueueClient) Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, "/pb.TaskQueue/Nack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskQueueClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (TaskQueue_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TaskQueue_serviceDesc.Streams[0], "/pb.TaskQueue/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &taskQueueSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TaskQueue_SubscribeClient interface {
+	Recv() (*TaskMessage, error)
+	grpc.ClientStream
+}
+
+type taskQueueSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *taskQueueSubscribeClient) Recv() (*TaskMessage, error) {
+	m := new(TaskMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TaskQueueServer is the server API for TaskQueue service.
 type TaskQueueServer interface {
 	Enqueue(context.Context, *EnqueueRequest) (*TaskReply, error)
 	Topics(context.Context, *TopicRequest) (*TaskReply, error)
 	Dequeue(context.Context, *DequeueRequest) (*TaskMessage, error)
+	Ack(context.Context, *AckRequest) (*TaskReply, error)
+	Nack(context.Context, *NackRequest) (*TaskReply, error)
+	Subscribe(*SubscribeRequest, TaskQueue_SubscribeServer) error
 }
 
 func RegisterTaskQueueServer(s *grpc.Server, srv TaskQueueServer) {
@@ -425,6 +709,63 @@ func _TaskQueue_Dequeue_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskQueue_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskQueueServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.TaskQueue/Ack",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskQueueServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskQueue_Nack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskQueueServer).Nack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.TaskQueue/Nack",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskQueueServer).Nack(ctx, req.(*NackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskQueue_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskQueueServer).Subscribe(m, &taskQueueSubscribeServer{stream})
+}
+
+type TaskQueue_SubscribeServer interface {
+	Send(*TaskMessage) error
+	grpc.ServerStream
+}
+
+type taskQueueSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *taskQueueSubscribeServer) Send(m *TaskMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _TaskQueue_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.TaskQueue",
 	HandlerType: (*TaskQueueServer)(nil),
@@ -441,7 +782,21 @@ var _TaskQueue_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Dequeue",
 			Handler:    _TaskQueue_Dequeue_Handler,
 		},
+		{
+			MethodName: "Ack",
+			Handler:    _TaskQueue_Ack_Handler,
+		},
+		{
+			MethodName: "Nack",
+			Handler:    _TaskQueue_Nack_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _TaskQueue_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "message.proto",
 }
@@ -20,6 +20,7 @@ package server
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gridworkz/kato/util"
 
@@ -32,26 +33,38 @@ import (
 
 	proto "github.com/golang/protobuf/proto"
 	grpc1 "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type mqServer struct {
 	actionMQ mq.ActionMQ
+	tracker  *mq.Tracker
 }
 
 func (s *mqServer) Enqueue(ctx context.Context, in *pb.EnqueueRequest) (*pb.TaskReply, error) {
 	if in.Topic == "" || !s.actionMQ.TopicIsExist(in.Topic) {
 		return nil, fmt.Errorf("topic %s is not support", in.Topic)
 	}
+	if in.Message == nil {
+		return nil, status.Error(codes.InvalidArgument, "message can not be empty")
+	}
 	if in.Message.TaskId == "" {
 		in.Message.TaskId = util.NewUUID()
 	}
+	if in.Priority != 0 {
+		in.Message.Priority = in.Priority
+	}
+	if in.NotBefore != 0 {
+		in.Message.NotBefore = in.NotBefore
+	}
 	message, err := proto.Marshal(in.Message)
 	if err != nil {
 		return nil, err
 	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	err = s.actionMQ.Enqueue(ctx, in.Topic, string(message))
+	err = s.actionMQ.Enqueue(ctx, in.Topic, string(message), mq.TaskEnqueueOption(in.Message))
 	if err != nil {
 		return nil, err
 	}
@@ -82,11 +95,94 @@ func (s *mqServer) Dequeue(ctx context.Context, in *pb.DequeueRequest) (*pb.Task
 	if err != nil {
 		return nil, err
 	}
+	s.tracker.Deliver(in.Topic, in.ClientHost, &task)
 	logrus.Debugf("task (%s) dnqueue by (%s).", task.GetTaskType(), in.ClientHost)
 	return &task, nil
 }
 
+//Subscribe pushes the tasks of the topic to the consumer. When the tasks need to be acked,
+//at most max_in_flight unacked tasks are held by the consumer at the same time.
+func (s *mqServer) Subscribe(in *pb.SubscribeRequest, stream pb.TaskQueue_SubscribeServer) error {
+	if in.Topic == "" || !s.actionMQ.TopicIsExist(in.Topic) {
+		return fmt.Errorf("topic %s is not support", in.Topic)
+	}
+	if in.ConsumerId == "" {
+		return fmt.Errorf("consumer id can not be empty")
+	}
+	maxInFlight := int(in.MaxInFlight)
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	ctx := stream.Context()
+	logrus.Infof("consumer (%s) subscribe topic %s, max in flight %d", in.ConsumerId, in.Topic, maxInFlight)
+	defer func() {
+		// the consumer may still handle the held tasks and ack them after it reconnects,
+		// they are requeued when the visibility timeout expires
+		logrus.Infof("consumer (%s) unsubscribe topic %s, %d tasks in flight", in.ConsumerId, in.Topic,
+			s.tracker.InFlight(in.Topic, in.ConsumerId))
+	}()
+	for {
+		if err := s.tracker.WaitSlot(ctx, in.Topic, in.ConsumerId, maxInFlight); err != nil {
+			return nil
+		}
+		message, err := s.actionMQ.Dequeue(ctx, in.Topic)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		var task pb.TaskMessage
+		if err := proto.Unmarshal([]byte(message), &task); err != nil {
+			logrus.Errorf("unmarshal task of topic %s failure %s", in.Topic, err.Error())
+			continue
+		}
+		s.tracker.Deliver(in.Topic, in.ConsumerId, &task)
+		if err := stream.Send(&task); err != nil {
+			// the task is not received by the consumer, requeue it without counting a retry
+			s.tracker.Ack(in.Topic, task.TaskId, in.ConsumerId)
+			s.requeue(in.Topic, &task)
+			return err
+		}
+		logrus.Debugf("task (%s) pushed to (%s).", task.GetTaskType(), in.ConsumerId)
+	}
+}
+
+//requeue enqueue the task that failed to push back to the topic
+func (s *mqServer) requeue(topic string, task *pb.TaskMessage) {
+	message, err := proto.Marshal(task)
+	if err != nil {
+		logrus.Errorf("marshal task %s failure %s", task.TaskId, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := s.actionMQ.Enqueue(ctx, topic, string(message), mq.TaskEnqueueOption(task)); err != nil {
+		logrus.Errorf("requeue task %s failure %s", task.TaskId, err.Error())
+	}
+}
+
+func (s *mqServer) Ack(ctx context.Context, in *pb.AckRequest) (*pb.TaskReply, error) {
+	if err := s.tracker.Ack(in.Topic, in.TaskId, in.ClientHost); err != nil {
+		return nil, err
+	}
+	logrus.Debugf("task (%s) acked by (%s).", in.TaskId, in.ClientHost)
+	return &pb.TaskReply{
+		Status: "success",
+	}, nil
+}
+
+func (s *mqServer) Nack(ctx context.Context, in *pb.NackRequest) (*pb.TaskReply, error) {
+	if err := s.tracker.Nack(in.Topic, in.TaskId, in.ClientHost); err != nil {
+		return nil, err
+	}
+	logrus.Infof("task (%s) nacked by (%s): %s", in.TaskId, in.ClientHost, in.Reason)
+	return &pb.TaskReply{
+		Status: "success",
+	}, nil
+}
+
 //RegisterServer
-func RegisterServer(server *grpc1.Server, actionMQ mq.ActionMQ) {
-	pb.RegisterTaskQueueServer(server, &mqServer{actionMQ})
+func RegisterServer(server *grpc1.Server, actionMQ mq.ActionMQ, tracker *mq.Tracker) {
+	pb.RegisterTaskQueueServer(server, &mqServer{actionMQ, tracker})
 }
@@ -0,0 +1,110 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+)
+
+func testBackend(t *testing.T, mq ActionMQ) {
+	if err := mq.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mq.Stop()
+	if !mq.TopicIsExist("builder") {
+		t.Fatal("default topic builder is not registered")
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if err := mq.Enqueue(context.Background(), "builder", v, EnqueueOption{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if size := mq.MessageQueueSize("builder"); size != 3 {
+		t.Fatalf("expected queue size 3, got %d", size)
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		got, err := mq.Dequeue(context.Background(), "builder")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := mq.Dequeue(ctx, "builder"); err == nil {
+		t.Fatal("expected dequeue on an empty topic to return when the context is done")
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mq.Enqueue(context.Background(), "worker", "d", EnqueueOption{})
+	}()
+	got, err := mq.Dequeue(context.Background(), "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "d" {
+		t.Fatalf("expected d, got %s", got)
+	}
+	testPriority(t, mq)
+}
+
+func testPriority(t *testing.T, mq ActionMQ) {
+	mq.Enqueue(context.Background(), "builder", "delayed", EnqueueOption{Priority: MaxPriority, NotBefore: time.Now().Add(300 * time.Millisecond)})
+	mq.Enqueue(context.Background(), "builder", "low", EnqueueOption{})
+	mq.Enqueue(context.Background(), "builder", "high", EnqueueOption{Priority: 5})
+	mq.Enqueue(context.Background(), "builder", "low2", EnqueueOption{})
+	for _, want := range []string{"high", "low", "low2", "delayed"} {
+		got, err := mq.Dequeue(context.Background(), "builder")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestMemoryBackend(t *testing.T) {
+	mq, err := NewActionMQ(context.TODO(), option.Config{Backend: "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, mq)
+}
+
+func TestBoltBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mq, err := NewActionMQ(context.TODO(), option.Config{Backend: "bolt", DataPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, mq)
+}
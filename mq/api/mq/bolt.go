@@ -0,0 +1,224 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+
+	"golang.org/x/net/context"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/sirupsen/logrus"
+)
+
+//boltQueue is an embedded durable queue, each topic is a bucket in a local bolt db file.
+//The message keys are the inverted priority, the due time and the bucket sequence,
+//so the keys are sorted in dequeue order.
+type boltQueue struct {
+	topics
+	config option.Config
+	db     *bolt.DB
+	notify notifier
+}
+
+func newBoltQueue(ctx context.Context, c option.Config) (ActionMQ, error) {
+	if c.DataPath == "" {
+		return nil, fmt.Errorf("mq: bolt backend requires a data path")
+	}
+	return &boltQueue{config: c}, nil
+}
+
+func (b *boltQueue) Start() error {
+	if err := os.MkdirAll(b.config.DataPath, 0755); err != nil {
+		return fmt.Errorf("create mq data path failure %s", err.Error())
+	}
+	db, err := bolt.Open(path.Join(b.config.DataPath, "mq.db"), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return fmt.Errorf("open mq bolt db failure %s", err.Error())
+	}
+	b.db = db
+	b.loadTopics()
+	logrus.Info("bolt message queue started success")
+	return nil
+}
+
+func (b *boltQueue) Stop() error {
+	if b.db != nil {
+		return b.db.Close()
+	}
+	return nil
+}
+
+func (b *boltQueue) Enqueue(ctx context.Context, topic, value string, opt EnqueueOption) error {
+	EnqueueNumber++
+	priority, due := opt.normalize()
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(topic))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(messageKey(priority, due, seq), []byte(value))
+	})
+	if err != nil {
+		return err
+	}
+	b.notify.broadcast()
+	return nil
+}
+
+//Dequeue blocks until a message is due or the context is done
+func (b *boltQueue) Dequeue(ctx context.Context, topic string) (string, error) {
+	DequeueNumber++
+	for {
+		wait := b.notify.wait()
+		value, next, err := b.pop(topic)
+		if err != nil {
+			return "", err
+		}
+		if value != nil {
+			return string(value), nil
+		}
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-wait:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+//pop removes the first due message, if there is none it returns the duration until the next due
+func (b *boltQueue) pop(topic string) (value []byte, next time.Duration, err error) {
+	next = time.Minute
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(topic))
+		if bucket == nil {
+			return nil
+		}
+		now := time.Now()
+		cursor := bucket.Cursor()
+		k, v := cursor.First()
+		for k != nil {
+			if due := messageDue(k); due.After(now) {
+				if due.Sub(now) < next {
+					next = due.Sub(now)
+				}
+				// the rest messages of this priority are not due either
+				k, v = cursor.Seek([]byte{k[0] + 1})
+				continue
+			}
+			value = append([]byte(nil), v...)
+			return cursor.Delete()
+		}
+		return nil
+	})
+	return
+}
+
+func (b *boltQueue) Peek(topic string, limit int) ([]string, error) {
+	var messages []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(topic))
+		if bucket == nil {
+			return nil
+		}
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil && (limit <= 0 || len(messages) < limit); k, v = cursor.Next() {
+			messages = append(messages, string(v))
+		}
+		return nil
+	})
+	return messages, err
+}
+
+func (b *boltQueue) MessageQueueSize(topic string) int64 {
+	var size int64
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket([]byte(topic)); bucket != nil {
+			size = int64(bucket.Stats().KeyN)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("get message queue size failure %s", err.Error())
+	}
+	return size
+}
+
+//inflightBucket keeps the in-flight messages of the tracker, it is not a topic
+var inflightBucket = []byte("_inflight")
+
+func (b *boltQueue) SaveInflight(key string, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(inflightBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key), value)
+	})
+}
+
+func (b *boltQueue) DeleteInflight(key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(inflightBucket); bucket != nil {
+			return bucket.Delete([]byte(key))
+		}
+		return nil
+	})
+}
+
+func (b *boltQueue) ListInflight() (map[string][]byte, error) {
+	values := make(map[string][]byte)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(inflightBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			values[string(k)] = append([]byte(nil), v...)
+			return nil
+		})
+	})
+	return values, err
+}
+
+func messageKey(priority int, due time.Time, seq uint64) []byte {
+	key := make([]byte, 17)
+	key[0] = byte(MaxPriority - priority)
+	binary.BigEndian.PutUint64(key[1:9], uint64(due.UnixNano()))
+	binary.BigEndian.PutUint64(key[9:], seq)
+	return key
+}
+
+func messageDue(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[1:9])))
+}
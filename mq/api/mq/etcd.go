@@ -0,0 +1,150 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+
+	"golang.org/x/net/context"
+
+	etcdutil "github.com/gridworkz/kato/util/etcd"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/sirupsen/logrus"
+)
+
+type etcdQueue struct {
+	topics
+	config option.Config
+	ctx    context.Context
+	client *clientv3.Client
+}
+
+func newEtcdQueue(ctx context.Context, c option.Config) (ActionMQ, error) {
+	return &etcdQueue{
+		config: c,
+		ctx:    ctx,
+	}, nil
+}
+
+func (e *etcdQueue) Start() error {
+	logrus.Debug("etcd message queue client starting")
+	etcdClientArgs := &etcdutil.ClientArgs{
+		Endpoints:   e.config.EtcdEndPoints,
+		CaFile:      e.config.EtcdCaFile,
+		CertFile:    e.config.EtcdCertFile,
+		KeyFile:     e.config.EtcdKeyFile,
+		DialTimeout: time.Duration(e.config.EtcdTimeout) * time.Second,
+	}
+	cli, err := etcdutil.NewClient(context.Background(), etcdClientArgs)
+	if err != nil {
+		etcdutil.HandleEtcdError(err)
+		return err
+	}
+	e.client = cli
+	e.loadTopics()
+	logrus.Info("etcd message queue client started success")
+	return nil
+}
+
+func (e *etcdQueue) Stop() error {
+	if e.client != nil {
+		e.client.Close()
+	}
+	return nil
+}
+func (e *etcdQueue) queueKey(topic string) string {
+	return e.config.EtcdPrefix + "/" + topic
+}
+func (e *etcdQueue) Enqueue(ctx context.Context, topic, value string, opt EnqueueOption) error {
+	EnqueueNumber++
+	queue := etcdutil.NewPriorityQueue(ctx, e.client, e.queueKey(topic))
+	return queue.Enqueue(value, opt.Priority, opt.NotBefore)
+}
+
+func (e *etcdQueue) Dequeue(ctx context.Context, topic string) (string, error) {
+	DequeueNumber++
+	queue := etcdutil.NewPriorityQueue(ctx, e.client, e.queueKey(topic))
+	return queue.Dequeue()
+}
+
+func (e *etcdQueue) Peek(topic string, limit int) ([]string, error) {
+	ctx, cancel := context.WithCancel(e.ctx)
+	defer cancel()
+	res, err := e.client.Get(ctx, e.queueKey(topic)+"/", clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend), clientv3.WithLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	var messages []string
+	for _, kv := range res.Kvs {
+		messages = append(messages, string(kv.Value))
+	}
+	return messages, nil
+}
+
+func (e *etcdQueue) MessageQueueSize(topic string) int64 {
+	ctx, cancel := context.WithCancel(e.ctx)
+	defer cancel()
+	res, err := e.client.Get(ctx, e.queueKey(topic), clientv3.WithPrefix())
+	if err != nil {
+		logrus.Errorf("get message queue size failure %s", err.Error())
+	}
+	if res != nil {
+		return res.Count
+	}
+	return 0
+}
+
+//inflightKey the in-flight messages are kept out of the queue keys, so they are not counted in the queue size
+func (e *etcdQueue) inflightKey(key string) string {
+	return e.config.EtcdPrefix + "_inflight/" + key
+}
+
+func (e *etcdQueue) SaveInflight(key string, value []byte) error {
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	defer cancel()
+	_, err := e.client.Put(ctx, e.inflightKey(key), string(value))
+	return err
+}
+
+func (e *etcdQueue) DeleteInflight(key string) error {
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	defer cancel()
+	_, err := e.client.Delete(ctx, e.inflightKey(key))
+	return err
+}
+
+func (e *etcdQueue) ListInflight() (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	defer cancel()
+	prefix := e.inflightKey("")
+	res, err := e.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]byte, len(res.Kvs))
+	for _, kv := range res.Kvs {
+		values[strings.TrimPrefix(string(kv.Key), prefix)] = kv.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+
+	"golang.org/x/net/context"
+
+	"github.com/sirupsen/logrus"
+)
+
+//memoryQueue keeps messages in process memory, messages are lost when the process exits.
+//It is mainly used for tests and single node development environments.
+type memoryQueue struct {
+	topics
+	lock     sync.Mutex
+	messages map[string]*list.List
+	notify   notifier
+}
+
+type memoryMessage struct {
+	value    string
+	priority int
+	due      time.Time
+}
+
+//after whether the message is dequeued after the other one
+func (m *memoryMessage) after(other *memoryMessage) bool {
+	if m.priority != other.priority {
+		return m.priority < other.priority
+	}
+	return m.due.After(other.due)
+}
+
+func newMemoryQueue(ctx context.Context, c option.Config) (ActionMQ, error) {
+	return &memoryQueue{
+		messages: make(map[string]*list.List),
+	}, nil
+}
+
+func (m *memoryQueue) Start() error {
+	m.loadTopics()
+	logrus.Info("memory message queue started success")
+	return nil
+}
+
+func (m *memoryQueue) Stop() error {
+	return nil
+}
+
+func (m *memoryQueue) Enqueue(ctx context.Context, topic, value string, opt EnqueueOption) error {
+	EnqueueNumber++
+	priority, due := opt.normalize()
+	message := &memoryMessage{value: value, priority: priority, due: due}
+	m.lock.Lock()
+	queue, ok := m.messages[topic]
+	if !ok {
+		queue = list.New()
+		m.messages[topic] = queue
+	}
+	// keep the list in dequeue order, higher priority first and then earlier due
+	e := queue.Back()
+	for e != nil && e.Value.(*memoryMessage).after(message) {
+		e = e.Prev()
+	}
+	if e == nil {
+		queue.PushFront(message)
+	} else {
+		queue.InsertAfter(message, e)
+	}
+	m.lock.Unlock()
+	m.notify.broadcast()
+	return nil
+}
+
+//Dequeue blocks until a message is due or the context is done
+func (m *memoryQueue) Dequeue(ctx context.Context, topic string) (string, error) {
+	DequeueNumber++
+	for {
+		wait := m.notify.wait()
+		value, next, ok := m.pop(topic)
+		if ok {
+			return value, nil
+		}
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-wait:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+//pop removes the first due message, if there is none it returns the duration until the next due
+func (m *memoryQueue) pop(topic string) (string, time.Duration, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	next := time.Minute
+	queue, ok := m.messages[topic]
+	if !ok {
+		return "", next, false
+	}
+	now := time.Now()
+	for e := queue.Front(); e != nil; e = e.Next() {
+		message := e.Value.(*memoryMessage)
+		if message.due.After(now) {
+			if message.due.Sub(now) < next {
+				next = message.due.Sub(now)
+			}
+			continue
+		}
+		queue.Remove(e)
+		return message.value, 0, true
+	}
+	return "", next, false
+}
+
+func (m *memoryQueue) Peek(topic string, limit int) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var messages []string
+	if queue, ok := m.messages[topic]; ok {
+		for e := queue.Front(); e != nil && (limit <= 0 || len(messages) < limit); e = e.Next() {
+			messages = append(messages, e.Value.(*memoryMessage).value)
+		}
+	}
+	return messages, nil
+}
+
+func (m *memoryQueue) MessageQueueSize(topic string) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if queue, ok := m.messages[topic]; ok {
+		return int64(queue.Len())
+	}
+	return 0
+}
@@ -19,7 +19,9 @@
 package mq
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,17 +30,41 @@ import (
 	"github.com/gridworkz/kato/mq/client"
 
 	"golang.org/x/net/context"
+)
 
-	etcdutil "github.com/gridworkz/kato/util/etcd"
+//MaxPriority the max priority of the messages
+const MaxPriority = 9
 
-	"github.com/coreos/etcd/clientv3"
-	"github.com/sirupsen/logrus"
-)
+//EnqueueOption the delivery options of a message
+type EnqueueOption struct {
+	//Priority messages with higher priority are dequeued first, 0 to MaxPriority
+	Priority int
+	//NotBefore the message is not dequeued before this time
+	NotBefore time.Time
+}
+
+//normalize returns the priority within range and the time the message is due
+func (o EnqueueOption) normalize() (int, time.Time) {
+	priority := o.Priority
+	if priority < 0 {
+		priority = 0
+	}
+	if priority > MaxPriority {
+		priority = MaxPriority
+	}
+	due := time.Now()
+	if o.NotBefore.After(due) {
+		due = o.NotBefore
+	}
+	return priority, due
+}
 
 //ActionMQ
 type ActionMQ interface {
-	Enqueue(context.Context, string, string) error
+	Enqueue(ctx context.Context, topic, value string, opt EnqueueOption) error
 	Dequeue(context.Context, string) (string, error)
+	//Peek returns the messages of the topic in dequeue order without removing them
+	Peek(topic string, limit int) ([]string, error)
 	TopicIsExist(string) bool
 	GetAllTopics() []string
 	Start() error
@@ -52,104 +78,111 @@ var EnqueueNumber float64 = 0
 // DequeueNumber
 var DequeueNumber float64 = 0
 
-// NewActionMQ
-func NewActionMQ(ctx context.Context, c option.Config) ActionMQ {
-	etcdQueue := etcdQueue{
-		config: c,
-		ctx:    ctx,
-		queues: make(map[string]string),
+// Creator builds a message queue backend with the given config
+type Creator func(ctx context.Context, c option.Config) (ActionMQ, error)
+
+var backends = make(map[string]Creator)
+var backendsLock sync.Mutex
+
+// RegisterBackend registers a message queue backend with the given name
+func RegisterBackend(name string, c Creator) error {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+	if _, ok := backends[name]; ok {
+		return fmt.Errorf("mq: backend named '%s' is already registered", name)
 	}
-	return &etcdQueue
+	backends[name] = c
+	return nil
 }
 
-type etcdQueue struct {
-	config     option.Config
-	ctx        context.Context
-	queues     map[string]string
-	queuesLock sync.Mutex
-	client     *clientv3.Client
+func init() {
+	RegisterBackend("etcd", newEtcdQueue)
+	RegisterBackend("bolt", newBoltQueue)
+	RegisterBackend("memory", newMemoryQueue)
 }
 
-func (e *etcdQueue) Start() error {
-	logrus.Debug("etcd message queue client starting")
-	etcdClientArgs := &etcdutil.ClientArgs{
-		Endpoints:   e.config.EtcdEndPoints,
-		CaFile:      e.config.EtcdCaFile,
-		CertFile:    e.config.EtcdCertFile,
-		KeyFile:     e.config.EtcdKeyFile,
-		DialTimeout: time.Duration(e.config.EtcdTimeout) * time.Second,
+// NewActionMQ create the message queue backend selected by c.Backend, default is etcd
+func NewActionMQ(ctx context.Context, c option.Config) (ActionMQ, error) {
+	name := c.Backend
+	if name == "" {
+		name = "etcd"
 	}
-	cli, err := etcdutil.NewClient(context.Background(), etcdClientArgs)
-	if err != nil {
-		etcdutil.HandleEtcdError(err)
-		return err
+	backendsLock.Lock()
+	creator, ok := backends[name]
+	backendsLock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("mq: no backend named '%s' is registered", name)
 	}
-	e.client = cli
-	topics := os.Getenv("topics")
-	if topics != "" {
-		ts := strings.Split(topics, ",")
-		for _, t := range ts {
-			e.registerTopic(t)
+	return creator(ctx, c)
+}
+
+//topics the topic registry shared by all backends
+type topics struct {
+	queues     map[string]string
+	queuesLock sync.Mutex
+}
+
+//loadTopics register the default topics and the topics specified by env
+func (t *topics) loadTopics() {
+	if ts := os.Getenv("topics"); ts != "" {
+		for _, topic := range strings.Split(ts, ",") {
+			t.registerTopic(topic)
 		}
 	}
-	e.registerTopic(client.BuilderTopic)
-	e.registerTopic(client.WindowsBuilderTopic)
-	e.registerTopic(client.WorkerTopic)
-	logrus.Info("etcd message queue client started success")
-	return nil
+	t.registerTopic(client.BuilderTopic)
+	t.registerTopic(client.WindowsBuilderTopic)
+	t.registerTopic(client.WorkerTopic)
 }
 
 //registerTopic
-func (e *etcdQueue) registerTopic(topic string) {
-	e.queuesLock.Lock()
-	defer e.queuesLock.Unlock()
-	e.queues[topic] = topic
+func (t *topics) registerTopic(topic string) {
+	t.queuesLock.Lock()
+	defer t.queuesLock.Unlock()
+	if t.queues == nil {
+		t.queues = make(map[string]string)
+	}
+	t.queues[topic] = topic
 }
 
-func (e *etcdQueue) TopicIsExist(topic string) bool {
-	e.queuesLock.Lock()
-	defer e.queuesLock.Unlock()
-	_, ok := e.queues[topic]
+func (t *topics) TopicIsExist(topic string) bool {
+	t.queuesLock.Lock()
+	defer t.queuesLock.Unlock()
+	_, ok := t.queues[topic]
 	return ok
 }
-func (e *etcdQueue) GetAllTopics() []string {
+
+func (t *topics) GetAllTopics() []string {
+	t.queuesLock.Lock()
+	defer t.queuesLock.Unlock()
 	var topics []string
-	for k := range e.queues {
+	for k := range t.queues {
 		topics = append(topics, k)
 	}
+	sort.Strings(topics)
 	return topics
 }
 
-func (e *etcdQueue) Stop() error {
-	if e.client != nil {
-		e.client.Close()
-	}
-	return nil
-}
-func (e *etcdQueue) queueKey(topic string) string {
-	return e.config.EtcdPrefix + "/" + topic
-}
-func (e *etcdQueue) Enqueue(ctx context.Context, topic, value string) error {
-	EnqueueNumber++
-	queue := etcdutil.NewQueue(ctx, e.client, e.queueKey(topic))
-	return queue.Enqueue(value)
+//notifier wakes up the waiters of the in-process backends and the tracker
+type notifier struct {
+	lock sync.Mutex
+	ch   chan struct{}
 }
 
-func (e *etcdQueue) Dequeue(ctx context.Context, topic string) (string, error) {
-	DequeueNumber++
-	queue := etcdutil.NewQueue(ctx, e.client, e.queueKey(topic))
-	return queue.Dequeue()
+//wait returns a channel that is closed on the next broadcast
+func (n *notifier) wait() <-chan struct{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	return n.ch
 }
 
-func (e *etcdQueue) MessageQueueSize(topic string) int64 {
-	ctx, cancel := context.WithCancel(e.ctx)
-	defer cancel()
-	res, err := e.client.Get(ctx, e.queueKey(topic), clientv3.WithPrefix())
-	if err != nil {
-		logrus.Errorf("get message queue size failure %s", err.Error())
-	}
-	if res != nil {
-		return res.Count
+func (n *notifier) broadcast() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
 	}
-	return 0
 }
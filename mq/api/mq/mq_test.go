@@ -25,17 +25,20 @@ import (
 )
 
 func TestEnqueue(t *testing.T) {
-	mq := NewActionMQ(context.TODO(), option.Config{
+	mq, err := NewActionMQ(context.TODO(), option.Config{
 		EtcdEndPoints: []string{"http://127.0.0.1:2379"},
 		EtcdPrefix:    "/mq",
 		EtcdTimeout:   5,
 	})
-	err := mq.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mq.Start()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer mq.Stop()
-	err = mq.Enqueue(context.Background(), "manager", "hello word")
+	err = mq.Enqueue(context.Background(), "manager", "hello word", EnqueueOption{})
 	if err != nil {
 		t.Fatal(err)
 	}
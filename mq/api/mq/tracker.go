@@ -0,0 +1,378 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+	"github.com/gridworkz/kato/mq/api/grpc/pb"
+
+	"golang.org/x/net/context"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
+)
+
+//DeadLetterTopic returns the topic that keeps the messages of topic which exceed the max retry
+func DeadLetterTopic(topic string) string {
+	return "dead_letter_" + topic
+}
+
+//TaskEnqueueOption returns the delivery options carried by the task
+func TaskEnqueueOption(task *pb.TaskMessage) EnqueueOption {
+	opt := EnqueueOption{Priority: int(task.Priority)}
+	if task.NotBefore > 0 {
+		opt.NotBefore = time.Unix(task.NotBefore, 0)
+	}
+	return opt
+}
+
+//requeueRetryInterval the interval to requeue an expired message again after the requeue failed
+var requeueRetryInterval = time.Second * 10
+
+//InflightStore is implemented by the durable backends to keep the in-flight messages,
+//so that they are requeued by their deadlines after the mq restarts
+type InflightStore interface {
+	SaveInflight(key string, value []byte) error
+	DeleteInflight(key string) error
+	ListInflight() (map[string][]byte, error)
+}
+
+//Tracker tracks the messages delivered to consumers. A message that is not acked
+//before the visibility timeout of its topic is requeued with its retry counter increased,
+//and moved to the dead letter topic once the retry counter exceeds the max retry.
+//The in-flight messages are persisted if the backend is an InflightStore, otherwise
+//they are kept in memory and requeued when the tracker stops.
+type Tracker struct {
+	mq                ActionMQ
+	store             InflightStore
+	visibilityTimeout time.Duration
+	topicTimeouts     map[string]time.Duration
+	maxRetry          int32
+	ctx               context.Context
+	cancel            context.CancelFunc
+	lock              sync.Mutex
+	inflight          map[string]*inflightMessage
+	released          notifier
+}
+
+type inflightMessage struct {
+	topic    string
+	consumer string
+	message  *pb.TaskMessage
+	deadline time.Time
+}
+
+//persistedMessage the in-flight message saved in the InflightStore
+type persistedMessage struct {
+	Topic    string `json:"topic"`
+	Consumer string `json:"consumer"`
+	Message  []byte `json:"message"`
+	Deadline int64  `json:"deadline"`
+}
+
+//NewTracker
+func NewTracker(ctx context.Context, mq ActionMQ, c option.Config) *Tracker {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &Tracker{
+		mq:                mq,
+		visibilityTimeout: time.Duration(c.VisibilityTimeout) * time.Second,
+		topicTimeouts:     make(map[string]time.Duration, len(c.TopicTimeouts)),
+		maxRetry:          int32(c.MaxRetry),
+		ctx:               ctx,
+		cancel:            cancel,
+		inflight:          make(map[string]*inflightMessage),
+	}
+	for topic, seconds := range c.TopicTimeouts {
+		if seconds > 0 {
+			t.topicTimeouts[topic] = time.Duration(seconds) * time.Second
+		}
+	}
+	if store, ok := mq.(InflightStore); ok {
+		t.store = store
+	}
+	return t
+}
+
+//timeout returns the visibility timeout of the topic
+func (t *Tracker) timeout(topic string) time.Duration {
+	if timeout, ok := t.topicTimeouts[topic]; ok {
+		return timeout
+	}
+	return t.visibilityTimeout
+}
+
+//Enabled whether the delivered messages need to be acked
+func (t *Tracker) Enabled() bool {
+	return t.visibilityTimeout > 0
+}
+
+//Start restores the persisted in-flight messages and starts requeueing the expired messages
+func (t *Tracker) Start() {
+	if !t.Enabled() {
+		logrus.Info("mq visibility timeout is disabled, messages are removed once dequeued")
+		return
+	}
+	t.restore()
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.ctx.Done():
+				return
+			case <-ticker.C:
+				t.requeueExpired()
+			}
+		}
+	}()
+}
+
+//Stop requeue all in-flight messages which are not persisted, they will be delivered again after restart
+func (t *Tracker) Stop() {
+	t.cancel()
+	if t.store != nil {
+		return
+	}
+	t.lock.Lock()
+	inflight := t.inflight
+	t.inflight = make(map[string]*inflightMessage)
+	t.lock.Unlock()
+	for _, m := range inflight {
+		t.requeue(m, false)
+	}
+}
+
+func inflightKey(topic, taskID string) string {
+	return topic + "/" + taskID
+}
+
+//restore loads the in-flight messages persisted before the restart, they are requeued by their deadlines
+func (t *Tracker) restore() {
+	if t.store == nil {
+		return
+	}
+	values, err := t.store.ListInflight()
+	if err != nil {
+		logrus.Errorf("list persisted in-flight messages failure %s", err.Error())
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key, value := range values {
+		var persisted persistedMessage
+		var message pb.TaskMessage
+		if err := json.Unmarshal(value, &persisted); err != nil {
+			logrus.Errorf("unmarshal in-flight message %s failure %s", key, err.Error())
+			continue
+		}
+		if err := proto.Unmarshal(persisted.Message, &message); err != nil {
+			logrus.Errorf("unmarshal in-flight task %s failure %s", key, err.Error())
+			continue
+		}
+		t.inflight[key] = &inflightMessage{
+			topic:    persisted.Topic,
+			consumer: persisted.Consumer,
+			message:  &message,
+			deadline: time.Unix(0, persisted.Deadline),
+		}
+	}
+	if len(values) > 0 {
+		logrus.Infof("restored %d in-flight messages", len(values))
+	}
+}
+
+func (t *Tracker) persist(key string, m *inflightMessage) {
+	if t.store == nil {
+		return
+	}
+	message, err := proto.Marshal(m.message)
+	if err != nil {
+		logrus.Errorf("marshal task %s failure %s", m.message.TaskId, err.Error())
+		return
+	}
+	value, _ := json.Marshal(&persistedMessage{
+		Topic:    m.topic,
+		Consumer: m.consumer,
+		Message:  message,
+		Deadline: m.deadline.UnixNano(),
+	})
+	if err := t.store.SaveInflight(key, value); err != nil {
+		logrus.Errorf("persist in-flight task %s failure %s", m.message.TaskId, err.Error())
+	}
+}
+
+func (t *Tracker) unpersist(key string) {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.DeleteInflight(key); err != nil {
+		logrus.Errorf("delete persisted in-flight message %s failure %s", key, err.Error())
+	}
+}
+
+//Deliver records the message delivered to the consumer
+func (t *Tracker) Deliver(topic, consumer string, message *pb.TaskMessage) {
+	if !t.Enabled() {
+		return
+	}
+	key := inflightKey(topic, message.TaskId)
+	m := &inflightMessage{
+		topic:    topic,
+		consumer: consumer,
+		message:  message,
+		deadline: time.Now().Add(t.timeout(topic)),
+	}
+	t.lock.Lock()
+	t.inflight[key] = m
+	t.lock.Unlock()
+	t.persist(key, m)
+}
+
+//remove removes the in-flight message, a message redelivered to another consumer is not removed
+func (t *Tracker) remove(topic, taskID, consumer string) (*inflightMessage, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	key := inflightKey(topic, taskID)
+	m, ok := t.inflight[key]
+	if !ok {
+		return nil, fmt.Errorf("task %s is not in flight on topic %s", taskID, topic)
+	}
+	if consumer != "" && m.consumer != "" && consumer != m.consumer {
+		return nil, fmt.Errorf("task %s is delivered to %s", taskID, m.consumer)
+	}
+	delete(t.inflight, key)
+	t.released.broadcast()
+	t.unpersist(key)
+	return m, nil
+}
+
+//Ack the message is handled and will not be delivered again
+func (t *Tracker) Ack(topic, taskID, consumer string) error {
+	if !t.Enabled() {
+		return nil
+	}
+	_, err := t.remove(topic, taskID, consumer)
+	return err
+}
+
+//Nack the message is not handled and will be requeued immediately
+func (t *Tracker) Nack(topic, taskID, consumer string) error {
+	if !t.Enabled() {
+		return nil
+	}
+	m, err := t.remove(topic, taskID, consumer)
+	if err != nil {
+		return err
+	}
+	return t.requeue(m, true)
+}
+
+//InFlight returns the number of messages of the topic held by the consumer
+func (t *Tracker) InFlight(topic, consumer string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var count int
+	for _, m := range t.inflight {
+		if m.topic == topic && m.consumer == consumer {
+			count++
+		}
+	}
+	return count
+}
+
+//WaitSlot blocks until the consumer holds less than maxInFlight messages of the topic.
+//The slot is freed when a message is acked, nacked or expired.
+func (t *Tracker) WaitSlot(ctx context.Context, topic, consumer string, maxInFlight int) error {
+	if !t.Enabled() || maxInFlight <= 0 {
+		return ctx.Err()
+	}
+	for {
+		released := t.released.wait()
+		if t.InFlight(topic, consumer) < maxInFlight {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (t *Tracker) requeueExpired() {
+	now := time.Now()
+	expired := make(map[string]*inflightMessage)
+	t.lock.Lock()
+	for key, m := range t.inflight {
+		if now.After(m.deadline) {
+			expired[key] = m
+			delete(t.inflight, key)
+		}
+	}
+	t.lock.Unlock()
+	if len(expired) > 0 {
+		t.released.broadcast()
+	}
+	for key, m := range expired {
+		logrus.Warningf("task %s is not acked by %s in %s, requeue it", m.message.TaskId, m.consumer, t.timeout(m.topic))
+		// the message is kept persisted until it is requeued, it is restored again if the mq stops before
+		if err := t.requeue(m, true); err != nil {
+			// keep it in flight and requeue it again later
+			m.message.Retry--
+			m.deadline = time.Now().Add(requeueRetryInterval)
+			t.lock.Lock()
+			if _, ok := t.inflight[key]; !ok {
+				t.inflight[key] = m
+			}
+			t.lock.Unlock()
+			continue
+		}
+		t.unpersist(key)
+	}
+}
+
+//requeue enqueue the message back to its topic, or to the dead letter topic
+//if the retry counter exceeds the max retry
+func (t *Tracker) requeue(m *inflightMessage, retry bool) error {
+	topic := m.topic
+	if retry {
+		m.message.Retry++
+		if m.message.Retry > t.maxRetry {
+			topic = DeadLetterTopic(m.topic)
+			logrus.Warningf("task %s exceeds the max retry %d, move it to %s", m.message.TaskId, t.maxRetry, topic)
+		}
+	}
+	message, err := proto.Marshal(m.message)
+	if err != nil {
+		logrus.Errorf("marshal task %s failure %s", m.message.TaskId, err.Error())
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := t.mq.Enqueue(ctx, topic, string(message), TaskEnqueueOption(m.message)); err != nil {
+		logrus.Errorf("requeue task %s to %s failure %s", m.message.TaskId, topic, err.Error())
+		return err
+	}
+	return nil
+}
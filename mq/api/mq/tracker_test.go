@@ -0,0 +1,182 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gridworkz/kato/cmd/mq/option"
+	"github.com/gridworkz/kato/mq/api/grpc/pb"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+func TestTrackerNack(t *testing.T) {
+	mq, _ := NewActionMQ(context.TODO(), option.Config{Backend: "memory"})
+	if err := mq.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mq.Stop()
+	tracker := NewTracker(context.TODO(), mq, option.Config{VisibilityTimeout: 60, MaxRetry: 1})
+	task := &pb.TaskMessage{TaskId: "t1", TaskType: "build_from_image"}
+	tracker.Deliver("builder", "builder-1", task)
+	if err := tracker.Ack("builder", "t1", "builder-2"); err == nil {
+		t.Fatal("a task delivered to another consumer should not be acked")
+	}
+	if err := tracker.Nack("builder", "t1", "builder-1"); err != nil {
+		t.Fatal(err)
+	}
+	if size := mq.MessageQueueSize("builder"); size != 1 {
+		t.Fatalf("expected the nacked task to be requeued, queue size %d", size)
+	}
+	value, _ := mq.Dequeue(context.Background(), "builder")
+	var requeued pb.TaskMessage
+	if err := proto.Unmarshal([]byte(value), &requeued); err != nil {
+		t.Fatal(err)
+	}
+	if requeued.Retry != 1 {
+		t.Fatalf("expected retry 1, got %d", requeued.Retry)
+	}
+	tracker.Deliver("builder", "builder-1", &requeued)
+	if err := tracker.Nack("builder", "t1", "builder-1"); err != nil {
+		t.Fatal(err)
+	}
+	if size := mq.MessageQueueSize(DeadLetterTopic("builder")); size != 1 {
+		t.Fatalf("expected the task to be dead lettered, dead letter size %d", size)
+	}
+	if err := tracker.Ack("builder", "t1", "builder-1"); err == nil {
+		t.Fatal("a dead lettered task should not be in flight")
+	}
+}
+
+func TestTrackerWaitSlot(t *testing.T) {
+	mq, _ := NewActionMQ(context.TODO(), option.Config{Backend: "memory"})
+	if err := mq.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mq.Stop()
+	tracker := NewTracker(context.TODO(), mq, option.Config{VisibilityTimeout: 60, MaxRetry: 3})
+	tracker.Deliver("worker", "worker-1", &pb.TaskMessage{TaskId: "t1"})
+	tracker.Deliver("worker", "worker-1", &pb.TaskMessage{TaskId: "t2"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := tracker.WaitSlot(ctx, "worker", "worker-1", 2); err == nil {
+		t.Fatal("expected no free slot while two tasks are in flight")
+	}
+	if err := tracker.WaitSlot(context.Background(), "worker", "worker-2", 2); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- tracker.WaitSlot(context.Background(), "worker", "worker-1", 2)
+	}()
+	tracker.Ack("worker", "t1", "worker-1")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the slot is not freed by ack")
+	}
+}
+
+type failingMQ struct {
+	ActionMQ
+	fail bool
+}
+
+func (f *failingMQ) Enqueue(ctx context.Context, topic, value string, opt EnqueueOption) error {
+	if f.fail {
+		return errors.New("enqueue failure")
+	}
+	return f.ActionMQ.Enqueue(ctx, topic, value, opt)
+}
+
+func TestTrackerRequeueFailure(t *testing.T) {
+	memory, _ := NewActionMQ(context.TODO(), option.Config{Backend: "memory"})
+	if err := memory.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer memory.Stop()
+	mq := &failingMQ{ActionMQ: memory, fail: true}
+	tracker := NewTracker(context.TODO(), mq, option.Config{VisibilityTimeout: 60, MaxRetry: 3})
+	tracker.Deliver("worker", "worker-1", &pb.TaskMessage{TaskId: "t1"})
+	key := inflightKey("worker", "t1")
+	tracker.inflight[key].deadline = time.Now().Add(-time.Second)
+	tracker.requeueExpired()
+	m, ok := tracker.inflight[key]
+	if !ok {
+		t.Fatal("the task should be kept in flight when the requeue fails")
+	}
+	if m.message.Retry != 0 || !m.deadline.After(time.Now()) {
+		t.Fatalf("expected retry 0 and a pushed back deadline, got retry %d deadline %s", m.message.Retry, m.deadline)
+	}
+	mq.fail = false
+	m.deadline = time.Now().Add(-time.Second)
+	tracker.requeueExpired()
+	if _, ok := tracker.inflight[key]; ok {
+		t.Fatal("the requeued task should not be in flight")
+	}
+	if size := memory.MessageQueueSize("worker"); size != 1 {
+		t.Fatalf("expected the task to be requeued, queue size %d", size)
+	}
+}
+
+func TestTrackerRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mq, _ := NewActionMQ(context.TODO(), option.Config{Backend: "bolt", DataPath: dir})
+	if err := mq.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mq.Stop()
+	config := option.Config{VisibilityTimeout: 60, MaxRetry: 3, TopicTimeouts: map[string]int{"builder": 600}}
+	tracker := NewTracker(context.TODO(), mq, config)
+	tracker.Start()
+	tracker.Deliver("builder", "builder-1", &pb.TaskMessage{TaskId: "t1"})
+	tracker.Stop()
+	if size := mq.MessageQueueSize("builder"); size != 0 {
+		t.Fatalf("expected the persisted task not to be requeued, queue size %d", size)
+	}
+
+	restored := NewTracker(context.TODO(), mq, config)
+	restored.Start()
+	defer restored.Stop()
+	if n := restored.InFlight("builder", "builder-1"); n != 1 {
+		t.Fatalf("expected the task to be restored in flight, got %d", n)
+	}
+	if m := restored.inflight[inflightKey("builder", "t1")]; time.Until(m.deadline) <= time.Minute {
+		t.Fatalf("expected the visibility timeout of the topic, deadline in %s", time.Until(m.deadline))
+	}
+	if err := restored.Ack("builder", "t1", "builder-1"); err != nil {
+		t.Fatal(err)
+	}
+	if values, _ := mq.(InflightStore).ListInflight(); len(values) != 0 {
+		t.Fatalf("expected the acked task to be removed from the store, got %d", len(values))
+	}
+}
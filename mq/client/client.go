@@ -21,6 +21,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/gridworkz/kato/mq/api/grpc/pb"
@@ -45,6 +46,7 @@ type MQClient interface {
 	pb.TaskQueueClient
 	Close()
 	SendBuilderTopic(t TaskStruct) error
+	SubscribeTopic(ctx context.Context, req *pb.SubscribeRequest, handle func(*pb.TaskMessage)) error
 }
 
 type mqClient struct {
@@ -94,6 +96,10 @@ type TaskStruct struct {
 	Topic    string
 	TaskType string
 	TaskBody interface{}
+	//Priority tasks with higher priority are dequeued first, 0-9
+	Priority int32
+	//NotBefore the task is not dequeued before this time
+	NotBefore time.Time
 }
 
 //BuildTask
@@ -105,6 +111,10 @@ func buildTask(t TaskStruct) (*pb.EnqueueRequest, error) {
 		return &er, err
 	}
 	er.Topic = t.Topic
+	er.Priority = t.Priority
+	if !t.NotBefore.IsZero() {
+		er.NotBefore = t.NotBefore.Unix()
+	}
 	er.Message = &pb.TaskMessage{
 		TaskType:   t.TaskType,
 		CreateTime: time.Now().Format(time.RFC3339),
@@ -127,3 +137,25 @@ func (m *mqClient) SendBuilderTopic(t TaskStruct) error {
 	}
 	return nil
 }
+
+//SubscribeTopic receives the tasks pushed by mq and calls handle for each of them.
+//It blocks until the context is done or the subscription is broken, the caller
+//should subscribe again on error.
+func (m *mqClient) SubscribeTopic(ctx context.Context, req *pb.SubscribeRequest, handle func(*pb.TaskMessage)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := m.TaskQueueClient.Subscribe(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		task, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("subscription of topic %s is closed by server", req.Topic)
+			}
+			return err
+		}
+		handle(task)
+	}
+}
@@ -19,18 +19,29 @@
 package controller
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path"
-	"runtime"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/gridworkz/kato/node/localvolume"
 	"github.com/gridworkz/kato/util"
 	"github.com/sirupsen/logrus"
 
 	httputil "github.com/gridworkz/kato/util/http"
 )
 
+//localDataPath returns the dir of the local volumes on the node
+func localDataPath() string {
+	return localvolume.DataPath()
+}
+
 //CreateLocalVolume
 func CreateLocalVolume(w http.ResponseWriter, r *http.Request) {
 	var requestopt = make(map[string]string)
@@ -42,16 +53,7 @@ func CreateLocalVolume(w http.ResponseWriter, r *http.Request) {
 	serviceID := requestopt["service_id"]
 	pvcName := requestopt["pvcname"]
 	var volumeHostPath = ""
-	localPath := os.Getenv("LOCAL_DATA_PATH")
-	if runtime.GOOS == "windows" {
-		if localPath == "" {
-			localPath = `c:\`
-		}
-	} else {
-		if localPath == "" {
-			localPath = "/grlocaldata"
-		}
-	}
+	localPath := localDataPath()
 	volumeHostPath = path.Join(localPath, "tenant", tenantID, "service", serviceID, pvcName)
 	volumePath, volumeok := requestopt["volume_path"]
 	podName, podok := requestopt["pod_name"]
@@ -63,6 +65,13 @@ func CreateLocalVolume(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
 		return
 	}
+	if capacity, _ := strconv.ParseInt(requestopt["capacity"], 10, 64); capacity > 0 {
+		if err := localvolume.Default().Set(volumeHostPath, capacity); err != nil {
+			logrus.Errorf("path: %s; limit the capacity of local volume: %v", volumeHostPath, err)
+			w.WriteHeader(500)
+			return
+		}
+	}
 	httputil.ReturnSuccess(r, w, map[string]string{"path": volumeHostPath})
 }
 
@@ -73,12 +82,130 @@ func DeleteLocalVolume(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(400)
 		return
 	}
-	path := requestopt["path"]
-
+	path, ok := checkLocalVolumePath(requestopt["path"])
+	if !ok {
+		httputil.ReturnError(r, w, 400, "the path is not a local volume")
+		return
+	}
+	if err := localvolume.Default().Remove(path); err != nil {
+		logrus.Errorf("path: %s; remove the capacity limit of local volume: %v", path, err)
+		w.WriteHeader(500)
+		return
+	}
 	if err := os.RemoveAll(path); err != nil {
 		logrus.Errorf("path: %s; remove pv path: %v", path, err)
 		w.WriteHeader(500)
+		return
+	}
+
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// LocalVolumeUsage returns the usage of the local volumes in the body
+func LocalVolumeUsage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Paths []string `json:"paths"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.ReturnError(r, w, 400, "the body is not valid")
+		return
+	}
+	usages := make([]*localvolume.Usage, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		volumePath, ok := checkLocalVolumePath(p)
+		if !ok {
+			httputil.ReturnError(r, w, 400, fmt.Sprintf("the path %s is not a local volume", p))
+			return
+		}
+		usage, err := localvolume.Default().Usage(volumePath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.Warningf("path: %s; get the usage of local volume: %v", volumePath, err)
+			}
+			continue
+		}
+		usages = append(usages, usage)
+	}
+	httputil.ReturnSuccess(r, w, usages)
+}
+
+//checkLocalVolumePath returns the cleaned path, it must be in the dir of the local volumes
+func checkLocalVolumePath(volumePath string) (string, bool) {
+	if volumePath == "" {
+		return "", false
+	}
+	volumePath = filepath.Clean(volumePath)
+	tenantPath := filepath.Join(localDataPath(), "tenant") + string(filepath.Separator)
+	return volumePath, strings.HasPrefix(volumePath, tenantPath)
+}
+
+//localVolumePath returns the path of the local volume in the query
+func localVolumePath(r *http.Request) (string, bool) {
+	return checkLocalVolumePath(r.URL.Query().Get("path"))
+}
+
+// ArchiveLocalVolume writes the data of the local volume as a tar.gz archive.
+// The data may take longer than the request time out, the request context is not used.
+func ArchiveLocalVolume(w http.ResponseWriter, r *http.Request) {
+	volumePath, ok := localVolumePath(r)
+	if !ok {
+		httputil.ReturnError(r, w, 400, "the path is not a local volume")
+		return
+	}
+	if _, err := os.Stat(volumePath); err != nil {
+		if os.IsNotExist(err) {
+			httputil.ReturnError(r, w, 404, "the local volume is not found")
+			return
+		}
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := util.TarDir(tw, volumePath, ""); err != nil {
+		// the status code is sent, the client finds the broken archive
+		logrus.Errorf("path: %s; archive local volume: %v", volumePath, err)
+		return
+	}
+	if err := tw.Close(); err != nil {
+		logrus.Errorf("path: %s; close tar writer: %v", volumePath, err)
+		return
+	}
+	if err := gw.Close(); err != nil {
+		logrus.Errorf("path: %s; close gzip writer: %v", volumePath, err)
 	}
+}
 
+// RestoreLocalVolume replaces the data of the local volume with the tar.gz archive in the body
+func RestoreLocalVolume(w http.ResponseWriter, r *http.Request) {
+	volumePath, ok := localVolumePath(r)
+	if !ok {
+		httputil.ReturnError(r, w, 400, "the path is not a local volume")
+		return
+	}
+	gr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		httputil.ReturnError(r, w, 400, "the body is not a gzip archive")
+		return
+	}
+	defer gr.Close()
+	// the data are replaced only if the archive is extracted completely
+	restorePath := volumePath + ".restoring"
+	if err := os.RemoveAll(restorePath); err != nil {
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	defer os.RemoveAll(restorePath)
+	if err := util.UnTarDir(tar.NewReader(gr), restorePath, nil); err != nil {
+		logrus.Errorf("path: %s; extract local volume archive: %v", volumePath, err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	if err := util.ReplaceDir(volumePath, restorePath); err != nil {
+		logrus.Errorf("path: %s; restore local volume: %v", volumePath, err)
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
 	httputil.ReturnSuccess(r, w, nil)
 }
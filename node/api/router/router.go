@@ -55,6 +55,9 @@ func Routers(mode string) *chi.Mux {
 		r.Route("/localvolumes", func(r chi.Router) {
 			r.Post("/create", controller.CreateLocalVolume)
 			r.Delete("/", controller.DeleteLocalVolume)
+			r.Get("/archive", controller.ArchiveLocalVolume)
+			r.Post("/restore", controller.RestoreLocalVolume)
+			r.Post("/usage", controller.LocalVolumeUsage)
 		})
 		//The following APIs are only available for management nodes
 		if mode == "master" {
@@ -0,0 +1,216 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package localvolume
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	//ModeAuto uses xfs project quota if the file system supports it, otherwise the loop image
+	ModeAuto = "auto"
+	//ModeXFS uses xfs project quota
+	ModeXFS = "xfs"
+	//ModeLoop mounts a loop image with the capacity of the volume
+	ModeLoop = "loop"
+	//ModeNone does not limit the capacity
+	ModeNone = "none"
+)
+
+//the first project id of the local volumes
+const firstProjectID uint32 = 1000
+
+//DataPath returns the dir of the local volumes on the node
+func DataPath() string {
+	localPath := os.Getenv("LOCAL_DATA_PATH")
+	if runtime.GOOS == "windows" {
+		if localPath == "" {
+			localPath = `c:\`
+		}
+	} else {
+		if localPath == "" {
+			localPath = "/grlocaldata"
+		}
+	}
+	return localPath
+}
+
+//Usage the usage of the local volume, in bytes
+type Usage struct {
+	Path string `json:"path"`
+	Used int64  `json:"used"`
+	// Capacity is 0 if the capacity of the volume is not limited
+	Capacity int64 `json:"capacity"`
+}
+
+//Quota enforces the capacity of the local volumes
+type Quota struct {
+	dataPath string
+	mode     string
+	lock     sync.Mutex
+}
+
+var defaultQuota *Quota
+var once sync.Once
+
+//Default returns the quota of the local volumes in the data path, the mode is set by env LOCAL_VOLUME_QUOTA
+func Default() *Quota {
+	once.Do(func() {
+		defaultQuota = New(DataPath(), os.Getenv("LOCAL_VOLUME_QUOTA"))
+	})
+	return defaultQuota
+}
+
+//New creates a quota
+func New(dataPath, mode string) *Quota {
+	if mode == "" {
+		mode = ModeAuto
+	}
+	return &Quota{dataPath: dataPath, mode: mode}
+}
+
+func (q *Quota) projectsFile() string {
+	return filepath.Join(q.dataPath, ".quota-projects")
+}
+
+//the project ids of the volumes, the ids can not be derived from the paths without collisions
+func (q *Quota) loadProjects() (map[string]uint32, error) {
+	projects := make(map[string]uint32)
+	body, err := ioutil.ReadFile(q.projectsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return projects, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &projects); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", q.projectsFile(), err)
+	}
+	return projects, nil
+}
+
+func (q *Quota) saveProjects(projects map[string]uint32) error {
+	body, err := json.Marshal(projects)
+	if err != nil {
+		return err
+	}
+	tmp := q.projectsFile() + ".tmp"
+	if err := ioutil.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.projectsFile())
+}
+
+//nextProjectID returns the smallest unused project id
+func nextProjectID(projects map[string]uint32) uint32 {
+	used := make(map[uint32]bool, len(projects))
+	for _, id := range projects {
+		used[id] = true
+	}
+	id := firstProjectID
+	for used[id] {
+		id++
+	}
+	return id
+}
+
+type mountPoint struct {
+	path    string
+	fsType  string
+	options []string
+}
+
+func (m *mountPoint) hasOption(options ...string) bool {
+	for _, o := range m.options {
+		for _, option := range options {
+			if o == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//projectQuota returns whether the project quota of xfs is enabled
+func (m *mountPoint) projectQuota() bool {
+	return m.fsType == "xfs" && m.hasOption("prjquota", "pquota", "pqnoenforce")
+}
+
+//parseMounts parses the mount points in the format of /proc/mounts
+func parseMounts(r io.Reader) ([]mountPoint, error) {
+	var mounts []mountPoint
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, mountPoint{
+			path:    unescapeMountPath(fields[1]),
+			fsType:  fields[2],
+			options: strings.Split(fields[3], ","),
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+//unescapeMountPath unescapes the octal characters such as \040 in the mount paths
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			b.WriteByte((s[i+1]-'0')<<6 | (s[i+2]-'0')<<3 | (s[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+//findMount returns the mount point the path belongs to, the later mount shadows the earlier one
+func findMount(mounts []mountPoint, path string) *mountPoint {
+	path = filepath.Clean(path)
+	var found *mountPoint
+	for i := range mounts {
+		m := &mounts[i]
+		if m.path != "/" && path != m.path && !strings.HasPrefix(path, m.path+"/") {
+			continue
+		}
+		if found == nil || len(m.path) >= len(found.path) {
+			found = m
+		}
+	}
+	return found
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+// +build linux
+
+package localvolume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gridworkz/kato/util"
+	"github.com/sirupsen/logrus"
+)
+
+func readMounts() ([]mountPoint, error) {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseMounts(f)
+}
+
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %v, %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+//loopImage returns the image file of the volume in the loop mode
+func loopImage(path string) string {
+	return filepath.Clean(path) + ".img"
+}
+
+//Set limits the capacity of the volume in bytes, it does nothing if the volume is limited already
+func (q *Quota) Set(path string, capacity int64) error {
+	if capacity <= 0 || q.mode == ModeNone {
+		return nil
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	mounts, err := readMounts()
+	if err != nil {
+		return fmt.Errorf("read mounts: %v", err)
+	}
+	mount := findMount(mounts, path)
+	if mount != nil && mount.path == filepath.Clean(path) && mount.fsType != "xfs" {
+		// the loop image is mounted
+		return nil
+	}
+	switch {
+	case mount != nil && mount.projectQuota() && q.mode != ModeLoop:
+		return q.setProjectQuota(mount, path, capacity)
+	case q.mode == ModeXFS:
+		return fmt.Errorf("the project quota of xfs is not enabled for %s", path)
+	default:
+		if _, err := exec.LookPath("mkfs.ext4"); err != nil {
+			if q.mode == ModeLoop {
+				return fmt.Errorf("mkfs.ext4 is required by the loop image: %v", err)
+			}
+			logrus.Warningf("path: %s; neither the project quota of xfs nor mkfs.ext4 is available, the capacity is not limited", path)
+			return nil
+		}
+		return q.mountLoopImage(path, capacity)
+	}
+}
+
+func (q *Quota) setProjectQuota(mount *mountPoint, path string, capacity int64) error {
+	projects, err := q.loadProjects()
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	id, ok := projects[path]
+	if !ok {
+		id = nextProjectID(projects)
+		projects[path] = id
+		// save the id first, a failed setting is cleared by Remove
+		if err := q.saveProjects(projects); err != nil {
+			return fmt.Errorf("save project id: %v", err)
+		}
+		if err := run("xfs_quota", "-x", "-c", fmt.Sprintf("project -s -p %s %d", path, id), mount.path); err != nil {
+			return err
+		}
+	}
+	return run("xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=%dk %d", capacity/1024, id), mount.path)
+}
+
+func (q *Quota) mountLoopImage(path string, capacity int64) error {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		// can not mount the image over the data
+		logrus.Warningf("path: %s; the volume has data already, the capacity is not limited", path)
+		return nil
+	}
+	image := loopImage(path)
+	if _, err := os.Stat(image); os.IsNotExist(err) {
+		f, err := os.OpenFile(image, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		// the image is sparse, the space is allocated on writing
+		err = f.Truncate(capacity)
+		f.Close()
+		if err == nil {
+			err = run("mkfs.ext4", "-q", "-F", image)
+		}
+		if err != nil {
+			os.Remove(image)
+			return err
+		}
+	}
+	if err := run("mount", "-o", "loop", image, path); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0777)
+}
+
+//Remove removes the limit of the volume, the data of the volume are kept
+func (q *Quota) Remove(path string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	path = filepath.Clean(path)
+	mounts, err := readMounts()
+	if err != nil {
+		return fmt.Errorf("read mounts: %v", err)
+	}
+	if mount := findMount(mounts, path); mount != nil && mount.path == path {
+		if err := run("umount", path); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(loopImage(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	projects, err := q.loadProjects()
+	if err != nil {
+		return err
+	}
+	id, ok := projects[path]
+	if !ok {
+		return nil
+	}
+	if mount := findMount(mounts, path); mount != nil && mount.projectQuota() {
+		if err := run("xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=0 %d", id), mount.path); err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err == nil {
+			if err := run("xfs_quota", "-x", "-c", fmt.Sprintf("project -C -p %s %d", path, id), mount.path); err != nil {
+				return err
+			}
+		}
+	}
+	delete(projects, path)
+	return q.saveProjects(projects)
+}
+
+//Usage returns the usage of the volume
+func (q *Quota) Usage(path string) (*Usage, error) {
+	path = filepath.Clean(path)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	limited := false
+	if _, err := os.Stat(loopImage(path)); err == nil {
+		limited = true
+	} else if projects, err := q.loadProjects(); err == nil {
+		_, limited = projects[path]
+	}
+	if limited {
+		// statfs reports the loop file system or the project quota of the dir
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return nil, err
+		}
+		return &Usage{
+			Path:     path,
+			Used:     int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize),
+			Capacity: int64(stat.Blocks) * int64(stat.Bsize),
+		}, nil
+	}
+	return &Usage{Path: path, Used: int64(util.GetDirSize(path) * 1024)}, nil
+}
+
+//Remount mounts the loop images of the volumes again after the node is restarted
+func (q *Quota) Remount() error {
+	images, err := filepath.Glob(filepath.Join(q.dataPath, "tenant", "*", "service", "*", "*.img"))
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	mounts, err := readMounts()
+	if err != nil {
+		return fmt.Errorf("read mounts: %v", err)
+	}
+	for _, image := range images {
+		path := strings.TrimSuffix(image, ".img")
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		if mount := findMount(mounts, path); mount != nil && mount.path == path {
+			continue
+		}
+		if err := run("mount", "-o", "loop", image, path); err != nil {
+			logrus.Errorf("path: %s; remount local volume: %v", path, err)
+		}
+	}
+	return nil
+}
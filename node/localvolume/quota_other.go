@@ -0,0 +1,51 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+// +build !linux
+
+package localvolume
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gridworkz/kato/util"
+)
+
+//Set does not limit the capacity of the volume, the quota is only supported on linux
+func (q *Quota) Set(path string, capacity int64) error {
+	return nil
+}
+
+//Remove does nothing, the quota is only supported on linux
+func (q *Quota) Remove(path string) error {
+	return nil
+}
+
+//Usage returns the usage of the volume
+func (q *Quota) Usage(path string) (*Usage, error) {
+	path = filepath.Clean(path)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &Usage{Path: path, Used: int64(util.GetDirSize(path) * 1024)}, nil
+}
+
+//Remount does nothing, the quota is only supported on linux
+func (q *Quota) Remount() error {
+	return nil
+}
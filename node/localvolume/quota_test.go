@@ -0,0 +1,67 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package localvolume
+
+import (
+	"strings"
+	"testing"
+)
+
+const mounts = `/dev/vda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/vdb /grlocaldata xfs rw,relatime,attr2,inode64,prjquota 0 0
+/dev/loop0 /grlocaldata/tenant/t1/service/s1/manual1-s1-0 ext4 rw,relatime 0 0
+/dev/vdc /mnt/my\040data xfs rw,relatime 0 0
+`
+
+func TestFindMount(t *testing.T) {
+	ms, err := parseMounts(strings.NewReader(mounts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path, mount  string
+		projectQuota bool
+	}{
+		{"/grlocaldata/tenant/t1/service/s1/manual2-s1-0", "/grlocaldata", true},
+		{"/grlocaldata/tenant/t1/service/s1/manual1-s1-0", "/grlocaldata/tenant/t1/service/s1/manual1-s1-0", false},
+		{"/grlocaldata/tenant/t1/service/s1/manual1-s1-0/sub", "/grlocaldata/tenant/t1/service/s1/manual1-s1-0", false},
+		{"/grlocaldata2/tenant", "/", false},
+		{"/mnt/my data/tenant", "/mnt/my data", false},
+	}
+	for _, tc := range tests {
+		m := findMount(ms, tc.path)
+		if m == nil || m.path != tc.mount {
+			t.Errorf("path %s: want mount %s, got %+v", tc.path, tc.mount, m)
+			continue
+		}
+		if m.projectQuota() != tc.projectQuota {
+			t.Errorf("path %s: want project quota %v", tc.path, tc.projectQuota)
+		}
+	}
+}
+
+func TestNextProjectID(t *testing.T) {
+	if id := nextProjectID(map[string]uint32{}); id != firstProjectID {
+		t.Errorf("want %d, got %d", firstProjectID, id)
+	}
+	projects := map[string]uint32{"/a": firstProjectID, "/b": firstProjectID + 2}
+	if id := nextProjectID(projects); id != firstProjectID+1 {
+		t.Errorf("want %d, got %d", firstProjectID+1, id)
+	}
+}
@@ -93,11 +93,11 @@ func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_mod
 			return envoyv2.GetOptionValues(nil)
 		}
 		var clusterOption envoyv2.ClusterOptions
-		clusterOption.Name = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, GetServiceAliasByService(service), port.Port)
+		clusterOption.Name = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, GetServiceAliasByService(service), port.Port) + getClusterSuffix(service)
 		options := getOptions()
 		clusterOption.OutlierDetection = envoyv2.CreatOutlierDetection(options)
 		clusterOption.CircuitBreakers = envoyv2.CreateCircuitBreaker(options)
-		clusterOption.ServiceName = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, destServiceAlias, port.Port)
+		clusterOption.ServiceName = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, destServiceAlias, port.Port) + getClusterSuffix(service)
 		if domain, ok := service.Annotations["domain"]; ok && domain != "" {
 			logrus.Debugf("domain endpoint[%s], create logical_dns cluster: ", domain)
 			clusterOption.ClusterType = v2.Cluster_LOGICAL_DNS
@@ -44,7 +44,7 @@ func OneNodeClusterLoadAssignment(serviceAlias, namespace string, endpoints []*c
 			logrus.Errorf("service alias is empty in k8s service %s", service.Name)
 			continue
 		}
-		clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, destServiceAlias, service.Spec.Ports[0].Port)
+		clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, destServiceAlias, service.Spec.Ports[0].Port) + getClusterSuffix(service)
 		selectEndpoint := getEndpointsByServiceName(endpoints, service.Name)
 		logrus.Debugf("select endpoints %d for service %s", len(selectEndpoint), service.Name)
 		var lendpoints []*endpoint.LocalityLbEndpoints // localityLbEndpoints just support only one content
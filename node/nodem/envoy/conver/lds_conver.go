@@ -122,7 +122,7 @@ func upstreamListener(serviceAlias, namespace string, dependsServices []*api_mod
 				ListenPort = int32(origin)
 			}
 		}
-		clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, GetServiceAliasByService(service), port)
+		clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, GetServiceAliasByService(service), port) + getClusterSuffix(service)
 		listennerName := fmt.Sprintf("%s_%s_%s_%s_%d", namespace, serviceAlias, GetServiceAliasByService(service), strings.ToLower(string(protocol)), ListenPort)
 		destService := ListennerConfig[listennerName]
 		statPrefix := fmt.Sprintf("%s_%s", serviceAlias, GetServiceAliasByService(service))
@@ -132,8 +132,11 @@ func upstreamListener(serviceAlias, namespace string, dependsServices []*api_mod
 		} else {
 			logrus.Warningf("destService is nil for service %s listenner name %s", serviceAlias, listennerName)
 		}
-		// Unique by listen port
-		if _, ok := portMap[ListenPort]; !ok {
+		if weight, ok := getServiceWeight(service); ok {
+			options.Weight = weight
+		}
+		// Unique by listen port, the canary service only joins the http routes
+		if _, ok := portMap[ListenPort]; !ok && getClusterSuffix(service) == "" {
 			//listener name depend listner port
 			listenerName := fmt.Sprintf("%s_%s_%d", namespace, serviceAlias, ListenPort)
 			var listener *v2.Listener
@@ -314,3 +317,24 @@ func GetServiceAliasByService(service *corev1.Service) string {
 	}
 	return ""
 }
+
+//getClusterSuffix the canary service of a progressive release is a separate cluster of the same upstream
+func getClusterSuffix(service *corev1.Service) string {
+	if service.Labels["release"] == "canary" {
+		return "_canary"
+	}
+	return ""
+}
+
+//getServiceWeight the route weight set on the service by the progressive release
+func getServiceWeight(service *corev1.Service) (uint32, bool) {
+	value, ok := service.Annotations["weight"]
+	if !ok {
+		return 0, false
+	}
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 0 || weight > 100 {
+		return 0, false
+	}
+	return uint32(weight), true
+}
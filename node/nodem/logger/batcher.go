@@ -0,0 +1,303 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/sirupsen/logrus"
+)
+
+//ErrBatcherClosed the batcher is closed and can not accept log entry
+var ErrBatcherClosed = errors.New("log batcher is closed")
+
+//ErrBufferFull the buffer of the batcher is full, the log entry is dropped
+var ErrBufferFull = errors.New("log buffer is full, log entry dropped")
+
+//PermanentError the batch can never be sent successfully, the batcher drops it without retry
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+//BatchLogOpts log opts that are handled by the batcher, log drivers based on the
+//batcher should accept them in their LogOptValidator
+var BatchLogOpts = map[string]bool{
+	"batch-size":  true,
+	"batch-wait":  true,
+	"buffer-size": true,
+	"max-retries": true,
+	"min-backoff": true,
+	"max-backoff": true,
+}
+
+//Entry a copy of the log message held by the batcher
+type Entry struct {
+	Line      []byte
+	Source    string
+	Timestamp time.Time
+	//the level parsed from the json log, empty if unknown
+	Level string
+}
+
+//FlushFunc send a batch of log entries to the remote server
+type FlushFunc func(ctx context.Context, entries []*Entry) error
+
+//BatchConfig batch config
+type BatchConfig struct {
+	//max entries of a batch
+	BatchSize int
+	//max wait time before a not full batch is sent
+	BatchWait time.Duration
+	//max entries cached in memory
+	BufferSize int
+	//if Blocking is true, Log will wait when the buffer is full, otherwise the entry is dropped
+	Blocking   bool
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+//DefaultBatchConfig default batch config
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		BatchSize:  100,
+		BatchWait:  time.Second,
+		BufferSize: 10000,
+		MaxRetries: 5,
+		MinBackoff: time.Millisecond * 500,
+		MaxBackoff: time.Second * 30,
+	}
+}
+
+//ValidateBatchOpt validate the value of a batch log opt
+func ValidateBatchOpt(key, value string) error {
+	switch key {
+	case "batch-size", "buffer-size":
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return fmt.Errorf("%s must be a positive number", key)
+		}
+	case "max-retries":
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%s must be a non-negative number", key)
+		}
+	case "batch-wait", "min-backoff", "max-backoff":
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("%s must be a positive duration, for example 1s", key)
+		}
+	default:
+		return fmt.Errorf("unknown batch log opt '%s'", key)
+	}
+	return nil
+}
+
+//ParseBatchConfig parse batch config from log opts, unset opts use the default value
+func ParseBatchConfig(cfg map[string]string) (BatchConfig, error) {
+	conf := DefaultBatchConfig()
+	for key, value := range cfg {
+		if !BatchLogOpts[key] {
+			continue
+		}
+		if err := ValidateBatchOpt(key, value); err != nil {
+			return conf, err
+		}
+		switch key {
+		case "batch-size":
+			conf.BatchSize, _ = strconv.Atoi(value)
+		case "buffer-size":
+			conf.BufferSize, _ = strconv.Atoi(value)
+		case "max-retries":
+			conf.MaxRetries, _ = strconv.Atoi(value)
+		case "batch-wait":
+			conf.BatchWait, _ = time.ParseDuration(value)
+		case "min-backoff":
+			conf.MinBackoff, _ = time.ParseDuration(value)
+		case "max-backoff":
+			conf.MaxBackoff, _ = time.ParseDuration(value)
+		}
+	}
+	if conf.MaxBackoff < conf.MinBackoff {
+		return conf, fmt.Errorf("max-backoff can not be less than min-backoff")
+	}
+	conf.Blocking = containertypes.LogMode(cfg["mode"]) == containertypes.LogModeBlocking
+	return conf, nil
+}
+
+//Batcher caches log entries in a bounded buffer and sends them in batches.
+//A batch is sent when it is full or BatchWait passed, failed batches are retried
+//with exponential backoff and dropped after MaxRetries.
+type Batcher struct {
+	name    string
+	conf    BatchConfig
+	flush   FlushFunc
+	queue   chan *Entry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closed  chan struct{}
+	once    sync.Once
+	dropped uint64
+}
+
+//NewBatcher create and start a batcher
+func NewBatcher(name string, conf BatchConfig, flush FlushFunc) *Batcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Batcher{
+		name:   name,
+		conf:   conf,
+		flush:  flush,
+		queue:  make(chan *Entry, conf.BufferSize),
+		ctx:    ctx,
+		cancel: cancel,
+		closed: make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+//Add add a log message to the batcher. The message line is copied because
+//the message may be reused after Log returns.
+func (b *Batcher) Add(msg *Message) error {
+	entry := &Entry{
+		Line:      append(make([]byte, 0, len(msg.Line)), msg.Line...),
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+		Level:     msg.Attrs["level"],
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	select {
+	case <-b.ctx.Done():
+		return ErrBatcherClosed
+	default:
+	}
+	if b.conf.Blocking {
+		select {
+		case b.queue <- entry:
+			return nil
+		case <-b.ctx.Done():
+			return ErrBatcherClosed
+		}
+	}
+	select {
+	case b.queue <- entry:
+		return nil
+	default:
+		if n := atomic.AddUint64(&b.dropped, 1); n%1000 == 1 {
+			logrus.Warningf("%s log buffer is full, %d log entries dropped", b.name, n)
+		}
+		return ErrBufferFull
+	}
+}
+
+//Dropped the number of entries dropped because of the full buffer
+func (b *Batcher) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+func (b *Batcher) run() {
+	defer close(b.closed)
+	ticker := time.NewTicker(b.conf.BatchWait)
+	defer ticker.Stop()
+	batch := make([]*Entry, 0, b.conf.BatchSize)
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := b.send(batch)
+		batch = make([]*Entry, 0, b.conf.BatchSize)
+		return err
+	}
+	for {
+		select {
+		case entry := <-b.queue:
+			batch = append(batch, entry)
+			if len(batch) >= b.conf.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case <-b.ctx.Done():
+			// drain the entries that have been cached, give up when the server is unavailable
+			for {
+				select {
+				case entry := <-b.queue:
+					batch = append(batch, entry)
+					if len(batch) >= b.conf.BatchSize {
+						if err := send(); err != nil {
+							logrus.Errorf("%s drop %d cached log entries when closing", b.name, len(b.queue))
+							return
+						}
+					}
+				default:
+					send()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *Batcher) send(batch []*Entry) error {
+	backoff := b.conf.MinBackoff
+	for retry := 0; ; retry++ {
+		// the batcher may be closed, send the last batches with a limited time
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		err := b.flush(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*PermanentError); ok {
+			logrus.Errorf("%s send %d log entries failure %s, drop them", b.name, len(batch), err.Error())
+			return nil
+		}
+		if retry >= b.conf.MaxRetries || b.ctx.Err() != nil {
+			logrus.Errorf("%s send %d log entries failure %s, drop them after %d retries", b.name, len(batch), err.Error(), retry)
+			return err
+		}
+		logrus.Debugf("%s send log entries failure %s, will retry after %s", b.name, err.Error(), backoff)
+		select {
+		case <-time.After(backoff):
+		case <-b.ctx.Done():
+		}
+		backoff *= 2
+		if backoff > b.conf.MaxBackoff {
+			backoff = b.conf.MaxBackoff
+		}
+	}
+}
+
+//Close stop accepting entries and wait for the cached entries to be sent
+func (b *Batcher) Close() error {
+	b.once.Do(func() {
+		b.cancel()
+	})
+	<-b.closed
+	return nil
+}
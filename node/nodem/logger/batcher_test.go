@@ -0,0 +1,111 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcherRetry(t *testing.T) {
+	var lock sync.Mutex
+	var calls int
+	var sent []*Entry
+	conf := DefaultBatchConfig()
+	conf.BatchSize = 2
+	conf.BatchWait = time.Millisecond * 10
+	conf.MinBackoff = time.Millisecond
+	conf.MaxBackoff = time.Millisecond * 5
+	b := NewBatcher("test", conf, func(ctx context.Context, entries []*Entry) error {
+		lock.Lock()
+		defer lock.Unlock()
+		calls++
+		if calls == 1 {
+			return fmt.Errorf("server unavailable")
+		}
+		sent = append(sent, entries...)
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		msg := &Message{Line: []byte(fmt.Sprintf("line %d", i)), Source: "stdout"}
+		if err := b.Add(msg); err != nil {
+			t.Fatal(err)
+		}
+		// the batcher must copy the line, the message may be reused
+		msg.Line[0] = 'x'
+	}
+	for i := 0; i < 100; i++ {
+		lock.Lock()
+		n := len(sent)
+		lock.Unlock()
+		if n == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	b.Close()
+	if len(sent) != 3 {
+		t.Fatalf("expect 3 entries sent, got %d", len(sent))
+	}
+	if string(sent[0].Line) != "line 0" {
+		t.Fatalf("expect line 0, got %s", sent[0].Line)
+	}
+	if err := b.Add(&Message{Line: []byte("after close")}); err != ErrBatcherClosed {
+		t.Fatalf("expect ErrBatcherClosed, got %v", err)
+	}
+}
+
+func TestBatcherDropWhenFull(t *testing.T) {
+	conf := DefaultBatchConfig()
+	conf.BufferSize = 1
+	block := make(chan struct{})
+	b := NewBatcher("test", conf, func(ctx context.Context, entries []*Entry) error {
+		<-block
+		return nil
+	})
+	var dropped bool
+	for i := 0; i < 10; i++ {
+		if err := b.Add(&Message{Line: []byte("line")}); err == ErrBufferFull {
+			dropped = true
+		}
+	}
+	close(block)
+	b.Close()
+	if !dropped || b.Dropped() == 0 {
+		t.Fatal("expect entries dropped when the buffer is full")
+	}
+}
+
+func TestParseBatchConfig(t *testing.T) {
+	conf, err := ParseBatchConfig(map[string]string{"batch-size": "10", "batch-wait": "2s", "mode": "blocking"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.BatchSize != 10 || conf.BatchWait != 2*time.Second || !conf.Blocking {
+		t.Fatalf("unexpected batch config %+v", conf)
+	}
+	if _, err := ParseBatchConfig(map[string]string{"batch-size": "-1"}); err == nil {
+		t.Fatal("expect error for negative batch size")
+	}
+	if _, err := ParseBatchConfig(map[string]string{"min-backoff": "10s", "max-backoff": "1s"}); err == nil {
+		t.Fatal("expect error when max-backoff less than min-backoff")
+	}
+}
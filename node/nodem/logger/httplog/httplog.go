@@ -0,0 +1,262 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package httplog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const name = "httplog"
+
+const (
+	formatArray  = "json"
+	formatNDJSON = "ndjson"
+)
+
+func init() {
+	if err := logger.RegisterLogDriver(name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//HTTPLog push container logs to a http endpoint as json
+type HTTPLog struct {
+	url     string
+	method  string
+	format  string
+	gzip    bool
+	headers map[string]string
+	base    record
+	client  *http.Client
+	batcher *logger.Batcher
+}
+
+type record struct {
+	Time         string            `json:"time"`
+	Source       string            `json:"source,omitempty"`
+	Level        string            `json:"level,omitempty"`
+	Line         string            `json:"line"`
+	TenantID     string            `json:"tenant_id"`
+	ServiceID    string            `json:"service_id"`
+	ServiceAlias string            `json:"service_alias"`
+	ContainerID  string            `json:"container_id"`
+	Host         string            `json:"host,omitempty"`
+	Attrs        map[string]string `json:"attrs,omitempty"`
+}
+
+//New logger
+func New(ctx logger.Info) (logger.Logger, error) {
+	pushURL, err := parseURL(ctx.Config["http-url"])
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(ctx.Config["http-headers"])
+	if err != nil {
+		return nil, err
+	}
+	client, err := logger.NewHTTPClient(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := logger.ParseBatchConfig(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	tenantID, serviceID, serviceAlias := ctx.ServiceInfo()
+	hostname, _ := ctx.Hostname()
+	h := &HTTPLog{
+		url:     pushURL,
+		method:  strings.ToUpper(ctx.Config["http-method"]),
+		format:  ctx.Config["http-format"],
+		headers: headers,
+		base: record{
+			TenantID:     tenantID,
+			ServiceID:    serviceID,
+			ServiceAlias: serviceAlias,
+			ContainerID:  ctx.ContainerID,
+			Host:         hostname,
+			Attrs:        ctx.ExtraAttributes(nil),
+		},
+		client: client,
+	}
+	if h.method == "" {
+		h.method = "POST"
+	}
+	if h.format == "" {
+		h.format = formatArray
+	}
+	h.gzip, _ = strconv.ParseBool(ctx.Config["http-gzip"])
+	h.batcher = logger.NewBatcher(name, conf, h.flush)
+	logrus.Infof("create http logger for container %s, server %s", ctx.ContainerName, pushURL)
+	return h, nil
+}
+
+func parseURL(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("http-url is required for %s log driver", name)
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("http-url %s is invalid %s", address, err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("http-url scheme %s is not supported", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+//parseHeaders parse headers in format Key1:Value1,Key2:Value2
+func parseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, kv := range strings.Split(value, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		info := strings.SplitN(kv, ":", 2)
+		if len(info) != 2 || strings.TrimSpace(info[0]) == "" {
+			return nil, fmt.Errorf("http-headers %s is invalid, the format is Key1:Value1,Key2:Value2", value)
+		}
+		headers[strings.TrimSpace(info[0])] = strings.TrimSpace(info[1])
+	}
+	return headers, nil
+}
+
+//ValidateLogOpt
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch {
+		case key == "http-url":
+			if _, err := parseURL(value); err != nil {
+				return err
+			}
+		case key == "http-method":
+			if m := strings.ToUpper(value); m != "POST" && m != "PUT" {
+				return fmt.Errorf("http-method only support POST and PUT")
+			}
+		case key == "http-format":
+			if value != formatArray && value != formatNDJSON {
+				return fmt.Errorf("http-format only support %s and %s", formatArray, formatNDJSON)
+			}
+		case key == "http-gzip":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("http-gzip must be a bool value")
+			}
+		case key == "http-headers":
+			if _, err := parseHeaders(value); err != nil {
+				return err
+			}
+		case key == "labels", key == "env":
+		case logger.TLSLogOpts[key]:
+		case logger.BatchLogOpts[key]:
+			if err := logger.ValidateBatchOpt(key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, name)
+		}
+	}
+	if _, ok := cfg["http-url"]; !ok {
+		return fmt.Errorf("http-url is required for %s log driver", name)
+	}
+	return logger.ValidateTLSOpts(cfg)
+}
+
+//Log
+func (h *HTTPLog) Log(msg *logger.Message) error {
+	return h.batcher.Add(msg)
+}
+
+//encode encode the entries as a json array or newline delimited json
+func (h *HTTPLog) encode(entries []*logger.Entry) ([]byte, error) {
+	records := make([]record, 0, len(entries))
+	for _, e := range entries {
+		r := h.base
+		r.Time = e.Timestamp.UTC().Format(time.RFC3339Nano)
+		r.Source = e.Source
+		r.Level = e.Level
+		r.Line = strings.TrimRight(string(e.Line), "\n")
+		records = append(records, r)
+	}
+	if h.format == formatArray {
+		return json.Marshal(records)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *HTTPLog) flush(ctx context.Context, entries []*logger.Entry) error {
+	body, err := h.encode(entries)
+	if err != nil {
+		return &logger.PermanentError{Err: err}
+	}
+	if h.gzip {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+		body = buf.Bytes()
+	}
+	req, err := http.NewRequest(h.method, h.url, bytes.NewReader(body))
+	if err != nil {
+		return &logger.PermanentError{Err: err}
+	}
+	if h.format == formatArray {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if h.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+	return logger.DoPush(ctx, h.client, req)
+}
+
+//Close
+func (h *HTTPLog) Close() error {
+	return h.batcher.Close()
+}
+
+//Name - logger name
+func (h *HTTPLog) Name() string {
+	return name
+}
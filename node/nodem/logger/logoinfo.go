@@ -127,3 +127,32 @@ func (info *Info) ImageFullID() string {
 func (info *Info) ImageName() string {
 	return info.ContainerImageName
 }
+
+// ServiceInfo returns the tenant id, service id and service alias of the kato
+// component that the container belongs to.
+func (info *Info) ServiceInfo() (tenantID, serviceID, serviceAlias string) {
+	for _, e := range info.ContainerEnv {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "TENANT_ID":
+			tenantID = kv[1]
+		case "SERVICE_ID":
+			serviceID = kv[1]
+		case "SERVICE_NAME":
+			serviceAlias = kv[1]
+		}
+	}
+	if tenantID == "" {
+		tenantID = "default"
+	}
+	if serviceID == "" {
+		serviceID = "default"
+	}
+	if serviceAlias == "" {
+		serviceAlias = serviceID
+	}
+	return
+}
@@ -0,0 +1,253 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gridworkz/kato/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const name = "loki"
+
+const defaultPushPath = "/loki/api/v1/push"
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func init() {
+	if err := logger.RegisterLogDriver(name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//Loki push container logs to the loki compatible push api
+type Loki struct {
+	url      string
+	tenantID string
+	username string
+	password string
+	labels   map[string]string
+	client   *http.Client
+	batcher  *logger.Batcher
+}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+//New logger
+func New(ctx logger.Info) (logger.Logger, error) {
+	pushURL, err := parseURL(ctx.Config["loki-url"])
+	if err != nil {
+		return nil, err
+	}
+	client, err := logger.NewHTTPClient(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := logger.ParseBatchConfig(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	tenantID, serviceID, serviceAlias := ctx.ServiceInfo()
+	labels := map[string]string{
+		"job":           "kato",
+		"tenant_id":     tenantID,
+		"service_id":    serviceID,
+		"service_alias": serviceAlias,
+	}
+	if hostname, err := ctx.Hostname(); err == nil {
+		labels["host"] = hostname
+	}
+	for k, v := range ctx.ExtraAttributes(labelName) {
+		labels[k] = v
+	}
+	external, _ := parseLabels(ctx.Config["loki-external-labels"])
+	for k, v := range external {
+		labels[k] = v
+	}
+	l := &Loki{
+		url:      pushURL,
+		tenantID: ctx.Config["loki-tenant-id"],
+		username: ctx.Config["loki-username"],
+		password: ctx.Config["loki-password"],
+		labels:   labels,
+		client:   client,
+	}
+	l.batcher = logger.NewBatcher(name, conf, l.flush)
+	logrus.Infof("create loki logger for container %s, server %s", ctx.ContainerName, pushURL)
+	return l, nil
+}
+
+func parseURL(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("loki-url is required for %s log driver", name)
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("loki-url %s is invalid %s", address, err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("loki-url scheme %s is not supported", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultPushPath
+	}
+	return u.String(), nil
+}
+
+//parseLabels parse labels in format k1=v1,k2=v2
+func parseLabels(value string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, kv := range strings.Split(value, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		info := strings.SplitN(kv, "=", 2)
+		if len(info) != 2 || !labelNameRegexp.MatchString(info[0]) {
+			return nil, fmt.Errorf("loki-external-labels %s is invalid, the format is k1=v1,k2=v2", value)
+		}
+		labels[info[0]] = info[1]
+	}
+	return labels, nil
+}
+
+//labelName replace the chars that loki label name does not support
+func labelName(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key)
+	if key != "" && key[0] >= '0' && key[0] <= '9' {
+		key = "_" + key
+	}
+	return key
+}
+
+//ValidateLogOpt
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch {
+		case key == "loki-url":
+			if _, err := parseURL(value); err != nil {
+				return err
+			}
+		case key == "loki-external-labels":
+			if _, err := parseLabels(value); err != nil {
+				return err
+			}
+		case key == "loki-tenant-id", key == "loki-username", key == "loki-password":
+		case key == "labels", key == "env":
+		case logger.TLSLogOpts[key]:
+		case logger.BatchLogOpts[key]:
+			if err := logger.ValidateBatchOpt(key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, name)
+		}
+	}
+	if _, ok := cfg["loki-url"]; !ok {
+		return fmt.Errorf("loki-url is required for %s log driver", name)
+	}
+	return logger.ValidateTLSOpts(cfg)
+}
+
+//Log
+func (l *Loki) Log(msg *logger.Message) error {
+	return l.batcher.Add(msg)
+}
+
+//encode group the entries by source and level, the entries of a stream keep the order of time
+func (l *Loki) encode(entries []*logger.Entry) ([]byte, error) {
+	var req pushRequest
+	index := make(map[string]int)
+	for _, e := range entries {
+		key := e.Source + "/" + e.Level
+		i, ok := index[key]
+		if !ok {
+			labels := make(map[string]string, len(l.labels)+1)
+			for k, v := range l.labels {
+				labels[k] = v
+			}
+			if e.Source != "" {
+				labels["stream"] = e.Source
+			}
+			if e.Level != "" {
+				labels["level"] = e.Level
+			}
+			req.Streams = append(req.Streams, stream{Stream: labels})
+			i = len(req.Streams) - 1
+			index[key] = i
+		}
+		req.Streams[i].Values = append(req.Streams[i].Values, [2]string{
+			strconv.FormatInt(e.Timestamp.UnixNano(), 10),
+			strings.TrimRight(string(e.Line), "\n"),
+		})
+	}
+	return json.Marshal(req)
+}
+
+func (l *Loki) flush(ctx context.Context, entries []*logger.Entry) error {
+	body, err := l.encode(entries)
+	if err != nil {
+		return &logger.PermanentError{Err: err}
+	}
+	req, err := http.NewRequest("POST", l.url, bytes.NewReader(body))
+	if err != nil {
+		return &logger.PermanentError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.tenantID)
+	}
+	if l.username != "" {
+		req.SetBasicAuth(l.username, l.password)
+	}
+	return logger.DoPush(ctx, l.client, req)
+}
+
+//Close
+func (l *Loki) Close() error {
+	return l.batcher.Close()
+}
+
+//Name - logger name
+func (l *Loki) Name() string {
+	return name
+}
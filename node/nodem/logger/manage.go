@@ -323,6 +323,13 @@ func (container *ContainerLog) StartLogging() error {
 		}
 		return fmt.Errorf("failed to initialize logging driver: %v", err)
 	}
+	pipelineConf, err := getPipelineConfig(container.Config.Env, container.Config.Labels)
+	if err != nil {
+		logrus.Warnf("container %s log pipeline config is invalid %s, logs are sent as is", container.Name, err.Error())
+	}
+	if pipelineConf != nil {
+		loggers = []Logger{NewPipeline(pipelineConf, loggers)}
+	}
 	copier := NewCopier(container.reader, loggers, container.since)
 	container.LogCopier = copier
 	copier.Run()
@@ -336,6 +343,9 @@ type ContainerLoggerConfig struct {
 	Options map[string]string
 }
 
+//getLoggerConfig get the log drivers of the container from env. Every env with prefix
+//LOGGER_DRIVER_NAME enables a driver, for example LOGGER_DRIVER_NAME_LOKI=loki, and
+//the options of the driver are set by LOGGER_DRIVER_OPT_<driver name> in json format.
 func getLoggerConfig(envs []string) []*ContainerLoggerConfig {
 	var configs = make(map[string]*ContainerLoggerConfig)
 	var envMap = make(map[string]string, len(envs))
@@ -356,7 +366,9 @@ func getLoggerConfig(envs []string) []*ContainerLoggerConfig {
 	for i, c := range configs {
 		if config, ok := envMap[strings.ToLower("LOGGER_DRIVER_OPT_"+c.Name)]; ok {
 			var options = make(map[string]string)
-			json.Unmarshal([]byte(config), &options)
+			if err := json.Unmarshal([]byte(config), &options); err != nil {
+				logrus.Warnf("log driver %s options is not valid json %s", c.Name, err.Error())
+			}
 			configs[i].Options = options
 		}
 		re = append(re, configs[i])
@@ -378,6 +390,10 @@ func (container *ContainerLog) startLogger() ([]Logger, error) {
 			logrus.Warnf("get container log driver failure %s", err.Error())
 			continue
 		}
+		if err := ValidateLogOpts(config.Name, config.Options); err != nil {
+			logrus.Warnf("container %s log driver %s options is invalid %s", container.Name, config.Name, err.Error())
+			continue
+		}
 		createTime, _ := time.Parse(RFC3339NanoFixed, container.Created)
 		info := Info{
 			Config:              config.Options,
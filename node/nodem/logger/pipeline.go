@@ -0,0 +1,314 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//pipeline config keys, set by the env of the component or the label of the image
+const (
+	multilinePatternKey  = "LOGGER_MULTILINE_PATTERN"
+	multilineMaxLinesKey = "LOGGER_MULTILINE_MAX_LINES"
+	multilineTimeoutKey  = "LOGGER_MULTILINE_TIMEOUT"
+	jsonParseKey         = "LOGGER_JSON_PARSE"
+	jsonLevelKey         = "LOGGER_JSON_LEVEL_KEY"
+	jsonMessageKey       = "LOGGER_JSON_MESSAGE_KEY"
+)
+
+//the label of the key, for example LOGGER_MULTILINE_PATTERN -> kato.logger.multiline.pattern
+func pipelineLabel(key string) string {
+	return "kato." + strings.Replace(strings.ToLower(key), "_", ".", -1)
+}
+
+var (
+	defaultLevelKeys   = []string{"level", "lvl", "severity", "loglevel"}
+	defaultMessageKeys = []string{"message", "msg", "log"}
+)
+
+//PipelineConfig multi-line merge and json parse rules of the container log
+type PipelineConfig struct {
+	//the line matching the pattern starts a new log record,
+	//the following lines are merged into it until the next matching line
+	MultilinePattern *regexp.Regexp
+	//max lines of a merged log record
+	MultilineMaxLines int
+	//a pending log record is sent if no line is appended in the timeout
+	MultilineTimeout time.Duration
+	JSONParse        bool
+	JSONLevelKey     string
+	JSONMessageKey   string
+}
+
+//getPipelineConfig get the pipeline config from the container env and labels,
+//env takes precedence over labels. returns nil if no rule is configured.
+func getPipelineConfig(envs []string, labels map[string]string) (*PipelineConfig, error) {
+	var values = make(map[string]string)
+	for _, key := range []string{multilinePatternKey, multilineMaxLinesKey, multilineTimeoutKey, jsonParseKey, jsonLevelKey, jsonMessageKey} {
+		if v, ok := labels[pipelineLabel(key)]; ok {
+			values[key] = v
+		}
+	}
+	for _, v := range envs {
+		info := strings.SplitN(v, "=", 2)
+		if len(info) == 2 && strings.HasPrefix(info[0], "LOGGER_") {
+			values[info[0]] = info[1]
+		}
+	}
+	conf := &PipelineConfig{
+		MultilineMaxLines: 500,
+		MultilineTimeout:  time.Second,
+		JSONLevelKey:      values[jsonLevelKey],
+		JSONMessageKey:    values[jsonMessageKey],
+	}
+	if pattern := values[multilinePatternKey]; pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s is invalid %s", multilinePatternKey, pattern, err.Error())
+		}
+		conf.MultilinePattern = re
+	}
+	if v := values[multilineMaxLinesKey]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%s must be a positive number", multilineMaxLinesKey)
+		}
+		conf.MultilineMaxLines = n
+	}
+	if v := values[multilineTimeoutKey]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s must be a positive duration, for example 1s", multilineTimeoutKey)
+		}
+		conf.MultilineTimeout = d
+	}
+	if v := values[jsonParseKey]; v != "" {
+		parse, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a bool value", jsonParseKey)
+		}
+		conf.JSONParse = parse
+	}
+	if conf.MultilinePattern == nil && !conf.JSONParse {
+		return nil, nil
+	}
+	return conf, nil
+}
+
+type pendingRecord struct {
+	msg   *Message
+	lines int
+	last  time.Time
+}
+
+//Pipeline merges multi-line logs and parses json logs before they are sent to the log drivers
+type Pipeline struct {
+	conf    *PipelineConfig
+	dst     []Logger
+	lock    sync.Mutex
+	pending map[string]*pendingRecord
+	closed  chan struct{}
+	once    sync.Once
+}
+
+//NewPipeline create a pipeline logger that sends the processed messages to dst
+func NewPipeline(conf *PipelineConfig, dst []Logger) *Pipeline {
+	p := &Pipeline{
+		conf:    conf,
+		dst:     dst,
+		pending: make(map[string]*pendingRecord),
+		closed:  make(chan struct{}),
+	}
+	if conf.MultilinePattern != nil {
+		go p.flushTimeout()
+	}
+	return p
+}
+
+//Log
+func (p *Pipeline) Log(msg *Message) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.conf.JSONParse {
+		if parsed, ok := p.parseJSON(msg); ok {
+			// a json log is a complete record
+			p.flush(msg.Source)
+			return p.emit(parsed)
+		}
+	}
+	if p.conf.MultilinePattern == nil {
+		return p.emit(msg)
+	}
+	select {
+	case <-p.closed:
+		return p.emit(msg)
+	default:
+	}
+	record, ok := p.pending[msg.Source]
+	if !ok || p.conf.MultilinePattern.Match(msg.Line) {
+		p.flush(msg.Source)
+		p.pending[msg.Source] = &pendingRecord{msg: copyMessage(msg), lines: 1, last: time.Now()}
+		return nil
+	}
+	if !bytes.HasSuffix(record.msg.Line, []byte("\n")) {
+		record.msg.Line = append(record.msg.Line, '\n')
+	}
+	record.msg.Line = append(record.msg.Line, msg.Line...)
+	record.lines++
+	record.last = time.Now()
+	if record.lines >= p.conf.MultilineMaxLines {
+		p.flush(msg.Source)
+	}
+	return nil
+}
+
+//parseJSON extract the level and message of the json log, the other fields are dropped
+func (p *Pipeline) parseJSON(msg *Message) (*Message, bool) {
+	line := bytes.TrimSpace(msg.Line)
+	if len(line) < 2 || line[0] != '{' {
+		return nil, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, false
+	}
+	message, ok := lookupField(fields, p.conf.JSONMessageKey, defaultMessageKeys)
+	if !ok {
+		return nil, false
+	}
+	parsed := &Message{
+		Line:      []byte(strings.TrimRight(message, "\n") + "\n"),
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+		Partial:   msg.Partial,
+		Attrs:     make(LogAttributes, len(msg.Attrs)+1),
+	}
+	for k, v := range msg.Attrs {
+		parsed.Attrs[k] = v
+	}
+	if level, ok := lookupField(fields, p.conf.JSONLevelKey, defaultLevelKeys); ok {
+		parsed.Attrs["level"] = strings.ToLower(level)
+	}
+	return parsed, true
+}
+
+func lookupField(fields map[string]interface{}, key string, defaultKeys []string) (string, bool) {
+	keys := defaultKeys
+	if key != "" {
+		keys = []string{key}
+	}
+	for _, k := range keys {
+		if v, ok := fields[k]; ok {
+			switch value := v.(type) {
+			case string:
+				return value, true
+			case nil:
+				return "", true
+			default:
+				b, _ := json.Marshal(value)
+				return string(b), true
+			}
+		}
+	}
+	return "", false
+}
+
+func copyMessage(msg *Message) *Message {
+	return &Message{
+		Line:      append(make([]byte, 0, len(msg.Line)), msg.Line...),
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+		Attrs:     msg.Attrs,
+		Partial:   msg.Partial,
+	}
+}
+
+//flush send the pending record of the source, must be called with the lock held
+func (p *Pipeline) flush(source string) {
+	record, ok := p.pending[source]
+	if !ok {
+		return
+	}
+	delete(p.pending, source)
+	p.emit(record.msg)
+}
+
+func (p *Pipeline) emit(msg *Message) error {
+	var err error
+	for _, d := range p.dst {
+		if e := d.Log(msg); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (p *Pipeline) flushTimeout() {
+	interval := p.conf.MultilineTimeout / 2
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-ticker.C:
+			p.lock.Lock()
+			for source, record := range p.pending {
+				if time.Since(record.last) >= p.conf.MultilineTimeout {
+					p.flush(source)
+				}
+			}
+			p.lock.Unlock()
+		}
+	}
+}
+
+//Close send the pending records and close the log drivers
+func (p *Pipeline) Close() error {
+	p.once.Do(func() {
+		close(p.closed)
+	})
+	p.lock.Lock()
+	for source := range p.pending {
+		p.flush(source)
+	}
+	p.lock.Unlock()
+	for _, d := range p.dst {
+		if err := d.Close(); err != nil {
+			logrus.Errorf("close log driver %s failure %s", d.Name(), err.Error())
+		}
+	}
+	return nil
+}
+
+//Name - logger name
+func (p *Pipeline) Name() string {
+	return "pipeline"
+}
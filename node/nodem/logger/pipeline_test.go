@@ -0,0 +1,116 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type collectLogger struct {
+	lock     sync.Mutex
+	messages []*Message
+}
+
+func (c *collectLogger) Log(msg *Message) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func (c *collectLogger) lines() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var lines []string
+	for _, m := range c.messages {
+		lines = append(lines, string(m.Line))
+	}
+	return lines
+}
+
+func (c *collectLogger) Name() string { return "collect" }
+
+func (c *collectLogger) Close() error { return nil }
+
+func TestGetPipelineConfig(t *testing.T) {
+	conf, err := getPipelineConfig(nil, nil)
+	if err != nil || conf != nil {
+		t.Fatalf("expect no pipeline config, got %v %v", conf, err)
+	}
+	conf, err = getPipelineConfig([]string{"LOGGER_MULTILINE_PATTERN=^\\d{4}-", "LOGGER_MULTILINE_MAX_LINES=10"},
+		map[string]string{"kato.logger.multiline.max.lines": "20", "kato.logger.json.parse": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.MultilinePattern == nil || conf.MultilineMaxLines != 10 || !conf.JSONParse {
+		t.Fatalf("unexpected pipeline config %+v", conf)
+	}
+	if _, err := getPipelineConfig([]string{"LOGGER_MULTILINE_PATTERN=(["}, nil); err == nil {
+		t.Fatal("expect error for invalid pattern")
+	}
+}
+
+func TestPipelineMultiline(t *testing.T) {
+	conf, _ := getPipelineConfig([]string{"LOGGER_MULTILINE_PATTERN=^\\d{4}-", "LOGGER_MULTILINE_MAX_LINES=3", "LOGGER_MULTILINE_TIMEOUT=50ms"}, nil)
+	dst := &collectLogger{}
+	p := NewPipeline(conf, []Logger{dst})
+	for _, line := range []string{
+		"2021-01-01 ERROR request failed\n",
+		"java.lang.NullPointerException\n",
+		"\tat com.example.Main.main(Main.java:10)\n",
+		"\tat com.example.Main.run(Main.java:20)\n",
+		"2021-01-01 INFO next request\n",
+	} {
+		p.Log(&Message{Line: []byte(line), Source: "stdout"})
+	}
+	lines := dst.lines()
+	if len(lines) != 2 {
+		t.Fatalf("expect 2 records, got %d %v", len(lines), lines)
+	}
+	if lines[0] != "2021-01-01 ERROR request failed\njava.lang.NullPointerException\n\tat com.example.Main.main(Main.java:10)\n" {
+		t.Fatalf("unexpected merged record %q", lines[0])
+	}
+	if lines[1] != "\tat com.example.Main.run(Main.java:20)\n" {
+		t.Fatalf("unexpected record %q", lines[1])
+	}
+	time.Sleep(time.Millisecond * 200)
+	if lines = dst.lines(); len(lines) != 3 || lines[2] != "2021-01-01 INFO next request\n" {
+		t.Fatalf("expect pending record flushed after timeout, got %v", lines)
+	}
+	p.Close()
+}
+
+func TestPipelineJSON(t *testing.T) {
+	conf, _ := getPipelineConfig([]string{"LOGGER_JSON_PARSE=true"}, nil)
+	dst := &collectLogger{}
+	p := NewPipeline(conf, []Logger{dst})
+	p.Log(&Message{Line: []byte(`{"level":"WARN","msg":"disk is almost full","ts":1}` + "\n"), Source: "stdout"})
+	p.Log(&Message{Line: []byte("plain text\n"), Source: "stdout"})
+	p.Close()
+	if len(dst.messages) != 2 {
+		t.Fatalf("expect 2 messages, got %d", len(dst.messages))
+	}
+	if string(dst.messages[0].Line) != "disk is almost full\n" || dst.messages[0].Attrs["level"] != "warn" {
+		t.Fatalf("unexpected parsed message %q %v", dst.messages[0].Line, dst.messages[0].Attrs)
+	}
+	if string(dst.messages[1].Line) != "plain text\n" {
+		t.Fatalf("unexpected message %q", dst.messages[1].Line)
+	}
+}
@@ -207,7 +207,7 @@ func ValidateLogOpt(cfg map[string]string) error {
 	for key, value := range cfg {
 		switch key {
 		case "stream-server":
-		case "cache-error-log-size":
+		case "cache-error-log-size", "cache-log-size":
 			if _, err := strconv.Atoi(value); err != nil {
 				return errors.New("cache error log size must be a number")
 			}
@@ -288,6 +288,9 @@ func (s *StreamLog) Log(msg *logger.Message) error {
 	buf := bytes.NewBuffer(nil)
 	buf.WriteString(s.containerID[0:12] + ",")
 	buf.WriteString(s.serviceID)
+	if level := msg.Attrs["level"]; level != "" {
+		buf.WriteString("[" + strings.ToUpper(level) + "] ")
+	}
 	buf.Write(msg.Line)
 	s.cache(buf.String())
 	return nil
@@ -0,0 +1,297 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/node/nodem/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const name = "syslog"
+
+//the enterprise number reserved for documentation, used as the SD-ID of kato structured data
+const sdID = "kato@32473"
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+const (
+	severityError = 3
+	severityInfo  = 6
+)
+
+//severities the syslog severity of the level parsed from json logs
+var severities = map[string]int{
+	"emerg": 0, "panic": 0, "alert": 1, "crit": 2, "critical": 2, "fatal": 2,
+	"err": 3, "error": 3, "warn": 4, "warning": 4, "notice": 5,
+	"info": 6, "debug": 7, "trace": 7,
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+//Syslog send container logs to the syslog server in RFC5424 format,
+//messages are framed with octet counting (RFC6587) over tcp or tls.
+type Syslog struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	facility  int
+	hostname  string
+	appName   string
+	procID    string
+	sd        string
+	conn      net.Conn
+	lock      sync.Mutex
+	batcher   *logger.Batcher
+}
+
+//New logger
+func New(ctx logger.Info) (logger.Logger, error) {
+	network, address, err := parseAddress(ctx.Config["syslog-address"])
+	if err != nil {
+		return nil, err
+	}
+	var tlsConfig *tls.Config
+	if network == "tcp+tls" {
+		tlsConfig, err = logger.NewTLSConfig(ctx.Config)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+	}
+	facility := facilities["daemon"]
+	if f, ok := ctx.Config["syslog-facility"]; ok {
+		facility = facilities[f]
+	}
+	hostname, err := ctx.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	tenantID, serviceID, serviceAlias := ctx.ServiceInfo()
+	appName := ctx.Config["tag"]
+	if appName == "" {
+		appName = serviceAlias
+	}
+	conf, err := logger.ParseBatchConfig(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	procID := ctx.ContainerID
+	if len(procID) > 12 {
+		procID = procID[:12]
+	}
+	s := &Syslog{
+		network:   network,
+		address:   address,
+		tlsConfig: tlsConfig,
+		facility:  facility,
+		hostname:  truncate(hostname, 255),
+		appName:   truncate(appName, 48),
+		procID:    procID,
+		sd:        structuredData(tenantID, serviceID, ctx.ExtraAttributes(nil)),
+	}
+	s.batcher = logger.NewBatcher(name, conf, s.flush)
+	logrus.Infof("create syslog logger for container %s, server %s://%s", ctx.ContainerName, network, address)
+	return s, nil
+}
+
+func parseAddress(address string) (string, string, error) {
+	if address == "" {
+		return "", "", fmt.Errorf("syslog-address is required for %s log driver", name)
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", "", fmt.Errorf("syslog-address %s is invalid %s", address, err.Error())
+	}
+	switch u.Scheme {
+	case "tcp", "tcp+tls":
+	default:
+		return "", "", fmt.Errorf("syslog-address scheme %s is not supported, only support tcp and tcp+tls", u.Scheme)
+	}
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "514")
+		if u.Scheme == "tcp+tls" {
+			host = net.JoinHostPort(u.Host, "6514")
+		}
+	}
+	return u.Scheme, host, nil
+}
+
+//ValidateLogOpt
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch {
+		case key == "syslog-address":
+			if _, _, err := parseAddress(value); err != nil {
+				return err
+			}
+		case key == "syslog-facility":
+			if _, ok := facilities[value]; !ok {
+				return fmt.Errorf("syslog-facility %s is invalid", value)
+			}
+		case key == "tag", key == "labels", key == "env":
+		case logger.TLSLogOpts[key]:
+		case logger.BatchLogOpts[key]:
+			if err := logger.ValidateBatchOpt(key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, name)
+		}
+	}
+	if _, ok := cfg["syslog-address"]; !ok {
+		return fmt.Errorf("syslog-address is required for %s log driver", name)
+	}
+	return logger.ValidateTLSOpts(cfg)
+}
+
+//Log
+func (s *Syslog) Log(msg *logger.Message) error {
+	return s.batcher.Add(msg)
+}
+
+func (s *Syslog) flush(ctx context.Context, entries []*logger.Entry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line := s.format(e)
+		buf.WriteString(strconv.Itoa(len(line)))
+		buf.WriteByte(' ')
+		buf.Write(line)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	deadline, _ := ctx.Deadline()
+	s.conn.SetWriteDeadline(deadline)
+	if _, err := s.conn.Write(buf.Bytes()); err != nil {
+		// the connection is broken, reconnect on next flush
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *Syslog) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: time.Second * 5, KeepAlive: time.Second * 30}
+	if s.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", s.address, s.tlsConfig)
+	}
+	return dialer.DialContext(ctx, "tcp", s.address)
+}
+
+//format format the entry in RFC5424
+func (s *Syslog) format(e *logger.Entry) []byte {
+	severity := severityInfo
+	if e.Source == "stderr" {
+		severity = severityError
+	}
+	if s, ok := severities[e.Level]; ok {
+		severity = s
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s - %s ", s.facility*8+severity,
+		e.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), s.hostname, s.appName, s.procID, s.sd)
+	buf.Write(bytes.TrimRight(e.Line, "\n"))
+	return buf.Bytes()
+}
+
+func structuredData(tenantID, serviceID string, extra map[string]string) string {
+	var buf bytes.Buffer
+	buf.WriteString("[" + sdID)
+	fmt.Fprintf(&buf, ` tenant_id="%s" service_id="%s"`, escapeParam(tenantID), escapeParam(serviceID))
+	for k, v := range extra {
+		fmt.Fprintf(&buf, ` %s="%s"`, sdName(k), escapeParam(v))
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+//sdName PARAM-NAME can not contain '=', ' ', ']', '"' and is at most 32 chars
+func sdName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= 32 || r >= 127 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	return truncate(name, 32)
+}
+
+//escapeParam escape '"', '\' and ']' in PARAM-VALUE
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+//Close
+func (s *Syslog) Close() error {
+	s.batcher.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return nil
+}
+
+//Name - logger name
+func (s *Syslog) Name() string {
+	return name
+}
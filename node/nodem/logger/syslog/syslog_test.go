@@ -0,0 +1,98 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gridworkz/kato/node/nodem/logger"
+)
+
+func TestValidateLogOpt(t *testing.T) {
+	if err := ValidateLogOpt(map[string]string{"syslog-address": "tcp+tls://127.0.0.1", "syslog-facility": "local0", "batch-size": "10"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []map[string]string{
+		{},
+		{"syslog-address": "udp://127.0.0.1:514"},
+		{"syslog-address": "tcp://127.0.0.1:514", "syslog-facility": "unknown"},
+		{"syslog-address": "tcp://127.0.0.1:514", "tls-cert": "/tmp/cert.pem"},
+		{"syslog-address": "tcp://127.0.0.1:514", "unknown": "value"},
+	} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("expect error for %v", cfg)
+		}
+	}
+}
+
+func TestSyslog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return
+			}
+			received <- string(buf)
+		}
+	}()
+	l, err := New(logger.Info{
+		Config:        map[string]string{"syslog-address": "tcp://" + listener.Addr().String(), "batch-wait": "10ms"},
+		ContainerID:   "9874f23cbfc8201571bc654955aad941",
+		ContainerName: "test",
+		ContainerEnv:  []string{"TENANT_ID=tenant", "SERVICE_ID=service", "SERVICE_NAME=gr123456"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Log(&logger.Message{Line: []byte("hello kato\n"), Source: "stderr", Timestamp: time.Now()})
+	select {
+	case msg := <-received:
+		if !strings.HasPrefix(msg, "<27>1 ") {
+			t.Fatalf("unexpected priority in %s", msg)
+		}
+		if !strings.Contains(msg, ` gr123456 9874f23cbfc8 - [kato@32473 tenant_id="tenant" service_id="service"] hello kato`) {
+			t.Fatalf("unexpected message %s", msg)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("syslog message is not received")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//TLSLogOpts log opts used to config the tls connection to the log server
+var TLSLogOpts = map[string]bool{
+	"tls-ca-cert":     true,
+	"tls-cert":        true,
+	"tls-key":         true,
+	"tls-skip-verify": true,
+}
+
+//ValidateTLSOpts validate the tls log opts
+func ValidateTLSOpts(cfg map[string]string) error {
+	if _, ok := cfg["tls-skip-verify"]; ok {
+		if _, err := strconv.ParseBool(cfg["tls-skip-verify"]); err != nil {
+			return fmt.Errorf("tls-skip-verify must be a bool value")
+		}
+	}
+	if (cfg["tls-cert"] == "") != (cfg["tls-key"] == "") {
+		return fmt.Errorf("tls-cert and tls-key must be set at the same time")
+	}
+	return nil
+}
+
+//NewTLSConfig create the tls config from log opts
+func NewTLSConfig(cfg map[string]string) (*tls.Config, error) {
+	if err := ValidateTLSOpts(cfg); err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{}
+	tlsConfig.InsecureSkipVerify, _ = strconv.ParseBool(cfg["tls-skip-verify"])
+	if ca := cfg["tls-ca-cert"]; ca != "" {
+		pem, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca cert failure %s", err.Error())
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca cert %s is invalid", ca)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg["tls-cert"] != "" {
+		cert, err := tls.LoadX509KeyPair(cfg["tls-cert"], cfg["tls-key"])
+		if err != nil {
+			return nil, fmt.Errorf("load tls cert failure %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+//NewHTTPClient create the http client used to push logs, tls is configured by the tls log opts
+func NewHTTPClient(cfg map[string]string) (*http.Client, error) {
+	tlsConfig, err := NewTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   5 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSClientConfig:     tlsConfig,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}, nil
+}
+
+//DoPush send the push request. 429 and 5xx responses are returned as errors to retry,
+//other non 2xx responses are returned as PermanentError because retrying does not help.
+func DoPush(ctx context.Context, client *http.Client, req *http.Request) error {
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("push logs to %s failure, status %d: %s", req.URL.Host, res.StatusCode, string(body))
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode/100 == 5 {
+		return err
+	}
+	return &PermanentError{Err: err}
+}
@@ -9,6 +9,8 @@ const (
 	GrdataLogPath = "/grdata/logs"
 	// ImagePullSecretKey the key of environment IMAGE_PULL_SECRET
 	ImagePullSecretKey = "IMAGE_PULL_SECRET"
+	// IdleAnnotation marks the workload and ingresses of a component scaled to 0 by its idle policy
+	IdleAnnotation = "kato.io/idle"
 )
 
 // Kubernetes recommended Labels
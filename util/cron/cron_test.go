@@ -0,0 +1,86 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 8 * * 1-5", "*/15 0-6,20-23 1,15 jan-jun mon-fri", "@daily", "0 0 * * 7", "5/10 * * * *"} {
+		if _, err := Parse(spec); err != nil {
+			t.Errorf("parse %q: %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "*-5 * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expect error of %q", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		spec string
+		from time.Time
+		next time.Time
+	}{
+		// friday evening to monday morning
+		{"0 8 * * 1-5", time.Date(2021, 3, 5, 20, 0, 0, 0, shanghai), time.Date(2021, 3, 8, 8, 0, 0, 0, shanghai)},
+		{"0 20 * * 1-5", time.Date(2021, 3, 5, 8, 0, 0, 0, shanghai), time.Date(2021, 3, 5, 20, 0, 0, 0, shanghai)},
+		{"*/15 * * * *", time.Date(2021, 3, 5, 8, 7, 30, 0, time.UTC), time.Date(2021, 3, 5, 8, 15, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{"0 0 13 * fri", time.Date(2021, 3, 6, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+	for _, tc := range tests {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next := s.Next(tc.from); !next.Equal(tc.next) {
+			t.Errorf("%q from %s: expect %s, got %s", tc.spec, tc.from, tc.next, next)
+		}
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, _ := Parse("30 2 * * *")
+	// 2:30 does not exist on 2021-03-14
+	next := s.Next(time.Date(2021, 3, 13, 12, 0, 0, 0, newYork))
+	if !next.Equal(time.Date(2021, 3, 15, 2, 30, 0, 0, newYork)) {
+		t.Errorf("unexpected next time %s", next)
+	}
+	s, _ = Parse("0 * * * *")
+	from := time.Date(2021, 11, 7, 1, 30, 0, 0, newYork)
+	next = s.Next(from)
+	if !next.After(from) || next.Sub(from) > time.Hour {
+		t.Errorf("unexpected next time %s", next)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package etcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"golang.org/x/net/context"
+)
+
+//MaxPriority the max priority of PriorityQueue
+const MaxPriority = 9
+
+// PriorityQueue implements a multi-reader, multi-writer distributed queue.
+// The keys are <prefix>/<MaxPriority-priority>/<due unix nano>, so messages with a
+// higher priority are dequeued first and messages of the same priority are dequeued
+// in the order they become due. The keys written by Queue are dequeued as due messages.
+type PriorityQueue struct {
+	client *v3.Client
+	ctx    context.Context
+
+	keyPrefix string
+}
+
+// NewPriorityQueue
+func NewPriorityQueue(ctx context.Context, client *v3.Client, keyPrefix string) *PriorityQueue {
+	return &PriorityQueue{client, ctx, keyPrefix}
+}
+
+// Enqueue puts a value into the queue, it is not dequeued before notBefore
+func (q *PriorityQueue) Enqueue(val string, priority int, notBefore time.Time) error {
+	if priority < 0 {
+		priority = 0
+	}
+	if priority > MaxPriority {
+		priority = MaxPriority
+	}
+	due := time.Now()
+	if notBefore.After(due) {
+		due = notBefore
+	}
+	for nano := due.UnixNano(); ; nano++ {
+		key := fmt.Sprintf("%s/%d/%019d", q.keyPrefix, MaxPriority-priority, nano)
+		_, err := putNewKV(q.ctx, q.client, key, val, 0)
+		if err == nil {
+			return nil
+		}
+		if err != ErrKeyExists {
+			return err
+		}
+	}
+}
+
+// Dequeue returns the first due value of the highest priority, it blocks until a value is due.
+func (q *PriorityQueue) Dequeue() (string, error) {
+	for {
+		// only the keys are listed, the value is read when the key is claimed
+		resp, err := q.client.Get(q.ctx, q.keyPrefix+"/", v3.WithPrefix(), v3.WithKeysOnly(),
+			v3.WithSort(v3.SortByKey, v3.SortAscend))
+		if err != nil {
+			return "", err
+		}
+		now := time.Now()
+		wait := time.Second * 30
+		for _, kv := range resp.Kvs {
+			if due := q.dueTime(string(kv.Key)); due.After(now) {
+				if due.Sub(now) < wait {
+					wait = due.Sub(now)
+				}
+				continue
+			}
+			val, ok, err := q.claimKey(string(kv.Key), kv.ModRevision)
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return val, nil
+			}
+		}
+		// nothing is due yet; wait for new values or the next due value
+		ctx, cancel := context.WithTimeout(q.ctx, wait)
+		wc := q.client.Watch(ctx, q.keyPrefix+"/", v3.WithPrefix(), v3.WithRev(resp.Header.Revision+1), v3.WithFilterDelete())
+		select {
+		case <-wc:
+		case <-ctx.Done():
+		}
+		cancel()
+		if q.ctx.Err() != nil {
+			return "", q.ctx.Err()
+		}
+	}
+}
+
+// dueTime parse the due time from the key, the keys without due time are due now
+func (q *PriorityQueue) dueTime(key string) time.Time {
+	fields := strings.Split(strings.TrimPrefix(key, q.keyPrefix+"/"), "/")
+	if len(fields) != 2 {
+		return time.Time{}
+	}
+	nano, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
+// claimKey reads and deletes the key by revision, returning false if key is missing
+func (q *PriorityQueue) claimKey(key string, rev int64) (string, bool, error) {
+	cmp := v3.Compare(v3.ModRevision(key), "=", rev)
+	txnresp, err := q.client.Txn(q.ctx).If(cmp).Then(v3.OpGet(key), v3.OpDelete(key)).Commit()
+	if err != nil {
+		return "", false, err
+	}
+	if !txnresp.Succeeded {
+		return "", false, nil
+	}
+	kvs := txnresp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 {
+		return "", false, nil
+	}
+	return string(kvs[0].Value), true, nil
+}
@@ -40,6 +40,9 @@ var translationMetadata = map[string]string{
 	"(restart)Application model init create failure": "(restart)Application model init create failure",
 	"horizontal scaling service error":               "horizontal scaling service error",
 	"horizontal scaling service timeout":             "horizontal scaling service timeout",
+	"scale idle service error":                       "scale idle service error",
+	"wake idle service error":                        "wake idle service error",
+	"wake idle service timeout":                      "wake idle service timeout",
 	"upgrade service error":                          "upgrade service error",
 	"upgrade service timeout":                        "upgrade service timeout",
 	"Check for log location code errors":             "Check for log location code errors",
@@ -0,0 +1,230 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package util
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//TarDir writes the files in the dir source to tw, the names of the files are prefixed with prefix.
+//The owner, mode and modification time of the files are kept.
+func TarDir(tw *tar.Writer, source, prefix string) error {
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+}
+
+//UnTarDir extracts the files of tr to the dir target. The name of each file is mapped by mapName
+//first, the file is skipped if mapName returns false. The files out of target are refused, so are
+//the symbolic and hard links pointing out of target. Existing entries are replaced, never followed.
+func UnTarDir(tr *tar.Reader, target string, mapName func(name string) (string, bool)) error {
+	if err := CheckAndCreateDir(target); err != nil {
+		return err
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := header.Name
+		if mapName != nil {
+			var ok bool
+			if name, ok = mapName(name); !ok {
+				continue
+			}
+		}
+		rel := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(name, "/")))
+		if rel == "." {
+			continue
+		}
+		if outOfDir(rel) {
+			return fmt.Errorf("file %s is out of the target dir", header.Name)
+		}
+		if underSymlink(target, rel) {
+			return fmt.Errorf("file %s is under a symbolic link", header.Name)
+		}
+		path := filepath.Join(target, rel)
+		mode := os.FileMode(header.Mode).Perm()
+		// an existing entry is replaced, not followed
+		if info, err := os.Lstat(path); err == nil && !(info.IsDir() && header.Typeflag == tar.TypeDir) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode); err != nil {
+				return err
+			}
+			if err := os.Chmod(path, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			linkRel := filepath.Join(filepath.Dir(rel), filepath.FromSlash(header.Linkname))
+			if filepath.IsAbs(header.Linkname) || outOfDir(linkRel) {
+				return fmt.Errorf("symbolic link %s points out of the target dir", header.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			linkRel := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(header.Linkname, "/")))
+			if mapName != nil {
+				mapped, ok := mapName(header.Linkname)
+				if !ok {
+					continue
+				}
+				linkRel = filepath.Clean(filepath.FromSlash(strings.TrimPrefix(mapped, "/")))
+			}
+			if outOfDir(linkRel) || underSymlink(target, linkRel) {
+				return fmt.Errorf("hard link %s points out of the target dir", header.Name)
+			}
+			linkPath := filepath.Join(target, linkRel)
+			if info, err := os.Lstat(linkPath); err != nil || !info.Mode().IsRegular() {
+				return fmt.Errorf("hard link %s does not point to a regular file", header.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.Link(linkPath, path); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		// the owner can only be changed by root, the files are owned by the current user otherwise
+		_ = os.Lchown(path, header.Uid, header.Gid)
+		if header.Typeflag != tar.TypeSymlink && header.Typeflag != tar.TypeLink {
+			_ = os.Chtimes(path, header.ModTime, header.ModTime)
+		}
+	}
+}
+
+//outOfDir checks whether the cleaned relative path rel leaves its base dir
+func outOfDir(rel string) bool {
+	return filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+//underSymlink checks whether any parent dir of rel in target is a symbolic link,
+//the files written through it could be out of target.
+func underSymlink(target, rel string) bool {
+	dir := target
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for _, part := range parts {
+		if part == "." {
+			continue
+		}
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return false
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+//ReplaceDir replaces the files in dir with the files in src and removes src.
+//The dir itself is kept so that the mounts of it still work.
+func ReplaceDir(dir, src string) error {
+	if err := CheckAndCreateDir(dir); err != nil {
+		return err
+	}
+	olds, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, old := range olds {
+		if err := os.RemoveAll(filepath.Join(dir, old.Name())); err != nil {
+			return err
+		}
+	}
+	news, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, file := range news {
+		if err := os.Rename(filepath.Join(src, file.Name()), filepath.Join(dir, file.Name())); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(src)
+}
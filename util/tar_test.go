@@ -0,0 +1,183 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTarDir(t *testing.T) {
+	source, err := ioutil.TempDir("", "tar-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(source)
+	if err := os.MkdirAll(filepath.Join(source, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "sub", "a.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/a.txt", filepath.Join(source, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := TarDir(tw, source, "pod-0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := ioutil.TempDir("", "tar-target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+	mapName := func(name string) (string, bool) {
+		if !strings.HasPrefix(name, "pod-0/") {
+			return "", false
+		}
+		return strings.TrimPrefix(name, "pod-0/"), true
+	}
+	if err := UnTarDir(tar.NewReader(&buf), target, mapName); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(target, "sub", "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("expected content hello, got %s", content)
+	}
+	info, err := os.Stat(filepath.Join(target, "sub", "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	link, err := os.Readlink(filepath.Join(target, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "sub/a.txt" {
+		t.Errorf("expected link sub/a.txt, got %s", link)
+	}
+}
+
+func TestUnTarDirRefuse(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{
+			name: "parent dir",
+			headers: []*tar.Header{
+				{Name: "../escape.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+		{
+			name: "symbolic link",
+			headers: []*tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/tmp", Mode: 0777},
+				{Name: "link/escape.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+		{
+			name: "relative symbolic link",
+			headers: []*tar.Header{
+				{Name: "sub/link", Typeflag: tar.TypeSymlink, Linkname: "../../escape", Mode: 0777},
+			},
+		},
+		{
+			name: "hard link",
+			headers: []*tar.Header{
+				{Name: "link", Typeflag: tar.TypeLink, Linkname: "../escape.txt", Mode: 0644},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, header := range tc.headers {
+				if err := tw.WriteHeader(header); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			target, err := ioutil.TempDir("", "tar-target")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(target)
+			if err := UnTarDir(tar.NewReader(&buf), target, nil); err == nil {
+				t.Errorf("expected error, but returned nil")
+			}
+		})
+	}
+}
+
+func TestUnTarDirReplaceSymlink(t *testing.T) {
+	outside, err := ioutil.TempFile("", "tar-outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside.WriteString("keep")
+	outside.Close()
+	defer os.Remove(outside.Name())
+	target, err := ioutil.TempDir("", "tar-target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+	if err := os.Symlink(outside.Name(), filepath.Join(target, "x")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "x", Typeflag: tar.TypeReg, Mode: 0644, Size: 3}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte("new"))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnTarDir(tar.NewReader(&buf), target, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(outside.Name()); string(data) != "keep" {
+		t.Errorf("the file out of target was overwritten: %s", data)
+	}
+	info, err := os.Lstat(filepath.Join(target, "x"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Errorf("want x replaced by a regular file, got %v %v", info, err)
+	}
+}
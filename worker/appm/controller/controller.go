@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/gridworkz/kato/api/client/prometheus"
 	"github.com/gridworkz/kato/util"
 	"github.com/gridworkz/kato/worker/appm/store"
 	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
@@ -64,6 +65,12 @@ var TypeApplyConfigController TypeController = "apply_config"
 // TypeControllerRefreshHPA -
 var TypeControllerRefreshHPA TypeController = "refreshhpa"
 
+//TypeIdleController scales the idle service to 0
+var TypeIdleController TypeController = "idle"
+
+//TypeWakeController scales the idle service back to its replicas
+var TypeWakeController TypeController = "wake"
+
 //Manager controller manager
 type Manager struct {
 	ctx           context.Context
@@ -73,11 +80,12 @@ type Manager struct {
 	apply         apply.Applicator
 	controllers   map[string]Controller
 	store         store.Storer
+	prometheusCli prometheus.Interface
 	lock          sync.Mutex
 }
 
 //NewManager new manager
-func NewManager(store store.Storer, client kubernetes.Interface, runtimeClient client.Client) *Manager {
+func NewManager(store store.Storer, client kubernetes.Interface, runtimeClient client.Client, prometheusCli prometheus.Interface) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
 		ctx:           ctx,
@@ -87,6 +95,7 @@ func NewManager(store store.Storer, client kubernetes.Interface, runtimeClient c
 		runtimeClient: runtimeClient,
 		controllers:   make(map[string]Controller),
 		store:         store,
+		prometheusCli: prometheusCli,
 	}
 }
 
@@ -171,6 +180,22 @@ func (m *Manager) StartController(controllerType TypeController, apps ...v1.AppS
 			stopChan:     make(chan struct{}),
 			ctx:          context.Background(),
 		}
+	case TypeIdleController:
+		controller = &idleController{
+			controllerID: controllerID,
+			appService:   apps,
+			manager:      m,
+			stopChan:     make(chan struct{}),
+			ctx:          context.Background(),
+		}
+	case TypeWakeController:
+		controller = &wakeController{
+			controllerID: controllerID,
+			appService:   apps,
+			manager:      m,
+			stopChan:     make(chan struct{}),
+			ctx:          context.Background(),
+		}
 	default:
 		return fmt.Errorf("No support controller")
 	}
@@ -181,6 +206,24 @@ func (m *Manager) StartController(controllerType TypeController, apps ...v1.AppS
 	return nil
 }
 
+//StartProgressiveUpgrade create and start the progressive upgrade controller of the app
+func (m *Manager) StartProgressiveUpgrade(strategy *ReleaseStrategy, app v1.AppService) error {
+	controllerID := util.NewUUID()
+	controller := &progressiveController{
+		controllerID: controllerID,
+		appService:   app,
+		strategy:     strategy,
+		manager:      m,
+		stopChan:     make(chan struct{}),
+		ctx:          context.Background(),
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.controllers[controllerID] = controller
+	go controller.Begin()
+	return nil
+}
+
 func (m *Manager) callback(controllerID string, err error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
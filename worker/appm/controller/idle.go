@@ -0,0 +1,177 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/event"
+	"github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/util/constants"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+)
+
+//idleController scales the components to 0 and marks them idle, so that the gateway
+//holds their requests and wakes them up
+type idleController struct {
+	controllerID string
+	appService   []v1.AppService
+	manager      *Manager
+	stopChan     chan struct{}
+	ctx          context.Context
+}
+
+//Begin start handle service idle
+func (s *idleController) Begin() {
+	var wait sync.WaitGroup
+	for _, service := range s.appService {
+		wait.Add(1)
+		go func(service v1.AppService) {
+			defer wait.Done()
+			service.Logger.Info("App runtime begin scale idle app service "+service.ServiceAlias+" to 0", event.GetLoggerOption("starting"))
+			if err := s.idleOne(service); err != nil {
+				service.Logger.Error(util.Translation("scale idle service error"), event.GetCallbackLoggerOption())
+				logrus.Errorf("scale idle service %s failure %s", service.ServiceAlias, err.Error())
+			} else {
+				service.Logger.Info(fmt.Sprintf("idle service %s is scaled to 0", service.ServiceAlias), event.GetLastLoggerOption())
+			}
+		}(service)
+	}
+	wait.Wait()
+	s.manager.callback(s.controllerID, nil)
+}
+
+func (s *idleController) idleOne(app v1.AppService) error {
+	// mark the ingresses first, the gateway keeps the rules of idle components without endpoints
+	if err := patchIngressesIdle(s.ctx, s.manager, &app, true); err != nil {
+		return err
+	}
+	return patchWorkloadIdle(s.ctx, s.manager, &app, true, 0)
+}
+
+func (s *idleController) Stop() error {
+	close(s.stopChan)
+	return nil
+}
+
+//wakeController scales the idle components back to their replicas
+type wakeController struct {
+	controllerID string
+	appService   []v1.AppService
+	manager      *Manager
+	stopChan     chan struct{}
+	ctx          context.Context
+}
+
+//Begin start handle service wake
+func (s *wakeController) Begin() {
+	var wait sync.WaitGroup
+	for _, service := range s.appService {
+		wait.Add(1)
+		go func(service v1.AppService) {
+			defer wait.Done()
+			service.Logger.Info("App runtime begin wake idle app service "+service.ServiceAlias, event.GetLoggerOption("starting"))
+			if err := s.wakeOne(service); err != nil {
+				if err != ErrWaitTimeOut {
+					service.Logger.Error(util.Translation("wake idle service error"), event.GetCallbackLoggerOption())
+					logrus.Errorf("wake idle service %s failure %s", service.ServiceAlias, err.Error())
+				} else {
+					service.Logger.Error(util.Translation("wake idle service timeout"), event.GetTimeoutLoggerOption())
+				}
+			} else {
+				service.Logger.Info(fmt.Sprintf("idle service %s is woken up", service.ServiceAlias), event.GetLastLoggerOption())
+			}
+		}(service)
+	}
+	wait.Wait()
+	s.manager.callback(s.controllerID, nil)
+}
+
+func (s *wakeController) wakeOne(app v1.AppService) error {
+	if err := patchWorkloadIdle(s.ctx, s.manager, &app, false, app.Replicas); err != nil {
+		return err
+	}
+	if err := WaitReady(s.manager.store, &app, time.Minute*2, app.Logger, s.stopChan); err != nil {
+		return err
+	}
+	// the gateway holds the requests until the endpoints are ready, the mark can be removed now
+	return patchIngressesIdle(s.ctx, s.manager, &app, false)
+}
+
+func (s *wakeController) Stop() error {
+	close(s.stopChan)
+	return nil
+}
+
+//idlePatch the merge patch to mark the workload idle with 0 replicas, or to restore the replicas
+func idlePatch(idle bool, replicas int) []byte {
+	var mark interface{}
+	if idle {
+		mark = "true"
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{constants.IdleAnnotation: mark},
+		},
+		"spec": map[string]interface{}{"replicas": replicas},
+	})
+	return patch
+}
+
+func patchWorkloadIdle(ctx context.Context, m *Manager, app *v1.AppService, idle bool, replicas int) error {
+	patch := idlePatch(idle, replicas)
+	if statefulset := app.GetStatefulSet(); statefulset != nil {
+		_, err := m.client.AppsV1().StatefulSets(statefulset.Namespace).Patch(ctx, statefulset.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("patch statefulset %s failure %s", statefulset.Name, err.Error())
+		}
+	}
+	if deployment := app.GetDeployment(); deployment != nil {
+		_, err := m.client.AppsV1().Deployments(deployment.Namespace).Patch(ctx, deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("patch deployment %s failure %s", deployment.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func patchIngressesIdle(ctx context.Context, m *Manager, app *v1.AppService, idle bool) error {
+	var mark interface{}
+	if idle {
+		mark = "true"
+	}
+	for _, ing := range app.GetIngress(true) {
+		if ing.Spec.DefaultBackend != nil {
+			// tcp rule, the gateway can not hold the connections of it
+			continue
+		}
+		_, err := m.client.NetworkingV1().Ingresses(ing.Namespace).Patch(ctx, ing.Name, types.MergePatchType,
+			mergePatch(map[string]interface{}{constants.IdleAnnotation: mark}), metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("patch ingress %s failure %s", ing.Name, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,641 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/db"
+	"github.com/gridworkz/kato/event"
+	"github.com/gridworkz/kato/gateway/annotations/parser"
+	"github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/worker/appm/f"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	//ReleaseCanary shift the traffic to the new version step by step
+	ReleaseCanary = "canary"
+	//ReleaseBlueGreen run the new version with full replicas and switch all traffic at once
+	ReleaseBlueGreen = "blue-green"
+)
+
+//the suffix of the resources of the new version during the release
+const canarySuffix = "-canary"
+
+//serviceWeightAnnotation the weight of the k8s service in the mesh routes, see node envoy lds conver
+const serviceWeightAnnotation = "weight"
+
+//ReleaseStrategy progressive delivery strategy of the rolling upgrade
+type ReleaseStrategy struct {
+	Mode string
+	//the traffic percentage of the new version in every step
+	Steps []int
+	//the time to watch the new version in every step
+	StepInterval time.Duration
+	//the max 5xx rate of the gateway requests, the release is rolled back if it is exceeded
+	MaxErrorRate float64
+}
+
+//ParseReleaseStrategy parse the strategy of the rolling upgrade task, for example
+//["canary", "steps=10,50,100", "interval=2m", "max-error-rate=0.05"].
+//returns nil if the strategy is not a progressive delivery
+func ParseReleaseStrategy(strategy []string) (*ReleaseStrategy, error) {
+	var rs *ReleaseStrategy
+	for _, s := range strategy {
+		switch s {
+		case ReleaseCanary:
+			rs = &ReleaseStrategy{Mode: ReleaseCanary, Steps: []int{10, 50, 100}}
+		case ReleaseBlueGreen:
+			rs = &ReleaseStrategy{Mode: ReleaseBlueGreen, Steps: []int{100}}
+		}
+	}
+	if rs == nil {
+		return nil, nil
+	}
+	rs.StepInterval = time.Minute * 2
+	rs.MaxErrorRate = 0.05
+	for _, s := range strategy {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "steps":
+			if rs.Mode == ReleaseBlueGreen {
+				return nil, fmt.Errorf("steps is not supported by %s release", ReleaseBlueGreen)
+			}
+			var steps []int
+			for _, v := range strings.Split(kv[1], ",") {
+				step, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil || step <= 0 || step > 100 || (len(steps) > 0 && step <= steps[len(steps)-1]) {
+					return nil, fmt.Errorf("steps %s is invalid, it must be increasing percentages", kv[1])
+				}
+				steps = append(steps, step)
+			}
+			if steps[len(steps)-1] != 100 {
+				steps = append(steps, 100)
+			}
+			rs.Steps = steps
+		case "interval":
+			d, err := time.ParseDuration(kv[1])
+			if err != nil || d < time.Second*10 {
+				return nil, fmt.Errorf("interval %s is invalid, it must be a duration not less than 10s", kv[1])
+			}
+			rs.StepInterval = d
+		case "max-error-rate":
+			rate, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || rate <= 0 || rate >= 1 {
+				return nil, fmt.Errorf("max-error-rate %s is invalid, it must be between 0 and 1", kv[1])
+			}
+			rs.MaxErrorRate = rate
+		}
+	}
+	return rs, nil
+}
+
+//progressiveController runs the new version alongside the old one, shifts the traffic
+//to it step by step and promotes or rolls back it by the health and error rate.
+type progressiveController struct {
+	stopChan     chan struct{}
+	controllerID string
+	appService   v1.AppService
+	strategy     *ReleaseStrategy
+	manager      *Manager
+	ctx          context.Context
+	//the weight annotations of the stable ingresses before the release
+	ingressWeights map[string]*string
+	switched       bool
+}
+
+func (p *progressiveController) Begin() {
+	defer p.manager.callback(p.controllerID, nil)
+	app := p.appService
+	app.Logger.Info(fmt.Sprintf("App runtime begin %s release app service %s", p.strategy.Mode, app.ServiceAlias), event.GetLoggerOption("starting"))
+	upgrade := &upgradeController{
+		controllerID: p.controllerID,
+		appService:   []v1.AppService{app},
+		manager:      p.manager,
+		stopChan:     p.stopChan,
+		ctx:          p.ctx,
+	}
+	if app.GetDeployment() == nil {
+		// statefulset can not run two versions with the same identity, upgrade it in place
+		app.Logger.Info(fmt.Sprintf("%s release only supports stateless component, upgrade it in place", p.strategy.Mode), event.GetLoggerOption("running"))
+		upgrade.Begin()
+		return
+	}
+	if err := p.release(upgrade); err != nil {
+		logrus.Errorf("%s release service %s failure %s", p.strategy.Mode, app.ServiceAlias, err.Error())
+		if err == ErrWaitTimeOut {
+			app.Logger.Error(util.Translation("upgrade service timeout"), event.GetTimeoutLoggerOption())
+			return
+		}
+		app.Logger.Error(fmt.Sprintf("%s release failure: %s", p.strategy.Mode, err.Error()), event.GetCallbackLoggerOption())
+		return
+	}
+	app.Logger.Info(fmt.Sprintf("upgrade service %s success", app.ServiceAlias), event.GetLastLoggerOption())
+}
+
+func (p *progressiveController) Stop() error {
+	close(p.stopChan)
+	return nil
+}
+
+func (p *progressiveController) release(upgrade *upgradeController) error {
+	app := p.appService
+	stable := p.manager.store.GetAppService(app.ServiceID)
+	if stable == nil {
+		return fmt.Errorf("the running service %s is not found", app.ServiceAlias)
+	}
+	p.ensureConfigMaps()
+	for _, secret := range app.GetEnvVarSecrets(true) {
+		if err := f.CreateOrUpdateSecret(p.manager.client, secret); err != nil {
+			return fmt.Errorf("create or update secrets: %v", err)
+		}
+	}
+	stableReplicas := int32(stable.Replicas)
+	if stableReplicas <= 0 {
+		stableReplicas = 1
+	}
+	canaryReplicas := stableReplicas
+	if p.strategy.Mode == ReleaseCanary && len(p.strategy.Steps) > 1 {
+		// the canary only serves the traffic of the steps before the last one, it is scaled
+		// to the stable replicas before the last step switches all traffic to it
+		percent := p.strategy.Steps[len(p.strategy.Steps)-2]
+		canaryReplicas = int32(math.Ceil(float64(stableReplicas) * float64(percent) / 100))
+	}
+	if err := p.createCanary(canaryReplicas); err != nil {
+		p.rollback("create the new version failure")
+		return err
+	}
+	if err := p.waitCanaryReady(canaryReplicas); err != nil {
+		p.rollback("the new version is not ready")
+		return err
+	}
+	baseline, _ := p.errorRate()
+	restarts := p.canaryRestarts()
+	for _, percent := range p.strategy.Steps {
+		if err := p.shift(percent, stableReplicas, canaryReplicas); err != nil {
+			p.rollback("shift traffic failure")
+			return err
+		}
+		app.Logger.Info(fmt.Sprintf("%d%% of the traffic is shifted to the new version, watch it for %s", percent, p.strategy.StepInterval), event.GetLoggerOption("running"))
+		if err := p.watch(baseline, restarts); err != nil {
+			p.rollback(err.Error())
+			return err
+		}
+	}
+	app.Logger.Info("the new version is healthy, promote it", event.GetLoggerOption("running"))
+	if err := upgrade.upgradeOne(app); err != nil {
+		// the new version has taken all traffic, keep it and let the user retry
+		return fmt.Errorf("promote the new version failure %s", err.Error())
+	}
+	p.cleanup()
+	return nil
+}
+
+//ensureConfigMaps create the config maps that the new version requires, the existing config
+//maps are updated when the new version is promoted
+func (p *progressiveController) ensureConfigMaps() {
+	for _, cm := range p.appService.GetConfigMaps() {
+		_, err := p.manager.client.CoreV1().ConfigMaps(cm.Namespace).Get(p.ctx, cm.Name, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			if _, err := p.manager.client.CoreV1().ConfigMaps(cm.Namespace).Create(p.ctx, cm, metav1.CreateOptions{}); err != nil {
+				logrus.Errorf("create config map %s failure %s", cm.Name, err.Error())
+			}
+		}
+	}
+}
+
+func canaryName(name string) string {
+	return name + canarySuffix
+}
+
+//canaryLabels the labels of the canary resources. creater_id is removed so that the app
+//store does not take them as the resources of the running service.
+func canaryLabels(labels map[string]string, serviceAlias string) map[string]string {
+	re := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		re[k] = v
+	}
+	delete(re, "creater_id")
+	re["release"] = "canary"
+	if serviceAlias != "" && re["name"] == serviceAlias {
+		re["name"] = canaryName(serviceAlias)
+	}
+	return re
+}
+
+func (p *progressiveController) createCanary(replicas int32) error {
+	app := p.appService
+	deployment := app.GetDeployment().DeepCopy()
+	canary := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        canaryName(deployment.Name),
+			Namespace:   deployment.Namespace,
+			Labels:      canaryLabels(deployment.Labels, app.ServiceAlias),
+			Annotations: deployment.Annotations,
+		},
+		Spec: deployment.Spec,
+	}
+	canary.Spec.Replicas = &replicas
+	canary.Spec.Selector = &metav1.LabelSelector{MatchLabels: canaryLabels(deployment.Spec.Selector.MatchLabels, app.ServiceAlias)}
+	canary.Spec.Template.Labels = canaryLabels(deployment.Spec.Template.Labels, app.ServiceAlias)
+	if _, err := p.manager.client.AppsV1().Deployments(canary.Namespace).Create(p.ctx, canary, metav1.CreateOptions{}); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("create deployment %s failure %s", canary.Name, err.Error())
+		}
+		if _, err := p.manager.client.AppsV1().Deployments(canary.Namespace).Update(p.ctx, canary, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update deployment %s failure %s", canary.Name, err.Error())
+		}
+	}
+	for _, service := range app.GetServices(true) {
+		canary := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        canaryName(service.Name),
+				Namespace:   service.Namespace,
+				Labels:      canaryLabels(service.Labels, ""),
+				Annotations: make(map[string]string, len(service.Annotations)+1),
+			},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeClusterIP,
+				Ports:    service.Spec.Ports,
+				Selector: make(map[string]string, len(service.Spec.Selector)),
+			},
+		}
+		for k, v := range service.Annotations {
+			canary.Annotations[k] = v
+		}
+		canary.Annotations[serviceWeightAnnotation] = "0"
+		for k, v := range service.Spec.Selector {
+			canary.Spec.Selector[k] = v
+		}
+		canary.Spec.Selector["name"] = canaryName(app.ServiceAlias)
+		for i := range canary.Spec.Ports {
+			canary.Spec.Ports[i].NodePort = 0
+		}
+		if _, err := p.manager.client.CoreV1().Services(canary.Namespace).Create(p.ctx, canary, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("create service %s failure %s", canary.Name, err.Error())
+		}
+	}
+	app.Logger.Info(fmt.Sprintf("the new version is created with %d instances", replicas), event.GetLoggerOption("running"))
+	return nil
+}
+
+func (p *progressiveController) scaleCanary(replicas int32) error {
+	app := p.appService
+	name := canaryName(app.GetDeployment().Name)
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"replicas": replicas}})
+	if _, err := p.manager.client.AppsV1().Deployments(app.TenantID).Patch(p.ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("scale deployment %s failure %s", name, err.Error())
+	}
+	app.Logger.Info(fmt.Sprintf("the new version is scaled to %d instances", replicas), event.GetLoggerOption("running"))
+	return nil
+}
+
+func (p *progressiveController) waitCanaryReady(replicas int32) error {
+	app := p.appService
+	name := canaryName(app.GetDeployment().Name)
+	timeout := upgradeTimeout(app, replicas)
+	app.Logger.Info(fmt.Sprintf("waiting the new version ready timeout %ds", int(timeout.Seconds())), event.GetLoggerOption("running"))
+	ticker := time.NewTicker(time.Second * 5)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		deployment, err := p.manager.client.AppsV1().Deployments(app.TenantID).Get(p.ctx, name, metav1.GetOptions{})
+		if err == nil && deployment.Status.ReadyReplicas >= replicas {
+			return nil
+		}
+		select {
+		case <-p.stopChan:
+			return ErrWaitCancel
+		case <-timer.C:
+			return ErrWaitTimeOut
+		case <-ticker.C:
+		}
+	}
+}
+
+//gatewayWeights the gateway weight of every instance, the pool of a rule contains the instances
+//of both versions, so the weight is adjusted by the replicas to get the traffic percentage
+func gatewayWeights(percent int, stableReplicas, canaryReplicas int32) (int, int) {
+	stable := (100 - percent) * int(canaryReplicas)
+	canary := percent * int(stableReplicas)
+	g := gcd(stable, canary)
+	if g > 0 {
+		stable, canary = stable/g, canary/g
+	}
+	return stable, canary
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func mergePatch(annotations map[string]interface{}) []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	return patch
+}
+
+//shift shift the traffic of the gateway and the mesh to the new version. When all traffic
+//is shifted the selector of the services is switched to the instances of the new version.
+func (p *progressiveController) shift(percent int, stableReplicas, canaryReplicas int32) error {
+	if percent >= 100 {
+		if canaryReplicas < stableReplicas {
+			if err := p.scaleCanary(stableReplicas); err != nil {
+				return err
+			}
+			if err := p.waitCanaryReady(stableReplicas); err != nil {
+				return err
+			}
+		}
+		if err := p.resetWeights(); err != nil {
+			return err
+		}
+		return p.switchSelector(true)
+	}
+	weightKey := parser.GetAnnotationWithPrefix("weight")
+	stableWeight, canaryWeight := gatewayWeights(percent, stableReplicas, canaryReplicas)
+	stable := p.manager.store.GetAppService(p.appService.ServiceID)
+	if p.ingressWeights == nil {
+		p.ingressWeights = make(map[string]*string)
+		for _, ing := range stable.GetIngress(true) {
+			if w, ok := ing.Annotations[weightKey]; ok {
+				p.ingressWeights[ing.Name] = &w
+			} else {
+				p.ingressWeights[ing.Name] = nil
+			}
+		}
+	}
+	for _, ing := range stable.GetIngress(true) {
+		if ing.Spec.DefaultBackend != nil {
+			// tcp rule, the gateway can not split the traffic of it
+			continue
+		}
+		_, err := p.manager.client.NetworkingV1().Ingresses(ing.Namespace).Patch(p.ctx, ing.Name, types.MergePatchType,
+			mergePatch(map[string]interface{}{weightKey: strconv.Itoa(stableWeight)}), metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("update weight of ingress %s failure %s", ing.Name, err.Error())
+		}
+		canary := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        canaryName(ing.Name),
+				Namespace:   ing.Namespace,
+				Labels:      canaryLabels(ing.Labels, ""),
+				Annotations: make(map[string]string, len(ing.Annotations)),
+			},
+			Spec: *ing.Spec.DeepCopy(),
+		}
+		for k, v := range ing.Annotations {
+			canary.Annotations[k] = v
+		}
+		canary.Annotations[weightKey] = strconv.Itoa(canaryWeight)
+		for _, rule := range canary.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.HTTP.Paths {
+				if backend := rule.HTTP.Paths[i].Backend.Service; backend != nil {
+					backend.Name = canaryName(backend.Name)
+				}
+			}
+		}
+		old, err := p.manager.client.NetworkingV1().Ingresses(ing.Namespace).Get(p.ctx, canary.Name, metav1.GetOptions{})
+		if err == nil {
+			canary.ResourceVersion = old.ResourceVersion
+			_, err = p.manager.client.NetworkingV1().Ingresses(ing.Namespace).Update(p.ctx, canary, metav1.UpdateOptions{})
+		} else if errors.IsNotFound(err) {
+			_, err = p.manager.client.NetworkingV1().Ingresses(ing.Namespace).Create(p.ctx, canary, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("update canary ingress %s failure %s", canary.Name, err.Error())
+		}
+	}
+	// increase the weight of the canary services first, the mesh limits the sum of the weights to 100
+	if err := p.patchServiceWeights(true, strconv.Itoa(percent)); err != nil {
+		return err
+	}
+	return p.patchServiceWeights(false, strconv.Itoa(100-percent))
+}
+
+func (p *progressiveController) patchServiceWeights(canary bool, weight interface{}) error {
+	for _, service := range p.appService.GetServices(true) {
+		name := service.Name
+		if canary {
+			name = canaryName(name)
+		}
+		_, err := p.manager.client.CoreV1().Services(service.Namespace).Patch(p.ctx, name, types.MergePatchType,
+			mergePatch(map[string]interface{}{serviceWeightAnnotation: weight}), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("update weight of service %s failure %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+//resetWeights restore the weights of the stable resources and remove the canary ingresses
+func (p *progressiveController) resetWeights() error {
+	weightKey := parser.GetAnnotationWithPrefix("weight")
+	for name, weight := range p.ingressWeights {
+		var value interface{}
+		if weight != nil {
+			value = *weight
+		}
+		_, err := p.manager.client.NetworkingV1().Ingresses(p.appService.TenantID).Patch(p.ctx, name, types.MergePatchType,
+			mergePatch(map[string]interface{}{weightKey: value}), metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("restore weight of ingress %s failure %s", name, err.Error())
+		}
+		err = p.manager.client.NetworkingV1().Ingresses(p.appService.TenantID).Delete(p.ctx, canaryName(name), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete canary ingress %s failure %s", canaryName(name), err.Error())
+		}
+	}
+	p.ingressWeights = nil
+	if err := p.patchServiceWeights(true, "0"); err != nil {
+		return err
+	}
+	return p.patchServiceWeights(false, nil)
+}
+
+//switchSelector switch the selector of the stable services to the instances of the new version or back
+func (p *progressiveController) switchSelector(toCanary bool) error {
+	name := p.appService.ServiceAlias
+	if toCanary {
+		name = canaryName(name)
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"selector": map[string]string{"name": name}},
+	})
+	for _, service := range p.appService.GetServices(true) {
+		_, err := p.manager.client.CoreV1().Services(service.Namespace).Patch(p.ctx, service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("switch selector of service %s failure %s", service.Name, err.Error())
+		}
+	}
+	p.switched = toCanary
+	return nil
+}
+
+//watch watch the new version for a step, returns error if it is unhealthy
+func (p *progressiveController) watch(baseline float64, restarts int32) error {
+	threshold := p.strategy.MaxErrorRate
+	if baseline > threshold {
+		// the old version already has errors, only a further increase is counted
+		threshold = baseline + p.strategy.MaxErrorRate
+	}
+	name := canaryName(p.appService.GetDeployment().Name)
+	ticker := time.NewTicker(time.Second * 10)
+	defer ticker.Stop()
+	timer := time.NewTimer(p.strategy.StepInterval)
+	defer timer.Stop()
+	var unavailable int
+	for {
+		select {
+		case <-p.stopChan:
+			return ErrWaitCancel
+		case <-timer.C:
+			return nil
+		case <-ticker.C:
+		}
+		deployment, err := p.manager.client.AppsV1().Deployments(p.appService.TenantID).Get(p.ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("get the new version failure %s", err.Error())
+		}
+		if deployment.Status.UnavailableReplicas > 0 {
+			unavailable++
+			if unavailable >= 3 {
+				return fmt.Errorf("%d instances of the new version are unavailable", deployment.Status.UnavailableReplicas)
+			}
+		} else {
+			unavailable = 0
+		}
+		if current := p.canaryRestarts(); current > restarts {
+			return fmt.Errorf("the instances of the new version restarted %d times", current-restarts)
+		}
+		if rate, ok := p.errorRate(); ok && rate > threshold {
+			return fmt.Errorf("the error rate %.2f%% exceeds the limit %.2f%%", rate*100, threshold*100)
+		}
+	}
+}
+
+func (p *progressiveController) canaryRestarts() int32 {
+	pods, err := p.manager.client.CoreV1().Pods(p.appService.TenantID).List(p.ctx, metav1.ListOptions{
+		LabelSelector: "name=" + canaryName(p.appService.ServiceAlias),
+	})
+	if err != nil {
+		logrus.Warningf("list pods of the new version failure %s", err.Error())
+		return 0
+	}
+	var restarts int32
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+	return restarts
+}
+
+//errorRate the 5xx rate of the gateway requests of the service in the last minute
+func (p *progressiveController) errorRate() (float64, bool) {
+	if p.manager.prometheusCli == nil {
+		return 0, false
+	}
+	id := p.appService.ServiceID
+	expr := fmt.Sprintf(`sum(rate(gateway_requests{service_id="%s",status=~"5.."}[1m])) / sum(rate(gateway_requests{service_id="%s"}[1m]))`, id, id)
+	metric := p.manager.prometheusCli.GetMetric(expr, time.Now())
+	if metric.Error != "" {
+		logrus.Warningf("query error rate of service %s failure %s", p.appService.ServiceAlias, metric.Error)
+		return 0, false
+	}
+	if len(metric.MetricValues) == 0 || metric.MetricValues[0].Sample == nil {
+		return 0, false
+	}
+	rate := metric.MetricValues[0].Sample.Value()
+	if math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return 0, false
+	}
+	return rate, true
+}
+
+//rollback shift all traffic back to the old version and remove the new version
+func (p *progressiveController) rollback(reason string) {
+	app := p.appService
+	app.Logger.Error(fmt.Sprintf("%s, roll back to the old version", reason), event.GetLoggerOption("failure"))
+	if p.switched {
+		if err := p.switchSelector(false); err != nil {
+			logrus.Errorf("rollback service %s failure %s", app.ServiceAlias, err.Error())
+		}
+	}
+	p.cleanup()
+	// the deploy version is changed before the task, restore it
+	if stable := p.manager.store.GetAppService(app.ServiceID); stable != nil && stable.DeployVersion != "" {
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(app.ServiceID)
+		if err == nil && service.DeployVersion != stable.DeployVersion {
+			service.DeployVersion = stable.DeployVersion
+			if err := db.GetManager().TenantServiceDao().UpdateModel(service); err != nil {
+				logrus.Errorf("restore deploy version of service %s failure %s", app.ServiceAlias, err.Error())
+			}
+		}
+	}
+}
+
+//cleanup remove the canary resources after the release
+func (p *progressiveController) cleanup() {
+	app := p.appService
+	if p.switched {
+		// the old instances are upgraded, switch the traffic back to them
+		if err := p.switchSelector(false); err != nil {
+			logrus.Errorf("switch selector of service %s failure %s", app.ServiceAlias, err.Error())
+		}
+	}
+	if err := p.resetWeights(); err != nil {
+		logrus.Errorf("reset weights of service %s failure %s", app.ServiceAlias, err.Error())
+	}
+	for _, service := range app.GetServices(true) {
+		err := p.manager.client.CoreV1().Services(service.Namespace).Delete(p.ctx, canaryName(service.Name), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logrus.Errorf("delete canary service %s failure %s", canaryName(service.Name), err.Error())
+		}
+	}
+	deployment := app.GetDeployment()
+	err := p.manager.client.AppsV1().Deployments(deployment.Namespace).Delete(p.ctx, canaryName(deployment.Name), metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logrus.Errorf("delete canary deployment %s failure %s", canaryName(deployment.Name), err.Error())
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseReleaseStrategy(t *testing.T) {
+	rs, err := ParseReleaseStrategy(nil)
+	if err != nil || rs != nil {
+		t.Fatalf("expect no release strategy, got %v %v", rs, err)
+	}
+	rs, err = ParseReleaseStrategy([]string{"canary", "steps=5,20,60", "interval=1m", "max-error-rate=0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rs.Steps, []int{5, 20, 60, 100}) || rs.StepInterval != time.Minute || rs.MaxErrorRate != 0.1 {
+		t.Fatalf("unexpected release strategy %+v", rs)
+	}
+	rs, err = ParseReleaseStrategy([]string{"blue-green"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rs.Steps, []int{100}) {
+		t.Fatalf("unexpected blue green steps %v", rs.Steps)
+	}
+	for _, strategy := range [][]string{
+		{"canary", "steps=50,20"},
+		{"canary", "steps=0,50"},
+		{"canary", "interval=1s"},
+		{"canary", "max-error-rate=2"},
+		{"blue-green", "steps=10,100"},
+	} {
+		if _, err := ParseReleaseStrategy(strategy); err == nil {
+			t.Fatalf("expect error for strategy %v", strategy)
+		}
+	}
+}
+
+func TestGatewayWeights(t *testing.T) {
+	tests := []struct {
+		percent                        int
+		stableReplicas, canaryReplicas int32
+		stable, canary                 int
+	}{
+		{percent: 10, stableReplicas: 1, canaryReplicas: 1, stable: 9, canary: 1},
+		{percent: 50, stableReplicas: 4, canaryReplicas: 2, stable: 1, canary: 2},
+		{percent: 20, stableReplicas: 3, canaryReplicas: 1, stable: 4, canary: 3},
+	}
+	for _, tc := range tests {
+		stable, canary := gatewayWeights(tc.percent, tc.stableReplicas, tc.canaryReplicas)
+		if stable != tc.stable || canary != tc.canary {
+			t.Errorf("percent %d: expect %d/%d, got %d/%d", tc.percent, tc.stable, tc.canary, stable, canary)
+		}
+		// the traffic of all instances matches the percentage
+		total := float64(stable)*float64(tc.stableReplicas) + float64(canary)*float64(tc.canaryReplicas)
+		if got := float64(canary) * float64(tc.canaryReplicas) / total * 100; int(got+0.5) != tc.percent {
+			t.Errorf("percent %d: got %f", tc.percent, got)
+		}
+	}
+}
+
+func TestCanaryLabels(t *testing.T) {
+	labels := canaryLabels(map[string]string{"name": "gr123456", "creater_id": "1", "service_id": "sid"}, "gr123456")
+	expect := map[string]string{"name": "gr123456-canary", "service_id": "sid", "release": "canary"}
+	if !reflect.DeepEqual(labels, expect) {
+		t.Fatalf("expect %v, got %v", expect, labels)
+	}
+}
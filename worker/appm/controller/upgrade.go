@@ -224,6 +224,19 @@ func (s *upgradeController) upgradeOne(app v1.AppService) error {
 //WaitingReady wait app start or upgrade ready
 func (s *upgradeController) WaitingReady(app v1.AppService) error {
 	storeAppService := s.manager.store.GetAppService(app.ServiceID)
+	replicas := int32(-1)
+	if storeAppService != nil {
+		replicas = int32(storeAppService.Replicas)
+	}
+	timeout := upgradeTimeout(app, replicas)
+	if err := WaitUpgradeReady(s.manager.store, storeAppService, timeout, app.Logger, s.stopChan); err != nil {
+		return err
+	}
+	return nil
+}
+
+//upgradeTimeout the timeout of waiting the replicas of the app ready, a negative replicas is unknown
+func upgradeTimeout(app v1.AppService, replicas int32) time.Duration {
 	var initTime int32
 	if podt := app.GetPodTemplate(); podt != nil {
 		for _, c := range podt.Spec.Containers {
@@ -239,11 +252,8 @@ func (s *upgradeController) WaitingReady(app v1.AppService) error {
 	}
 	//at least waiting time is 40 second
 	timeout := time.Second * time.Duration(40+initTime)
-	if storeAppService != nil && storeAppService.Replicas >= 0 {
-		timeout = timeout * time.Duration((storeAppService.Replicas)*2)
+	if replicas >= 0 {
+		timeout = timeout * time.Duration(replicas*2)
 	}
-	if err := WaitUpgradeReady(s.manager.store, storeAppService, timeout, app.Logger, s.stopChan); err != nil {
-		return err
-	}
-	return nil
+	return timeout
 }
@@ -78,6 +78,10 @@ func newHPAs(as *v1.AppService, dbmanager db.Manager) ([]*autoscalingv2.Horizont
 		})
 
 		hpa := newHPA(as.TenantID, kind, name, labels, rule, metrics)
+		if hpa == nil {
+			logrus.Warningf("rule id: %s; no valid metrics, skip it", rule.RuleID)
+			continue
+		}
 
 		hpas = append(hpas, hpa)
 	}
@@ -113,6 +117,111 @@ func createResourceMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) aut
 	return ms
 }
 
+// customMetricTarget returns the target of the custom metric. The target value of
+// custom metrics can be 0, kubernetes only accepts positive targets, so 0 is
+// converted to the smallest quantity, which scales out once the metric is above 0.
+func customMetricTarget(metric *model.TenantServiceAutoscalerRuleMetrics) (autoscalingv2.MetricTarget, error) {
+	if metric.MetricTargetValue < 0 {
+		return autoscalingv2.MetricTarget{}, fmt.Errorf("target value %d is negative", metric.MetricTargetValue)
+	}
+	quantity := resource.NewQuantity(int64(metric.MetricTargetValue), resource.DecimalSI)
+	if metric.MetricTargetValue == 0 {
+		quantity = resource.NewMilliQuantity(1, resource.DecimalSI)
+	}
+	switch metric.MetricTargetType {
+	case "value":
+		return autoscalingv2.MetricTarget{
+			Type:  autoscalingv2.ValueMetricType,
+			Value: quantity,
+		}, nil
+	case "average_value":
+		return autoscalingv2.MetricTarget{
+			Type:         autoscalingv2.AverageValueMetricType,
+			AverageValue: quantity,
+		}, nil
+	}
+	return autoscalingv2.MetricTarget{}, fmt.Errorf("unsupported target type %s", metric.MetricTargetType)
+}
+
+func customMetricIdentifier(metric *model.TenantServiceAutoscalerRuleMetrics) (autoscalingv2.MetricIdentifier, error) {
+	identifier := autoscalingv2.MetricIdentifier{
+		Name: metric.MetricsName,
+	}
+	if metric.MetricSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(metric.MetricSelector)
+		if err != nil {
+			return identifier, fmt.Errorf("parse metric selector %s: %v", metric.MetricSelector, err)
+		}
+		identifier.Selector = selector
+	}
+	return identifier, nil
+}
+
+func createPodsMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) (*autoscalingv2.MetricSpec, error) {
+	if metric.MetricTargetType != "average_value" {
+		return nil, fmt.Errorf("pods metrics only support the average_value target type")
+	}
+	target, err := customMetricTarget(metric)
+	if err != nil {
+		return nil, err
+	}
+	identifier, err := customMetricIdentifier(metric)
+	if err != nil {
+		return nil, err
+	}
+	return &autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: identifier,
+			Target: target,
+		},
+	}, nil
+}
+
+func createObjectMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) (*autoscalingv2.MetricSpec, error) {
+	if metric.ObjectKind == "" || metric.ObjectName == "" {
+		return nil, fmt.Errorf("the described object of object metrics is required")
+	}
+	target, err := customMetricTarget(metric)
+	if err != nil {
+		return nil, err
+	}
+	identifier, err := customMetricIdentifier(metric)
+	if err != nil {
+		return nil, err
+	}
+	return &autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{
+			DescribedObject: autoscalingv2.CrossVersionObjectReference{
+				Kind:       metric.ObjectKind,
+				Name:       metric.ObjectName,
+				APIVersion: metric.ObjectAPIVersion,
+			},
+			Metric: identifier,
+			Target: target,
+		},
+	}, nil
+}
+
+func createExternalMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) (*autoscalingv2.MetricSpec, error) {
+	target, err := customMetricTarget(metric)
+	if err != nil {
+		return nil, err
+	}
+	identifier, err := customMetricIdentifier(metric)
+	if err != nil {
+		return nil, err
+	}
+	return &autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: identifier,
+			Target: target,
+		},
+	}, nil
+}
+
 func newHPA(namespace, kind, name string, labels map[string]string, rule *model.TenantServiceAutoscalerRules, metrics []*model.TenantServiceAutoscalerRuleMetrics) *autoscalingv2.HorizontalPodAutoscaler {
 	hpa := &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -133,18 +242,31 @@ func newHPA(namespace, kind, name string, labels map[string]string, rule *model.
 	}
 
 	for _, metric := range metrics {
-		if metric.MetricsType != "resource_metrics" {
+		var ms *autoscalingv2.MetricSpec
+		var err error
+		switch metric.MetricsType {
+		case "resource_metrics":
+			if metric.MetricTargetValue <= 0 {
+				// If the target value of cpu and memory is 0, it will not take effect.
+				continue
+			}
+			resourceMetric := createResourceMetrics(metric)
+			ms = &resourceMetric
+		case "pods_metrics":
+			ms, err = createPodsMetrics(metric)
+		case "object_metrics":
+			ms, err = createObjectMetrics(metric)
+		case "external_metrics":
+			ms, err = createExternalMetrics(metric)
+		default:
 			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
 			continue
 		}
-		if metric.MetricTargetValue <= 0 {
-			// TODO: If the target value of cpu and memory is 0, it will not take effect.
-			// TODO: The target value of the custom indicator can be 0.
+		if err != nil {
+			logrus.Warningf("rule id: %s; metric %s: %v", rule.RuleID, metric.MetricsName, err)
 			continue
 		}
-
-		ms := createResourceMetrics(metric)
-		spec.Metrics = append(spec.Metrics, ms)
+		spec.Metrics = append(spec.Metrics, *ms)
 	}
 	if len(spec.Metrics) == 0 {
 		return nil
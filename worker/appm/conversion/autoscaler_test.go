@@ -5,6 +5,7 @@ import (
 
 	"github.com/gridworkz/kato/db/model"
 	k8sutil "github.com/gridworkz/kato/util/k8s"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
 )
 
 func TestCreateMetricSpec(t *testing.T) {
@@ -19,6 +20,66 @@ func TestCreateMetricSpec(t *testing.T) {
 	t.Logf("%#v", metricSpec)
 }
 
+func TestNewHPAWithCustomMetrics(t *testing.T) {
+	rule := &model.TenantServiceAutoscalerRules{
+		RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+		MinReplicas: 1,
+		MaxReplicas: 10,
+	}
+	metrics := []*model.TenantServiceAutoscalerRuleMetrics{
+		{
+			MetricsType:       "pods_metrics",
+			MetricsName:       "http_requests",
+			MetricTargetType:  "average_value",
+			MetricTargetValue: 100,
+		},
+		{
+			MetricsType:       "object_metrics",
+			MetricsName:       "requests_per_second",
+			MetricTargetType:  "value",
+			MetricTargetValue: 2000,
+			ObjectKind:        "Ingress",
+			ObjectName:        "main-route",
+			ObjectAPIVersion:  "extensions/v1beta1",
+		},
+		{
+			MetricsType:       "external_metrics",
+			MetricsName:       "queue_messages_ready",
+			MetricTargetType:  "average_value",
+			MetricTargetValue: 0,
+			MetricSelector:    "queue=orders",
+		},
+		{
+			// pods metrics only support average value
+			MetricsType:       "pods_metrics",
+			MetricsName:       "http_requests",
+			MetricTargetType:  "value",
+			MetricTargetValue: 100,
+		},
+	}
+
+	hpa := newHPA("namespace", "Deployment", "name", nil, rule, metrics)
+	if hpa == nil || len(hpa.Spec.Metrics) != 3 {
+		t.Fatalf("expect 3 metrics, got %#v", hpa)
+	}
+	pods := hpa.Spec.Metrics[0]
+	if pods.Type != autoscalingv2.PodsMetricSourceType || pods.Pods.Target.AverageValue.Value() != 100 {
+		t.Errorf("unexpected pods metric %#v", pods)
+	}
+	object := hpa.Spec.Metrics[1]
+	if object.Type != autoscalingv2.ObjectMetricSourceType || object.Object.DescribedObject.Name != "main-route" ||
+		object.Object.Target.Value.Value() != 2000 {
+		t.Errorf("unexpected object metric %#v", object)
+	}
+	external := hpa.Spec.Metrics[2]
+	if external.Type != autoscalingv2.ExternalMetricSourceType || external.External.Metric.Selector.MatchLabels["queue"] != "orders" {
+		t.Errorf("unexpected external metric %#v", external)
+	}
+	if external.External.Target.AverageValue.Sign() != 1 {
+		t.Errorf("the zero target should be converted to a positive quantity")
+	}
+}
+
 func TestNewHPA(t *testing.T) {
 	rule := &model.TenantServiceAutoscalerRules{
 		RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
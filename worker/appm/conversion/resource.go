@@ -19,24 +19,14 @@
 package conversion
 
 import (
+	workerutil "github.com/gridworkz/kato/worker/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 //Allocate the CPU at the ratio of 4g memory to 1 core CPU
 func createResourcesByDefaultCPU(memory int, setCPURequest, setCPULimit int64) corev1.ResourceRequirements {
-	var cpuRequest, cpuLimit int64
-	base := int64(memory) / 128
-	if base <= 0 {
-		base = 1
-	}
-	if memory < 512 {
-		cpuRequest, cpuLimit = base*30, base*80
-	} else if memory <= 1024 {
-		cpuRequest, cpuLimit = base*30, base*160
-	} else {
-		cpuRequest, cpuLimit = base*30, ((int64(memory)-1024)/1024*500 + 1280)
-	}
+	cpuRequest, cpuLimit := workerutil.DefaultCPU(memory)
 	if setCPULimit > 0 {
 		cpuLimit = setCPULimit
 	}
@@ -1103,7 +1103,7 @@ func (a *appRuntimeStore) GetNeedBillingStatus(serviceIDs []string) map[string]s
 	return statusMap
 }
 func isClosedStatus(curStatus string) bool {
-	return curStatus == v1.BUILDEFAILURE || curStatus == v1.CLOSED || curStatus == v1.UNDEPLOY || curStatus == v1.BUILDING || curStatus == v1.UNKNOW
+	return curStatus == v1.BUILDEFAILURE || curStatus == v1.CLOSED || curStatus == v1.UNDEPLOY || curStatus == v1.BUILDING || curStatus == v1.UNKNOW || curStatus == v1.IDLE
 }
 
 func getServiceInfoFromPod(pod *corev1.Pod) v1.AbnormalInfo {
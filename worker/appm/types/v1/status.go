@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/gridworkz/kato/pkg/apis/kato/v1alpha1"
+	"github.com/gridworkz/kato/util/constants"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
@@ -70,6 +71,17 @@ func (a *AppService) IsClosed() bool {
 	return false
 }
 
+//IsIdle the component is scaled to 0 by its idle policy
+func (a *AppService) IsIdle() bool {
+	if a.statefulset != nil {
+		return a.statefulset.Annotations[constants.IdleAnnotation] == "true"
+	}
+	if a.deployment != nil {
+		return a.deployment.Annotations[constants.IdleAnnotation] == "true"
+	}
+	return false
+}
+
 var (
 	//RUNNING if stateful or deployment exist and ready pod number is equal to the service Replicas
 	RUNNING = "running"
@@ -93,6 +105,8 @@ var (
 	BUILDEFAILURE = "build_failure"
 	//UNDEPLOY init status
 	UNDEPLOY = "undeploy"
+	//IDLE the component is scaled to 0 by its idle policy and waits for requests to wake up
+	IDLE = "idle"
 )
 
 func conversionThirdComponent(obj runtime.Object) *v1alpha1.ThirdComponent {
@@ -158,6 +172,9 @@ func (a *AppService) GetServiceStatus() string {
 	if a.IsClosed() {
 		return CLOSED
 	}
+	if a.IsIdle() && a.IsEmpty() {
+		return IDLE
+	}
 	if a.statefulset == nil && a.deployment == nil && len(a.pods) > 0 {
 		return STOPPING
 	}
@@ -96,62 +96,75 @@ func (t *TaskManager) Start() error {
 //Do do
 func (t *TaskManager) Do() {
 	logrus.Info("start receive task from mq")
-	hostname, _ := os.Hostname()
+	req := &pb.SubscribeRequest{Topic: client.WorkerTopic, ConsumerId: t.consumerID(), MaxInFlight: 1}
 	for {
 		select {
 		case <-t.ctx.Done():
 			return
 		default:
-			data, err := t.client.Dequeue(t.ctx, &pb.DequeueRequest{Topic: client.WorkerTopic, ClientHost: hostname + "-worker"})
+			err := t.client.SubscribeTopic(t.ctx, req, t.handleTask)
 			if err != nil {
-				if grpc1.ErrorDesc(err) == context.DeadlineExceeded.Error() {
-					continue
-				}
 				if grpc1.ErrorDesc(err) == "context canceled" {
 					logrus.Info("receive task core context canceled")
 					healthStatus["status"] = "unusual"
 					healthStatus["info"] = "receive task core context canceled"
 					return
 				}
-				if grpc1.ErrorDesc(err) == "context timeout" {
-					continue
-				}
 				logrus.Error("receive task error.", err.Error())
 				time.Sleep(time.Second * 2)
-				continue
-			}
-			logrus.Debugf("receive a task: %v", data)
-			transData, err := model.TransTask(data)
-			if err != nil {
-				logrus.Error("trans mq msg data error ", err.Error())
-				continue
-			}
-			rc := t.handleManager.AnalystToExec(transData)
-			if rc != nil && rc != handle.ErrCallback {
-				logrus.Warningf("execute task: %v", rc)
-				TaskError++
-			} else if rc != nil && rc == handle.ErrCallback {
-				logrus.Errorf("err callback; analyst to exet: %v", rc)
-				ctx, cancel := context.WithCancel(t.ctx)
-				reply, err := t.client.Enqueue(ctx, &pb.EnqueueRequest{
-					Topic:   client.WorkerTopic,
-					Message: data,
-				})
-				cancel()
-				logrus.Debugf("retry send task to mq ,reply is %v", reply)
-				if err != nil {
-					logrus.Errorf("enqueue task %v to mq topic %v Error", data, client.WorkerTopic)
-					continue
-				}
-				//if handle is waiting, sleep 3 second
-				time.Sleep(time.Second * 3)
-			} else {
-				TaskNum++
 			}
 		}
 	}
 }
 
+func (t *TaskManager) handleTask(data *pb.TaskMessage) {
+	consumer := t.consumerID()
+	logrus.Debugf("receive a task: %v", data)
+	transData, err := model.TransTask(data)
+	if err != nil {
+		logrus.Error("trans mq msg data error ", err.Error())
+		t.ack(data, consumer)
+		return
+	}
+	rc := t.handleManager.AnalystToExec(transData)
+	t.ack(data, consumer)
+	if rc != nil && rc != handle.ErrCallback {
+		logrus.Warningf("execute task: %v", rc)
+		TaskError++
+	} else if rc != nil && rc == handle.ErrCallback {
+		logrus.Errorf("err callback; analyst to exet: %v", rc)
+		ctx, cancel := context.WithCancel(t.ctx)
+		reply, err := t.client.Enqueue(ctx, &pb.EnqueueRequest{
+			Topic:   client.WorkerTopic,
+			Message: data,
+		})
+		cancel()
+		logrus.Debugf("retry send task to mq ,reply is %v", reply)
+		if err != nil {
+			logrus.Errorf("enqueue task %v to mq topic %v Error", data, client.WorkerTopic)
+			return
+		}
+		//if handle is waiting, sleep 3 second
+		time.Sleep(time.Second * 3)
+	} else {
+		TaskNum++
+	}
+}
+
+func (t *TaskManager) consumerID() string {
+	hostname, _ := os.Hostname()
+	return hostname + "-worker"
+}
+
+//ack tells mq the task is handled, an unacked task is delivered again after the visibility timeout
+func (t *TaskManager) ack(task *pb.TaskMessage, clientHost string) {
+	ctx, cancel := context.WithTimeout(t.ctx, time.Second*5)
+	defer cancel()
+	if _, err := t.client.Ack(ctx, &pb.AckRequest{Topic: client.WorkerTopic, TaskId: task.TaskId, ClientHost: clientHost}); err != nil {
+		logrus.Warningf("ack task %s failure %s", task.TaskId, err.Error())
+	}
+}
+
 //Stop stop
 func (t *TaskManager) Stop() error {
 	logrus.Info("discover manager is stoping.")
@@ -165,6 +165,27 @@ func NewTaskBody(taskType string, body []byte) TaskBody {
 			return nil
 		}
 		return b
+	case "idle":
+		b := IdleTaskBody{}
+		err := ffjson.Unmarshal(body, &b)
+		if err != nil {
+			return nil
+		}
+		return b
+	case "wake":
+		b := WakeTaskBody{}
+		err := ffjson.Unmarshal(body, &b)
+		if err != nil {
+			return nil
+		}
+		return b
+	case "expand_volume":
+		b := ExpandVolumeTaskBody{}
+		err := ffjson.Unmarshal(body, &b)
+		if err != nil {
+			return nil
+		}
+		return b
 	default:
 		return DefaultTaskBody{}
 	}
@@ -197,6 +218,12 @@ func CreateTaskBody(taskType string) TaskBody {
 		return DeleteTenantTaskBody{}
 	case "refreshhpa":
 		return RefreshHPATaskBody{}
+	case "idle":
+		return IdleTaskBody{}
+	case "wake":
+		return WakeTaskBody{}
+	case "expand_volume":
+		return ExpandVolumeTaskBody{}
 	default:
 		return DefaultTaskBody{}
 	}
@@ -232,6 +259,8 @@ type HorizontalScalingTaskBody struct {
 	Replicas  int32  `json:"replicas"`
 	EventID   string `json:"event_id"`
 	Username  string `json:"username"`
+	// ScheduleID is set when the scaling is triggered by the autoscaler schedule
+	ScheduleID string `json:"schedule_id,omitempty"`
 }
 
 //VerticalScalingTaskBody vertical scaling operation task body
@@ -358,5 +387,29 @@ type RefreshHPATaskBody struct {
 	EventID   string `json:"eventID"`
 }
 
+//IdleTaskBody scales the component to 0 because it has no traffic for IdleMinutes
+type IdleTaskBody struct {
+	TenantID    string `json:"tenant_id"`
+	ServiceID   string `json:"service_id"`
+	IdleMinutes int    `json:"idle_minutes"`
+}
+
+//WakeTaskBody scales the idle component back to its replicas, sent by the gateway
+//when a request of the component comes in
+type WakeTaskBody struct {
+	ServiceID string `json:"service_id"`
+}
+
+//ExpandVolumeTaskBody grows the claims of the volume to the capacity
+type ExpandVolumeTaskBody struct {
+	TenantID   string `json:"tenant_id"`
+	ServiceID  string `json:"service_id"`
+	VolumeID   int    `json:"volume_id"`
+	VolumeName string `json:"volume_name"`
+	// Capacity the new capacity in Gi
+	Capacity int64  `json:"capacity"`
+	EventID  string `json:"event_id"`
+}
+
 //DefaultTaskBody default operation task body
 type DefaultTaskBody map[string]interface{}
@@ -121,6 +121,15 @@ func (m *Manager) AnalystToExec(task *model.Task) error {
 	case "refreshhpa":
 		logrus.Info("start a 'refreshhpa' task worker")
 		return m.ExecRefreshHPATask(task)
+	case "idle":
+		logrus.Info("start a 'idle' task worker")
+		return m.idleExec(task)
+	case "wake":
+		logrus.Info("start a 'wake' task worker")
+		return m.wakeExec(task)
+	case "expand_volume":
+		logrus.Info("start a 'expand_volume' task worker")
+		return m.expandVolumeExec(task)
 	default:
 		logrus.Warning("task can not execute because no type is identified")
 		return nil
@@ -252,10 +261,15 @@ func (m *Manager) horizontalScalingExec(task *model.Task) (err error) {
 			desc = fmt.Sprintf(desc, oldReplicas, newReplicas, err)
 			reason = "FailedRescale"
 		}
+		recordType := "manual"
+		if body.ScheduleID != "" {
+			recordType = "cron"
+		}
 		scalingRecord := &dbmodel.TenantServiceScalingRecords{
 			ServiceID:   body.ServiceID,
+			RuleID:      body.ScheduleID,
 			EventName:   util.NewUUID(),
-			RecordType:  "manual",
+			RecordType:  recordType,
 			Reason:      reason,
 			Count:       1,
 			Description: desc,
@@ -280,6 +294,92 @@ func (m *Manager) horizontalScalingExec(task *model.Task) (err error) {
 	return nil
 }
 
+//idleExec scales the component without traffic to 0
+func (m *Manager) idleExec(task *model.Task) error {
+	body, ok := task.Body.(model.IdleTaskBody)
+	if !ok {
+		logrus.Errorf("idle body convert to taskbody error")
+		return fmt.Errorf("idle body convert to taskbody error")
+	}
+	appService := m.store.GetAppService(body.ServiceID)
+	if appService == nil || appService.IsClosed() || appService.IsIdle() {
+		logrus.Debugf("component %s is closed or idle, no need to scale it to 0", body.ServiceID)
+		return nil
+	}
+	logger, err := m.systemEventLogger(appService.TenantID, body.ServiceID, "idle-service")
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("no requests in the last %d minutes", body.IdleMinutes), event.GetLoggerOption("starting"))
+	appService.Logger = logger
+	if err := m.controllerManager.StartController(controller.TypeIdleController, *appService); err != nil {
+		logrus.Errorf("component run idle controller failure:%s", err.Error())
+		logger.Error("component run idle controller failure", event.GetCallbackLoggerOption())
+		event.GetManager().ReleaseLogger(logger)
+		return fmt.Errorf("component idle failure")
+	}
+	logrus.Infof("service(%s) %s working is running.", body.ServiceID, "idle")
+	return nil
+}
+
+//wakeExec scales the idle component back to its replicas
+func (m *Manager) wakeExec(task *model.Task) error {
+	body, ok := task.Body.(model.WakeTaskBody)
+	if !ok {
+		logrus.Errorf("wake body convert to taskbody error")
+		return fmt.Errorf("wake body convert to taskbody error")
+	}
+	appService := m.store.GetAppService(body.ServiceID)
+	if appService == nil || !appService.IsIdle() {
+		// the gateways send the wake task for every held request, the first one wakes the component
+		logrus.Debugf("component %s is not idle, no need to wake it", body.ServiceID)
+		return nil
+	}
+	service, err := m.dbmanager.TenantServiceDao().GetServiceByID(body.ServiceID)
+	if err != nil {
+		logrus.Errorf("wake component %s: get component: %v", body.ServiceID, err)
+		return err
+	}
+	logger, err := m.systemEventLogger(appService.TenantID, body.ServiceID, "wake-service")
+	if err != nil {
+		return err
+	}
+	logger.Info("a request is waiting for the idle component", event.GetLoggerOption("starting"))
+	appService.Logger = logger
+	appService.Replicas = service.Replicas
+	if appService.Replicas < 1 {
+		appService.Replicas = 1
+	}
+	if err := m.controllerManager.StartController(controller.TypeWakeController, *appService); err != nil {
+		logrus.Errorf("component run wake controller failure:%s", err.Error())
+		logger.Error("component run wake controller failure", event.GetCallbackLoggerOption())
+		event.GetManager().ReleaseLogger(logger)
+		return fmt.Errorf("component wake failure")
+	}
+	logrus.Infof("service(%s) %s working is running.", body.ServiceID, "wake")
+	return nil
+}
+
+//systemEventLogger creates the event of the operation triggered by the platform itself
+func (m *Manager) systemEventLogger(tenantID, serviceID, optType string) (event.Logger, error) {
+	ev := &dbmodel.ServiceEvent{
+		EventID:   util.NewUUID(),
+		TenantID:  tenantID,
+		ServiceID: serviceID,
+		Target:    dbmodel.TargetTypeService,
+		TargetID:  serviceID,
+		UserName:  "system",
+		StartTime: time.Now().Format(time.RFC3339),
+		SynType:   dbmodel.ASYNEVENTTYPE,
+		OptType:   optType,
+	}
+	if err := m.dbmanager.ServiceEventDao().AddModel(ev); err != nil {
+		logrus.Errorf("create %s event of component %s: %v", optType, serviceID, err)
+		return nil, err
+	}
+	return event.GetManager().GetLogger(ev.EventID), nil
+}
+
 func (m *Manager) verticalScalingExec(task *model.Task) error {
 	body, ok := task.Body.(model.VerticalScalingTaskBody)
 	if !ok {
@@ -363,6 +463,23 @@ func (m *Manager) rollingUpgradeExec(task *model.Task) error {
 		logger.Error(fmt.Sprintf("component get upgrade info error:%s", err.Error()), event.GetCallbackLoggerOption())
 		return nil
 	}
+	strategy, err := controller.ParseReleaseStrategy(body.Strategy)
+	if err != nil {
+		logger.Error(fmt.Sprintf("component release strategy is invalid:%s", err.Error()), event.GetCallbackLoggerOption())
+		event.GetManager().ReleaseLogger(logger)
+		return nil
+	}
+	if strategy != nil {
+		//run the new version alongside the old one and shift the traffic progressively
+		if err := m.controllerManager.StartProgressiveUpgrade(strategy, *newAppService); err != nil {
+			logrus.Errorf("component run progressive upgrade controller failure:%s", err.Error())
+			logger.Info("component run progressive upgrade controller failure", event.GetCallbackLoggerOption())
+			event.GetManager().ReleaseLogger(logger)
+			return fmt.Errorf("component upgrade failure")
+		}
+		logrus.Infof("service(%s) %s working is running.", body.ServiceID, strategy.Mode)
+		return nil
+	}
 	//if service already deploy,upgrade it:
 	err = m.controllerManager.StartController(controller.TypeUpgradeController, *newAppService)
 	if err != nil {
@@ -0,0 +1,259 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gridworkz/kato/event"
+	"github.com/gridworkz/kato/worker/discover/model"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+//the time of waiting the claims resized
+var expandVolumeTimeout = 10 * time.Minute
+
+//expandVolumeExec grows the claims of the volume, the resize is tracked in the background
+func (m *Manager) expandVolumeExec(task *model.Task) error {
+	body, ok := task.Body.(model.ExpandVolumeTaskBody)
+	if !ok {
+		logrus.Errorf("expand_volume body convert to taskbody error")
+		return fmt.Errorf("expand_volume body convert to taskbody error")
+	}
+	logger := event.GetManager().GetLogger(body.EventID)
+	go func() {
+		defer event.GetManager().ReleaseLogger(logger)
+		if err := m.expandVolume(body, logger); err != nil {
+			logrus.Errorf("expand volume %s of component %s: %v", body.VolumeName, body.ServiceID, err)
+			logger.Error(fmt.Sprintf("expand volume %s failure: %v", body.VolumeName, err), event.GetCallbackLoggerOption())
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) expandVolume(body model.ExpandVolumeTaskBody, logger event.Logger) error {
+	capacity, err := resource.ParseQuantity(fmt.Sprintf("%dGi", body.Capacity))
+	if err != nil {
+		return fmt.Errorf("parse capacity: %v", err)
+	}
+	claimName := fmt.Sprintf("manual%d", body.VolumeID)
+	logger.Info(fmt.Sprintf("start expanding volume %s to %s", body.VolumeName, capacity.String()), event.GetLoggerOption("starting"))
+
+	if appService := m.store.GetAppService(body.ServiceID); appService != nil {
+		if statefulset := appService.GetStatefulSet(); statefulset != nil {
+			if err := m.expandClaimTemplate(statefulset.Namespace, statefulset.Name, claimName, capacity, logger); err != nil {
+				return err
+			}
+		}
+	}
+
+	claims, err := m.cfg.KubeClient.CoreV1().PersistentVolumeClaims(body.TenantID).List(context.Background(), metav1.ListOptions{
+		LabelSelector: "service_id=" + body.ServiceID,
+	})
+	if err != nil {
+		return fmt.Errorf("list claims: %v", err)
+	}
+	var pending []string
+	for _, claim := range claims.Items {
+		if !isClaimOfVolume(claim.Name, claimName) {
+			continue
+		}
+		request := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		if request.Cmp(capacity) >= 0 {
+			logger.Info(fmt.Sprintf("claim %s is %s already", claim.Name, request.String()), event.GetLoggerOption("running"))
+			continue
+		}
+		patch := fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":"%s"}}}}`, capacity.String())
+		if _, err := m.cfg.KubeClient.CoreV1().PersistentVolumeClaims(claim.Namespace).Patch(context.Background(), claim.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("patch claim %s: %v", claim.Name, err)
+		}
+		logger.Info(fmt.Sprintf("claim %s is requested to grow from %s to %s", claim.Name, request.String(), capacity.String()), event.GetLoggerOption("running"))
+		pending = append(pending, claim.Name)
+	}
+	if len(pending) == 0 {
+		logger.Info(fmt.Sprintf("expand volume %s success", body.VolumeName), event.GetLastLoggerOption())
+		return nil
+	}
+	return m.waitClaimsResized(body.TenantID, pending, capacity, logger)
+}
+
+//isClaimOfVolume returns whether the claim is created for the volume, directly or by the statefulset
+func isClaimOfVolume(name, claimName string) bool {
+	return name == claimName || strings.HasPrefix(name, claimName+"-")
+}
+
+//expandClaimTemplate updates the claim template of the statefulset, so that the new pods request the capacity.
+//The templates can not be updated, the statefulset is recreated and the pods are kept running.
+func (m *Manager) expandClaimTemplate(namespace, name, claimName string, capacity resource.Quantity, logger event.Logger) error {
+	client := m.cfg.KubeClient.AppsV1().StatefulSets(namespace)
+	statefulset, err := client.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get statefulset: %v", err)
+	}
+	if !setClaimTemplateCapacity(statefulset, claimName, capacity) {
+		return nil
+	}
+	logger.Info("recreate the statefulset to update the claim template, the pods keep running", event.GetLoggerOption("running"))
+	orphan := metav1.DeletePropagationOrphan
+	if err := client.Delete(context.Background(), name, metav1.DeleteOptions{PropagationPolicy: &orphan}); err != nil {
+		return fmt.Errorf("delete statefulset: %v", err)
+	}
+	var gone bool
+	for i := 0; i < 30; i++ {
+		if _, err := client.Get(context.Background(), name, metav1.GetOptions{}); k8sErrors.IsNotFound(err) {
+			gone = true
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if !gone {
+		return fmt.Errorf("statefulset %s is not deleted in 30 seconds", name)
+	}
+	statefulset.ResourceVersion = ""
+	statefulset.UID = ""
+	statefulset.CreationTimestamp = metav1.Time{}
+	statefulset.ManagedFields = nil
+	statefulset.Status = appsv1.StatefulSetStatus{}
+	var lastErr error
+	for i := 0; i < 3; i++ {
+		if _, lastErr = client.Create(context.Background(), statefulset, metav1.CreateOptions{}); lastErr == nil || k8sErrors.IsAlreadyExists(lastErr) {
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("recreate statefulset: %v", lastErr)
+}
+
+//setClaimTemplateCapacity sets the capacity of the claim template, it returns false if it is large enough
+func setClaimTemplateCapacity(statefulset *appsv1.StatefulSet, claimName string, capacity resource.Quantity) bool {
+	for i := range statefulset.Spec.VolumeClaimTemplates {
+		template := &statefulset.Spec.VolumeClaimTemplates[i]
+		if template.Name != claimName {
+			continue
+		}
+		request := template.Spec.Resources.Requests[corev1.ResourceStorage]
+		if request.Cmp(capacity) >= 0 {
+			return false
+		}
+		if template.Spec.Resources.Requests == nil {
+			template.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		template.Spec.Resources.Requests[corev1.ResourceStorage] = capacity
+		return true
+	}
+	return false
+}
+
+//claimResizeStatus returns whether the claim is resized, or the status of resizing
+func claimResizeStatus(claim *corev1.PersistentVolumeClaim, capacity resource.Quantity) (bool, string) {
+	for _, condition := range claim.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.PersistentVolumeClaimResizing:
+			return false, "the volume is resizing"
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return false, "waiting for the file system resized on the node"
+		}
+	}
+	current := claim.Status.Capacity[corev1.ResourceStorage]
+	if current.Cmp(capacity) >= 0 {
+		return true, ""
+	}
+	return false, "waiting for the volume resized"
+}
+
+func (m *Manager) waitClaimsResized(namespace string, claims []string, capacity resource.Quantity, logger event.Logger) error {
+	start := time.Now()
+	status := make(map[string]string, len(claims))
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		case <-ticker.C:
+		}
+		var pending []string
+		for _, name := range claims {
+			claim, err := m.cfg.KubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("get claim %s: %v", name, err)
+			}
+			if reason := m.claimResizeFailure(claim, start); reason != "" {
+				return fmt.Errorf("resize claim %s: %s", name, reason)
+			}
+			done, msg := claimResizeStatus(claim, capacity)
+			if done {
+				logger.Info(fmt.Sprintf("claim %s is resized to %s", name, capacity.String()), event.GetLoggerOption("running"))
+				continue
+			}
+			if status[name] != msg {
+				status[name] = msg
+				logger.Info(fmt.Sprintf("claim %s: %s", name, msg), event.GetLoggerOption("running"))
+			}
+			pending = append(pending, name)
+		}
+		if len(pending) == 0 {
+			logger.Info("expand volume success", event.GetLastLoggerOption())
+			return nil
+		}
+		claims = pending
+		if time.Since(start) < expandVolumeTimeout {
+			continue
+		}
+		for _, name := range pending {
+			if status[name] != "waiting for the file system resized on the node" {
+				return fmt.Errorf("claims %s are not resized in %s", strings.Join(pending, ","), expandVolumeTimeout)
+			}
+		}
+		// the file system is resized when a pod mounts the volume
+		logger.Info("the volume is resized, the file system is resized when the pods are restarted", event.GetLastLoggerOption())
+		return nil
+	}
+}
+
+//claimResizeFailure returns the message of the resize failure event of the claim since the time
+func (m *Manager) claimResizeFailure(claim *corev1.PersistentVolumeClaim, since time.Time) string {
+	events, err := m.cfg.KubeClient.CoreV1().Events(claim.Namespace).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=" + claim.Name + ",reason=VolumeResizeFailed",
+	})
+	if err != nil {
+		logrus.Warningf("list events of claim %s: %v", claim.Name, err)
+		return ""
+	}
+	for _, ev := range events.Items {
+		if ev.LastTimestamp.Time.After(since) {
+			return ev.Message
+		}
+	}
+	return ""
+}
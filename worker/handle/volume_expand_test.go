@@ -0,0 +1,86 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handle
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestIsClaimOfVolume(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"manual12", true},
+		{"manual12-gr2a2e1b-0", true},
+		{"manual123-gr2a2e1b-0", false},
+		{"manual1", false},
+	}
+	for _, tc := range tests {
+		if got := isClaimOfVolume(tc.name, "manual12"); got != tc.want {
+			t.Errorf("claim %s: want %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSetClaimTemplateCapacity(t *testing.T) {
+	statefulset := &appsv1.StatefulSet{}
+	statefulset.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+		{Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		}}},
+	}
+	statefulset.Spec.VolumeClaimTemplates[0].Name = "manual12"
+	if setClaimTemplateCapacity(statefulset, "manual13", resource.MustParse("20Gi")) {
+		t.Error("the template of another volume is changed")
+	}
+	if setClaimTemplateCapacity(statefulset, "manual12", resource.MustParse("5Gi")) {
+		t.Error("the template is shrunk")
+	}
+	if !setClaimTemplateCapacity(statefulset, "manual12", resource.MustParse("20Gi")) {
+		t.Fatal("the template is not changed")
+	}
+	request := statefulset.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	if request.String() != "20Gi" {
+		t.Errorf("want 20Gi, got %s", request.String())
+	}
+}
+
+func TestClaimResizeStatus(t *testing.T) {
+	capacity := resource.MustParse("20Gi")
+	claim := &corev1.PersistentVolumeClaim{}
+	claim.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	if done, _ := claimResizeStatus(claim, capacity); done {
+		t.Error("want not resized")
+	}
+	claim.Status.Capacity[corev1.ResourceStorage] = capacity
+	claim.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	if done, msg := claimResizeStatus(claim, capacity); done || msg == "" {
+		t.Error("want waiting for the file system resize")
+	}
+	claim.Status.Conditions = nil
+	if done, _ := claimResizeStatus(claim, capacity); !done {
+		t.Error("want resized")
+	}
+}
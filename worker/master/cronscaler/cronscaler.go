@@ -0,0 +1,194 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cronscaler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/mq/client"
+	"github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/util/cron"
+	"github.com/gridworkz/kato/worker/discover/model"
+	"github.com/sirupsen/logrus"
+)
+
+//CronScaler scales the components to the replicas of their autoscaler schedules.
+//It should only run on the leader of the workers so that each schedule fires once.
+type CronScaler struct {
+	dbmanager db.Manager
+	mqClient  client.MQClient
+	interval  time.Duration
+}
+
+//New create a cron scaler
+func New(dbmanager db.Manager, mqClient client.MQClient) *CronScaler {
+	return &CronScaler{
+		dbmanager: dbmanager,
+		mqClient:  mqClient,
+		interval:  time.Second * 20,
+	}
+}
+
+//Run checks the schedules until the context is done. The schedules missed before
+//running are not fired.
+func (c *CronScaler) Run(ctx context.Context) {
+	logrus.Info("cron scaler running")
+	lastCheck := time.Now()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("cron scaler stopped")
+			return
+		case now := <-ticker.C:
+			schedules, err := c.dbmanager.TenantServiceAutoscalerSchedulesDao().ListEnableOnes()
+			if err != nil {
+				logrus.Errorf("list autoscaler schedules: %v", err)
+				continue
+			}
+			for _, schedule := range dueSchedules(schedules, lastCheck, now) {
+				c.scale(schedule)
+			}
+			lastCheck = now
+		}
+	}
+}
+
+//dueSchedules returns the schedules that fire in (from, to]. If several schedules of
+//a component fire at the same time, only the latest one is returned.
+func dueSchedules(schedules []*dbmodel.TenantServiceAutoscalerSchedules, from, to time.Time) []*dbmodel.TenantServiceAutoscalerSchedules {
+	type due struct {
+		schedule *dbmodel.TenantServiceAutoscalerSchedules
+		at       time.Time
+	}
+	dues := make(map[string]*due)
+	var order []string
+	for _, schedule := range schedules {
+		loc := time.UTC
+		if schedule.TimeZone != "" {
+			l, err := time.LoadLocation(schedule.TimeZone)
+			if err != nil {
+				logrus.Warningf("schedule %s: unknown time zone %s", schedule.ScheduleID, schedule.TimeZone)
+				continue
+			}
+			loc = l
+		}
+		s, err := cron.Parse(schedule.Schedule)
+		if err != nil {
+			logrus.Warningf("schedule %s: %v", schedule.ScheduleID, err)
+			continue
+		}
+		next := s.Next(from.In(loc))
+		if next.IsZero() || next.After(to) {
+			continue
+		}
+		d, ok := dues[schedule.ServiceID]
+		if !ok {
+			order = append(order, schedule.ServiceID)
+			dues[schedule.ServiceID] = &due{schedule: schedule, at: next}
+			continue
+		}
+		if !next.Before(d.at) {
+			d.schedule, d.at = schedule, next
+		}
+	}
+	var re []*dbmodel.TenantServiceAutoscalerSchedules
+	for _, serviceID := range order {
+		re = append(re, dues[serviceID].schedule)
+	}
+	return re
+}
+
+//boundReplicas keeps the replicas between the min and max replicas of the enabled hpa
+//rules, otherwise the hpa scales the component back immediately.
+func boundReplicas(replicas int, rules []*dbmodel.TenantServiceAutoscalerRules) int {
+	for _, rule := range rules {
+		if replicas < rule.MinReplicas {
+			replicas = rule.MinReplicas
+		}
+		if rule.MaxReplicas > 0 && replicas > rule.MaxReplicas {
+			replicas = rule.MaxReplicas
+		}
+	}
+	return replicas
+}
+
+func (c *CronScaler) scale(schedule *dbmodel.TenantServiceAutoscalerSchedules) {
+	service, err := c.dbmanager.TenantServiceDao().GetServiceByID(schedule.ServiceID)
+	if err != nil {
+		logrus.Errorf("schedule %s: get component %s: %v", schedule.ScheduleID, schedule.ServiceID, err)
+		return
+	}
+	rules, err := c.dbmanager.TenantServceAutoscalerRulesDao().ListEnableOnesByServiceID(service.ServiceID)
+	if err != nil {
+		logrus.Errorf("schedule %s: list autoscaler rules: %v", schedule.ScheduleID, err)
+		return
+	}
+	replicas := boundReplicas(schedule.Replicas, rules)
+	if service.Replicas == replicas {
+		logrus.Debugf("schedule %s: component %s already has %d replicas", schedule.ScheduleID, service.ServiceID, replicas)
+		return
+	}
+
+	event := &dbmodel.ServiceEvent{
+		EventID:   util.NewUUID(),
+		TenantID:  service.TenantID,
+		ServiceID: service.ServiceID,
+		Target:    dbmodel.TargetTypeService,
+		TargetID:  service.ServiceID,
+		UserName:  "system",
+		StartTime: time.Now().Format(time.RFC3339),
+		SynType:   dbmodel.ASYNEVENTTYPE,
+		OptType:   "horizontal-service",
+	}
+	if err := c.dbmanager.ServiceEventDao().AddModel(event); err != nil {
+		logrus.Errorf("schedule %s: create event: %v", schedule.ScheduleID, err)
+		return
+	}
+	oldReplicas := service.Replicas
+	service.Replicas = replicas
+	if err := c.dbmanager.TenantServiceDao().UpdateModel(service); err != nil {
+		logrus.Errorf("schedule %s: update replicas of component %s: %v", schedule.ScheduleID, service.ServiceID, err)
+		return
+	}
+	err = c.mqClient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "horizontal_scaling",
+		TaskBody: model.HorizontalScalingTaskBody{
+			TenantID:   service.TenantID,
+			ServiceID:  service.ServiceID,
+			Replicas:   int32(replicas),
+			EventID:    event.EventID,
+			Username:   "system",
+			ScheduleID: schedule.ScheduleID,
+		},
+		Topic: client.WorkerTopic,
+	})
+	if err != nil {
+		logrus.Errorf("schedule %s: send horizontal_scaling task: %v", schedule.ScheduleID, err)
+		service.Replicas = oldReplicas
+		if err := c.dbmanager.TenantServiceDao().UpdateModel(service); err != nil {
+			logrus.Warningf("schedule %s: roll back replicas: %v", schedule.ScheduleID, err)
+		}
+		return
+	}
+	logrus.Infof("schedule %s: scale component %s from %d to %d replicas", schedule.ScheduleID, service.ServiceID, oldReplicas, replicas)
+}
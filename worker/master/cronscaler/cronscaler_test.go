@@ -0,0 +1,82 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cronscaler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
+func TestDueSchedules(t *testing.T) {
+	schedules := []*dbmodel.TenantServiceAutoscalerSchedules{
+		{ScheduleID: "up", ServiceID: "a", Schedule: "0 8 * * 1-5", TimeZone: "Asia/Shanghai", Replicas: 10},
+		{ScheduleID: "down", ServiceID: "a", Schedule: "0 20 * * 1-5", TimeZone: "Asia/Shanghai", Replicas: 2},
+		{ScheduleID: "half", ServiceID: "b", Schedule: "30 * * * *", Replicas: 3},
+		{ScheduleID: "invalid", ServiceID: "c", Schedule: "0 25 * * *", Replicas: 3},
+	}
+	shanghai := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		from   time.Time
+		expect []string
+	}{
+		// monday
+		{from: time.Date(2021, 3, 8, 7, 59, 50, 0, shanghai), expect: []string{"up"}},
+		{from: time.Date(2021, 3, 8, 19, 59, 50, 0, shanghai), expect: []string{"down"}},
+		// saturday
+		{from: time.Date(2021, 3, 13, 7, 59, 50, 0, shanghai), expect: nil},
+		{from: time.Date(2021, 3, 13, 10, 29, 50, 0, time.UTC), expect: []string{"half"}},
+		{from: time.Date(2021, 3, 13, 10, 30, 0, 0, time.UTC), expect: nil},
+	}
+	for _, tc := range tests {
+		due := dueSchedules(schedules, tc.from, tc.from.Add(time.Second*20))
+		var got []string
+		for _, schedule := range due {
+			got = append(got, schedule.ScheduleID)
+		}
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("from %s: expect %v, got %v", tc.from, tc.expect, got)
+		}
+	}
+}
+
+func TestDueSchedulesLatestWins(t *testing.T) {
+	schedules := []*dbmodel.TenantServiceAutoscalerSchedules{
+		{ScheduleID: "first", ServiceID: "a", Schedule: "0 8 * * *", Replicas: 10},
+		{ScheduleID: "second", ServiceID: "a", Schedule: "1 8 * * *", Replicas: 2},
+	}
+	from := time.Date(2021, 3, 8, 7, 59, 0, 0, time.UTC)
+	due := dueSchedules(schedules, from, from.Add(time.Minute*3))
+	if len(due) != 1 || due[0].ScheduleID != "second" {
+		t.Fatalf("unexpected due schedules %v", due)
+	}
+}
+
+func TestBoundReplicas(t *testing.T) {
+	rules := []*dbmodel.TenantServiceAutoscalerRules{{MinReplicas: 2, MaxReplicas: 8}}
+	for replicas, expect := range map[int]int{0: 2, 5: 5, 10: 8} {
+		if got := boundReplicas(replicas, rules); got != expect {
+			t.Errorf("replicas %d: expect %d, got %d", replicas, expect, got)
+		}
+	}
+	if got := boundReplicas(0, nil); got != 0 {
+		t.Errorf("expect 0 without rules, got %d", got)
+	}
+}
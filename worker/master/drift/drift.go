@@ -0,0 +1,253 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/worker/appm/store"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+//Drift is a difference between the database and the live kubernetes objects of a component
+type Drift struct {
+	TenantID     string `json:"tenant_id"`
+	AppID        string `json:"app_id"`
+	ServiceID    string `json:"service_id"`
+	ServiceAlias string `json:"service_alias"`
+	Field        string `json:"field"`
+	Expected     string `json:"expected"`
+	Actual       string `json:"actual"`
+}
+
+//Report is the result of the last drift check
+type Report struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Components int       `json:"components"`
+	Drifts     []*Drift  `json:"drifts"`
+}
+
+//Detector periodically compares the components in the database with their live workloads in the store.
+//It should only run on the leader of the workers.
+type Detector struct {
+	dbmanager db.Manager
+	store     store.Storer
+	interval  time.Duration
+
+	lock   sync.RWMutex
+	report *Report
+}
+
+//New create a drift detector
+func New(dbmanager db.Manager, store store.Storer, interval time.Duration) *Detector {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &Detector{
+		dbmanager: dbmanager,
+		store:     store,
+		interval:  interval,
+	}
+}
+
+//Run checks the running components until the context is done
+func (d *Detector) Run(ctx context.Context) {
+	logrus.Info("drift detector running")
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("drift detector stopped")
+			return
+		case now := <-ticker.C:
+			report := d.detect(now)
+			for _, drift := range report.Drifts {
+				logrus.Warningf("component %s(%s) drifted on %s: expected %s, actual %s",
+					drift.ServiceAlias, drift.ServiceID, drift.Field, drift.Expected, drift.Actual)
+			}
+			d.lock.Lock()
+			d.report = report
+			d.lock.Unlock()
+		}
+	}
+}
+
+//Report returns the last drift report, nil before the first check
+func (d *Detector) Report() *Report {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.report
+}
+
+func (d *Detector) detect(now time.Time) *Report {
+	report := &Report{CheckedAt: now}
+	for _, app := range d.store.GetAllAppServices() {
+		if app.IsCustomComponent() || app.IsThirdComponent() || app.IsClosed() {
+			continue
+		}
+		var replicas *int32
+		var podSpec *corev1.PodSpec
+		var version string
+		if deploy := app.GetDeployment(); deploy != nil {
+			replicas, podSpec, version = deploy.Spec.Replicas, &deploy.Spec.Template.Spec, deploy.Spec.Template.Labels["version"]
+		} else if sts := app.GetStatefulSet(); sts != nil {
+			replicas, podSpec, version = sts.Spec.Replicas, &sts.Spec.Template.Spec, sts.Spec.Template.Labels["version"]
+		} else {
+			continue
+		}
+		svc, err := d.dbmanager.TenantServiceDao().GetServiceByID(app.ServiceID)
+		if err != nil {
+			logrus.Debugf("get component %s: %v", app.ServiceID, err)
+			continue
+		}
+		ports, err := d.containerPorts(app.ServiceID)
+		if err != nil {
+			logrus.Errorf("get ports of component %s: %v", app.ServiceID, err)
+			continue
+		}
+		scaled, err := d.autoscaled(app)
+		if err != nil {
+			logrus.Errorf("get autoscaler schedules of component %s: %v", app.ServiceID, err)
+			continue
+		}
+		if scaled {
+			replicas = nil
+		}
+		report.Components++
+		for _, drift := range componentDrifts(svc, ports, replicas, podSpec, version) {
+			drift.AppID = app.AppID
+			report.Drifts = append(report.Drifts, drift)
+		}
+	}
+	return report
+}
+
+//autoscaled whether the replicas are owned by the hpa, the cron schedules or the idle policy
+//instead of the replicas in the database
+func (d *Detector) autoscaled(app *v1.AppService) (bool, error) {
+	if app.IsIdle() || len(app.GetHPAs()) > 0 {
+		return true, nil
+	}
+	schedules, err := d.dbmanager.TenantServiceAutoscalerSchedulesDao().ListByServiceID(app.ServiceID)
+	if err != nil {
+		return false, err
+	}
+	for _, schedule := range schedules {
+		if schedule.Enable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//containerPorts returns the container ports the workload should expose,
+//the ports of components with inbound net plugins are mapped to the plugin ports like the conversion does
+func (d *Detector) containerPorts(serviceID string) ([]int, error) {
+	ports, err := d.dbmanager.TenantServicesPortDao().GetPortsByServiceID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	mapping := make(map[int]int)
+	for _, pluginModel := range []string{dbmodel.InBoundNetPlugin, dbmodel.InBoundAndOutBoundNetPlugin} {
+		ok, err := d.dbmanager.TenantServicePluginRelationDao().CheckSomeModelPluginByServiceID(serviceID, pluginModel)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		pluginPorts, err := d.dbmanager.TenantServicesStreamPluginPortDao().GetPluginMappingPorts(serviceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, pport := range pluginPorts {
+			mapping[pport.ContainerPort] = pport.PluginPort
+		}
+		break
+	}
+	var result []int
+	for _, port := range ports {
+		if pluginPort, ok := mapping[port.ContainerPort]; ok {
+			result = append(result, pluginPort)
+			continue
+		}
+		result = append(result, port.ContainerPort)
+	}
+	return result, nil
+}
+
+//componentDrifts compares the replicas, deploy version, memory limit and container ports of a workload with the database
+func componentDrifts(svc *dbmodel.TenantServices, ports []int, replicas *int32, podSpec *corev1.PodSpec, version string) []*Drift {
+	var drifts []*Drift
+	add := func(field, expected, actual string) {
+		if expected == actual {
+			return
+		}
+		drifts = append(drifts, &Drift{
+			TenantID:     svc.TenantID,
+			AppID:        svc.AppID,
+			ServiceID:    svc.ServiceID,
+			ServiceAlias: svc.ServiceAlias,
+			Field:        field,
+			Expected:     expected,
+			Actual:       actual,
+		})
+	}
+	if replicas != nil {
+		add("replicas", fmt.Sprint(svc.Replicas), fmt.Sprint(*replicas))
+	}
+	if version != "" && svc.DeployVersion != "" {
+		add("deploy_version", svc.DeployVersion, version)
+	}
+	var container *corev1.Container
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == svc.ServiceID {
+			container = &podSpec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return drifts
+	}
+	if svc.ContainerMemory > 0 {
+		if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			add("container_memory", fmt.Sprint(svc.ContainerMemory), fmt.Sprint(limit.Value()/1024/1024))
+		}
+	}
+	var expected, actual []string
+	for _, port := range ports {
+		expected = append(expected, fmt.Sprint(port))
+	}
+	for _, port := range container.Ports {
+		actual = append(actual, fmt.Sprint(port.ContainerPort))
+	}
+	sort.Strings(expected)
+	sort.Strings(actual)
+	add("ports", strings.Join(expected, ","), strings.Join(actual, ","))
+	return drifts
+}
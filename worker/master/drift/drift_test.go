@@ -0,0 +1,64 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package drift
+
+import (
+	"testing"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestComponentDrifts(t *testing.T) {
+	svc := &dbmodel.TenantServices{ServiceID: "s1", Replicas: 2, ContainerMemory: 512, DeployVersion: "v2"}
+	podSpec := func(memory string, ports ...int32) *corev1.PodSpec {
+		container := corev1.Container{
+			Name: "s1",
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+			},
+		}
+		for _, port := range ports {
+			container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: port})
+		}
+		return &corev1.PodSpec{Containers: []corev1.Container{container}}
+	}
+	two, three := int32(2), int32(3)
+
+	if drifts := componentDrifts(svc, []int{80, 443}, &two, podSpec("512Mi", 443, 80), "v2"); len(drifts) != 0 {
+		t.Fatalf("want no drifts, got %d: %+v", len(drifts), drifts[0])
+	}
+
+	drifts := componentDrifts(svc, []int{80, 443}, &three, podSpec("1Gi", 80), "v1")
+	want := map[string][2]string{
+		"replicas":         {"2", "3"},
+		"deploy_version":   {"v2", "v1"},
+		"container_memory": {"512", "1024"},
+		"ports":            {"443,80", "80"},
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("want %d drifts, got %d", len(want), len(drifts))
+	}
+	for _, drift := range drifts {
+		w, ok := want[drift.Field]
+		if !ok || drift.Expected != w[0] || drift.Actual != w[1] {
+			t.Errorf("%s: want %v, got %s -> %s", drift.Field, w, drift.Expected, drift.Actual)
+		}
+	}
+}
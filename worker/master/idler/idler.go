@@ -0,0 +1,144 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package idler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gridworkz/kato/api/client/prometheus"
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/mq/client"
+	"github.com/gridworkz/kato/worker/appm/store"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
+	"github.com/gridworkz/kato/worker/discover/model"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+//Idler scales the components without gateway traffic to 0 according to their idle policies.
+//It should only run on the leader of the workers.
+type Idler struct {
+	dbmanager     db.Manager
+	store         store.Storer
+	prometheusCli prometheus.Interface
+	mqClient      client.MQClient
+	interval      time.Duration
+}
+
+//New create a idler
+func New(dbmanager db.Manager, store store.Storer, prometheusCli prometheus.Interface, mqClient client.MQClient) *Idler {
+	return &Idler{
+		dbmanager:     dbmanager,
+		store:         store,
+		prometheusCli: prometheusCli,
+		mqClient:      mqClient,
+		interval:      time.Minute,
+	}
+}
+
+//Run checks the components with enabled idle policies until the context is done
+func (i *Idler) Run(ctx context.Context) {
+	logrus.Info("idler running")
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("idler stopped")
+			return
+		case now := <-ticker.C:
+			policies, err := i.dbmanager.TenantServiceIdlePolicyDao().ListEnableOnes()
+			if err != nil {
+				logrus.Errorf("list idle policies: %v", err)
+				continue
+			}
+			for _, policy := range policies {
+				i.check(policy, now)
+			}
+		}
+	}
+}
+
+func (i *Idler) check(policy *dbmodel.TenantServiceIdlePolicy, now time.Time) {
+	if policy.IdleMinutes <= 0 {
+		return
+	}
+	app := i.store.GetAppService(policy.ServiceID)
+	if app == nil || app.IsIdle() || app.GetServiceStatus() != v1.RUNNING {
+		return
+	}
+	window := time.Duration(policy.IdleMinutes) * time.Minute
+	if !runningFor(app.GetPods(false), window, now) {
+		// the pods started in the window, the requests before are not counted
+		return
+	}
+	requests, ok := i.requests(policy.ServiceID, policy.IdleMinutes, now)
+	if !ok || requests > 0 {
+		return
+	}
+	err := i.mqClient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "idle",
+		TaskBody: model.IdleTaskBody{
+			TenantID:    app.TenantID,
+			ServiceID:   policy.ServiceID,
+			IdleMinutes: policy.IdleMinutes,
+		},
+		Topic: client.WorkerTopic,
+	})
+	if err != nil {
+		logrus.Errorf("send idle task of component %s: %v", policy.ServiceID, err)
+		return
+	}
+	logrus.Infof("component %s has no requests in the last %d minutes, scale it to 0", policy.ServiceID, policy.IdleMinutes)
+}
+
+//runningFor whether all pods have been started for the duration
+func runningFor(pods []*corev1.Pod, d time.Duration, now time.Time) bool {
+	if len(pods) == 0 {
+		return false
+	}
+	for _, pod := range pods {
+		if pod.Status.StartTime == nil || now.Sub(pod.Status.StartTime.Time) < d {
+			return false
+		}
+	}
+	return true
+}
+
+//requests the number of gateway requests of the component in the last minutes
+func (i *Idler) requests(serviceID string, minutes int, now time.Time) (float64, bool) {
+	expr := fmt.Sprintf(`sum(increase(gateway_requests{service_id="%s"}[%dm]))`, serviceID, minutes)
+	metric := i.prometheusCli.GetMetric(expr, now)
+	if metric.Error != "" {
+		logrus.Warningf("query requests of component %s failure %s", serviceID, metric.Error)
+		return 0, false
+	}
+	if len(metric.MetricValues) == 0 || metric.MetricValues[0].Sample == nil {
+		// no series, the component has never been requested through the gateway
+		return 0, true
+	}
+	requests := metric.MetricValues[0].Sample.Value()
+	if math.IsNaN(requests) || math.IsInf(requests, 0) {
+		return 0, false
+	}
+	return requests, true
+}
@@ -24,9 +24,11 @@ import (
 	"strings"
 	"time"
 
+	promclient "github.com/gridworkz/kato/api/client/prometheus"
 	"github.com/gridworkz/kato/cmd/worker/option"
 	"github.com/gridworkz/kato/db"
 	"github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/mq/client"
 	"github.com/gridworkz/kato/pkg/common"
 	"github.com/gridworkz/kato/pkg/generated/clientset/versioned"
 	"github.com/gridworkz/kato/util/leader"
@@ -34,7 +36,11 @@ import (
 	mcontroller "github.com/gridworkz/kato/worker/master/controller"
 	"github.com/gridworkz/kato/worker/master/controller/helmapp"
 	"github.com/gridworkz/kato/worker/master/controller/thirdcomponent"
+	"github.com/gridworkz/kato/worker/master/cronscaler"
+	"github.com/gridworkz/kato/worker/master/drift"
+	"github.com/gridworkz/kato/worker/master/idler"
 	"github.com/gridworkz/kato/worker/master/podevent"
+	"github.com/gridworkz/kato/worker/master/volumebackup"
 	"github.com/gridworkz/kato/worker/master/volumes/provider"
 	"github.com/gridworkz/kato/worker/master/volumes/provider/lib/controller"
 	"github.com/gridworkz/kato/worker/master/volumes/statistical"
@@ -58,6 +64,8 @@ type Controller struct {
 	memoryUse           *prometheus.GaugeVec
 	cpuUse              *prometheus.GaugeVec
 	fsUse               *prometheus.GaugeVec
+	volumeUse           *prometheus.GaugeVec
+	volumeCapacity      *prometheus.GaugeVec
 	diskCache           *statistical.DiskCache
 	namespaceMemRequest *prometheus.GaugeVec
 	namespaceMemLimit   *prometheus.GaugeVec
@@ -65,6 +73,10 @@ type Controller struct {
 	namespaceCPULimit   *prometheus.GaugeVec
 	pc                  *controller.ProvisionController
 	helmAppController   *helmapp.Controller
+	cronScaler          *cronscaler.CronScaler
+	idler               *idler.Idler
+	volumeBackuper      *volumebackup.Scheduler
+	driftDetector       *drift.Detector
 	controllers         []mcontroller.Controller
 	isLeader            bool
 
@@ -81,7 +93,7 @@ type Controller struct {
 }
 
 //NewMasterController new master controller
-func NewMasterController(conf option.Config, store store.Storer, kubeClient kubernetes.Interface, katoClient versioned.Interface, restConfig *rest.Config) (*Controller, error) {
+func NewMasterController(conf option.Config, store store.Storer, kubeClient kubernetes.Interface, katoClient versioned.Interface, restConfig *rest.Config, mqClient client.MQClient, prometheusCli promclient.Interface) (*Controller, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// The controller needs to know what the server version is because out-of-tree
@@ -115,6 +127,10 @@ func NewMasterController(conf option.Config, store store.Storer, kubeClient kube
 		restConfig:        restConfig,
 		pc:                pc,
 		helmAppController: helmAppController,
+		cronScaler:        cronscaler.New(db.GetManager(), mqClient),
+		idler:             idler.New(db.GetManager(), store, prometheusCli, mqClient),
+		volumeBackuper:    volumebackup.New(db.GetManager(), mqClient),
+		driftDetector:     drift.New(db.GetManager(), store, conf.DriftCheckInterval),
 		store:             store,
 		stopCh:            stopCh,
 		cancel:            cancel,
@@ -135,6 +151,16 @@ func NewMasterController(conf option.Config, store store.Storer, kubeClient kube
 			Name:      "appfs",
 			Help:      "tenant service fs used.",
 		}, []string{"tenant_id", "app_id", "service_id", "volume_type"}),
+		volumeUse: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "app_resource",
+			Name:      "volume_used_bytes",
+			Help:      "tenant service local volume used.",
+		}, []string{"tenant_id", "app_id", "service_id", "volume_name", "pv_name"}),
+		volumeCapacity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "app_resource",
+			Name:      "volume_capacity_bytes",
+			Help:      "tenant service local volume capacity enforced by the node, 0 if not limited.",
+		}, []string{"tenant_id", "app_id", "service_id", "volume_name", "pv_name"}),
 		namespaceMemRequest: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: "namespace_resource",
 			Name:      "memory_request",
@@ -155,7 +181,7 @@ func NewMasterController(conf option.Config, store store.Storer, kubeClient kube
 			Name:      "cpu_limit",
 			Help:      "total cpu limit in namespace",
 		}, []string{"namespace"}),
-		diskCache:       statistical.CreatDiskCache(ctx),
+		diskCache:       statistical.CreatDiskCache(ctx, kubeClient),
 		podEvent:        podevent.New(conf.KubeClient, stopCh),
 		volumeTypeEvent: sync.New(stopCh),
 		kubeClient:      kubeClient,
@@ -170,6 +196,11 @@ func (m *Controller) IsLeader() bool {
 	return m.isLeader
 }
 
+//DriftReport returns the last drift report, it is only generated on the leader
+func (m *Controller) DriftReport() *drift.Report {
+	return m.driftDetector.Report()
+}
+
 //Start start
 func (m *Controller) Start() error {
 	logrus.Debug("master controller starting")
@@ -197,6 +228,15 @@ func (m *Controller) Start() error {
 		go m.helmAppController.Start()
 		defer m.helmAppController.Stop()
 
+		// scheduled scaling of components
+		go m.cronScaler.Run(ctx)
+		// scale-to-zero of idle components
+		go m.idler.Run(ctx)
+		// scheduled backups of volume data
+		go m.volumeBackuper.Run(ctx)
+		// drift between the database and the live workloads
+		go m.driftDetector.Run(ctx)
+
 		// start controller
 		mgr, err := ctrl.NewManager(m.restConfig, ctrl.Options{
 			Scheme:           common.Scheme,
@@ -291,6 +331,18 @@ func (m *Controller) Scrape(ch chan<- prometheus.Metric, scrapeDurationDesc *pro
 			m.fsUse.WithLabelValues(key[2], key[1], key[0], string(model.ShareFileVolumeType)).Set(v)
 		}
 	}
+	m.volumeUse.Reset()
+	m.volumeCapacity.Reset()
+	localUse := make(map[[3]string]float64)
+	for _, v := range m.diskCache.GetLocalVolumes() {
+		m.volumeUse.WithLabelValues(v.TenantID, v.AppID, v.ServiceID, v.VolumeName, v.PVName).Set(float64(v.Used))
+		m.volumeCapacity.WithLabelValues(v.TenantID, v.AppID, v.ServiceID, v.VolumeName, v.PVName).Set(float64(v.Capacity))
+		// appfs is in kb
+		localUse[[3]string{v.TenantID, v.AppID, v.ServiceID}] += float64(v.Used) / 1024
+	}
+	for k, v := range localUse {
+		m.fsUse.WithLabelValues(k[0], k[1], k[2], string(model.LocalVolumeType)).Set(v)
+	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "collect.fs")
 	resources := m.store.GetTenantResourceList()
 	for _, re := range resources {
@@ -300,6 +352,8 @@ func (m *Controller) Scrape(ch chan<- prometheus.Metric, scrapeDurationDesc *pro
 		m.namespaceCPURequest.WithLabelValues(re.Namespace).Set(float64(re.CPURequest))
 	}
 	m.fsUse.Collect(ch)
+	m.volumeUse.Collect(ch)
+	m.volumeCapacity.Collect(ch)
 	m.memoryUse.Collect(ch)
 	m.cpuUse.Collect(ch)
 	m.namespaceMemLimit.Collect(ch)
@@ -0,0 +1,172 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package volumebackup
+
+import (
+	"context"
+	"time"
+
+	"github.com/gridworkz/kato/db"
+	dbmodel "github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/mq/client"
+	"github.com/gridworkz/kato/util"
+	"github.com/gridworkz/kato/util/cron"
+	"github.com/sirupsen/logrus"
+)
+
+//Scheduler creates the volume backups of the backup policies, the builder backups the data.
+//It should only run on the leader of the workers so that each backup is created once.
+type Scheduler struct {
+	dbmanager db.Manager
+	mqClient  client.MQClient
+	interval  time.Duration
+}
+
+//New create a volume backup scheduler
+func New(dbmanager db.Manager, mqClient client.MQClient) *Scheduler {
+	return &Scheduler{
+		dbmanager: dbmanager,
+		mqClient:  mqClient,
+		interval:  time.Second * 30,
+	}
+}
+
+//Run checks the backup policies until the context is done. The backups missed before
+//running are not created.
+func (s *Scheduler) Run(ctx context.Context) {
+	logrus.Info("volume backup scheduler running")
+	lastCheck := time.Now()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("volume backup scheduler stopped")
+			return
+		case now := <-ticker.C:
+			policies, err := s.dbmanager.TenantServiceVolumeBackupPolicyDao().ListEnableOnes()
+			if err != nil {
+				logrus.Errorf("list volume backup policies: %v", err)
+				continue
+			}
+			for _, policy := range duePolicies(policies, lastCheck, now) {
+				s.backup(policy)
+			}
+			lastCheck = now
+		}
+	}
+}
+
+//duePolicies returns the policies that fire in (from, to]
+func duePolicies(policies []*dbmodel.TenantServiceVolumeBackupPolicy, from, to time.Time) []*dbmodel.TenantServiceVolumeBackupPolicy {
+	var re []*dbmodel.TenantServiceVolumeBackupPolicy
+	for _, policy := range policies {
+		loc := time.UTC
+		if policy.TimeZone != "" {
+			l, err := time.LoadLocation(policy.TimeZone)
+			if err != nil {
+				logrus.Warningf("backup policy of %s/%s: unknown time zone %s", policy.ServiceID, policy.VolumeName, policy.TimeZone)
+				continue
+			}
+			loc = l
+		}
+		schedule, err := cron.Parse(policy.Schedule)
+		if err != nil {
+			logrus.Warningf("backup policy of %s/%s: %v", policy.ServiceID, policy.VolumeName, err)
+			continue
+		}
+		next := schedule.Next(from.In(loc))
+		if next.IsZero() || next.After(to) {
+			continue
+		}
+		re = append(re, policy)
+	}
+	return re
+}
+
+func (s *Scheduler) backup(policy *dbmodel.TenantServiceVolumeBackupPolicy) {
+	backups, err := s.dbmanager.TenantServiceVolumeBackupDao().ListByServiceVolume(policy.ServiceID, policy.VolumeName)
+	if err != nil {
+		logrus.Errorf("list backups of volume %s/%s: %v", policy.ServiceID, policy.VolumeName, err)
+		return
+	}
+	for _, backup := range backups {
+		if backup.Status == "starting" {
+			logrus.Warningf("backup %s of volume %s/%s is not complete, skip this one", backup.BackupID, policy.ServiceID, policy.VolumeName)
+			return
+		}
+	}
+	service, err := s.dbmanager.TenantServiceDao().GetServiceByID(policy.ServiceID)
+	if err != nil {
+		logrus.Errorf("backup policy of %s/%s: get component: %v", policy.ServiceID, policy.VolumeName, err)
+		return
+	}
+	volume, err := s.dbmanager.TenantServiceVolumeDao().GetVolumeByServiceIDAndName(policy.ServiceID, policy.VolumeName)
+	if err != nil {
+		logrus.Errorf("backup policy of %s/%s: get volume: %v", policy.ServiceID, policy.VolumeName, err)
+		return
+	}
+
+	event := &dbmodel.ServiceEvent{
+		EventID:   util.NewUUID(),
+		TenantID:  service.TenantID,
+		ServiceID: service.ServiceID,
+		Target:    dbmodel.TargetTypeService,
+		TargetID:  service.ServiceID,
+		UserName:  "system",
+		StartTime: time.Now().Format(time.RFC3339),
+		SynType:   dbmodel.ASYNEVENTTYPE,
+		OptType:   "backup-service-volume",
+	}
+	if err := s.dbmanager.ServiceEventDao().AddModel(event); err != nil {
+		logrus.Errorf("backup policy of %s/%s: create event: %v", policy.ServiceID, policy.VolumeName, err)
+		return
+	}
+	backup := &dbmodel.TenantServiceVolumeBackup{
+		BackupID:   util.NewUUID(),
+		TenantID:   service.TenantID,
+		ServiceID:  service.ServiceID,
+		VolumeName: volume.VolumeName,
+		VolumeType: volume.VolumeType,
+		Status:     "starting",
+		Scheduled:  true,
+		EventID:    event.EventID,
+	}
+	if err := s.dbmanager.TenantServiceVolumeBackupDao().AddModel(backup); err != nil {
+		logrus.Errorf("backup policy of %s/%s: create backup: %v", policy.ServiceID, policy.VolumeName, err)
+		return
+	}
+	err = s.mqClient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "backup_volume",
+		TaskBody: map[string]interface{}{
+			"backup_id": backup.BackupID,
+			"event_id":  event.EventID,
+		},
+		Topic: client.BuilderTopic,
+	})
+	if err != nil {
+		logrus.Errorf("backup policy of %s/%s: send backup_volume task: %v", policy.ServiceID, policy.VolumeName, err)
+		backup.Status = "failure"
+		backup.Message = err.Error()
+		if err := s.dbmanager.TenantServiceVolumeBackupDao().UpdateModel(backup); err != nil {
+			logrus.Warningf("update backup %s: %v", backup.BackupID, err)
+		}
+		return
+	}
+	logrus.Infof("backup policy of %s/%s: create backup %s", policy.ServiceID, policy.VolumeName, backup.BackupID)
+}
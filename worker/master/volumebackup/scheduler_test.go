@@ -0,0 +1,52 @@
+// Copyright (C) 2021 Gridworkz Co., Ltd.
+// KATO, Application Management Platform
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this 
+// software and associated documentation files (the "Software"), to deal in the Software
+// without restriction, including without limitation the rights to use, copy, modify, merge,
+// publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons 
+// to whom the Software is furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all copies or 
+// substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, 
+// INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+// PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE
+// FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package volumebackup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	dbmodel "github.com/gridworkz/kato/db/model"
+)
+
+func TestDuePolicies(t *testing.T) {
+	policies := []*dbmodel.TenantServiceVolumeBackupPolicy{
+		{ServiceID: "a", VolumeName: "daily", Schedule: "0 2 * * *", TimeZone: "Asia/Shanghai"},
+		{ServiceID: "a", VolumeName: "hourly", Schedule: "0 * * * *"},
+		{ServiceID: "b", VolumeName: "invalid", Schedule: "0 25 * * *"},
+	}
+	shanghai := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		from   time.Time
+		expect []string
+	}{
+		{from: time.Date(2021, 3, 8, 1, 59, 50, 0, shanghai), expect: []string{"daily", "hourly"}},
+		{from: time.Date(2021, 3, 8, 2, 59, 50, 0, shanghai), expect: []string{"hourly"}},
+		{from: time.Date(2021, 3, 8, 3, 0, 0, 0, shanghai), expect: nil},
+	}
+	for _, tc := range tests {
+		var got []string
+		for _, policy := range duePolicies(policies, tc.from, tc.from.Add(time.Second*30)) {
+			got = append(got, policy.VolumeName)
+		}
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("from %s: expect %v, got %v", tc.from, tc.expect, got)
+		}
+	}
+}
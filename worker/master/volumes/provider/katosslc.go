@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -148,6 +149,11 @@ func (p *katosslcProvisioner) createPath(options controller.VolumeOptions) (stri
 			"volume_name": volume.VolumeName,
 			"pod_name":    getPodNameByPVCName(options.PVC.Name),
 		}
+		// the node limits the capacity of the volume in bytes
+		if volume.VolumeCapacity > 0 {
+			storage := options.PVC.Spec.Resources.Requests[v1.ResourceStorage]
+			reqoptions["capacity"] = strconv.FormatInt(storage.Value(), 10)
+		}
 		var ip string
 		for _, address := range options.SelectedNode.Status.Addresses {
 			if address.Type == v1.NodeInternalIP {
@@ -264,10 +270,79 @@ func (p *katosslcProvisioner) Provision(options controller.VolumeOptions) (*v1.P
 // Delete removes the storage asset that was created by Provision represented
 // by the given PV.
 func (p *katosslcProvisioner) Delete(volume *v1.PersistentVolume) error {
-
+	if volume.Spec.HostPath == nil || volume.Spec.HostPath.Path == "" {
+		return nil
+	}
+	path := volume.Spec.HostPath.Path
+	hostname := getHostnameOfPV(volume)
+	if hostname == "" {
+		logrus.Warningf("[katosslcProvisioner] pv %s has no node affinity, skip deleting path %s", volume.Name, path)
+		return nil
+	}
+	ip, err := p.getNodeIP(hostname)
+	if err != nil {
+		return err
+	}
+	if ip == "" {
+		// the data are gone with the node
+		logrus.Warningf("[katosslcProvisioner] node %s of pv %s is not found, skip deleting path %s", hostname, volume.Name, path)
+		return nil
+	}
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(map[string]string{"path": path}); err != nil {
+		return fmt.Errorf("delete volume body failure %s", err.Error())
+	}
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s:6100/v2/localvolumes", ip), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request node %s to delete local volume %s: %v", hostname, path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("request node %s to delete local volume %s failure, code: %d", hostname, path, res.StatusCode)
+	}
+	logrus.Infof("delete katosslc pv %s, path %s on node %s", volume.Name, path, hostname)
 	return nil
 }
 
+//getHostnameOfPV returns the hostname in the node affinity of the pv
+func getHostnameOfPV(volume *v1.PersistentVolume) string {
+	if volume.Spec.NodeAffinity == nil || volume.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range volume.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == "kubernetes.io/hostname" && len(expression.Values) > 0 {
+				return expression.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+//getNodeIP returns the internal ip of the node, it is empty if the node is not found
+func (p *katosslcProvisioner) getNodeIP(hostname string) (string, error) {
+	nodes, err := p.kubecli.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{
+		LabelSelector: "kubernetes.io/hostname=" + hostname,
+	})
+	if err != nil {
+		return "", fmt.Errorf("list nodes: %v", err)
+	}
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if address.Type == v1.NodeInternalIP {
+				return address.Address, nil
+			}
+		}
+		return "", fmt.Errorf("node %s has no internal address", node.Name)
+	}
+	return "", nil
+}
+
 func (p *katosslcProvisioner) Name() string {
 	return p.name
 }
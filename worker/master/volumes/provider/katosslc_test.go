@@ -21,6 +21,7 @@ package provider
 import (
 	"testing"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"k8s.io/client-go/kubernetes"
@@ -51,3 +52,25 @@ func TestGetVolumeIDByPVCName(t *testing.T) {
 func TestGetPodNameByPVCName(t *testing.T) {
 	t.Log(getPodNameByPVCName("manual17-gra02c40-0"))
 }
+
+func TestGetHostnameOfPV(t *testing.T) {
+	pv := &v1.PersistentVolume{}
+	if hostname := getHostnameOfPV(pv); hostname != "" {
+		t.Errorf("want empty hostname, got %s", hostname)
+	}
+	pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+		Required: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{
+					MatchExpressions: []v1.NodeSelectorRequirement{
+						{Key: "kubernetes.io/os", Operator: v1.NodeSelectorOpIn, Values: []string{"linux"}},
+						{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"node1"}},
+					},
+				},
+			},
+		},
+	}
+	if hostname := getHostnameOfPV(pv); hostname != "node1" {
+		t.Errorf("want hostname node1, got %s", hostname)
+	}
+}
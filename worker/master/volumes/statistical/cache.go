@@ -19,34 +19,59 @@
 package statistical
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gridworkz/kato/db"
 	"github.com/gridworkz/kato/db/model"
+	"github.com/gridworkz/kato/node/localvolume"
 	"github.com/gridworkz/kato/util"
+	v1 "github.com/gridworkz/kato/worker/appm/types/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
+//VolumeUsage the usage of a local volume, in bytes
+type VolumeUsage struct {
+	TenantID   string
+	AppID      string
+	ServiceID  string
+	VolumeName string
+	PVName     string
+	Used       int64
+	// Capacity is 0 if the capacity of the volume is not limited
+	Capacity int64
+}
+
 //DiskCache disk asynchronous statistics
 type DiskCache struct {
 	cache []struct {
 		Key   string
 		Value float64
 	}
-	dbmanager db.Manager
-	ctx       context.Context
-	cancel    context.CancelFunc
+	localcache []*VolumeUsage
+	lock       sync.RWMutex
+	dbmanager  db.Manager
+	kubecli    kubernetes.Interface
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
 //CreatDiskCache creation
-func CreatDiskCache(ctx context.Context) *DiskCache {
+func CreatDiskCache(ctx context.Context, kubecli kubernetes.Interface) *DiskCache {
 	cctx, cancel := context.WithCancel(ctx)
 	return &DiskCache{
 		dbmanager: db.GetManager(),
+		kubecli:   kubecli,
 		ctx:       cctx,
 		cancel:    cancel,
 	}
@@ -55,6 +80,7 @@ func CreatDiskCache(ctx context.Context) *DiskCache {
 //Start start statistics
 func (d *DiskCache) Start() {
 	d.setcache ()
+	d.setLocalCache()
 	timer := time.NewTimer(time.Minute * 5)
 	defer timer.Stop()
 	for {
@@ -63,6 +89,7 @@ func (d *DiskCache) Start() {
 			return
 		case <-timer.C:
 			d.setcache ()
+			d.setLocalCache()
 			timer.Reset(time.Minute * 5)
 		}
 	}
@@ -113,6 +140,129 @@ func (d *DiskCache) setcache() {
 	logrus.Infof("end get all service disk size,time consum %2.f s", time.Since(start).Seconds())
 }
 
+//setLocalCache gets the usage of the local volumes from the nodes
+func (d *DiskCache) setLocalCache() {
+	start := time.Now()
+	pvs, err := d.kubecli.CoreV1().PersistentVolumes().List(d.ctx, metav1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("list persistent volumes: %v", err)
+		return
+	}
+	// the paths of the local volumes on each node
+	var nodePaths = make(map[string][]string)
+	var pvOfPath = make(map[string]*corev1.PersistentVolume)
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Spec.StorageClassName != v1.KatoStatefuleLocalStorageClass || pv.Spec.HostPath == nil {
+			continue
+		}
+		hostname := hostnameOfPV(pv)
+		if hostname == "" {
+			continue
+		}
+		key := hostname + ":" + pv.Spec.HostPath.Path
+		nodePaths[hostname] = append(nodePaths[hostname], pv.Spec.HostPath.Path)
+		pvOfPath[key] = pv
+	}
+	if len(nodePaths) == 0 {
+		d.lock.Lock()
+		d.localcache = nil
+		d.lock.Unlock()
+		return
+	}
+	nodes, err := d.kubecli.CoreV1().Nodes().List(d.ctx, metav1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("list nodes: %v", err)
+		return
+	}
+	var localcache []*VolumeUsage
+	for _, node := range nodes.Items {
+		paths, ok := nodePaths[node.Labels["kubernetes.io/hostname"]]
+		if !ok {
+			continue
+		}
+		var ip string
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				ip = address.Address
+			}
+		}
+		if ip == "" {
+			continue
+		}
+		usages, err := localVolumeUsages(ip, paths)
+		if err != nil {
+			logrus.Warningf("node: %s; get the usage of local volumes: %v", node.Name, err)
+			continue
+		}
+		for _, usage := range usages {
+			pv, ok := pvOfPath[node.Labels["kubernetes.io/hostname"]+":"+usage.Path]
+			if !ok {
+				continue
+			}
+			localcache = append(localcache, &VolumeUsage{
+				TenantID:   pv.Labels["tenant_id"],
+				AppID:      pv.Labels["app_id"],
+				ServiceID:  pv.Labels["service_id"],
+				VolumeName: pv.Labels["volume_name"],
+				PVName:     pv.Name,
+				Used:       usage.Used,
+				Capacity:   usage.Capacity,
+			})
+		}
+	}
+	d.lock.Lock()
+	d.localcache = localcache
+	d.lock.Unlock()
+	logrus.Infof("end get all local volume usage,time consum %2.f s", time.Since(start).Seconds())
+}
+
+func hostnameOfPV(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == "kubernetes.io/hostname" && len(expression.Values) > 0 {
+				return expression.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+var usageClient = &http.Client{Timeout: time.Minute * 2}
+
+//localVolumeUsages requests the node agent for the usage of the local volumes
+func localVolumeUsages(ip string, paths []string) ([]*localvolume.Usage, error) {
+	body, err := json.Marshal(map[string][]string{"paths": paths})
+	if err != nil {
+		return nil, err
+	}
+	res, err := usageClient.Post(fmt.Sprintf("http://%s:6100/v2/localvolumes/usage", ip), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("code: %d", res.StatusCode)
+	}
+	var result struct {
+		List []*localvolume.Usage `json:"list"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parse body: %v", err)
+	}
+	return result.List, nil
+}
+
+//GetLocalVolumes returns the usage of the local volumes
+func (d *DiskCache) GetLocalVolumes() []*VolumeUsage {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.localcache
+}
+
 //Get to obtain disk statistics
 func (d *DiskCache) Get() map[string]float64 {
 	newcache := make(map[string]float64)
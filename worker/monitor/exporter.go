@@ -90,6 +90,18 @@ func (t *ExporterManager) Start() error {
 		}
 		httputil.ReturnSuccess(r, w, healthStatus)
 	})
+	http.HandleFunc("/worker/drift", func(w http.ResponseWriter, r *http.Request) {
+		if !t.masterController.IsLeader() {
+			httputil.ReturnError(r, w, 400, "drift report is only available on the leader worker")
+			return
+		}
+		report := t.masterController.DriftReport()
+		if report == nil {
+			httputil.ReturnError(r, w, 404, "drift check has not run yet")
+			return
+		}
+		httputil.ReturnSuccess(r, w, report)
+	})
 	log.Infoln("Listening on", t.config.Listen)
 	go func() {
 		log.Fatal(http.ListenAndServe(t.config.Listen, nil))